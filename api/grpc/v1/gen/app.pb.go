@@ -57,15 +57,484 @@ func (*Empty) Descriptor() ([]byte, []int) {
 	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{0}
 }
 
+type Role struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Permissions   []string               `protobuf:"bytes,4,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Role) Reset() {
+	*x = Role{}
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Role) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Role) ProtoMessage() {}
+
+func (x *Role) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Role.ProtoReflect.Descriptor instead.
+func (*Role) Descriptor() ([]byte, []int) {
+	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Role) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Role) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Role) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Role) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+type UserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uid           string                 `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserRequest) Reset() {
+	*x = UserRequest{}
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserRequest) ProtoMessage() {}
+
+func (x *UserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserRequest.ProtoReflect.Descriptor instead.
+func (*UserRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UserRequest) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+type RolesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roles         []*Role                `protobuf:"bytes,1,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolesResponse) Reset() {
+	*x = RolesResponse{}
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolesResponse) ProtoMessage() {}
+
+func (x *RolesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolesResponse.ProtoReflect.Descriptor instead.
+func (*RolesResponse) Descriptor() ([]byte, []int) {
+	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RolesResponse) GetRoles() []*Role {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type RoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uid           string                 `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoleRequest) Reset() {
+	*x = RoleRequest{}
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoleRequest) ProtoMessage() {}
+
+func (x *RoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoleRequest.ProtoReflect.Descriptor instead.
+func (*RoleRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RoleRequest) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *RoleRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type DeviceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceRequest) Reset() {
+	*x = DeviceRequest{}
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceRequest) ProtoMessage() {}
+
+func (x *DeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceRequest.ProtoReflect.Descriptor instead.
+func (*DeviceRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeviceRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type TokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	TokenTypeHint string                 `protobuf:"bytes,2,opt,name=token_type_hint,json=tokenTypeHint,proto3" json:"token_type_hint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenRequest) Reset() {
+	*x = TokenRequest{}
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenRequest) ProtoMessage() {}
+
+func (x *TokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenRequest.ProtoReflect.Descriptor instead.
+func (*TokenRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *TokenRequest) GetTokenTypeHint() string {
+	if x != nil {
+		return x.TokenTypeHint
+	}
+	return ""
+}
+
+type IntrospectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Active        bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	Scope         string                 `protobuf:"bytes,2,opt,name=scope,proto3" json:"scope,omitempty"`
+	ClientId      string                 `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	TokenType     string                 `protobuf:"bytes,4,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
+	Exp           int64                  `protobuf:"varint,5,opt,name=exp,proto3" json:"exp,omitempty"`
+	Iat           int64                  `protobuf:"varint,6,opt,name=iat,proto3" json:"iat,omitempty"`
+	Sub           string                 `protobuf:"bytes,7,opt,name=sub,proto3" json:"sub,omitempty"`
+	Iss           string                 `protobuf:"bytes,8,opt,name=iss,proto3" json:"iss,omitempty"`
+	Jti           string                 `protobuf:"bytes,9,opt,name=jti,proto3" json:"jti,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,10,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectResponse) Reset() {
+	*x = IntrospectResponse{}
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectResponse) ProtoMessage() {}
+
+func (x *IntrospectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_v1_gen_app_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectResponse.ProtoReflect.Descriptor instead.
+func (*IntrospectResponse) Descriptor() ([]byte, []int) {
+	return file_api_grpc_v1_gen_app_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *IntrospectResponse) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *IntrospectResponse) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *IntrospectResponse) GetIat() int64 {
+	if x != nil {
+		return x.Iat
+	}
+	return 0
+}
+
+func (x *IntrospectResponse) GetSub() string {
+	if x != nil {
+		return x.Sub
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetIss() string {
+	if x != nil {
+		return x.Iss
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *IntrospectResponse) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
 var File_api_grpc_v1_gen_app_proto protoreflect.FileDescriptor
 
 const file_api_grpc_v1_gen_app_proto_rawDesc = "" +
 	"\n" +
 	"\x19api/grpc/v1/gen/app.proto\x12\x03gen\"\a\n" +
-	"\x05Empty2*\n" +
+	"\x05Empty\"n\n" +
+	"\x04Role\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12 \n" +
+	"\vpermissions\x18\x04 \x03(\tR\vpermissions\"\x1f\n" +
+	"\vUserRequest\x12\x10\n" +
+	"\x03uid\x18\x01 \x01(\tR\x03uid\"0\n" +
+	"\rRolesResponse\x12\x1f\n" +
+	"\x05roles\x18\x01 \x03(\v2\t.gen.RoleR\x05roles\"3\n" +
+	"\vRoleRequest\x12\x10\n" +
+	"\x03uid\x18\x01 \x01(\tR\x03uid\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\",\n" +
+	"\rDeviceRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"L\n" +
+	"\fTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12&\n" +
+	"\x0ftoken_type_hint\x18\x02 \x01(\tR\rtokenTypeHint\"\xf5\x01\n" +
+	"\x12IntrospectResponse\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x14\n" +
+	"\x05scope\x18\x02 \x01(\tR\x05scope\x12\x1b\n" +
+	"\tclient_id\x18\x03 \x01(\tR\bclientId\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x04 \x01(\tR\ttokenType\x12\x10\n" +
+	"\x03exp\x18\x05 \x01(\x03R\x03exp\x12\x10\n" +
+	"\x03iat\x18\x06 \x01(\x03R\x03iat\x12\x10\n" +
+	"\x03sub\x18\a \x01(\tR\x03sub\x12\x10\n" +
+	"\x03iss\x18\b \x01(\tR\x03iss\x12\x10\n" +
+	"\x03jti\x18\t \x01(\tR\x03jti\x12\x1b\n" +
+	"\tdevice_id\x18\n" +
+	" \x01(\tR\bdeviceId2\xd5\x03\n" +
 	"\x03App\x12#\n" +
 	"\tProcedure\x12\n" +
 	".gen.Empty\x1a\n" +
+	".gen.Empty\x125\n" +
+	"\rListUserRoles\x12\x10.gen.UserRequest\x1a\x12.gen.RolesResponse\x12*\n" +
+	"\n" +
+	"AssignRole\x12\x10.gen.RoleRequest\x1a\n" +
+	".gen.Empty\x12*\n" +
+	"\n" +
+	"RevokeRole\x12\x10.gen.RoleRequest\x1a\n" +
+	".gen.Empty\x12(\n" +
+	"\x06Logout\x12\x12.gen.DeviceRequest\x1a\n" +
+	".gen.Empty\x12#\n" +
+	"\tLogoutAll\x12\n" +
+	".gen.Empty\x1a\n" +
+	".gen.Empty\x12.\n" +
+	"\fLogoutOthers\x12\x12.gen.DeviceRequest\x1a\n" +
+	".gen.Empty\x12.\n" +
+	"\fDeleteDevice\x12\x12.gen.DeviceRequest\x1a\n" +
+	".gen.Empty\x12=\n" +
+	"\x0fIntrospectToken\x12\x11.gen.TokenRequest\x1a\x17.gen.IntrospectResponse\x12,\n" +
+	"\vRevokeToken\x12\x11.gen.TokenRequest\x1a\n" +
 	".gen.EmptyB4Z2github.com/JMURv/go-clean-template/api/grpc/v1/genb\x06proto3"
 
 var (
@@ -80,18 +549,44 @@ func file_api_grpc_v1_gen_app_proto_rawDescGZIP() []byte {
 	return file_api_grpc_v1_gen_app_proto_rawDescData
 }
 
-var file_api_grpc_v1_gen_app_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_grpc_v1_gen_app_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_api_grpc_v1_gen_app_proto_goTypes = []any{
-	(*Empty)(nil), // 0: gen.Empty
+	(*Empty)(nil),              // 0: gen.Empty
+	(*Role)(nil),               // 1: gen.Role
+	(*UserRequest)(nil),        // 2: gen.UserRequest
+	(*RolesResponse)(nil),      // 3: gen.RolesResponse
+	(*RoleRequest)(nil),        // 4: gen.RoleRequest
+	(*DeviceRequest)(nil),      // 5: gen.DeviceRequest
+	(*TokenRequest)(nil),       // 6: gen.TokenRequest
+	(*IntrospectResponse)(nil), // 7: gen.IntrospectResponse
 }
 var file_api_grpc_v1_gen_app_proto_depIdxs = []int32{
-	0, // 0: gen.App.Procedure:input_type -> gen.Empty
-	0, // 1: gen.App.Procedure:output_type -> gen.Empty
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: gen.RolesResponse.roles:type_name -> gen.Role
+	0,  // 1: gen.App.Procedure:input_type -> gen.Empty
+	2,  // 2: gen.App.ListUserRoles:input_type -> gen.UserRequest
+	4,  // 3: gen.App.AssignRole:input_type -> gen.RoleRequest
+	4,  // 4: gen.App.RevokeRole:input_type -> gen.RoleRequest
+	5,  // 5: gen.App.Logout:input_type -> gen.DeviceRequest
+	0,  // 6: gen.App.LogoutAll:input_type -> gen.Empty
+	5,  // 7: gen.App.LogoutOthers:input_type -> gen.DeviceRequest
+	5,  // 8: gen.App.DeleteDevice:input_type -> gen.DeviceRequest
+	6,  // 9: gen.App.IntrospectToken:input_type -> gen.TokenRequest
+	6,  // 10: gen.App.RevokeToken:input_type -> gen.TokenRequest
+	0,  // 11: gen.App.Procedure:output_type -> gen.Empty
+	3,  // 12: gen.App.ListUserRoles:output_type -> gen.RolesResponse
+	0,  // 13: gen.App.AssignRole:output_type -> gen.Empty
+	0,  // 14: gen.App.RevokeRole:output_type -> gen.Empty
+	0,  // 15: gen.App.Logout:output_type -> gen.Empty
+	0,  // 16: gen.App.LogoutAll:output_type -> gen.Empty
+	0,  // 17: gen.App.LogoutOthers:output_type -> gen.Empty
+	0,  // 18: gen.App.DeleteDevice:output_type -> gen.Empty
+	7,  // 19: gen.App.IntrospectToken:output_type -> gen.IntrospectResponse
+	0,  // 20: gen.App.RevokeToken:output_type -> gen.Empty
+	11, // [11:21] is the sub-list for method output_type
+	1,  // [1:11] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_api_grpc_v1_gen_app_proto_init() }
@@ -105,7 +600,7 @@ func file_api_grpc_v1_gen_app_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_grpc_v1_gen_app_proto_rawDesc), len(file_api_grpc_v1_gen_app_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -15,9 +15,49 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/jwt": {
-            "post": {
-                "description": "Verify reCAPTCHA, then authenticate and set JWT cookies",
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Returns public keys used to verify access tokens, including keys pending retirement. Empty when tokens are signed with HS256",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_jwt.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/.well-known/openid-configuration": {
+            "get": {
+                "description": "Discovery document for OpenID Connect clients. Endpoints are relative to OIDC_ISSUER. ID tokens are signed with the JWT keys published at jwks_uri, so clients need an asymmetric JWT_ALG to verify them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "OpenID Provider metadata",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.OpenIDConfiguration"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/totp": {
+            "delete": {
+                "description": "Turn 2FA off with an authenticator code or a recovery code",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,58 +65,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Two-factor"
                 ],
-                "summary": "Authenticate using email \u0026 password",
+                "summary": "Disable TOTP",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Client real IP address",
-                        "name": "X-Real-IP",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Client User-Agent",
-                        "name": "User-Agent",
+                        "description": "Authorization token",
+                        "name": "Authorization",
                         "in": "header",
                         "required": true
                     },
                     {
-                        "description": "Login credentials",
+                        "description": "Authenticator or recovery code",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.EmailAndPasswordRequest"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.SecondFactorRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully authenticated (sets cookies)"
+                    "204": {
+                        "description": "2FA disabled"
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "invalid payload",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "invalid code",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "2FA is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many invalid codes",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "internal error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
@@ -84,9 +123,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/jwt/refresh": {
+        "/auth/2fa/totp/confirm": {
             "post": {
-                "description": "Validate refresh token from cookie and issue new tokens",
+                "description": "Enable 2FA with the first authenticator code and return the one-time recovery codes. They are only shown once",
                 "consumes": [
                     "application/json"
                 ],
@@ -94,81 +133,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Two-factor"
                 ],
-                "summary": "Refresh JWT tokens",
+                "summary": "Confirm TOTP enrollment",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Client real IP address",
-                        "name": "X-Real-IP",
+                        "description": "Authorization token",
+                        "name": "Authorization",
                         "in": "header",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Client User-Agent",
-                        "name": "User-Agent",
-                        "in": "header",
-                        "required": true
+                        "description": "Authenticator code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TOTPCodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully refreshed tokens (sets cookies)"
+                        "description": "Recovery codes",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.RecoveryCodesResponse"
+                        }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "invalid payload",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "invalid code",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "no pending enrollment",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "2FA is already enabled",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "description": "Revoke refresh token, clear JWT cookies",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Authentication"
-                ],
-                "summary": "Logout user",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Authorization token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Revoked refresh token, cleared cookies"
                     },
-                    "404": {
-                        "description": "session not found",
+                    "429": {
+                        "description": "too many invalid codes",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
@@ -182,16 +200,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/device": {
-            "get": {
-                "description": "Retrieve a list of registered devices for the current user",
+        "/auth/2fa/totp/enroll": {
+            "post": {
+                "description": "Generate a new authenticator secret and its otpauth URI. 2FA is enabled once the first code is confirmed",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Device"
+                    "Two-factor"
                 ],
-                "summary": "List all devices for the authenticated user",
+                "summary": "Start TOTP enrollment",
                 "parameters": [
                     {
                         "type": "string",
@@ -203,19 +221,19 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Secret and otpauth URI",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.Device"
-                                }
-                            }
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TOTPEnrollResponse"
                         }
                     },
                     "404": {
-                        "description": "no devices found",
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA is already enabled",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
@@ -229,61 +247,92 @@ const docTemplate = `{
                 }
             }
         },
-        "/device/{id}": {
-            "get": {
-                "description": "Retrieve details of a device owned by the current user",
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Exchange the login challenge and an authenticator or recovery code for JWT cookies, or return them with X-Auth-Mode: token. The challenge is bound to the device that started the login",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Device"
+                    "Two-factor"
                 ],
-                "summary": "Get a specific device by ID",
+                "summary": "Complete a 2FA login",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
                     },
                     {
                         "type": "string",
-                        "description": "Authorization token",
-                        "name": "Authorization",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 'token' to receive tokens in the body",
+                        "name": "X-Auth-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Challenge and code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.VerifyTwoFactorRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Successfully authenticated (sets cookies unless X-Auth-Mode: token)",
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.Device"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TokenPair"
                         }
                     },
                     "400": {
-                        "description": "invalid device ID path parameter",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "404": {
-                        "description": "device not found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Modify properties of a device owned by the current user",
+            }
+        },
+        "/auth/code": {
+            "post": {
+                "description": "Verify reCAPTCHA and credentials, then email a one-time login code. Failed attempts count towards the same delays and lockouts as /auth/jwt",
                 "consumes": [
                     "application/json"
                 ],
@@ -291,101 +340,75 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Device"
+                    "Authentication"
                 ],
-                "summary": "Update a device",
+                "summary": "Request a login code",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device UUID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "description": "Update payload",
+                        "description": "Login credentials",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.UpdateDeviceRequest"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.LoginCodeRequest"
                         }
-                    },
-                    {
-                        "type": "string",
-                        "description": "Authorization token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "Login code sent"
                     },
                     "400": {
-                        "description": "invalid device ID or payload",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "404": {
-                        "description": "device not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "500": {
-                        "description": "internal error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Remove a device owned by the current user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Device"
-                ],
-                "summary": "Delete a device",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Device UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
                     },
-                    {
-                        "type": "string",
-                        "description": "Authorization token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
                     },
-                    "400": {
-                        "description": "invalid device ID",
+                    "423": {
+                        "description": "Locked",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "404": {
-                        "description": "device not found",
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
@@ -393,9 +416,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
-            "get": {
-                "description": "Retrieve a paginated list of users with optional filters",
+        "/auth/code/check": {
+            "post": {
+                "description": "Exchange a one-time login code for JWT cookies. Users with 2FA enabled get a challenge for /auth/2fa/verify instead",
                 "consumes": [
                     "application/json"
                 ],
@@ -403,91 +426,78 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Authentication"
                 ],
-                "summary": "List all users",
+                "summary": "Authenticate using a login code",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
                     },
                     {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 'token' to receive tokens in the body",
+                        "name": "X-Auth-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Email and login code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CheckLoginCodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Successfully authenticated (sets cookies unless X-Auth-Mode: token)",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.PaginatedUserResponse"
-                            }
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TokenPair"
                         }
                     },
-                    "500": {
-                        "description": "internal error",
+                    "202": {
+                        "description": "Second factor required",
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TwoFactorChallenge"
                         }
-                    }
-                }
-            },
-            "post": {
-                "description": "Creates a user with optional avatar upload",
-                "consumes": [
-                    "multipart/form-data"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "User"
-                ],
-                "summary": "Create a new user",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "JSON payload in 'data' field",
-                        "name": "data",
-                        "in": "formData",
-                        "required": true
                     },
-                    {
-                        "type": "file",
-                        "description": "Avatar image file",
-                        "name": "avatar",
-                        "in": "formData"
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CreateUserResponse"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "400": {
-                        "description": "bad request or file too large",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "409": {
-                        "description": "user already exists",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
@@ -495,9 +505,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/exists": {
+        "/auth/email/resend": {
             "post": {
-                "description": "Returns 200 if user exists, 404 otherwise",
+                "description": "Verify reCAPTCHA, then email a new verification code if the account exists and is not verified",
                 "consumes": [
                     "application/json"
                 ],
@@ -505,35 +515,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Authentication"
                 ],
-                "summary": "Check if a user exists by email",
+                "summary": "Resend email verification",
                 "parameters": [
                     {
-                        "description": "Email payload",
+                        "description": "Account email",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CheckEmailRequest"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.SendVerificationEmailRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Verification email sent (if the account exists)"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.ExistsUserResponse"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
-                    "404": {
-                        "description": "user not found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
@@ -541,37 +560,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/me": {
-            "get": {
-                "description": "Returns the authenticated user's profile",
+        "/auth/email/verify": {
+            "post": {
+                "description": "Check the verification code and mark the user's email as verified",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Authentication"
+                ],
+                "summary": "Verify email",
+                "parameters": [
+                    {
+                        "description": "Verification code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.VerifyEmailRequest"
+                        }
+                    }
                 ],
-                "summary": "Retrieve current user profile",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Email verified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.User"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "401": {
-                        "description": "unauthorized",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "404": {
-                        "description": "user not found",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
@@ -579,9 +615,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
-                "description": "Retrieve a user by their UUID",
+        "/auth/jwt": {
+            "post": {
+                "description": "Verify reCAPTCHA, then authenticate and set JWT cookies, or return them with X-Auth-Mode: token. With remember the session uses the long idle and absolute timeouts and the refresh cookie outlives the browser session. Users with 2FA enabled get a challenge for /auth/2fa/verify instead. Failed attempts delay the next one exponentially (429) and lock the account after AUTH_LOCKOUT_THRESHOLD of them (423)",
                 "consumes": [
                     "application/json"
                 ],
@@ -589,145 +625,464 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Authentication"
                 ],
-                "summary": "Get user by ID",
+                "summary": "Authenticate using email \u0026 password",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User UUID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 'token' to receive tokens in the body",
+                        "name": "X-Auth-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Login credentials",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.EmailAndPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Successfully authenticated (sets cookies unless X-Auth-Mode: token)",
                         "schema": {
-                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.User"
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TokenPair"
+                        }
+                    },
+                    "202": {
+                        "description": "Second factor required",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TwoFactorChallenge"
                         }
                     },
                     "400": {
-                        "description": "invalid UUID",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "404": {
-                        "description": "user not found",
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Updates user profile and avatar",
+            }
+        },
+        "/auth/jwt/refresh": {
+            "post": {
+                "description": "Validate refresh token from cookie and issue new tokens. The presented token is rotated; presenting it again revokes its device session. With X-Auth-Mode: token the refresh token is read from and the pair returned in the body",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Authentication"
                 ],
-                "summary": "Update an existing user",
+                "summary": "Refresh JWT tokens",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User UUID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
                     },
                     {
                         "type": "string",
-                        "description": "JSON payload in 'data' field",
-                        "name": "data",
-                        "in": "formData",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Avatar image file",
-                        "name": "avatar",
-                        "in": "formData"
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 'token' to use the body instead of cookies",
+                        "name": "X-Auth-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Refresh token (token mode only)",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.RefreshRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "Successfully refreshed tokens (sets cookies unless X-Auth-Mode: token)",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TokenPair"
+                        }
                     },
                     "400": {
-                        "description": "bad request",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "401": {
-                        "description": "unauthorized",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "404": {
-                        "description": "user not found",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "500": {
-                        "description": "internal error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Removes a user by UUID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revoke the refresh token of the current device and the access token in use, clear JWT cookies. Other devices stay signed in",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Authentication"
                 ],
-                "summary": "Delete a user",
+                "summary": "Logout user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User UUID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "Revoked refresh token, cleared cookies"
+                    },
+                    "400": {
+                        "description": "no device info",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout/all": {
+            "post": {
+                "description": "Revoke refresh and access tokens of every device and clear JWT cookies",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout everywhere",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revoked refresh tokens, cleared cookies"
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout/others": {
+            "post": {
+                "description": "Revoke refresh tokens of every device except the current one. Access tokens of all devices are revoked, so the current device has to refresh",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout other devices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revoked refresh tokens of other devices"
+                    },
+                    "400": {
+                        "description": "no device info",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/recovery": {
+            "put": {
+                "description": "Check the recovery code, set a new password and revoke all refresh tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Confirm password recovery",
+                "parameters": [
+                    {
+                        "description": "Recovery code and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CheckForgotPasswordEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password updated"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
                     },
                     "401": {
-                        "description": "unauthorized",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
                     },
                     "404": {
-                        "description": "user not found",
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Verify reCAPTCHA, then email a single-use recovery code if the account exists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Request password recovery",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.SendForgotPasswordEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Recovery email sent (if the account exists)"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/auth/webauthn/credentials": {
+            "get": {
+                "description": "Retrieve passkeys registered by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WebAuthn"
+                ],
+                "summary": "List passkeys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.WebAuthnCredential"
+                                }
+                            }
+                        }
                     },
                     "500": {
                         "description": "internal error",
@@ -737,40 +1092,3456 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_JMURv_golang-clean-template_internal_dto.CheckEmailRequest": {
+        },
+        "/auth/webauthn/credentials/{id}": {
+            "delete": {
+                "description": "Remove a passkey of the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WebAuthn"
+                ],
+                "summary": "Delete a passkey",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Credential ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid credential ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "passkey not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/webauthn/login/begin": {
+            "post": {
+                "description": "Return options for navigator.credentials.get. The options allow any discoverable passkey for the site and don't depend on the email, which only restricts the account that can finish the login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WebAuthn"
+                ],
+                "summary": "Start passkey login",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.WebAuthnLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Credential request options",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.WebAuthnRequestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/webauthn/login/finish": {
+            "post": {
+                "description": "Verify the assertion and set JWT cookies, or return them with X-Auth-Mode: token. A passkey verifies the user, so no second factor is asked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WebAuthn"
+                ],
+                "summary": "Finish passkey login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 'token' to receive tokens in the body",
+                        "name": "X-Auth-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Assertion",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.FinishWebAuthnLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully authenticated (sets cookies unless X-Auth-Mode: token)",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.TokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/webauthn/register/begin": {
+            "post": {
+                "description": "Return options for navigator.credentials.create. Passkeys the user already has are excluded",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WebAuthn"
+                ],
+                "summary": "Start passkey registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Credential creation options",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.WebAuthnCreationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/webauthn/register/finish": {
+            "post": {
+                "description": "Verify the attestation and store the passkey. It is linked to the current device if the device is known",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WebAuthn"
+                ],
+                "summary": "Finish passkey registration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client IP, only honoured from trusted proxies",
+                        "name": "X-Real-IP",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stable device ID, the signed device cookie is used otherwise",
+                        "name": "X-Device-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Passkey name and attestation",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.WebAuthnRegistrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Registered passkey",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.WebAuthnCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid challenge or credential",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "passkey already registered",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/device": {
+            "get": {
+                "description": "Retrieve a list of registered devices for the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Device"
+                ],
+                "summary": "List all devices for the authenticated user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.Device"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "no devices found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/{id}": {
+            "get": {
+                "description": "Retrieve details of a device owned by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Device"
+                ],
+                "summary": "Get a specific device by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.Device"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid device ID path parameter",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "device not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Modify properties of a device owned by the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Device"
+                ],
+                "summary": "Update a device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.UpdateDeviceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "invalid device ID or payload",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "device not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a device owned by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Device"
+                ],
+                "summary": "Delete a device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid device ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "device not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/rotate": {
+            "post": {
+                "description": "Generates a new signing key. Tokens signed with the previous key stay valid until it retires. Requires keys:rotate",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Rotate signing key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.RotateKeyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "description": "Validates an authorization code request of the logged in user. If the user already granted the scopes, redirects back to the client with a code. Otherwise returns the consent to ask for, which is answered with POST /oauth/authorize. An unknown client or redirect URI is never redirected to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "OAuth authorization endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be 'code'",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered redirect URI, optional if the client has only one",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space delimited scopes, all scopes of the client by default",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value returned to the client",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Must be 'S256'",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to 'consent' to ask again",
+                        "name": "prompt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Value copied into the ID token of openid requests",
+                        "name": "nonce",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Consent required",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.AuthorizeResponse"
+                        }
+                    },
+                    "302": {
+                        "description": "Redirect to the client"
+                    },
+                    "400": {
+                        "description": "unknown client or redirect URI",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Repeats the authorization request with the user's decision. Returns the URL to send the user agent to, with a code or access_denied",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Answer an OAuth consent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Authorization request and decision",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.ConsentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Redirect URL",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.AuthorizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "unknown client or redirect URI",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/clients": {
+            "get": {
+                "description": "Returns registered clients without their secrets. Requires oauth:clients",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "List OAuth clients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.OAuthClient"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Public clients get no secret and must use PKCE. The secret of a confidential client is only returned here. Requires oauth:clients",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Register an OAuth client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Client settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CreateOAuthClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CreateOAuthClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/clients/{id}": {
+            "delete": {
+                "description": "Removes the client with its consents and refresh tokens. Requires oauth:clients",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Delete an OAuth client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid client ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "client not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/device": {
+            "get": {
+                "description": "Returns the client and scopes behind the user code shown on a device, to ask the logged in user for approval. Each lookup counts towards a per-user limit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Look up a device authorization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User code shown on the device",
+                        "name": "user_code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.DeviceConsentResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "unknown or expired user code",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many user code checks",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Approves or denies the device authorization of the user code on behalf of the logged in user. The device receives its tokens on its next poll",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Answer a device authorization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "User code and decision",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.DeviceApprovalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "unknown or expired user code",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "too many user code checks",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/device/code": {
+            "post": {
+                "description": "Starts the device authorization grant for clients without a browser. The device shows user_code and verification_uri, then polls POST /oauth/token with the device_code every interval seconds. Clients authenticate like on the token endpoint",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "OAuth device authorization endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Space delimited scopes",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.DeviceCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid client",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "description": "Describes an access or refresh token (RFC 7662). Only confidential clients may introspect, authenticating like on the token endpoint. Revoked, rotated, expired and unknown tokens are reported as {\"active\": false}",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "OAuth token introspection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token to describe",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token or refresh_token, ignored",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.IntrospectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid or public client",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/logout": {
+            "get": {
+                "description": "Ends the session of the user named by id_token_hint on this device and clears JWT cookies. Redirects to post_logout_redirect_uri, which must be a redirect URI of the client, with state. Parameters can also be sent as a form",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "RP-initiated logout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID token issued to the client",
+                        "name": "id_token_hint",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, must match the ID token audience",
+                        "name": "client_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Where to send the user agent afterwards",
+                        "name": "post_logout_redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value returned to the client",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client User-Agent",
+                        "name": "User-Agent",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logged out"
+                    },
+                    "302": {
+                        "description": "Redirect to the client"
+                    },
+                    "400": {
+                        "description": "invalid hint, client or redirect URI",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "description": "Revokes an access or refresh token issued to the client (RFC 7009). Clients authenticate like on the token endpoint. Unknown and already inactive tokens are accepted",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "OAuth token revocation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token to revoke",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token or refresh_token, ignored",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Revoked"
+                    },
+                    "400": {
+                        "description": "missing token or token of another client",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid client",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "Issues tokens for the authorization_code (with PKCE), refresh_token, client_credentials and device_code grants. Confidential clients authenticate with HTTP Basic or client_secret in the form. Refresh tokens are rotated on use. An ID token is included when the openid scope is granted",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "OAuth token endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization_code, refresh_token, client_credentials or urn:ietf:params:oauth:grant-type:device_code",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Redirect URI of the authorization request, if it had one",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device code",
+                        "name": "device_code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space delimited scopes",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.OAuthTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid client",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_oauth.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "description": "Returns all roles with their permissions. Requires roles:read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts": {
+            "get": {
+                "description": "Requires service-accounts:manage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service accounts"
+                ],
+                "summary": "List service accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.ServiceAccount"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Service accounts are principals for machine clients. They authenticate with API keys instead of a password. Requires service-accounts:manage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service accounts"
+                ],
+                "summary": "Create a service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Service account",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CreateServiceAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.ServiceAccount"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "name is taken",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}": {
+            "delete": {
+                "description": "Removes the service account with its API keys. Requires service-accounts:manage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service accounts"
+                ],
+                "summary": "Delete a service account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid service account ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "service account not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}/keys": {
+            "get": {
+                "description": "Returns the keys of the service account with their prefix and last use, never the keys themselves. Requires service-accounts:manage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service accounts"
+                ],
+                "summary": "List API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.APIKey"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid service account ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Issues a named key of the service account. The key is only returned here. Scopes are permissions the key grants and must be held by the caller. Send the key as a bearer token. Requires service-accounts:manage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service accounts"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "API key settings",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CreateAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid payload or expiry",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "service account not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "name is taken",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/service-accounts/{id}/keys/{keyID}": {
+            "delete": {
+                "description": "Deletes the key, requests using it are rejected right away. Requires service-accounts:manage",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Service accounts"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "api key not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/userinfo": {
+            "get": {
+                "description": "Returns claims about the user the access token was issued for. Client tokens need the openid scope, email and profile add their claims",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OpenID Connect"
+                ],
+                "summary": "OpenID Connect userinfo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.UserInfoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "insufficient scope",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieve a paginated list of users with optional filters. Requires users:list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "List all users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.PaginatedUserResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a user with optional avatar upload",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JSON payload in 'data' field",
+                        "name": "data",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar image file",
+                        "name": "avatar",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CreateUserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "bad request or file too large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "user already exists",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/exists": {
+            "post": {
+                "description": "Returns 200 if user exists, 404 otherwise. Limited per client IP, so emails can't be enumerated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Check if a user exists by email",
+                "parameters": [
+                    {
+                        "description": "Email payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.CheckEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.ExistsUserResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "description": "Returns the authenticated user's profile. OAuth clients need the profile scope",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Retrieve current user profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.User"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Retrieve a user by their UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid UUID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates user profile and avatar. A changed email becomes unverified and gets a verification code. Other users require users:update",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update an existing user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON payload in 'data' field",
+                        "name": "data",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar image file",
+                        "name": "avatar",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "bad request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a user by UUID. Other users require users:delete",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/devices": {
+            "get": {
+                "description": "Retrieve devices registered by any user. Requires devices:read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Device"
+                ],
+                "summary": "List devices of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "array",
+                                "items": {
+                                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.Device"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid UUID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/devices/{device}": {
+            "delete": {
+                "description": "Remove a device of any user and revoke its tokens. Requires devices:delete",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Device"
+                ],
+                "summary": "Delete a device of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "device",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid UUID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "device not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles": {
+            "get": {
+                "description": "Returns roles assigned to the user. Requires roles:read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "List user roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Grants a role to the user. Requires roles:assign",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Assign role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role name",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_dto.AssignRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user or role not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles/{role}": {
+            "delete": {
+                "description": "Removes a role from the user. Requires roles:assign",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Revoke role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid UUID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "role is not assigned",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "description": "Lifts the lockout applied after too many failed logins and forgets the failures. Requires users:update",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Unlock a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_JMURv_golang-clean-template_internal_auth_jwt.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_jwt.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_jwt.JWK"
+                    }
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_oauth.Error": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "error_description": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.AssertionResponse": {
+            "type": "object",
+            "required": [
+                "authenticatorData",
+                "clientDataJSON",
+                "signature"
+            ],
+            "properties": {
+                "authenticatorData": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "clientDataJSON": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "signature": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "userHandle": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.AttestationResponse": {
+            "type": "object",
+            "required": [
+                "attestationObject",
+                "clientDataJSON"
+            ],
+            "properties": {
+                "attestationObject": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "clientDataJSON": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.AuthenticatorSelection": {
+            "type": "object",
+            "properties": {
+                "residentKey": {
+                    "type": "string"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.CreationOptions": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "type": "string"
+                },
+                "authenticatorSelection": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.AuthenticatorSelection"
+                },
+                "challenge": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "excludeCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.CredentialDescriptor"
+                    }
+                },
+                "pubKeyCredParams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.CredentialParameter"
+                    }
+                },
+                "rp": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.RelyingParty"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.User"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.CredentialDescriptor": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "transports": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.CredentialParameter": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.LoginResponse": {
+            "type": "object",
+            "required": [
+                "id",
+                "response",
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "response": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.AssertionResponse"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.RegistrationResponse": {
+            "type": "object",
+            "required": [
+                "id",
+                "response",
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "response": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.AttestationResponse"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.RelyingParty": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.RequestOptions": {
+            "type": "object",
+            "properties": {
+                "allowCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.CredentialDescriptor"
+                    }
+                },
+                "challenge": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "rpId": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "userVerification": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_auth_webauthn.User": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.AssignRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.AuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "type": "string"
+                },
+                "clientName": {
+                    "type": "string"
+                },
+                "redirectTo": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CheckEmailRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CheckForgotPasswordEmailRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token",
+                "uidb64"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "integer"
+                },
+                "uidb64": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CheckLoginCodeRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "email"
+            ],
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "remember": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.ConsentRequest": {
+            "type": "object",
+            "properties": {
+                "approve": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "code_challenge": {
+                    "type": "string"
+                },
+                "code_challenge_method": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "type": "string"
+                },
+                "prompt": {
+                    "type": "string"
+                },
+                "redirect_uri": {
+                    "type": "string"
+                },
+                "response_type": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.APIKey"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CreateOAuthClientRequest": {
+            "type": "object",
+            "required": [
+                "grantTypes",
+                "name",
+                "redirectUris",
+                "scopes"
+            ],
+            "properties": {
+                "grantTypes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "redirectUris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CreateOAuthClientResponse": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.OAuthClient"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CreateServiceAccountRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.CreateUserResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.DeviceApprovalRequest": {
+            "type": "object",
+            "required": [
+                "userCode"
+            ],
+            "properties": {
+                "approve": {
+                    "type": "boolean"
+                },
+                "userCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.DeviceCodeResponse": {
+            "type": "object",
+            "properties": {
+                "device_code": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "integer"
+                },
+                "user_code": {
+                    "type": "string"
+                },
+                "verification_uri": {
+                    "type": "string"
+                },
+                "verification_uri_complete": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.DeviceConsentResponse": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "type": "string"
+                },
+                "clientName": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.EmailAndPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "token"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "remember": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.ExistsUserResponse": {
+            "type": "object",
+            "properties": {
+                "exists": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.FinishWebAuthnLoginRequest": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.LoginResponse"
+                },
+                "remember": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.IntrospectionResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "exp": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "iss": {
+                    "type": "string"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.LoginCodeRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "token"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.OAuthTokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "id_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.OpenIDConfiguration": {
+            "type": "object",
+            "properties": {
+                "authorization_endpoint": {
+                    "type": "string"
+                },
+                "claims_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code_challenge_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "device_authorization_endpoint": {
+                    "type": "string"
+                },
+                "end_session_endpoint": {
+                    "type": "string"
+                },
+                "grant_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id_token_signing_alg_values_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "introspection_endpoint": {
+                    "type": "string"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "jwks_uri": {
+                    "type": "string"
+                },
+                "response_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "revocation_endpoint": {
+                    "type": "string"
+                },
+                "scopes_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token_endpoint": {
+                    "type": "string"
+                },
+                "token_endpoint_auth_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userinfo_endpoint": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.PaginatedUserResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "currentPage": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.User"
+                    }
+                },
+                "hasNextPage": {
+                    "type": "boolean"
+                },
+                "totalPages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.RefreshRequest": {
             "type": "object",
             "required": [
-                "email"
+                "refresh"
             ],
             "properties": {
-                "email": {
+                "refresh": {
                     "type": "string"
                 }
             }
         },
-        "github_com_JMURv_golang-clean-template_internal_dto.CreateUserResponse": {
+        "github_com_JMURv_golang-clean-template_internal_dto.RotateKeyResponse": {
             "type": "object",
             "properties": {
-                "id": {
+                "kid": {
                     "type": "string"
                 }
             }
         },
-        "github_com_JMURv_golang-clean-template_internal_dto.EmailAndPasswordRequest": {
+        "github_com_JMURv_golang-clean-template_internal_dto.SecondFactorRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "recoveryCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.SendForgotPasswordEmail": {
             "type": "object",
             "required": [
                 "email",
-                "password",
                 "token"
             ],
             "properties": {
                 "email": {
                     "type": "string"
                 },
-                "password": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.SendVerificationEmailRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "token"
+            ],
+            "properties": {
+                "email": {
                     "type": "string"
                 },
                 "token": {
@@ -778,34 +4549,53 @@ const docTemplate = `{
                 }
             }
         },
-        "github_com_JMURv_golang-clean-template_internal_dto.ExistsUserResponse": {
+        "github_com_JMURv_golang-clean-template_internal_dto.TOTPCodeRequest": {
             "type": "object",
+            "required": [
+                "code"
+            ],
             "properties": {
-                "exists": {
-                    "type": "boolean"
+                "code": {
+                    "type": "string"
                 }
             }
         },
-        "github_com_JMURv_golang-clean-template_internal_dto.PaginatedUserResponse": {
+        "github_com_JMURv_golang-clean-template_internal_dto.TOTPEnrollResponse": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
+                "secret": {
+                    "type": "string"
                 },
-                "currentPage": {
-                    "type": "integer"
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.TokenPair": {
+            "type": "object",
+            "properties": {
+                "access": {
+                    "type": "string"
                 },
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_models.User"
-                    }
+                "deviceId": {
+                    "type": "string"
                 },
-                "hasNextPage": {
-                    "type": "boolean"
+                "expiresAt": {
+                    "type": "string"
                 },
-                "totalPages": {
-                    "type": "integer"
+                "refresh": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.TwoFactorChallenge": {
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
                 }
             }
         },
@@ -820,6 +4610,94 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_JMURv_golang-clean-template_internal_dto.UserInfoResponse": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token",
+                "uidb64"
+            ],
+            "properties": {
+                "token": {
+                    "type": "integer"
+                },
+                "uidb64": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.VerifyTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge"
+            ],
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "recoveryCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.WebAuthnCreationResponse": {
+            "type": "object",
+            "properties": {
+                "publicKey": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.CreationOptions"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.WebAuthnLoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.WebAuthnRegistrationRequest": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.RegistrationResponse"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_dto.WebAuthnRequestResponse": {
+            "type": "object",
+            "properties": {
+                "publicKey": {
+                    "$ref": "#/definitions/github_com_JMURv_golang-clean-template_internal_auth_webauthn.RequestOptions"
+                }
+            }
+        },
         "github_com_JMURv_golang-clean-template_internal_hdl_http_utils.ErrorsResponse": {
             "type": "object",
             "properties": {
@@ -831,6 +4709,35 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_JMURv_golang-clean-template_internal_models.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "string"
+                },
+                "serviceAccountId": {
+                    "type": "string"
+                }
+            }
+        },
         "github_com_JMURv_golang-clean-template_internal_models.Device": {
             "type": "object",
             "properties": {
@@ -866,6 +4773,69 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_JMURv_golang-clean-template_internal_models.OAuthClient": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "grantTypes": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "redirectUris": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_models.Role": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_JMURv_golang-clean-template_internal_models.ServiceAccount": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "github_com_JMURv_golang-clean-template_internal_models.User": {
             "type": "object",
             "properties": {
@@ -903,6 +4873,38 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "github_com_JMURv_golang-clean-template_internal_models.WebAuthnCredential": {
+            "type": "object",
+            "properties": {
+                "aaguid": {
+                    "type": "string"
+                },
+                "alg": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deviceId": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "transports": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
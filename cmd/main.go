@@ -15,6 +15,7 @@ import (
 	"github.com/JMURv/golang-clean-template/internal/hdl/http"
 	"github.com/JMURv/golang-clean-template/internal/observability/metrics/prometheus"
 	"github.com/JMURv/golang-clean-template/internal/observability/tracing/jaeger"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
 	"github.com/JMURv/golang-clean-template/internal/repo/db"
 	"github.com/JMURv/golang-clean-template/internal/repo/s3"
 	"github.com/JMURv/golang-clean-template/internal/smtp"
@@ -50,15 +51,19 @@ func main() {
 	go prom.Start()
 	go jaeger.Start(ctx, conf.ServiceName, conf)
 
-	au := auth.New(conf)
 	cache := redis.New(conf)
 	repo := db.New(conf)
-	svc := ctrl.New(au, repo, cache, s3.New(conf), smtp.New(conf))
-	h := http.New(au, svc)
-	hg := grpc.New(conf.ServiceName, svc, au)
+	au := auth.New(conf, cache, repo)
+	worker := smtp.NewWorker(conf, repo, smtp.NewSender(conf))
+	svc := ctrl.New(conf, au, repo, cache, s3.New(conf), smtp.NewMailer(conf, smtp.NewQueue(repo)))
+	limiter := ratelimit.New(conf, cache)
+	h := http.New(conf, au, svc, limiter)
+	hg := grpc.New(conf.ServiceName, svc, au, limiter)
 
 	go h.Start(conf.Server.Port)
 	go hg.Start(conf.Server.GRPCPort)
+	go worker.Start(ctx)
+	go au.WatchKeys(ctx)
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -76,6 +81,10 @@ func main() {
 		zap.L().Warn("Error closing grpc handler", zap.Error(err))
 	}
 
+	if err := worker.Close(sdCtx); err != nil {
+		zap.L().Warn("Error closing email queue worker", zap.Error(err))
+	}
+
 	if err := cache.Close(sdCtx); err != nil {
 		zap.L().Warn("Failed to close connection to cache", zap.Error(err))
 	}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+)
+
+// APIKeyPrefix starts every API key, so they can be told apart from JWTs.
+const APIKeyPrefix = "sk_"
+
+const (
+	apiKeyIDSize     = 4
+	apiKeySecretSize = 32
+)
+
+// APIKeys resolves an API key to the claims of the service account owning
+// it.
+type APIKeys interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (jwt.Claims, error)
+}
+
+// GenerateAPIKey returns a new API key and its visible prefix. The prefix
+// identifies the key in listings, only the hash of the whole key is stored.
+func GenerateAPIKey() (key, prefix string, err error) {
+	id := make([]byte, apiKeyIDSize)
+	if _, err = rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, apiKeySecretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// IsAPIKey reports whether token looks like an API key rather than a JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// APIKeyID returns the visible prefix of key, which identifies it without
+// revealing the secret.
+func APIKeyID(key string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	return APIKeyPrefix + id
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, IsAPIKey(key))
+	assert.True(t, strings.HasPrefix(key, prefix+"_"))
+	assert.Len(t, prefix, len(APIKeyPrefix)+8)
+
+	other, otherPrefix, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, prefix, otherPrefix)
+	assert.Equal(t, prefix, APIKeyID(key))
+}
+
+func TestIsAPIKey(t *testing.T) {
+	assert.True(t, IsAPIKey("sk_0123abcd_secret"))
+	assert.False(t, IsAPIKey("eyJhbGciOiJIUzI1NiJ9.e30.sig"))
+	assert.False(t, IsAPIKey(""))
+}
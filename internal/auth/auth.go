@@ -16,22 +16,46 @@ import (
 type Core interface {
 	Hash(ctx context.Context, pswd string) (string, error)
 	ComparePasswords(hashed, pswd []byte) error
+	RevokeToken(ctx context.Context, claims jwt.Claims)
+	RevokeUserTokens(ctx context.Context, uid uuid.UUID)
+	RevokeDeviceTokens(ctx context.Context, uid uuid.UUID, deviceID string)
+	CheckRevoked(ctx context.Context, claims jwt.Claims) error
 	jwt.Port
 	captcha.Port
 }
 
 type Auth struct {
 	jwt     jwt.Port
+	keyring *jwt.Core
 	captcha captcha.Port
+	store   Store
 }
 
-func New(conf config.Config) *Auth {
+// New creates Auth. When keys is not nil the JWT keyring is loaded from and
+// rotated through it, so that every replica signs with the same key.
+func New(conf config.Config, store Store, keys KeyRepo) *Auth {
+	core := jwt.New(conf)
+	if keys != nil {
+		err := core.UseStore(context.Background(), &keyStore{repo: keys, key: []byte(conf.Auth.EncryptionKey)})
+		if err != nil {
+			zap.L().Fatal("failed to load jwt keyring", zap.Error(err))
+		}
+	}
+
 	return &Auth{
-		jwt:     jwt.New(conf),
+		jwt:     core,
+		keyring: core,
 		captcha: captcha.New(conf),
+		store:   store,
 	}
 }
 
+// WatchKeys keeps the keyring in sync with the other replicas until ctx is
+// done.
+func (a *Auth) WatchKeys(ctx context.Context) {
+	a.keyring.Watch(ctx)
+}
+
 func (a *Auth) Hash(ctx context.Context, val string) (string, error) {
 	span, _ := opentracing.StartSpanFromContext(ctx, "Hash")
 	bytes, err := bcrypt.GenerateFromPassword([]byte(val), bcrypt.MinCost)
@@ -62,18 +86,49 @@ func (a *Auth) GetRefreshTime() time.Time {
 	return a.jwt.GetRefreshTime()
 }
 
-func (a *Auth) GenPair(ctx context.Context, uid uuid.UUID) (string, string, error) {
-	return a.jwt.GenPair(ctx, uid)
+func (a *Auth) GenPair(ctx context.Context, uid uuid.UUID, deviceID string, acc jwt.Access) (string, string, error) {
+	return a.jwt.GenPair(ctx, uid, deviceID, acc)
+}
+
+func (a *Auth) NewToken(ctx context.Context, uid uuid.UUID, acc jwt.Access, d time.Duration) (string, error) {
+	return a.jwt.NewToken(ctx, uid, acc, d)
+}
+
+func (a *Auth) NewClientToken(
+	ctx context.Context,
+	uid uuid.UUID,
+	clientID, scope string,
+	d time.Duration,
+) (string, error) {
+	return a.jwt.NewClientToken(ctx, uid, clientID, scope, d)
 }
 
-func (a *Auth) NewToken(ctx context.Context, uid uuid.UUID, d time.Duration) (string, error) {
-	return a.jwt.NewToken(ctx, uid, d)
+func (a *Auth) NewIDToken(
+	ctx context.Context,
+	uid uuid.UUID,
+	clientID, nonce string,
+	id jwt.Identity,
+	d time.Duration,
+) (string, error) {
+	return a.jwt.NewIDToken(ctx, uid, clientID, nonce, id, d)
 }
 
 func (a *Auth) ParseClaims(ctx context.Context, tokenStr string) (jwt.Claims, error) {
 	return a.jwt.ParseClaims(ctx, tokenStr)
 }
 
+func (a *Auth) ParseIDToken(ctx context.Context, tokenStr string) (jwt.IDClaims, error) {
+	return a.jwt.ParseIDToken(ctx, tokenStr)
+}
+
+func (a *Auth) JWKS() jwt.JWKSet {
+	return a.jwt.JWKS()
+}
+
+func (a *Auth) Rotate(ctx context.Context) (string, error) {
+	return a.jwt.Rotate(ctx)
+}
+
 func (a *Auth) VerifyRecaptcha(
 	ctx context.Context,
 	token string,
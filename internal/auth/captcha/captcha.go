@@ -20,7 +20,10 @@ type Port interface {
 type Actions string
 
 const (
-	PassAuth Actions = "pass_auth"
+	PassAuth    Actions = "pass_auth"
+	CodeAuth    Actions = "code_auth"
+	ForgotPass  Actions = "forgot_pass"
+	VerifyEmail Actions = "verify_email"
 )
 
 const captchaScore = 0.1
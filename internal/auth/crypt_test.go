@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	key := []byte("key")
+
+	encrypted, err := Encrypt(key, "secret")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "secret")
+
+	other, err := Encrypt(key, "secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, other)
+
+	plain, err := Decrypt(key, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plain)
+
+	_, err = Decrypt([]byte("other"), encrypted)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = Decrypt(key, "!!")
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
@@ -1,16 +1,72 @@
 package auth
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
 
 	"github.com/JMURv/golang-clean-template/internal/dto"
 	md "github.com/JMURv/golang-clean-template/internal/models"
 	"github.com/mssola/useragent"
 )
 
+// maxDeviceIDLen matches devices.id.
+const maxDeviceIDLen = 36
+
+// FallbackDeviceID derives a device ID from IP and User-Agent for clients that
+// do not send one. It changes with the network, so clients should keep the ID
+// they are given and send it back.
+func FallbackDeviceID(ip, ua string) string {
+	hash := sha256.Sum256([]byte(ip + ua))
+	return hex.EncodeToString(hash[:8])
+}
+
+// ValidDeviceID reports whether id can be stored as a device ID. Only
+// letters, digits, '-' and '_' are allowed.
+func ValidDeviceID(id string) bool {
+	if id == "" || len(id) > maxDeviceIDLen {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// SignDeviceID returns id with an HMAC so it can be stored in a cookie.
+func SignDeviceID(key []byte, id string) string {
+	return id + "." + HashToken(key, id)
+}
+
+// VerifyDeviceID returns the device ID of a value made by SignDeviceID.
+func VerifyDeviceID(key []byte, signed string) (string, bool) {
+	id, mac, ok := strings.Cut(signed, ".")
+	if !ok || !ValidDeviceID(id) {
+		return "", false
+	}
+
+	if !hmac.Equal([]byte(mac), []byte(HashToken(key, id))) {
+		return "", false
+	}
+
+	return id, true
+}
+
+// GenerateDevice describes the device of d. The client supplied ID is used
+// when present, IP and User-Agent are kept as attributes.
 func GenerateDevice(d *dto.DeviceRequest) md.Device {
-	hash := sha256.Sum256([]byte(d.IP + d.UA))
+	id := d.ID
+	if id == "" {
+		id = FallbackDeviceID(d.IP, d.UA)
+	}
+
 	ua := useragent.New(d.UA)
 	bName, _ := ua.Browser()
 
@@ -30,7 +86,7 @@ func GenerateDevice(d *dto.DeviceRequest) md.Device {
 	}
 
 	return md.Device{
-		ID:         hex.EncodeToString(hash[:8]),
+		ID:         id,
 		Name:       "My " + dt,
 		DeviceType: dt,
 		OS:         ua.OS(),
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestValidDeviceID(t *testing.T) {
+	assert.True(t, ValidDeviceID("3f1c9a1e-8d3b-4b6e-9a51-1c2f3e4d5a6b"))
+	assert.True(t, ValidDeviceID("phone_1"))
+	assert.False(t, ValidDeviceID(""))
+	assert.False(t, ValidDeviceID(strings.Repeat("a", 37)))
+	assert.False(t, ValidDeviceID("id.with.dots"))
+	assert.False(t, ValidDeviceID("id with spaces"))
+}
+
+func TestSignDeviceID(t *testing.T) {
+	key := []byte("key")
+	signed := SignDeviceID(key, "device-1")
+
+	id, ok := VerifyDeviceID(key, signed)
+	assert.True(t, ok)
+	assert.Equal(t, "device-1", id)
+
+	_, ok = VerifyDeviceID([]byte("other"), signed)
+	assert.False(t, ok)
+
+	_, ok = VerifyDeviceID(key, "device-2"+signed[len("device-1"):])
+	assert.False(t, ok)
+
+	_, ok = VerifyDeviceID(key, "device-1")
+	assert.False(t, ok)
+}
+
+func TestGenerateDevice(t *testing.T) {
+	d := GenerateDevice(&dto.DeviceRequest{IP: "10.0.0.1", UA: "test-agent"})
+	assert.Equal(t, FallbackDeviceID("10.0.0.1", "test-agent"), d.ID)
+
+	d = GenerateDevice(&dto.DeviceRequest{ID: "device-1", IP: "10.0.0.1", UA: "test-agent"})
+	assert.Equal(t, "device-1", d.ID)
+	assert.Equal(t, "10.0.0.1", d.IP)
+	assert.Equal(t, "test-agent", d.UA)
+}
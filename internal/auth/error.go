@@ -7,4 +7,10 @@ var (
 	ErrInvalidToken       = errors.New("invalid token")
 	// ErrTokenRevoked is error that indicates token expired.
 	ErrTokenRevoked = errors.New("token revoked")
+	// ErrTokenReused is error that indicates an already rotated refresh token was presented.
+	ErrTokenReused = errors.New("token reuse detected")
+	// ErrSessionExpired is error that indicates the session passed its idle or absolute timeout.
+	ErrSessionExpired = errors.New("session expired")
+	// ErrPermissionDenied is error that indicates missing permission.
+	ErrPermissionDenied = errors.New("permission denied")
 )
@@ -11,4 +11,13 @@ var (
 
 	// ErrInvalidToken is an error that indicates invalid token.
 	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrInvalidKey is an error that indicates unusable signing key.
+	ErrInvalidKey = errors.New("invalid signing key")
+
+	// ErrMissingKey is an error that indicates no signing key is configured.
+	ErrMissingKey = errors.New("missing signing key")
+
+	// ErrUnknownKey is an error that indicates token signed by unknown key.
+	ErrUnknownKey = errors.New("unknown signing key")
 )
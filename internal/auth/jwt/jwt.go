@@ -2,6 +2,11 @@ package jwt
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/JMURv/golang-clean-template/internal/config"
@@ -14,23 +19,141 @@ import (
 type Port interface {
 	GetAccessTime() time.Time
 	GetRefreshTime() time.Time
-	GenPair(ctx context.Context, uid uuid.UUID) (string, string, error)
-	NewToken(ctx context.Context, uid uuid.UUID, d time.Duration) (string, error)
+	GenPair(ctx context.Context, uid uuid.UUID, deviceID string, acc Access) (string, string, error)
+	NewToken(ctx context.Context, uid uuid.UUID, acc Access, d time.Duration) (string, error)
+	NewClientToken(ctx context.Context, uid uuid.UUID, clientID, scope string, d time.Duration) (string, error)
+	NewIDToken(ctx context.Context, uid uuid.UUID, clientID, nonce string, id Identity, d time.Duration) (string, error)
 	ParseClaims(ctx context.Context, tokenStr string) (Claims, error)
+	ParseIDToken(ctx context.Context, tokenStr string) (IDClaims, error)
+	JWKS() JWKSet
+	Rotate(ctx context.Context) (string, error)
 }
 
+// Core signs and parses tokens. Configured keys come from the environment;
+// with a KeyStore they are merged with the keys shared by all replicas.
 type Core struct {
-	secret []byte
-	issuer string
+	ring            *Keyring
+	configured      []ringKey
+	store           KeyStore
+	refreshed       atomic.Int64
+	issuer          string
+	oidcIssuer      string
+	retireAfter     time.Duration
+	refreshInterval time.Duration
 }
 
+// Access is the set of roles and permissions embedded into access tokens.
+type Access struct {
+	Roles       []string
+	Permissions []string
+}
+
+// Token types carried in the typ claim.
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+	TypeID      = "id"
+)
+
+// Claims of access and refresh tokens. Session access tokens carry the
+// DeviceID they were issued to. Tokens issued to OAuth clients carry ClientID
+// and Scope instead of roles.
 type Claims struct {
-	UID uuid.UUID `json:"uid"`
+	UID         uuid.UUID `json:"uid"`
+	Type        string    `json:"typ,omitempty"`
+	DeviceID    string    `json:"did,omitempty"`
+	Roles       []string  `json:"roles,omitempty"`
+	Permissions []string  `json:"perms,omitempty"`
+	ClientID    string    `json:"client_id,omitempty"`
+	Scope       string    `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// IsAccess reports whether the claims belong to an access token, so that
+// refresh tokens can't be used as bearer tokens.
+func (c Claims) IsAccess() bool {
+	return c.Type == TypeAccess
+}
+
+func (c Claims) HasPermission(perm string) bool {
+	return slices.Contains(c.Permissions, perm)
+}
+
+// HasScope reports whether the space delimited Scope includes scope.
+func (c Claims) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(c.Scope), scope)
+}
+
 func New(conf config.Config) *Core {
-	return &Core{secret: []byte(conf.Auth.JWT.Secret), issuer: conf.Auth.JWT.Issuer}
+	ring, err := loadKeyring(conf)
+	if err != nil {
+		zap.L().Fatal(
+			"failed to load jwt signing keys",
+			zap.String("alg", conf.Auth.JWT.Alg),
+			zap.Error(err),
+		)
+	}
+
+	return &Core{
+		ring:            ring,
+		configured:      ring.snapshot(),
+		issuer:          conf.Auth.JWT.Issuer,
+		oidcIssuer:      conf.OIDCIssuer(),
+		retireAfter:     conf.Auth.JWT.RetireAfter,
+		refreshInterval: conf.Auth.JWT.RefreshInterval,
+	}
+}
+
+func NewWithKey(key *Key, issuer string) *Core {
+	ring := NewKeyring(key)
+	return &Core{
+		ring:            ring,
+		configured:      ring.snapshot(),
+		issuer:          issuer,
+		oidcIssuer:      issuer,
+		retireAfter:     config.RefreshTokenDuration,
+		refreshInterval: config.KeyRefreshCooldown,
+	}
+}
+
+// loadKeyring builds the keyring from the active key and the configured
+// verification-only keys, which retire RetireAfter from startup. Previous
+// secrets are given as kid:secret pairs.
+func loadKeyring(conf config.Config) (*Keyring, error) {
+	cfg := conf.Auth.JWT
+
+	var key *Key
+	var err error
+	if cfg.Alg == HS256 {
+		key, err = NewHMACKey(cfg.KeyID, []byte(cfg.Secret))
+	} else {
+		key, err = LoadKey(cfg.Alg, cfg.KeyFile, cfg.KeyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ring := NewKeyring(key)
+	retireAt := time.Now().Add(cfg.RetireAfter)
+	for _, path := range cfg.VerifyKeyFiles {
+		if key, err = LoadKey("", path, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ring.Add(key, retireAt)
+	}
+
+	for _, v := range cfg.VerifySecrets {
+		kid, secret, ok := strings.Cut(v, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: verify secret must be kid:secret", ErrInvalidKey)
+		}
+		if key, err = NewHMACKey(kid, []byte(secret)); err != nil {
+			return nil, err
+		}
+		ring.Add(key, retireAt)
+	}
+
+	return ring, nil
 }
 
 func (c *Core) GetAccessTime() time.Time {
@@ -41,12 +164,24 @@ func (c *Core) GetRefreshTime() time.Time {
 	return time.Now().Add(config.RefreshTokenDuration)
 }
 
-func (c *Core) GenPair(ctx context.Context, uid uuid.UUID) (string, string, error) {
+// GenPair issues an access token carrying acc and a refresh token without it,
+// so that roles are reloaded on every refresh. The typ claim tells them apart.
+// The access token is bound to deviceID so that a single session can be
+// revoked.
+func (c *Core) GenPair(ctx context.Context, uid uuid.UUID, deviceID string, acc Access) (string, string, error) {
 	const op = "auth.GenPair.jwt"
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
-	access, err := c.NewToken(ctx, uid, config.AccessTokenDuration)
+	access, err := c.sign(
+		span, &Claims{
+			UID:         uid,
+			Type:        TypeAccess,
+			DeviceID:    deviceID,
+			Roles:       acc.Roles,
+			Permissions: acc.Permissions,
+		}, config.AccessTokenDuration,
+	)
 	if err != nil {
 		zap.L().Error(
 			"Failed to generate token pair",
@@ -56,7 +191,7 @@ func (c *Core) GenPair(ctx context.Context, uid uuid.UUID) (string, string, erro
 		return "", "", err
 	}
 
-	refresh, err := c.NewToken(ctx, uid, config.RefreshTokenDuration)
+	refresh, err := c.sign(span, &Claims{UID: uid, Type: TypeRefresh}, config.RefreshTokenDuration)
 	if err != nil {
 		zap.L().Error(
 			"Failed to generate token pair",
@@ -69,22 +204,75 @@ func (c *Core) GenPair(ctx context.Context, uid uuid.UUID) (string, string, erro
 	return access, refresh, nil
 }
 
-func (c *Core) NewToken(ctx context.Context, uid uuid.UUID, d time.Duration) (string, error) {
+func (c *Core) NewToken(ctx context.Context, uid uuid.UUID, acc Access, d time.Duration) (string, error) {
 	const op = "auth.NewToken.jwt"
-	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	span, _ := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return c.sign(
+		span, &Claims{
+			UID:         uid,
+			Type:        TypeAccess,
+			Roles:       acc.Roles,
+			Permissions: acc.Permissions,
+		}, d,
+	)
+}
+
+// NewClientToken issues an access token to an OAuth client. The subject is
+// the user the client acts for, or the client itself when uid is uuid.Nil.
+func (c *Core) NewClientToken(
+	ctx context.Context,
+	uid uuid.UUID,
+	clientID, scope string,
+	d time.Duration,
+) (string, error) {
+	const op = "auth.NewClientToken.jwt"
+	span, _ := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
-	signed, err := jwt.NewWithClaims(
-		jwt.SigningMethodHS256, &Claims{
-			UID: uid,
+	sub := clientID
+	if uid != uuid.Nil {
+		sub = uid.String()
+	}
+
+	return c.sign(
+		span, &Claims{
+			UID:      uid,
+			Type:     TypeAccess,
+			ClientID: clientID,
+			Scope:    scope,
 			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(d)),
-				IssuedAt:  jwt.NewNumericDate(time.Now()),
-				Issuer:    c.issuer,
-				ID:        uuid.New().String(),
+				Subject: sub,
 			},
-		},
-	).SignedString(c.secret)
+		}, d,
+	)
+}
+
+// sign fills the registered claims and signs claims with the active key.
+func (c *Core) sign(span opentracing.Span, claims *Claims, d time.Duration) (string, error) {
+	c.stamp(&claims.RegisteredClaims, c.issuer, d)
+	return c.signClaims(span, claims)
+}
+
+// stamp sets the expiry, issue time, issuer and a unique ID of a new token.
+func (c *Core) stamp(rc *jwt.RegisteredClaims, issuer string, d time.Duration) {
+	rc.ExpiresAt = jwt.NewNumericDate(time.Now().Add(d))
+	rc.IssuedAt = jwt.NewNumericDate(time.Now())
+	rc.Issuer = issuer
+	rc.ID = uuid.New().String()
+}
+
+// signClaims signs claims with the active key.
+func (c *Core) signClaims(span opentracing.Span, claims jwt.Claims) (string, error) {
+	key := c.ring.Active()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+
+	signed, err := token.SignedString(key.Private)
 	if err != nil {
 		span.SetTag(config.ErrorSpanTag, true)
 		zap.L().Error(
@@ -97,21 +285,32 @@ func (c *Core) NewToken(ctx context.Context, uid uuid.UUID, d time.Duration) (st
 	return signed, nil
 }
 
+// keyFunc resolves the verification key of a token by its kid header.
+func (c *Core) keyFunc(ctx context.Context, span opentracing.Span) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := c.lookup(ctx, kid)
+		if err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			return nil, err
+		}
+
+		if token.Method.Alg() != key.Method.Alg() {
+			span.SetTag(config.ErrorSpanTag, true)
+			return nil, ErrUnexpectedSignMethod
+		}
+
+		return key.verifyKey(), nil
+	}
+}
+
 func (c *Core) ParseClaims(ctx context.Context, tokenStr string) (Claims, error) {
 	const op = "auth.ParseClaims.jwt"
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
 	claims := Claims{}
-	token, err := jwt.ParseWithClaims(
-		tokenStr, &claims, func(token *jwt.Token) (any, error) {
-			if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-				span.SetTag(config.ErrorSpanTag, true)
-				return nil, ErrUnexpectedSignMethod
-			}
-			return c.secret, nil
-		},
-	)
+	token, err := jwt.ParseWithClaims(tokenStr, &claims, c.keyFunc(ctx, span))
 	if err != nil {
 		span.SetTag(config.ErrorSpanTag, true)
 		zap.L().Error(
@@ -134,3 +333,209 @@ func (c *Core) ParseClaims(ctx context.Context, tokenStr string) (Claims, error)
 
 	return claims, nil
 }
+
+// JWKS returns the public parts of every usable key. HMAC keys are skipped.
+func (c *Core) JWKS() JWKSet {
+	keys := c.ring.Keys()
+	set := JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		if key.Public != nil {
+			set.Keys = append(set.Keys, key.JWK())
+		}
+	}
+
+	return set
+}
+
+// Rotate generates a new active key with the current algorithm and keeps the
+// previous one for verification until it retires. With a KeyStore the new key
+// is persisted and picked up by the other replicas on their next refresh;
+// otherwise it lives in this process only.
+func (c *Core) Rotate(ctx context.Context) (string, error) {
+	const op = "auth.Rotate.jwt"
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	prev := c.ring.Active()
+	next, err := GenerateKey(prev.Method.Alg())
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"Failed to generate signing key",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+		return "", err
+	}
+
+	retireAt := time.Now().Add(c.retireAfter)
+	if c.store == nil {
+		c.ring.Rotate(next, retireAt)
+	} else {
+		if err = c.persist(ctx, prev, next, retireAt); err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"Failed to store signing key",
+				zap.String("op", op),
+				zap.Error(err),
+			)
+			return "", err
+		}
+
+		if err = c.Refresh(ctx); err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			return "", err
+		}
+	}
+
+	zap.L().Info(
+		"Rotated signing key",
+		zap.String("op", op),
+		zap.String("kid", next.ID),
+	)
+	return next.ID, nil
+}
+
+func (c *Core) persist(ctx context.Context, prev, next *Key, retireAt time.Time) error {
+	priv, err := MarshalKey(next)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Rotate(
+		ctx,
+		StoredKey{ID: prev.ID, Alg: prev.Method.Alg(), RetireAt: retireAt},
+		StoredKey{ID: next.ID, Alg: next.Method.Alg(), Private: priv},
+	)
+}
+
+// UseStore shares the keyring through store and loads it. Configured
+// verification-only keys are recorded on first use, so their retire time is
+// counted from the first startup rather than from every restart.
+func (c *Core) UseStore(ctx context.Context, store KeyStore) error {
+	markers := make([]StoredKey, 0, len(c.configured))
+	for _, rk := range c.configured[1:] {
+		markers = append(markers, StoredKey{ID: rk.key.ID, Alg: rk.key.Method.Alg(), RetireAt: rk.retireAt})
+	}
+
+	if len(markers) > 0 {
+		if err := store.Retire(ctx, markers); err != nil {
+			return err
+		}
+	}
+
+	c.store = store
+	return c.Refresh(ctx)
+}
+
+// Refresh reloads the keyring from the store. The newest stored key that is
+// not retired becomes active, falling back to the configured one.
+func (c *Core) Refresh(ctx context.Context) error {
+	const op = "auth.Refresh.jwt"
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	c.refreshed.Store(time.Now().UnixNano())
+	stored, err := c.store.Keys(ctx)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"Failed to load signing keys",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	ring, err := c.merge(stored)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"Failed to load signing keys",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	c.ring.replace(ring)
+	return nil
+}
+
+// merge builds a keyring from the configured keys and stored ones. Stored
+// markers override the retire time of configured keys.
+func (c *Core) merge(stored []StoredKey) (*Keyring, error) {
+	pinned := make(map[string]time.Time, len(stored))
+	keys := make([]ringKey, 0, len(stored))
+	for _, sk := range stored {
+		if len(sk.Private) == 0 {
+			pinned[sk.ID] = sk.RetireAt
+			continue
+		}
+
+		key, err := UnmarshalKey(sk.Alg, sk.ID, sk.Private)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sk.ID, err)
+		}
+		keys = append(keys, ringKey{key: key, retireAt: sk.RetireAt})
+	}
+
+	active := c.configured[0].key
+	for i := len(keys) - 1; i >= 0; i-- {
+		if keys[i].retireAt.IsZero() {
+			active = keys[i].key
+			break
+		}
+	}
+
+	ring := NewKeyring(active)
+	for _, rk := range slices.Concat(c.configured, keys) {
+		if retireAt, ok := pinned[rk.key.ID]; ok {
+			rk.retireAt = retireAt
+		}
+		ring.Add(rk.key, rk.retireAt)
+	}
+
+	return ring, nil
+}
+
+// Watch refreshes the keyring every refresh interval until ctx is done.
+func (c *Core) Watch(ctx context.Context) {
+	if c.store == nil {
+		return
+	}
+
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.Refresh(ctx)
+		}
+	}
+}
+
+// lookup returns the key for kid. A kid unknown to this replica may come from
+// a rotation on another one, so the keyring is reloaded at most once per
+// config.KeyRefreshCooldown before giving up.
+func (c *Core) lookup(ctx context.Context, kid string) (*Key, error) {
+	key, err := c.ring.Lookup(kid)
+	if !errors.Is(err, ErrUnknownKey) || c.store == nil {
+		return key, err
+	}
+
+	last := c.refreshed.Load()
+	if time.Since(time.Unix(0, last)) < config.KeyRefreshCooldown ||
+		!c.refreshed.CompareAndSwap(last, time.Now().UnixNano()) {
+		return nil, err
+	}
+
+	if c.Refresh(ctx) != nil {
+		return nil, err
+	}
+
+	return c.ring.Lookup(kid)
+}
@@ -0,0 +1,236 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeKey(t *testing.T, priv any) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	raw := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	return path
+}
+
+func TestCore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		alg  string
+		priv any
+		kty  string
+	}{
+		{name: "RS256", alg: RS256, priv: rsaKey, kty: "RSA"},
+		{name: "ES256", alg: ES256, priv: ecKey, kty: "EC"},
+		{name: "EdDSA", alg: EdDSA, priv: edKey, kty: "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				key, err := LoadKey(tt.alg, writeKey(t, tt.priv), "")
+				require.NoError(t, err)
+				assert.NotEmpty(t, key.ID)
+
+				c := NewWithKey(key, "test")
+				token, err := c.NewToken(ctx, uid, Access{Roles: []string{"admin"}}, time.Minute)
+				require.NoError(t, err)
+
+				parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+				require.NoError(t, err)
+				assert.Equal(t, tt.alg, parsed.Method.Alg())
+				assert.Equal(t, key.ID, parsed.Header["kid"])
+
+				claims, err := c.ParseClaims(ctx, token)
+				require.NoError(t, err)
+				assert.Equal(t, uid, claims.UID)
+				assert.Equal(t, []string{"admin"}, claims.Roles)
+
+				set := c.JWKS()
+				require.Len(t, set.Keys, 1)
+				assert.Equal(t, tt.kty, set.Keys[0].Kty)
+				assert.Equal(t, key.ID, set.Keys[0].Kid)
+			},
+		)
+	}
+}
+
+func TestCore_GenPair(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New()
+	key, err := NewHMACKey("", []byte("secret"))
+	require.NoError(t, err)
+	c := NewWithKey(key, "test")
+
+	access, refresh, err := c.GenPair(ctx, uid, "device", Access{Roles: []string{"admin"}})
+	require.NoError(t, err)
+
+	claims, err := c.ParseClaims(ctx, access)
+	require.NoError(t, err)
+	assert.True(t, claims.IsAccess())
+	assert.Equal(t, "device", claims.DeviceID)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+
+	claims, err = c.ParseClaims(ctx, refresh)
+	require.NoError(t, err)
+	assert.False(t, claims.IsAccess())
+	assert.Equal(t, TypeRefresh, claims.Type)
+	assert.Empty(t, claims.Roles)
+}
+
+func TestCore_NewClientToken(t *testing.T) {
+	ctx := context.Background()
+	key, err := NewHMACKey("", []byte("secret"))
+	require.NoError(t, err)
+	c := NewWithKey(key, "test")
+
+	t.Run(
+		"User", func(t *testing.T) {
+			uid := uuid.New()
+			token, err := c.NewClientToken(ctx, uid, "client", "read write", time.Minute)
+			require.NoError(t, err)
+
+			claims, err := c.ParseClaims(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, uid, claims.UID)
+			assert.Equal(t, uid.String(), claims.Subject)
+			assert.Equal(t, "client", claims.ClientID)
+			assert.Equal(t, "read write", claims.Scope)
+			assert.True(t, claims.IsAccess())
+			assert.True(t, claims.HasScope("write"))
+			assert.False(t, claims.HasScope("rea"))
+			assert.Empty(t, claims.Permissions)
+			assert.NotEmpty(t, claims.ID)
+		},
+	)
+
+	t.Run(
+		"Client", func(t *testing.T) {
+			token, err := c.NewClientToken(ctx, uuid.Nil, "client", "", time.Minute)
+			require.NoError(t, err)
+
+			claims, err := c.ParseClaims(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, uuid.Nil, claims.UID)
+			assert.Equal(t, "client", claims.Subject)
+		},
+	)
+}
+
+func TestCore_ParseClaims_Rejects(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key, err := LoadKey(ES256, writeKey(t, ecKey), "current")
+	require.NoError(t, err)
+	c := NewWithKey(key, "test")
+
+	t.Run(
+		"UnexpectedSignMethod", func(t *testing.T) {
+			hmac, err := NewHMACKey("current", []byte("secret"))
+			require.NoError(t, err)
+
+			token, err := NewWithKey(hmac, "test").NewToken(ctx, uid, Access{}, time.Minute)
+			require.NoError(t, err)
+
+			_, err = c.ParseClaims(ctx, token)
+			assert.ErrorIs(t, err, ErrUnexpectedSignMethod)
+		},
+	)
+
+	t.Run(
+		"UnknownKey", func(t *testing.T) {
+			other := *key
+			other.ID = "retired"
+
+			token, err := NewWithKey(&other, "test").NewToken(ctx, uid, Access{}, time.Minute)
+			require.NoError(t, err)
+
+			_, err = c.ParseClaims(ctx, token)
+			assert.ErrorIs(t, err, ErrUnknownKey)
+		},
+	)
+}
+
+func TestParseKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs1 := pem.EncodeToMemory(
+		&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+	)
+
+	t.Run(
+		"PKCS1", func(t *testing.T) {
+			key, err := ParseKey(RS256, pkcs1, "kid")
+			require.NoError(t, err)
+			assert.Equal(t, "kid", key.ID)
+		},
+	)
+
+	t.Run(
+		"AlgMismatch", func(t *testing.T) {
+			_, err := ParseKey(ES256, pkcs1, "kid")
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		},
+	)
+
+	t.Run(
+		"NotPEM", func(t *testing.T) {
+			_, err := ParseKey(RS256, []byte("garbage"), "kid")
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		},
+	)
+
+	t.Run(
+		"Thumbprint", func(t *testing.T) {
+			// RFC 7638 section 3.1 example.
+			jwk := JWK{
+				Kty: "RSA",
+				E:   "AQAB",
+				N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECP" +
+					"ebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2Q" +
+					"vzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6" +
+					"WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+			}
+			tp, err := thumbprint(jwk)
+			require.NoError(t, err)
+			assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", tp)
+		},
+	)
+
+	t.Run(
+		"EmptySecret", func(t *testing.T) {
+			_, err := NewHMACKey("", nil)
+			assert.ErrorIs(t, err, ErrMissingKey)
+		},
+	)
+}
@@ -0,0 +1,116 @@
+package jwt
+
+import (
+	"sync"
+	"time"
+)
+
+// Keyring holds the active signing key and verification-only keys selected
+// by kid. Verification-only keys are dropped once their retire time passes.
+type Keyring struct {
+	mu     sync.RWMutex
+	active *Key
+	keys   map[string]ringKey
+	now    func() time.Time
+}
+
+type ringKey struct {
+	key      *Key
+	retireAt time.Time
+}
+
+func NewKeyring(active *Key) *Keyring {
+	return &Keyring{
+		active: active,
+		keys:   map[string]ringKey{active.ID: {key: active}},
+		now:    time.Now,
+	}
+}
+
+// Active returns the key new tokens are signed with.
+func (r *Keyring) Active() *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Add registers a verification-only key that retires at retireAt. A zero
+// retireAt keeps the key until it is rotated out.
+func (r *Keyring) Add(key *Key, retireAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key.ID == r.active.ID {
+		return
+	}
+	r.keys[key.ID] = ringKey{key: key, retireAt: retireAt}
+}
+
+// Rotate makes next the active key. The previous one stays valid for
+// verification until retireAt so already issued tokens keep working.
+func (r *Keyring) Rotate(next *Key, retireAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[r.active.ID] = ringKey{key: r.active, retireAt: retireAt}
+	r.keys[next.ID] = ringKey{key: next}
+	r.active = next
+}
+
+// Lookup returns the key for kid, which may be empty for tokens issued
+// without a kid header.
+func (r *Keyring) Lookup(kid string) (*Key, error) {
+	r.mu.RLock()
+	rk, ok := r.keys[kid]
+	r.mu.RUnlock()
+	if !ok || r.retired(rk) {
+		return nil, ErrUnknownKey
+	}
+
+	return rk.key, nil
+}
+
+// Keys returns every usable key, starting with the active one.
+func (r *Keyring) Keys() []*Key {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]*Key, 0, len(r.keys))
+	res = append(res, r.active)
+	for kid, rk := range r.keys {
+		if r.retired(rk) {
+			delete(r.keys, kid)
+			continue
+		}
+		if kid != r.active.ID {
+			res = append(res, rk.key)
+		}
+	}
+
+	return res
+}
+
+// snapshot returns the active key followed by the other keys.
+func (r *Keyring) snapshot() []ringKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]ringKey, 0, len(r.keys))
+	res = append(res, ringKey{key: r.active})
+	for kid, rk := range r.keys {
+		if kid != r.active.ID {
+			res = append(res, rk)
+		}
+	}
+
+	return res
+}
+
+// replace swaps in the keys of next.
+func (r *Keyring) replace(next *Keyring) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active, r.keys = next.active, next.keys
+}
+
+func (r *Keyring) retired(rk ringKey) bool {
+	return !rk.retireAt.IsZero() && !r.now().Before(rk.retireAt)
+}
@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestKeyring(t *testing.T) {
+	now := time.Now()
+	first, err := NewHMACKey("", []byte("first"))
+	require.NoError(t, err)
+	second, err := GenerateKey(ES256)
+	require.NoError(t, err)
+	third, err := GenerateKey(EdDSA)
+	require.NoError(t, err)
+
+	ring := NewKeyring(first)
+	ring.now = func() time.Time { return now }
+
+	ring.Rotate(second, now.Add(time.Hour))
+	assert.Equal(t, second, ring.Active())
+
+	key, err := ring.Lookup("")
+	require.NoError(t, err)
+	assert.Equal(t, first, key)
+
+	ring.Add(third, now.Add(2*time.Hour))
+	assert.Len(t, ring.Keys(), 3)
+	assert.Equal(t, second, ring.Keys()[0])
+
+	ring.now = func() time.Time { return now.Add(time.Hour) }
+	_, err = ring.Lookup("")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Len(t, ring.Keys(), 2)
+
+	ring.now = func() time.Time { return now.Add(2 * time.Hour) }
+	_, err = ring.Lookup(third.ID)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, []*Key{second}, ring.Keys())
+}
+
+func TestCore_Rotate(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New()
+
+	key, err := NewHMACKey("", []byte("secret"))
+	require.NoError(t, err)
+	c := NewWithKey(key, "test")
+
+	before, err := c.NewToken(ctx, uid, Access{}, time.Minute)
+	require.NoError(t, err)
+
+	kid, err := c.Rotate(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, kid)
+	assert.Equal(t, kid, c.ring.Active().ID)
+
+	after, err := c.NewToken(ctx, uid, Access{}, time.Minute)
+	require.NoError(t, err)
+
+	for _, token := range []string{before, after} {
+		claims, err := c.ParseClaims(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, uid, claims.UID)
+	}
+
+	c.ring.now = func() time.Time { return time.Now().Add(c.retireAfter) }
+	_, err = c.ParseClaims(ctx, before)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	old, err := GenerateKey(EdDSA)
+	require.NoError(t, err)
+
+	conf := config.Config{}
+	conf.Auth.JWT.Alg = HS256
+	conf.Auth.JWT.Secret = "secret"
+	conf.Auth.JWT.KeyID = "current"
+	conf.Auth.JWT.RetireAfter = time.Hour
+	conf.Auth.JWT.VerifyKeyFiles = []string{writeKey(t, old.Private)}
+	conf.Auth.JWT.VerifySecrets = []string{"previous:old-secret"}
+
+	ring, err := loadKeyring(conf)
+	require.NoError(t, err)
+	assert.Equal(t, "current", ring.Active().ID)
+	assert.Len(t, ring.Keys(), 3)
+
+	key, err := ring.Lookup(old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, EdDSA, key.Method.Alg())
+
+	conf.Auth.JWT.VerifySecrets = []string{"no-kid"}
+	_, err = loadKeyring(conf)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
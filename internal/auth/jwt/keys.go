@@ -0,0 +1,249 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/goccy/go-json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+const (
+	hmacKeySize = 32
+	rsaKeyBits  = 2048
+)
+
+// Key is a signing key identified by kid. Public is nil for HMAC keys, which
+// are never published.
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private any
+	Public  crypto.PublicKey
+}
+
+// verifyKey returns the key used to check signatures.
+func (k *Key) verifyKey() any {
+	if k.Public == nil {
+		return k.Private
+	}
+
+	return k.Public
+}
+
+// NewHMACKey wraps a shared secret for HS256.
+func NewHMACKey(kid string, secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, ErrMissingKey
+	}
+
+	return &Key{ID: kid, Method: jwt.SigningMethodHS256, Private: secret}, nil
+}
+
+// LoadKey reads a PEM encoded private key for alg from path. PKCS#8, PKCS#1
+// and SEC 1 encodings are accepted. An empty alg is inferred from the key
+// type, and an empty kid defaults to the RFC 7638 thumbprint of the public key.
+func LoadKey(alg, path, kid string) (*Key, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKey(alg, raw, kid)
+}
+
+func ParseKey(alg string, pemBytes []byte, kid string) (*Key, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, ErrInvalidKey
+	}
+
+	var priv any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return newKey(alg, priv, kid)
+}
+
+// newKey wraps an asymmetric private key. An empty alg is inferred from the
+// key type.
+func newKey(alg string, priv any, kid string) (*Key, error) {
+	var err error
+	key := &Key{ID: kid, Private: priv}
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, &k.PublicKey
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, k.Curve.Params().Name)
+		}
+		key.Method, key.Public = jwt.SigningMethodES256, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k.Public()
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidKey, priv)
+	}
+
+	if alg != "" && alg != key.Method.Alg() {
+		return nil, fmt.Errorf("%w: %s key for %s", ErrInvalidKey, key.Method.Alg(), alg)
+	}
+
+	if key.ID == "" {
+		key.ID, err = thumbprint(key.JWK())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// GenerateKey creates a fresh key for alg. HMAC keys get a random kid since
+// they have no public part to derive a thumbprint from.
+func GenerateKey(alg string) (*Key, error) {
+	var priv any
+	var err error
+	switch alg {
+	case HS256:
+		secret := make([]byte, hmacKeySize)
+		if _, err = rand.Read(secret); err != nil {
+			return nil, err
+		}
+		return NewHMACKey(uuid.NewString(), secret)
+	case RS256:
+		priv, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case ES256:
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case EdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: unsupported alg %s", ErrInvalidKey, alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newKey(alg, priv, "")
+}
+
+// JWK is a public key in RFC 7517 format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public part of the key. HMAC keys yield an empty JWK.
+func (k *Key) JWK() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Kid: k.ID,
+			N:   b64(pub.N.Bytes()),
+			E:   b64(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8 //nolint:mnd
+		return JWK{
+			Kty: "EC",
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Kid: k.ID,
+			Crv: pub.Curve.Params().Name,
+			X:   b64(pub.X.FillBytes(make([]byte, size))),
+			Y:   b64(pub.Y.FillBytes(make([]byte, size))),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Kid: k.ID,
+			Crv: "Ed25519",
+			X:   b64(pub),
+		}
+	}
+
+	return JWK{}
+}
+
+// thumbprint computes the RFC 7638 thumbprint from the required members only.
+func thumbprint(j JWK) (string, error) {
+	var members any
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{j.Crv, j.Kty, j.X, j.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	default:
+		return "", ErrInvalidKey
+	}
+
+	raw, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
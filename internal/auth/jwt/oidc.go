@@ -0,0 +1,87 @@
+package jwt
+
+import (
+	"context"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+// Identity holds the standard claims about the end-user. Claims the granted
+// scopes don't cover are left empty and omitted.
+type Identity struct {
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Picture       string `json:"picture,omitempty"`
+}
+
+// IDClaims are the claims of an OpenID Connect ID token. The audience is the
+// client the token was issued to and the subject is the user ID.
+type IDClaims struct {
+	Type  string `json:"typ"`
+	Nonce string `json:"nonce,omitempty"`
+	Identity
+	jwt.RegisteredClaims
+}
+
+// NewIDToken issues an ID token about uid to clientID. nonce is echoed from
+// the authorization request so the client can bind the token to it.
+func (c *Core) NewIDToken(
+	ctx context.Context,
+	uid uuid.UUID,
+	clientID, nonce string,
+	id Identity,
+	d time.Duration,
+) (string, error) {
+	const op = "auth.NewIDToken.jwt"
+	span, _ := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	claims := &IDClaims{
+		Type:     TypeID,
+		Nonce:    nonce,
+		Identity: id,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  uid.String(),
+			Audience: jwt.ClaimStrings{clientID},
+		},
+	}
+	c.stamp(&claims.RegisteredClaims, c.oidcIssuer, d)
+
+	return c.signClaims(span, claims)
+}
+
+// ParseIDToken verifies the signature and issuer of an ID token. Expiry is
+// not checked: ID tokens are only presented back as hints, for example on
+// logout, where an expired one still identifies the session.
+func (c *Core) ParseIDToken(ctx context.Context, tokenStr string) (IDClaims, error) {
+	const op = "auth.ParseIDToken.jwt"
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	claims := IDClaims{}
+	_, err := jwt.ParseWithClaims(
+		tokenStr, &claims, c.keyFunc(ctx, span),
+		jwt.WithoutClaimsValidation(),
+	)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Debug(
+			"Failed to parse id token",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+		return claims, ErrInvalidToken
+	}
+
+	if claims.Type != TypeID || claims.Issuer != c.oidcIssuer {
+		return claims, ErrInvalidToken
+	}
+
+	return claims, nil
+}
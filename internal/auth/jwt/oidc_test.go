@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCore_NewIDToken(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New()
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key, err := LoadKey(ES256, writeKey(t, ecKey), "current")
+	require.NoError(t, err)
+	c := NewWithKey(key, "https://sso.example.com")
+
+	verified := true
+	id := Identity{Email: "user@example.com", EmailVerified: &verified, Name: "User"}
+	token, err := c.NewIDToken(ctx, uid, "client", "n-0S6_WzA2Mj", id, time.Minute)
+	require.NoError(t, err)
+
+	claims, err := c.ParseIDToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, TypeID, claims.Type)
+	assert.Equal(t, uid.String(), claims.Subject)
+	assert.Equal(t, "https://sso.example.com", claims.Issuer)
+	assert.Equal(t, []string{"client"}, []string(claims.Audience))
+	assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+	assert.Equal(t, id, claims.Identity)
+
+	// ID tokens are never bearer tokens
+	access, err := c.ParseClaims(ctx, token)
+	require.NoError(t, err)
+	assert.False(t, access.IsAccess())
+
+	t.Run(
+		"ExpiredHint", func(t *testing.T) {
+			expired, err := c.NewIDToken(ctx, uid, "client", "", Identity{}, -time.Minute)
+			require.NoError(t, err)
+
+			_, err = c.ParseIDToken(ctx, expired)
+			assert.NoError(t, err)
+		},
+	)
+
+	t.Run(
+		"AccessToken", func(t *testing.T) {
+			access, err := c.NewClientToken(ctx, uid, "client", "openid", time.Minute)
+			require.NoError(t, err)
+
+			_, err = c.ParseIDToken(ctx, access)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		},
+	)
+
+	t.Run(
+		"OtherIssuer", func(t *testing.T) {
+			other, err := NewWithKey(key, "https://evil.example.com").NewIDToken(ctx, uid, "client", "", Identity{}, time.Minute)
+			require.NoError(t, err)
+
+			_, err = c.ParseIDToken(ctx, other)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		},
+	)
+}
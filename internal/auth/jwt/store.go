@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+)
+
+// StoredKey is a keyring entry shared by all replicas. Private is the
+// marshaled private key of generated keys and is empty for markers that only
+// pin the retire time of a configured key. A zero RetireAt marks the active
+// key.
+type StoredKey struct {
+	ID       string
+	Alg      string
+	Private  []byte
+	RetireAt time.Time
+}
+
+// KeyStore persists the keyring so that rotations reach every replica and
+// retire times survive restarts.
+type KeyStore interface {
+	// Keys returns every stored key, oldest first.
+	Keys(ctx context.Context) ([]StoredKey, error)
+	// Retire records keys as markers unless their kid is already stored.
+	Retire(ctx context.Context, keys []StoredKey) error
+	// Rotate retires the active keys and the key prev at prev.RetireAt, and
+	// stores next as the new active key.
+	Rotate(ctx context.Context, prev, next StoredKey) error
+}
+
+// MarshalKey encodes the private part of key: the raw secret for HMAC keys
+// and PKCS#8 PEM otherwise.
+func MarshalKey(key *Key) ([]byte, error) {
+	if secret, ok := key.Private.([]byte); ok {
+		return secret, nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// UnmarshalKey is the inverse of MarshalKey.
+func UnmarshalKey(alg, kid string, raw []byte) (*Key, error) {
+	if alg == HS256 {
+		return NewHMACKey(kid, raw)
+	}
+
+	return ParseKey(alg, raw, kid)
+}
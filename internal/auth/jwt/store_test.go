@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memKeyStore struct {
+	mu   sync.Mutex
+	keys []StoredKey
+}
+
+func (s *memKeyStore) Keys(context.Context) ([]StoredKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StoredKey(nil), s.keys...), nil
+}
+
+func (s *memKeyStore) Retire(_ context.Context, keys []StoredKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		s.add(k)
+	}
+	return nil
+}
+
+func (s *memKeyStore) Rotate(_ context.Context, prev, next StoredKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].RetireAt.IsZero() {
+			s.keys[i].RetireAt = prev.RetireAt
+		}
+	}
+	s.add(prev)
+	s.add(next)
+	return nil
+}
+
+func (s *memKeyStore) add(k StoredKey) {
+	for _, v := range s.keys {
+		if v.ID == k.ID {
+			return
+		}
+	}
+	s.keys = append(s.keys, k)
+}
+
+func TestCore_UseStore(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New()
+	store := &memKeyStore{}
+
+	replica := func() *Core {
+		key, err := NewHMACKey("configured", []byte("secret"))
+		require.NoError(t, err)
+
+		c := NewWithKey(key, "test")
+		require.NoError(t, c.UseStore(ctx, store))
+		return c
+	}
+
+	a, b := replica(), replica()
+	before, err := a.NewToken(ctx, uid, Access{}, time.Minute)
+	require.NoError(t, err)
+
+	kid, err := a.Rotate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, kid, a.ring.Active().ID)
+	assert.Equal(t, "configured", b.ring.Active().ID)
+
+	after, err := a.NewToken(ctx, uid, Access{}, time.Minute)
+	require.NoError(t, err)
+
+	// The other replica reloads the keyring once the cooldown has passed
+	_, err = b.ParseClaims(ctx, after)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	b.refreshed.Store(0)
+	claims, err := b.ParseClaims(ctx, after)
+	require.NoError(t, err)
+	assert.Equal(t, uid, claims.UID)
+	assert.Equal(t, kid, b.ring.Active().ID)
+
+	// A restarted replica keeps the rotated key and its retire time
+	c := replica()
+	assert.Equal(t, kid, c.ring.Active().ID)
+	for _, token := range []string{before, after} {
+		_, err = c.ParseClaims(ctx, token)
+		require.NoError(t, err)
+	}
+
+	c.ring.now = func() time.Time { return time.Now().Add(c.retireAfter) }
+	_, err = c.ParseClaims(ctx, before)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	_, err = c.ParseClaims(ctx, after)
+	assert.NoError(t, err)
+}
+
+func TestMarshalKey(t *testing.T) {
+	for _, alg := range []string{HS256, RS256, ES256, EdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			key, err := GenerateKey(alg)
+			require.NoError(t, err)
+
+			raw, err := MarshalKey(key)
+			require.NoError(t, err)
+
+			res, err := UnmarshalKey(alg, key.ID, raw)
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, res.ID)
+			assert.Equal(t, key.Private, res.Private)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+)
+
+// KeyRepo persists the JWT keyring.
+type KeyRepo interface {
+	ListSigningKeys(ctx context.Context) ([]md.SigningKey, error)
+	CreateSigningKeys(ctx context.Context, keys []md.SigningKey) error
+	RotateSigningKey(ctx context.Context, prev, next *md.SigningKey) error
+}
+
+// keyStore adapts KeyRepo to jwt.KeyStore and keeps private keys encrypted
+// at rest.
+type keyStore struct {
+	repo KeyRepo
+	key  []byte
+}
+
+func (s *keyStore) Keys(ctx context.Context) ([]jwt.StoredKey, error) {
+	keys, err := s.repo.ListSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]jwt.StoredKey, 0, len(keys))
+	for _, k := range keys {
+		sk := jwt.StoredKey{ID: k.ID, Alg: k.Alg}
+		if k.RetireAt != nil {
+			sk.RetireAt = *k.RetireAt
+		}
+
+		if k.PrivateKey != "" {
+			priv, err := Decrypt(s.key, k.PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			sk.Private = []byte(priv)
+		}
+
+		res = append(res, sk)
+	}
+
+	return res, nil
+}
+
+func (s *keyStore) Retire(ctx context.Context, keys []jwt.StoredKey) error {
+	res := make([]md.SigningKey, 0, len(keys))
+	for _, k := range keys {
+		sk, err := s.encode(k)
+		if err != nil {
+			return err
+		}
+		res = append(res, *sk)
+	}
+
+	return s.repo.CreateSigningKeys(ctx, res)
+}
+
+func (s *keyStore) Rotate(ctx context.Context, prev, next jwt.StoredKey) error {
+	p, err := s.encode(prev)
+	if err != nil {
+		return err
+	}
+
+	n, err := s.encode(next)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.RotateSigningKey(ctx, p, n)
+}
+
+func (s *keyStore) encode(k jwt.StoredKey) (*md.SigningKey, error) {
+	res := &md.SigningKey{ID: k.ID, Alg: k.Alg}
+	if !k.RetireAt.IsZero() {
+		res.RetireAt = &k.RetireAt
+	}
+
+	if len(k.Private) > 0 {
+		priv, err := Encrypt(s.key, string(k.Private))
+		if err != nil {
+			return nil, err
+		}
+		res.PrivateKey = priv
+	}
+
+	return res, nil
+}
+
+var _ jwt.KeyStore = (*keyStore)(nil)
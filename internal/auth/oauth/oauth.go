@@ -0,0 +1,279 @@
+// Package oauth holds the protocol rules of the OAuth 2.0 authorization
+// server (RFC 6749) that don't depend on storage: error responses, PKCE
+// (RFC 7636), scopes and redirect URI checks.
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Grant and response types.
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+	GrantDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+
+	ResponseTypeCode = "code"
+	MethodS256       = "S256"
+	TokenTypeBearer  = "Bearer"
+	PromptConsent    = "consent"
+
+	// ScopeProfile lets a client read the profile of the user it acts for.
+	ScopeProfile = "profile"
+	// ScopeOpenID makes an authorization request an OpenID Connect one, so
+	// the token response carries an ID token. ScopeEmail adds the email
+	// claims to it and to userinfo.
+	ScopeOpenID = "openid"
+	ScopeEmail  = "email"
+)
+
+const tokenSize = 32
+
+// Error is an OAuth 2.0 error response (RFC 6749 section 5.2).
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+
+	return e.Code + ": " + e.Description
+}
+
+// Is matches errors by code, so described errors match their sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDescription returns a copy of e with a human readable description.
+func (e *Error) WithDescription(desc string) *Error {
+	return &Error{Code: e.Code, Description: desc}
+}
+
+var (
+	ErrInvalidRequest          = &Error{Code: "invalid_request"}
+	ErrInvalidClient           = &Error{Code: "invalid_client"}
+	ErrInvalidGrant            = &Error{Code: "invalid_grant"}
+	ErrUnauthorizedClient      = &Error{Code: "unauthorized_client"}
+	ErrUnsupportedGrantType    = &Error{Code: "unsupported_grant_type"}
+	ErrUnsupportedResponseType = &Error{Code: "unsupported_response_type"}
+	ErrInvalidScope            = &Error{Code: "invalid_scope"}
+	ErrAccessDenied            = &Error{Code: "access_denied"}
+	ErrServerError             = &Error{Code: "server_error"}
+
+	// Device authorization errors (RFC 8628 section 3.5).
+	ErrAuthorizationPending = &Error{Code: "authorization_pending"}
+	ErrSlowDown             = &Error{Code: "slow_down"}
+	ErrExpiredToken         = &Error{Code: "expired_token"}
+)
+
+// GenerateToken returns a random URL safe token for codes, refresh tokens
+// and client secrets.
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// userCodeAlphabet has no vowels, so user codes don't spell words, and no
+// characters that are easily confused (RFC 8628 section 6.1).
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+const userCodeSize = 8
+
+// GenerateUserCode returns a user code of the device grant, such as
+// WDJB-MJHT.
+func GenerateUserCode() (string, error) {
+	// Bytes past the last multiple of the alphabet size are skipped, so every
+	// character is equally likely.
+	limit := byte(256 / len(userCodeAlphabet) * len(userCodeAlphabet))
+
+	code := make([]byte, 0, userCodeSize+1)
+	b := make([]byte, userCodeSize)
+	for n := 0; n < userCodeSize; {
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+
+		for _, v := range b {
+			if v >= limit || n == userCodeSize {
+				continue
+			}
+
+			if n == userCodeSize/2 {
+				code = append(code, '-')
+			}
+			code = append(code, userCodeAlphabet[int(v)%len(userCodeAlphabet)])
+			n++
+		}
+	}
+
+	return string(code), nil
+}
+
+// NormalizeUserCode uppercases a user code typed by the user and drops
+// separators, so WDJB-MJHT, wdjb mjht and WDJBMJHT match.
+func NormalizeUserCode(code string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(code) {
+		if strings.ContainsRune(userCodeAlphabet, c) {
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
+
+// ValidVerifier reports whether v is a code verifier of 43 to 128 unreserved
+// characters.
+func ValidVerifier(v string) bool {
+	if len(v) < 43 || len(v) > 128 {
+		return false
+	}
+
+	for _, c := range v {
+		if !isUnreserved(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// VerifyPKCE checks verifier against an S256 code challenge.
+func VerifyPKCE(verifier, challenge string) bool {
+	if !ValidVerifier(verifier) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(S256Challenge(verifier)), []byte(challenge)) == 1
+}
+
+// S256Challenge derives the code challenge of verifier.
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func isUnreserved(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// ParseScope splits a space delimited scope and drops duplicates.
+func ParseScope(scope string) []string {
+	var res []string
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(res, s) {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+// JoinScope formats scopes as a scope parameter.
+func JoinScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// Covers reports whether every requested scope is in granted.
+func Covers(granted, requested []string) bool {
+	for _, s := range requested {
+		if !slices.Contains(granted, s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Merge returns the union of a and b.
+func Merge(a, b []string) []string {
+	res := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(res, s) {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+// deniedSchemes can run code or read local data in the user agent.
+var deniedSchemes = []string{"javascript", "data", "vbscript", "file"}
+
+// ValidRedirectURI reports whether uri can be registered: an absolute URI
+// without a fragment. Native apps may use a private-use scheme in reverse
+// domain name form, such as com.example.app (RFC 8252 section 7.1).
+func ValidRedirectURI(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Fragment != "" || strings.Contains(uri, "#") {
+		return false
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "http" || scheme == "https" {
+		return u.Host != ""
+	}
+
+	return !slices.Contains(deniedSchemes, scheme) && strings.Contains(scheme, ".")
+}
+
+// ResolveRedirectURI matches the requested redirect URI exactly against the
+// registered ones. Without one, the only registered URI is used.
+func ResolveRedirectURI(registered []string, requested string) (string, bool) {
+	if requested == "" {
+		if len(registered) == 1 {
+			return registered[0], true
+		}
+
+		return "", false
+	}
+
+	return requested, slices.Contains(registered, requested)
+}
+
+// RedirectURI appends params to the query of uri, keeping the existing ones.
+func RedirectURI(uri string, params url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ErrorRedirect sends the error back to the client with its state.
+func ErrorRedirect(uri string, err *Error, state string) string {
+	params := url.Values{}
+	params.Set("error", err.Code)
+	if err.Description != "" {
+		params.Set("error_description", err.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+
+	return RedirectURI(uri, params)
+}
@@ -0,0 +1,126 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	// RFC 7636 appendix B
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	const challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	assert.Equal(t, challenge, S256Challenge(verifier))
+	assert.True(t, VerifyPKCE(verifier, challenge))
+	assert.False(t, VerifyPKCE(verifier, S256Challenge(verifier+"x")))
+	assert.False(t, VerifyPKCE("short", S256Challenge("short")))
+
+	bad := strings.Repeat("a", 42) + "!"
+	assert.False(t, VerifyPKCE(bad, S256Challenge(bad)))
+	assert.False(t, ValidVerifier(strings.Repeat("a", 129)))
+}
+
+func TestScope(t *testing.T) {
+	scopes := ParseScope(" read  write read ")
+	assert.Equal(t, []string{"read", "write"}, scopes)
+	assert.Equal(t, "read write", JoinScope(scopes))
+	assert.Nil(t, ParseScope(""))
+
+	assert.True(t, Covers(scopes, []string{"write"}))
+	assert.True(t, Covers(scopes, nil))
+	assert.False(t, Covers(scopes, []string{"admin"}))
+	assert.Equal(t, []string{"read", "write", "admin"}, Merge(scopes, []string{"write", "admin"}))
+}
+
+func TestValidRedirectURI(t *testing.T) {
+	tests := []struct {
+		uri   string
+		valid bool
+	}{
+		{"https://app.example/callback", true},
+		{"http://localhost:3000/cb?x=1", true},
+		{"com.example.app:/oauth", true},
+		{"javascript:alert(1)", false},
+		{"JavaScript://app.example/%0Aalert(1)", false},
+		{"data:text/html,<script>alert(1)</script>", false},
+		{"vbscript:msgbox(1)", false},
+		{"file:///etc/passwd", false},
+		{"myapp:/oauth", false},
+		{"https://app.example/callback#frag", false},
+		{"/callback", false},
+		{"https:///callback", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.uri, func(t *testing.T) {
+				assert.Equal(t, tt.valid, ValidRedirectURI(tt.uri))
+			},
+		)
+	}
+}
+
+func TestResolveRedirectURI(t *testing.T) {
+	one := []string{"https://a.example/cb"}
+	two := []string{"https://a.example/cb", "https://b.example/cb"}
+
+	uri, ok := ResolveRedirectURI(one, "")
+	assert.True(t, ok)
+	assert.Equal(t, one[0], uri)
+
+	_, ok = ResolveRedirectURI(two, "")
+	assert.False(t, ok)
+
+	_, ok = ResolveRedirectURI(two, "https://a.example/cb/")
+	assert.False(t, ok)
+
+	uri, ok = ResolveRedirectURI(two, two[1])
+	assert.True(t, ok)
+	assert.Equal(t, two[1], uri)
+}
+
+func TestErrorRedirect(t *testing.T) {
+	uri := ErrorRedirect("https://a.example/cb?keep=1", ErrAccessDenied.WithDescription("denied"), "xyz")
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "1", u.Query().Get("keep"))
+	assert.Equal(t, "access_denied", u.Query().Get("error"))
+	assert.Equal(t, "denied", u.Query().Get("error_description"))
+	assert.Equal(t, "xyz", u.Query().Get("state"))
+}
+
+func TestError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", ErrInvalidGrant.WithDescription("code expired"))
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+	assert.NotErrorIs(t, err, ErrInvalidClient)
+
+	var oe *Error
+	require.True(t, errors.As(err, &oe))
+	assert.Equal(t, "invalid_grant: code expired", oe.Error())
+}
+
+func TestGenerateToken(t *testing.T) {
+	a, err := GenerateToken()
+	require.NoError(t, err)
+	b, err := GenerateToken()
+	require.NoError(t, err)
+
+	assert.Len(t, a, 43)
+	assert.NotEqual(t, a, b)
+}
+
+func TestGenerateUserCode(t *testing.T) {
+	code, err := GenerateUserCode()
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^[BCDFGHJKLMNPQRSTVWXZ]{4}-[BCDFGHJKLMNPQRSTVWXZ]{4}$`, code)
+	assert.Equal(t, strings.ReplaceAll(code, "-", ""), NormalizeUserCode(strings.ToLower(code)))
+	assert.Equal(t, "WDJBMJHT", NormalizeUserCode(" wdjb mjht "))
+}
@@ -0,0 +1,17 @@
+package auth
+
+// Permissions seeded by migrations. The admin role holds all of them.
+const (
+	PermUsersList        = "users:list"
+	PermUsersUpdate      = "users:update"
+	PermUsersDelete      = "users:delete"
+	PermDevicesRead      = "devices:read"
+	PermDevicesDelete    = "devices:delete"
+	PermRolesRead        = "roles:read"
+	PermRolesAssign      = "roles:assign"
+	PermKeysRotate       = "keys:rotate"
+	PermOAuthClients     = "oauth:clients"
+	PermTokensIntrospect = "tokens:introspect"
+	PermTokensRevoke     = "tokens:revoke"
+	PermServiceAccounts  = "service-accounts:manage"
+)
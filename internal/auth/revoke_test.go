@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/cache"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type memStore struct {
+	vals map[string]int64
+	err  error
+}
+
+func (s *memStore) GetToStruct(_ context.Context, key string, dest any) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	v, ok := s.vals[key]
+	if !ok {
+		return cache.ErrNotFoundInCache
+	}
+
+	*dest.(*int64) = v
+	return nil
+}
+
+func (s *memStore) Set(_ context.Context, _ time.Duration, key string, val any) {
+	switch v := val.(type) {
+	case int:
+		s.vals[key] = int64(v)
+	case int64:
+		s.vals[key] = v
+	}
+}
+
+func TestAuth_CheckRevoked(t *testing.T) {
+	ctx := context.Background()
+	claims := func(iat time.Time) jwt.Claims {
+		return jwt.Claims{
+			UID: uuid.New(),
+			RegisteredClaims: gojwt.RegisteredClaims{
+				ID:        uuid.New().String(),
+				IssuedAt:  gojwt.NewNumericDate(iat),
+				ExpiresAt: gojwt.NewNumericDate(iat.Add(time.Hour)),
+			},
+		}
+	}
+
+	t.Run("NotRevoked", func(t *testing.T) {
+		a := &Auth{store: &memStore{vals: map[string]int64{}}}
+		assert.NoError(t, a.CheckRevoked(ctx, claims(time.Now())))
+	})
+
+	t.Run("RevokedByJTI", func(t *testing.T) {
+		a := &Auth{store: &memStore{vals: map[string]int64{}}}
+		c, other := claims(time.Now()), claims(time.Now())
+		a.RevokeToken(ctx, c)
+
+		assert.ErrorIs(t, a.CheckRevoked(ctx, c), ErrTokenRevoked)
+		assert.NoError(t, a.CheckRevoked(ctx, other))
+	})
+
+	t.Run("ExpiredTokenIsNotStored", func(t *testing.T) {
+		store := &memStore{vals: map[string]int64{}}
+		a := &Auth{store: store}
+		a.RevokeToken(ctx, claims(time.Now().Add(-2*time.Hour)))
+		assert.Empty(t, store.vals)
+	})
+
+	t.Run("RevokedByWatermark", func(t *testing.T) {
+		a := &Auth{store: &memStore{vals: map[string]int64{}}}
+		old := claims(time.Now().Add(-time.Minute))
+		a.RevokeUserTokens(ctx, old.UID)
+		assert.ErrorIs(t, a.CheckRevoked(ctx, old), ErrTokenRevoked)
+
+		fresh := claims(time.Now().Add(time.Second))
+		fresh.UID = old.UID
+		assert.NoError(t, a.CheckRevoked(ctx, fresh))
+	})
+
+	t.Run("RevokedByDeviceWatermark", func(t *testing.T) {
+		a := &Auth{store: &memStore{vals: map[string]int64{}}}
+		old := claims(time.Now().Add(-time.Minute))
+		old.DeviceID = "device"
+		other := old
+		other.DeviceID = "other"
+
+		a.RevokeDeviceTokens(ctx, old.UID, old.DeviceID)
+		assert.ErrorIs(t, a.CheckRevoked(ctx, old), ErrTokenRevoked)
+		assert.NoError(t, a.CheckRevoked(ctx, other))
+	})
+
+	t.Run("StoreErrorFailsOpen", func(t *testing.T) {
+		a := &Auth{store: &memStore{err: errors.New("connection refused")}}
+		assert.NoError(t, a.CheckRevoked(ctx, claims(time.Now())))
+	})
+}
@@ -0,0 +1,14 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHashToken(t *testing.T) {
+	hashed := HashToken([]byte("key"), "token")
+	assert.Len(t, hashed, 64)
+	assert.NotContains(t, hashed, "token")
+	assert.Equal(t, hashed, HashToken([]byte("key"), "token"))
+	assert.NotEqual(t, hashed, HashToken([]byte("other"), "token"))
+}
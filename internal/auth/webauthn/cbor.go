@@ -0,0 +1,175 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalidCBOR is error that indicates malformed or unsupported CBOR.
+var ErrInvalidCBOR = errors.New("invalid cbor")
+
+const maxCBORDepth = 16
+
+// decodeCBOR decodes the first CBOR item of data and returns it with the
+// number of bytes it took. Only the subset used by WebAuthn is supported:
+// integers, byte and text strings, arrays, maps, tags, booleans and null.
+// Integers are returned as int64, maps as map[any]any.
+func decodeCBOR(data []byte) (any, int, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return v, d.off, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	off  int
+}
+
+func (d *cborDecoder) value(depth int) (any, error) {
+	if depth > maxCBORDepth {
+		return nil, ErrInvalidCBOR
+	}
+
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, ErrInvalidCBOR
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, ErrInvalidCBOR
+		}
+		return -1 - int64(arg), nil
+	case 2:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 3:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case 4:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, ErrInvalidCBOR
+		}
+
+		res := make([]any, 0, arg)
+		for range arg {
+			v, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+		return res, nil
+	case 5:
+		if arg > uint64(len(d.data)-d.off)/2 {
+			return nil, ErrInvalidCBOR
+		}
+
+		res := make(map[any]any, arg)
+		for range arg {
+			k, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, ErrInvalidCBOR
+			}
+
+			v, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = v
+		}
+		return res, nil
+	case 6:
+		return d.value(depth + 1)
+	default:
+		switch arg {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		}
+		return nil, ErrInvalidCBOR
+	}
+}
+
+// head reads the initial byte and its argument. Indefinite lengths are not
+// allowed, as CTAP2 requires the canonical encoding.
+func (d *cborDecoder) head() (byte, uint64, error) {
+	if d.off >= len(d.data) {
+		return 0, 0, ErrInvalidCBOR
+	}
+
+	b := d.data[d.off]
+	d.off++
+
+	major, info := b>>5, b&0x1f
+	if major == 7 && info >= 24 {
+		return 0, 0, ErrInvalidCBOR
+	}
+
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		v, err := d.bytes(1)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(v[0]), nil
+	case info == 25:
+		v, err := d.bytes(2)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint16(v)), nil
+	case info == 26:
+		v, err := d.bytes(4)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, uint64(binary.BigEndian.Uint32(v)), nil
+	case info == 27:
+		v, err := d.bytes(8)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, binary.BigEndian.Uint64(v), nil
+	default:
+		return 0, 0, ErrInvalidCBOR
+	}
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrInvalidCBOR
+	}
+
+	b := d.data[d.off : d.off+int(n)] //nolint:gosec // bounded by len(d.data)
+	d.off += int(n)                   //nolint:gosec
+	return b, nil
+}
@@ -0,0 +1,60 @@
+package webauthn
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected any
+		n        int
+	}{
+		{name: "Uint", data: []byte{0x18, 0x64}, expected: int64(100), n: 2},
+		{name: "NegInt", data: []byte{0x38, 0x63}, expected: int64(-100), n: 2},
+		{name: "Bytes", data: []byte{0x42, 0x01, 0x02}, expected: []byte{1, 2}, n: 3},
+		{name: "Text", data: []byte{0x62, 'h', 'i'}, expected: "hi", n: 3},
+		{name: "Array", data: []byte{0x82, 0x01, 0xf5}, expected: []any{int64(1), true}, n: 3},
+		{
+			name:     "Map",
+			data:     []byte{0xa2, 0x01, 0x02, 0x61, 'k', 0xf6},
+			expected: map[any]any{int64(1): int64(2), "k": nil},
+			n:        6,
+		},
+		{name: "TrailingData", data: []byte{0x01, 0x02}, expected: int64(1), n: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, n, err := decodeCBOR(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, v)
+			assert.Equal(t, tt.n, n)
+		})
+	}
+}
+
+func TestDecodeCBOR_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Empty", data: nil},
+		{name: "ShortBytes", data: []byte{0x45, 0x01}},
+		{name: "HugeArray", data: []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{name: "Indefinite", data: []byte{0x9f, 0x01, 0xff}},
+		{name: "Float", data: []byte{0xf9, 0x3c, 0x00}},
+		{name: "ArrayKey", data: []byte{0xa1, 0x80, 0x01}},
+		{name: "TooDeep", data: []byte{0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeCBOR(tt.data)
+			assert.ErrorIs(t, err, ErrInvalidCBOR)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithm identifiers the relying party accepts, in order of
+// preference.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+const (
+	coseKty = 1
+	coseAlg = 3
+
+	ktyOKP = 1
+	ktyEC2 = 2
+	ktyRSA = 3
+
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+// ErrUnsupportedKey is error that indicates a credential key of a type or algorithm we don't verify.
+var ErrUnsupportedKey = errors.New("unsupported credential key")
+
+// publicKey is a parsed COSE_Key.
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func parsePublicKey(raw []byte) (*publicKey, error) {
+	v, n, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := v.(map[any]any)
+	if !ok || n != len(raw) {
+		return nil, ErrUnsupportedKey
+	}
+
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+	crv, _ := m[int64(-1)].(int64)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256 && crv == crvP256:
+		x, okX := m[int64(-2)].([]byte)
+		y, okY := m[int64(-3)].([]byte)
+		if !okX || !okY || len(x) != 32 || len(y) != 32 {
+			return nil, ErrUnsupportedKey
+		}
+
+		// Uncompressed point encoding validates that it is on the curve.
+		point := append(append([]byte{4}, x...), y...)
+		if _, err = ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, ErrUnsupportedKey
+		}
+
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		return &publicKey{alg: alg, key: pub}, nil
+	case kty == ktyOKP && alg == AlgEdDSA && crv == crvEd25519:
+		x, ok := m[int64(-2)].([]byte)
+		if !ok || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		nb, okN := m[int64(-1)].([]byte)
+		eb, okE := m[int64(-2)].([]byte)
+		if !okN || !okE || len(nb) < 256 || len(eb) == 0 || len(eb) > 4 {
+			return nil, ErrUnsupportedKey
+		}
+
+		e := new(big.Int).SetBytes(eb)
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(e.Int64())}
+		return &publicKey{alg: alg, key: pub}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// verify checks sig over data with the key's algorithm.
+func (k *publicKey) verify(data, sig []byte) bool {
+	switch pub := k.key.(type) {
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(pub, sum[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, data, sig)
+	case *rsa.PublicKey:
+		sum := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig) == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,437 @@
+// Package webauthn implements the relying party side of the WebAuthn
+// registration and assertion ceremonies for passkeys.
+//
+// Options ask for no attestation, so the attestation statement is not
+// verified and a credential is trusted on first use.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	CeremonyCreate = "webauthn.create"
+	CeremonyGet    = "webauthn.get"
+
+	PublicKeyType = "public-key"
+
+	challengeSize   = 32
+	maxCredentialID = 1023
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagBackupElig   = 0x08
+	flagAttested     = 0x40
+	flagExtensions   = 0x80
+)
+
+var (
+	// ErrInvalidClientData is error that indicates clientDataJSON of the wrong ceremony, challenge or origin.
+	ErrInvalidClientData = errors.New("invalid client data")
+	// ErrInvalidAuthData is error that indicates malformed authenticator data or a foreign RP ID.
+	ErrInvalidAuthData = errors.New("invalid authenticator data")
+	// ErrUserNotVerified is error that indicates the authenticator did not verify the user.
+	ErrUserNotVerified = errors.New("user not verified")
+	// ErrInvalidSignature is error that indicates an assertion signature that doesn't match the credential.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrSignCount is error that indicates a signature counter that went backwards, a sign of a cloned authenticator.
+	ErrSignCount = errors.New("signature counter did not increase")
+)
+
+// Bytes is a byte slice that is base64url encoded in JSON, as the WebAuthn
+// JSON serialization expects.
+type Bytes []byte
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+
+	res, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	*b = res
+	return nil
+}
+
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type User struct {
+	ID          Bytes  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         Bytes    `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions are passed to navigator.credentials.create.
+type CreationOptions struct {
+	Challenge              Bytes                  `json:"challenge"`
+	RP                     RelyingParty           `json:"rp"`
+	User                   User                   `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are passed to navigator.credentials.get.
+type RequestOptions struct {
+	Challenge        Bytes                  `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int64                  `json:"timeout"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+type AttestationResponse struct {
+	ClientDataJSON    Bytes    `json:"clientDataJSON"    validate:"required"`
+	AttestationObject Bytes    `json:"attestationObject" validate:"required"`
+	Transports        []string `json:"transports"`
+}
+
+// RegistrationResponse is the PublicKeyCredential returned by create.
+type RegistrationResponse struct {
+	ID       string              `json:"id"       validate:"required"`
+	Type     string              `json:"type"     validate:"required"`
+	Response AttestationResponse `json:"response" validate:"required"`
+}
+
+type AssertionResponse struct {
+	ClientDataJSON    Bytes `json:"clientDataJSON"    validate:"required"`
+	AuthenticatorData Bytes `json:"authenticatorData" validate:"required"`
+	Signature         Bytes `json:"signature"         validate:"required"`
+	UserHandle        Bytes `json:"userHandle"`
+}
+
+// LoginResponse is the PublicKeyCredential returned by get.
+type LoginResponse struct {
+	ID       string            `json:"id"       validate:"required"`
+	Type     string            `json:"type"     validate:"required"`
+	Response AssertionResponse `json:"response" validate:"required"`
+}
+
+type ClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// Credential is a verified public key credential.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	Alg            int64
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	BackupEligible bool
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// WebAuthn is a relying party.
+type WebAuthn struct {
+	rpID    string
+	rpName  string
+	origins []string
+	timeout time.Duration
+}
+
+func New(rpID, rpName string, origins []string, timeout time.Duration) *WebAuthn {
+	return &WebAuthn{
+		rpID:    rpID,
+		rpName:  rpName,
+		origins: origins,
+		timeout: timeout,
+	}
+}
+
+// NewChallenge returns a random ceremony challenge.
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, challengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// CreationOptions builds the registration options for user. Credentials in
+// exclude are already registered and won't be created again.
+func (w *WebAuthn) CreationOptions(challenge []byte, user User, exclude []CredentialDescriptor) *CreationOptions {
+	return &CreationOptions{
+		Challenge: challenge,
+		RP:        RelyingParty{ID: w.rpID, Name: w.rpName},
+		User:      user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: PublicKeyType, Alg: AlgES256},
+			{Type: PublicKeyType, Alg: AlgEdDSA},
+			{Type: PublicKeyType, Alg: AlgRS256},
+		},
+		Timeout:            w.timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "required",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions builds the login options. An empty allow list lets the
+// authenticator offer any discoverable credential for the RP.
+func (w *WebAuthn) RequestOptions(challenge []byte, allow []CredentialDescriptor) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        challenge,
+		RPID:             w.rpID,
+		Timeout:          w.timeout.Milliseconds(),
+		AllowCredentials: allow,
+		UserVerification: "required",
+	}
+}
+
+// ParseClientData decodes clientDataJSON without verifying it, so the
+// challenge can be used to find the ceremony state.
+func ParseClientData(raw []byte) (*ClientData, []byte, error) {
+	cd := &ClientData{}
+	if err := json.Unmarshal(raw, cd); err != nil {
+		return nil, nil, ErrInvalidClientData
+	}
+
+	challenge, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(cd.Challenge, "="))
+	if err != nil {
+		return nil, nil, ErrInvalidClientData
+	}
+
+	return cd, challenge, nil
+}
+
+// VerifyRegistration checks the result of navigator.credentials.create
+// against the challenge it was started with.
+func (w *WebAuthn) VerifyRegistration(challenge []byte, resp *RegistrationResponse) (*Credential, error) {
+	if resp.Type != PublicKeyType {
+		return nil, ErrInvalidClientData
+	}
+
+	if err := w.verifyClientData(resp.Response.ClientDataJSON, CeremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	v, n, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := v.(map[any]any)
+	if !ok || n != len(resp.Response.AttestationObject) {
+		return nil, ErrInvalidCBOR
+	}
+
+	raw, ok := obj["authData"].([]byte)
+	if !ok {
+		return nil, ErrInvalidAuthData
+	}
+
+	ad, err := w.parseAuthData(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if ad.flags&flagAttested == 0 {
+		return nil, ErrInvalidAuthData
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(resp.ID)
+	if err != nil || !bytes.Equal(id, ad.credentialID) {
+		return nil, ErrInvalidAuthData
+	}
+
+	key, err := parsePublicKey(ad.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             ad.credentialID,
+		PublicKey:      ad.publicKey,
+		Alg:            key.alg,
+		SignCount:      ad.signCount,
+		AAGUID:         ad.aaguid,
+		Transports:     resp.Response.Transports,
+		BackupEligible: ad.flags&flagBackupElig != 0,
+	}, nil
+}
+
+// VerifyAssertion checks the result of navigator.credentials.get made with
+// cred and returns the new signature counter.
+func (w *WebAuthn) VerifyAssertion(challenge []byte, cred *Credential, resp *LoginResponse) (uint32, error) {
+	if resp.Type != PublicKeyType {
+		return 0, ErrInvalidClientData
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(resp.ID)
+	if err != nil || !bytes.Equal(id, cred.ID) {
+		return 0, ErrInvalidAuthData
+	}
+
+	if err = w.verifyClientData(resp.Response.ClientDataJSON, CeremonyGet, challenge); err != nil {
+		return 0, err
+	}
+
+	ad, err := w.parseAuthData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(cred.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), resp.Response.AuthenticatorData...), clientDataHash[:]...)
+	if !key.verify(signed, resp.Response.Signature) {
+		return 0, ErrInvalidSignature
+	}
+
+	if (ad.signCount != 0 || cred.SignCount != 0) && ad.signCount <= cred.SignCount {
+		return 0, ErrSignCount
+	}
+
+	return ad.signCount, nil
+}
+
+func (w *WebAuthn) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	cd, got, err := ParseClientData(raw)
+	if err != nil {
+		return err
+	}
+
+	if cd.Type != ceremony || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return ErrInvalidClientData
+	}
+
+	if !slices.Contains(w.origins, cd.Origin) {
+		return ErrInvalidClientData
+	}
+
+	return nil
+}
+
+// parseAuthData decodes authenticator data and checks the RP ID hash and the
+// user presence and verification flags.
+func (w *WebAuthn) parseAuthData(raw []byte) (*authenticatorData, error) {
+	const minLen = 37
+	if len(raw) < minLen {
+		return nil, ErrInvalidAuthData
+	}
+
+	ad := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	rpIDHash := sha256.Sum256([]byte(w.rpID))
+	if subtle.ConstantTimeCompare(ad.rpIDHash, rpIDHash[:]) != 1 {
+		return nil, ErrInvalidAuthData
+	}
+
+	if ad.flags&flagUserPresent == 0 {
+		return nil, ErrInvalidAuthData
+	}
+
+	if ad.flags&flagUserVerified == 0 {
+		return nil, ErrUserNotVerified
+	}
+
+	rest := raw[minLen:]
+	if ad.flags&flagAttested != 0 {
+		if len(rest) < 18 {
+			return nil, ErrInvalidAuthData
+		}
+
+		ad.aaguid = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLen == 0 || idLen > maxCredentialID || len(rest) < idLen {
+			return nil, ErrInvalidAuthData
+		}
+
+		ad.credentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidAuthData
+		}
+
+		ad.publicKey = rest[:n]
+		rest = rest[n:]
+	}
+
+	if ad.flags&flagExtensions != 0 {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidAuthData
+		}
+
+		rest = rest[n:]
+	}
+
+	if len(rest) != 0 {
+		return nil, ErrInvalidAuthData
+	}
+
+	return ad, nil
+}
@@ -0,0 +1,168 @@
+package webauthn_test
+
+import (
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn"
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn/webauthntest"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const (
+	rpID   = "example.com"
+	origin = "https://example.com"
+)
+
+func register(t *testing.T, rp *webauthn.WebAuthn, a *webauthntest.Authenticator) *webauthn.Credential {
+	t.Helper()
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	opts := rp.CreationOptions(challenge, webauthn.User{ID: []byte("user"), Name: "user@example.com"}, nil)
+	resp, err := a.Register(opts)
+	require.NoError(t, err)
+
+	cred, err := rp.VerifyRegistration(challenge, resp)
+	require.NoError(t, err)
+	return cred
+}
+
+func TestWebAuthn_Ceremonies(t *testing.T) {
+	rp := webauthn.New(rpID, "sso", []string{origin}, time.Minute)
+	a, err := webauthntest.New(rpID, origin)
+	require.NoError(t, err)
+
+	cred := register(t, rp, a)
+	assert.Equal(t, a.CredentialID, cred.ID)
+	assert.Equal(t, webauthn.AlgES256, cred.Alg)
+	assert.Equal(t, []string{"internal"}, cred.Transports)
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	resp, err := a.Login(rp.RequestOptions(challenge, nil))
+	require.NoError(t, err)
+
+	count, err := rp.VerifyAssertion(challenge, cred, resp)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), count)
+
+	// A replayed assertion doesn't advance the counter
+	cred.SignCount = count
+	_, err = rp.VerifyAssertion(challenge, cred, resp)
+	assert.ErrorIs(t, err, webauthn.ErrSignCount)
+}
+
+func TestWebAuthn_VerifyRegistration(t *testing.T) {
+	rp := webauthn.New(rpID, "sso", []string{origin}, time.Minute)
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		rpID   string
+		origin string
+		flags  byte
+		err    error
+	}{
+		{name: "WrongOrigin", rpID: rpID, origin: "https://evil.com", err: webauthn.ErrInvalidClientData},
+		{name: "WrongRPID", rpID: "evil.com", origin: origin, err: webauthn.ErrInvalidAuthData},
+		{name: "UserNotVerified", rpID: rpID, origin: origin, flags: 0x01, err: webauthn.ErrUserNotVerified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := webauthntest.New(tt.rpID, tt.origin)
+			require.NoError(t, err)
+			a.Flags = tt.flags
+
+			resp, err := a.Register(rp.CreationOptions(challenge, webauthn.User{ID: []byte("user")}, nil))
+			require.NoError(t, err)
+
+			_, err = rp.VerifyRegistration(challenge, resp)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("WrongChallenge", func(t *testing.T) {
+		a, err := webauthntest.New(rpID, origin)
+		require.NoError(t, err)
+
+		resp, err := a.Register(rp.CreationOptions(challenge, webauthn.User{ID: []byte("user")}, nil))
+		require.NoError(t, err)
+
+		_, err = rp.VerifyRegistration([]byte("other"), resp)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidClientData)
+	})
+
+	t.Run("TruncatedAttestation", func(t *testing.T) {
+		a, err := webauthntest.New(rpID, origin)
+		require.NoError(t, err)
+
+		resp, err := a.Register(rp.CreationOptions(challenge, webauthn.User{ID: []byte("user")}, nil))
+		require.NoError(t, err)
+
+		obj := resp.Response.AttestationObject
+		resp.Response.AttestationObject = obj[:len(obj)-10]
+		_, err = rp.VerifyRegistration(challenge, resp)
+		assert.Error(t, err)
+	})
+}
+
+func TestWebAuthn_VerifyAssertion(t *testing.T) {
+	rp := webauthn.New(rpID, "sso", []string{origin}, time.Minute)
+	a, err := webauthntest.New(rpID, origin)
+	require.NoError(t, err)
+	cred := register(t, rp, a)
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	t.Run("OtherCredentialID", func(t *testing.T) {
+		other, err := webauthntest.New(rpID, origin)
+		require.NoError(t, err)
+
+		resp, err := other.Login(rp.RequestOptions(challenge, nil))
+		require.NoError(t, err)
+
+		_, err = rp.VerifyAssertion(challenge, cred, resp)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidAuthData)
+	})
+
+	t.Run("SignedByOtherKey", func(t *testing.T) {
+		other, err := webauthntest.New(rpID, origin)
+		require.NoError(t, err)
+		other.CredentialID = a.CredentialID
+
+		resp, err := other.Login(rp.RequestOptions(challenge, nil))
+		require.NoError(t, err)
+
+		_, err = rp.VerifyAssertion(challenge, cred, resp)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidSignature)
+	})
+
+	t.Run("WrongCeremony", func(t *testing.T) {
+		resp, err := a.Register(rp.CreationOptions(challenge, webauthn.User{ID: []byte("user")}, nil))
+		require.NoError(t, err)
+
+		login, err := a.Login(rp.RequestOptions(challenge, nil))
+		require.NoError(t, err)
+
+		login.Response.ClientDataJSON = resp.Response.ClientDataJSON
+		_, err = rp.VerifyAssertion(challenge, cred, login)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidClientData)
+	})
+}
+
+func TestBytes_JSON(t *testing.T) {
+	b, err := json.Marshal(webauthn.Bytes{0xfb, 0xff})
+	require.NoError(t, err)
+	assert.Equal(t, `"-_8"`, string(b))
+
+	var res webauthn.Bytes
+	require.NoError(t, json.Unmarshal([]byte(`"+/8="`), &res))
+	assert.Equal(t, webauthn.Bytes{0xfb, 0xff}, res)
+}
@@ -0,0 +1,194 @@
+// Package webauthntest provides a software authenticator that performs the
+// client side of WebAuthn ceremonies for tests.
+package webauthntest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn"
+	"github.com/goccy/go-json"
+)
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// Authenticator holds a single ES256 credential.
+type Authenticator struct {
+	RPID         string
+	Origin       string
+	CredentialID []byte
+	UserHandle   []byte
+	SignCount    uint32
+	// Flags overrides the user presence and verification flags when set.
+	Flags byte
+
+	key *ecdsa.PrivateKey
+}
+
+func New(rpID, origin string) (*Authenticator, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &Authenticator{
+		RPID:         rpID,
+		Origin:       origin,
+		CredentialID: id,
+		key:          key,
+	}, nil
+}
+
+// Register answers creation options with a "none" attestation.
+func (a *Authenticator) Register(opts *webauthn.CreationOptions) (*webauthn.RegistrationResponse, error) {
+	a.UserHandle = opts.User.ID
+
+	clientData, err := a.clientData(webauthn.CeremonyCreate, opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdh, err := a.key.PublicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+
+	point := ecdh.Bytes()
+	coseKey := encode(
+		cborMap{
+			{int64(1), int64(2)},
+			{int64(3), webauthn.AlgES256},
+			{int64(-1), int64(1)},
+			{int64(-2), point[1:33]},
+			{int64(-3), point[33:]},
+		},
+	)
+
+	authData := a.authData(flagAttested)
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.CredentialID))) //nolint:gosec
+	authData = append(authData, a.CredentialID...)
+	authData = append(authData, coseKey...)
+
+	return &webauthn.RegistrationResponse{
+		ID:   base64.RawURLEncoding.EncodeToString(a.CredentialID),
+		Type: webauthn.PublicKeyType,
+		Response: webauthn.AttestationResponse{
+			ClientDataJSON: clientData,
+			AttestationObject: encode(
+				cborMap{
+					{"fmt", "none"},
+					{"attStmt", cborMap{}},
+					{"authData", authData},
+				},
+			),
+			Transports: []string{"internal"},
+		},
+	}, nil
+}
+
+// Login answers request options and increments the signature counter.
+func (a *Authenticator) Login(opts *webauthn.RequestOptions) (*webauthn.LoginResponse, error) {
+	clientData, err := a.clientData(webauthn.CeremonyGet, opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	a.SignCount++
+	authData := a.authData(0)
+
+	hash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), hash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.LoginResponse{
+		ID:   base64.RawURLEncoding.EncodeToString(a.CredentialID),
+		Type: webauthn.PublicKeyType,
+		Response: webauthn.AssertionResponse{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authData,
+			Signature:         sig,
+			UserHandle:        a.UserHandle,
+		},
+	}, nil
+}
+
+func (a *Authenticator) clientData(ceremony string, challenge []byte) ([]byte, error) {
+	return json.Marshal(
+		map[string]any{
+			"type":        ceremony,
+			"challenge":   base64.RawURLEncoding.EncodeToString(challenge),
+			"origin":      a.Origin,
+			"crossOrigin": false,
+		},
+	)
+}
+
+func (a *Authenticator) authData(extra byte) []byte {
+	flags := a.Flags
+	if flags == 0 {
+		flags = flagUserPresent | flagUserVerified
+	}
+
+	rpIDHash := sha256.Sum256([]byte(a.RPID))
+	res := append([]byte(nil), rpIDHash[:]...)
+	res = append(res, flags|extra)
+	return binary.BigEndian.AppendUint32(res, a.SignCount)
+}
+
+// cborMap keeps the key order, as CTAP2 canonical encoding requires.
+type cborMap [][2]any
+
+func encode(v any) []byte {
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case cborMap:
+		res := head(5, uint64(len(v)))
+		for _, kv := range v {
+			res = append(res, encode(kv[0])...)
+			res = append(res, encode(kv[1])...)
+		}
+		return res
+	default:
+		panic("webauthntest: unsupported cbor value")
+	}
+}
+
+func head(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, n)
+	}
+}
@@ -49,11 +49,25 @@ func (c *Cache) Close(ctx context.Context) error {
 }
 
 func (c *Cache) GetToStruct(ctx context.Context, key string, dest any) error {
-	const op = "cache.GetToStruct"
+	return c.toStruct(ctx, "cache.GetToStruct", key, dest, c.cli.Get)
+}
+
+// GetDelToStruct reads the value under key and deletes it in one step, so
+// concurrent callers can't both get it.
+func (c *Cache) GetDelToStruct(ctx context.Context, key string, dest any) error {
+	return c.toStruct(ctx, "cache.GetDelToStruct", key, dest, c.cli.GetDel)
+}
+
+func (c *Cache) toStruct(
+	ctx context.Context,
+	op, key string,
+	dest any,
+	get func(ctx context.Context, key string) *redis.StringCmd,
+) error {
 	span, ctx := ot.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
-	val, err := c.cli.Get(ctx, key).Bytes()
+	val, err := get(ctx, key).Bytes()
 	if errors.Is(err, redis.Nil) {
 		zap.L().Info(
 			"[CACHE] --> MISS",
@@ -106,6 +120,38 @@ func (c *Cache) Set(ctx context.Context, t time.Duration, key string, val any) {
 	return
 }
 
+// incrScript starts the expiry with the first increment, so the window is
+// not extended by later ones.
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// Incr atomically increments the counter under key and returns its new
+// value. The counter expires t after it was created.
+func (c *Cache) Incr(ctx context.Context, t time.Duration, key string) (int64, error) {
+	const op = "cache.Incr"
+	span, ctx := ot.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	n, err := incrScript.Run(ctx, c.cli, []string{key}, t.Milliseconds()).Int64()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"[CACHE] --> ERROR",
+			zap.String("op", op),
+			zap.String("t", t.String()), zap.String("key", key),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+
+	return n, nil
+}
+
 func (c *Cache) Delete(ctx context.Context, key string) {
 	const op = "cache.Delete"
 	span, ctx := ot.StartSpanFromContext(ctx, op)
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
+	"github.com/go-redis/redis/v8"
+	ot "github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+// gcraScript implements the generic cell rate algorithm. The key holds the
+// theoretical arrival time of the next request in microseconds of the Redis
+// clock, so every instance of the service shares one view of time. A
+// request is allowed while that time is at most one period ahead of now.
+var gcraScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+if new_tat - now > period then
+	return {0, 0, tat - now, new_tat - now - period}
+end
+
+redis.call("SET", KEYS[1], string.format("%.0f", new_tat), "PX", math.ceil((new_tat - now) / 1000))
+return {1, math.floor((period - (new_tat - now)) / interval), new_tat - now, 0}
+`)
+
+// Allow counts a request under key against limit requests per period.
+func (c *Cache) Allow(ctx context.Context, key string, limit int, period time.Duration) (ratelimit.Result, error) {
+	const op = "cache.Allow"
+	span, ctx := ot.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	interval := max(period.Microseconds()/int64(limit), 1)
+	res, err := gcraScript.Run(ctx, c.cli, []string{key}, interval, period.Microseconds()).Int64Slice()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"[CACHE] --> ERROR",
+			zap.String("op", op),
+			zap.String("key", key),
+			zap.Error(err),
+		)
+		return ratelimit.Result{}, err
+	}
+
+	return ratelimit.Result{
+		Allowed:    res[0] == 1,
+		Limit:      limit,
+		Remaining:  int(res[1]),
+		ResetAfter: time.Duration(res[2]) * time.Microsecond,
+		RetryAfter: time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/caarlos0/env/v9"
 	"github.com/joho/godotenv"
@@ -17,35 +18,86 @@ type Config struct {
 	DB          dbConfig
 	Minio       s3Config
 	Redis       redisConfig
+	RateLimit   rateLimitConfig
 	Jaeger      jaegerConfig
 }
 
 type ServerConfig struct {
-	Scheme   string `env:"SERVER_SCHEME"             envDefault:"http"`
-	Domain   string `env:"SERVER_DOMAIN"             envDefault:"localhost"`
-	Port     int    `env:"SERVER_HTTP_PORT,required"`
-	GRPCPort int    `env:"SERVER_GRPC_PORT"          envDefault:"50050"`
-	PromPort int    `env:"SERVER_PROM_PORT"          envDefault:"8085"`
+	Scheme         string   `env:"SERVER_SCHEME"             envDefault:"http"`
+	Domain         string   `env:"SERVER_DOMAIN"             envDefault:"localhost"`
+	Port           int      `env:"SERVER_HTTP_PORT,required"`
+	GRPCPort       int      `env:"SERVER_GRPC_PORT"          envDefault:"50050"`
+	PromPort       int      `env:"SERVER_PROM_PORT"          envDefault:"8085"`
+	TrustedProxies []string `env:"SERVER_TRUSTED_PROXIES"                                  envSeparator:","`
 }
 
 type authConfig struct {
 	JWT struct {
-		Secret string `env:"JWT_SECRET,required"`
-		Issuer string `env:"JWT_ISSUER,required"`
+		Secret          string        `env:"JWT_SECRET"`
+		Issuer          string        `env:"JWT_ISSUER,required"`
+		Alg             string        `env:"JWT_ALG"              envDefault:"HS256"`
+		KeyFile         string        `env:"JWT_KEY_FILE"`
+		KeyID           string        `env:"JWT_KEY_ID"`
+		VerifyKeyFiles  []string      `env:"JWT_VERIFY_KEY_FILES"                      envSeparator:","`
+		VerifySecrets   []string      `env:"JWT_VERIFY_SECRETS"                        envSeparator:","`
+		RetireAfter     time.Duration `env:"JWT_KEY_RETIRE_AFTER" envDefault:"168h"`
+		RefreshInterval time.Duration `env:"JWT_KEY_REFRESH_INTERVAL" envDefault:"1m"`
+	}
+	OIDC struct {
+		Issuer string `env:"OIDC_ISSUER"`
 	}
 	Captcha struct {
 		Enabled bool   `env:"CAPTCHA_ENABLED" envDefault:"false"`
 		SiteKey string `env:"CAPTCHA_SITE_KEY"`
 		Secret  string `env:"CAPTCHA_SECRET"`
 	}
+	WebAuthn struct {
+		RPID    string   `env:"WEBAUTHN_RP_ID"   envDefault:"localhost"`
+		RPName  string   `env:"WEBAUTHN_RP_NAME" envDefault:"sso"`
+		Origins []string `env:"WEBAUTHN_ORIGINS" envDefault:"http://localhost:8080" envSeparator:","`
+	}
+	Admin struct {
+		Email    string `env:"AUTH_ADMIN_EMAIL"`
+		Password string `env:"AUTH_ADMIN_PASSWORD"`
+	}
+	Session struct {
+		IdleTimeout             time.Duration `env:"AUTH_SESSION_IDLE_TIMEOUT"              envDefault:"24h"`
+		AbsoluteTimeout         time.Duration `env:"AUTH_SESSION_ABSOLUTE_TIMEOUT"          envDefault:"72h"`
+		RememberIdleTimeout     time.Duration `env:"AUTH_SESSION_REMEMBER_IDLE_TIMEOUT"     envDefault:"168h"`
+		RememberAbsoluteTimeout time.Duration `env:"AUTH_SESSION_REMEMBER_ABSOLUTE_TIMEOUT" envDefault:"720h"`
+	}
+	Lockout struct {
+		Threshold   int           `env:"AUTH_LOCKOUT_THRESHOLD"    envDefault:"10"`
+		IPThreshold int           `env:"AUTH_LOCKOUT_IP_THRESHOLD" envDefault:"100"`
+		Window      time.Duration `env:"AUTH_LOCKOUT_WINDOW"       envDefault:"1h"`
+		Duration    time.Duration `env:"AUTH_LOCKOUT_DURATION"     envDefault:"15m"`
+		BaseDelay   time.Duration `env:"AUTH_LOCKOUT_BASE_DELAY"   envDefault:"1s"`
+		MaxDelay    time.Duration `env:"AUTH_LOCKOUT_MAX_DELAY"    envDefault:"30s"`
+	}
+	RequireVerifiedEmail bool     `env:"AUTH_REQUIRE_VERIFIED_EMAIL" envDefault:"false"`
+	TokenLookup          []string `env:"AUTH_TOKEN_LOOKUP"           envDefault:"header,cookie" envSeparator:","`
+	TokenHashKey         string   `env:"AUTH_TOKEN_HASH_KEY,required"`
+	DeviceKey            string   `env:"AUTH_DEVICE_KEY,required"`
+	EncryptionKey        string   `env:"AUTH_ENCRYPTION_KEY,required"`
 }
 
 type smtpConfig struct {
-	Server string `env:"EMAIL_SERVER" envDefault:"smtp.gmail.com"`
-	Port   int    `env:"EMAIL_PORT"   envDefault:"587"`
-	User   string `env:"EMAIL_USER"   envDefault:""`
-	Pass   string `env:"EMAIL_PASS"   envDefault:""`
-	Admin  string `env:"EMAIL_ADMIN"  envDefault:""`
+	Driver string `env:"EMAIL_DRIVER"   envDefault:"smtp"`
+	Server string `env:"EMAIL_SERVER"   envDefault:"smtp.gmail.com"`
+	Port   int    `env:"EMAIL_PORT"     envDefault:"587"`
+	User   string `env:"EMAIL_USER"     envDefault:""`
+	Pass   string `env:"EMAIL_PASS"     envDefault:""`
+	Admin  string `env:"EMAIL_ADMIN"    envDefault:""`
+	Locale string `env:"EMAIL_LOCALE"   envDefault:"en"`
+	Dir    string `env:"EMAIL_FILE_DIR" envDefault:"./tmp/emails"`
+	Queue  struct {
+		Interval    time.Duration `env:"EMAIL_QUEUE_INTERVAL"     envDefault:"5s"`
+		Batch       int           `env:"EMAIL_QUEUE_BATCH"        envDefault:"20"`
+		MaxAttempts int           `env:"EMAIL_QUEUE_MAX_ATTEMPTS" envDefault:"8"`
+		BaseDelay   time.Duration `env:"EMAIL_QUEUE_BASE_DELAY"   envDefault:"30s"`
+		MaxDelay    time.Duration `env:"EMAIL_QUEUE_MAX_DELAY"    envDefault:"1h"`
+		Retention   time.Duration `env:"EMAIL_QUEUE_RETENTION"    envDefault:"24h"`
+	}
 }
 
 type dbConfig struct {
@@ -69,6 +121,11 @@ type redisConfig struct {
 	Pass string `env:"REDIS_PASS" envDefault:""`
 }
 
+type rateLimitConfig struct {
+	Enabled  bool     `env:"RATE_LIMIT_ENABLED"  envDefault:"true"`
+	Policies []string `env:"RATE_LIMIT_POLICIES"                   envSeparator:","`
+}
+
 type jaegerConfig struct {
 	Sampler struct {
 		Type  string  `env:"JAEGER_SAMPLER_TYPE" envDefault:"const"`
@@ -81,6 +138,16 @@ type jaegerConfig struct {
 	} `yaml:"reporter"`
 }
 
+// OIDCIssuer returns the OpenID Connect issuer, the public URL of the server
+// unless OIDC_ISSUER overrides it.
+func (c Config) OIDCIssuer() string {
+	if c.Auth.OIDC.Issuer != "" {
+		return c.Auth.OIDC.Issuer
+	}
+
+	return c.Server.Scheme + "://" + c.Server.Domain
+}
+
 func MustLoad(path string) Config {
 	if err := godotenv.Load(path); err != nil {
 		if !os.IsNotExist(err) {
@@ -5,9 +5,13 @@ import "time"
 type ctxKey string
 
 const (
-	UidKey ctxKey = "uid"
-	IpKey  ctxKey = "ip"
-	UaKey  ctxKey = "ua"
+	UidKey    ctxKey = "uid"
+	IpKey     ctxKey = "ip"
+	UaKey     ctxKey = "ua"
+	LocaleKey ctxKey = "locale"
+	ClaimsKey ctxKey = "claims"
+	DeviceKey ctxKey = "device"
+	APIKeyKey ctxKey = "apikey"
 )
 
 const (
@@ -19,10 +23,39 @@ const (
 )
 
 const (
+	AuthModeHeader       = "X-Auth-Mode"
+	AuthModeToken        = "token"
 	AccessCookieName     = "access"
 	RefreshCookieName    = "refresh"
+	DeviceCookieName     = "device"
+	DeviceIDHeader       = "X-Device-ID"
+	DeviceCookieDuration = time.Hour * 24 * 365
 	AccessTokenDuration  = time.Minute * 30
 	RefreshTokenDuration = time.Hour * 24 * 7
+	KeyRefreshCooldown   = time.Second * 10
+)
+
+const (
+	RecoveryCodeDuration = time.Minute * 15
+	VerifyCodeDuration   = time.Hour * 24
+	VerifyResendCooldown = time.Minute
+	LoginCodeDuration    = time.Minute * 5
+	CodeResendCooldown   = time.Minute
+	MaxCodeAttempts      = 5
+	TwoFactorDuration    = time.Minute * 5
+	TOTPSkew             = 1
+	MaxTOTPFailures      = 5
+	TOTPLockDuration     = time.Minute * 15
+	RecoveryCodesCount   = 10
+	WebAuthnDuration     = time.Minute * 5
+)
+
+const (
+	OAuthCodeDuration         = time.Minute
+	OAuthRefreshTokenDuration = time.Hour * 24 * 30
+	DeviceCodeDuration        = time.Minute * 10
+	DeviceCodeInterval        = time.Second * 5
+	MaxUserCodeChecks         = 10
 )
 
 const ErrorSpanTag = "error"
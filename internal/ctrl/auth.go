@@ -3,9 +3,12 @@ package ctrl
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/dto"
 	md "github.com/JMURv/golang-clean-template/internal/models"
 	"github.com/JMURv/golang-clean-template/internal/repo"
@@ -15,18 +18,30 @@ import (
 )
 
 type authCtrl interface {
-	GenPair(ctx context.Context, d *dto.DeviceRequest, uid uuid.UUID) (dto.TokenPair, error)
+	GenPair(ctx context.Context, d *dto.DeviceRequest, uid uuid.UUID, remember bool) (dto.TokenPair, error)
 	Authenticate(
 		ctx context.Context,
 		d *dto.DeviceRequest,
 		req *dto.EmailAndPasswordRequest,
-	) (*dto.TokenPair, error)
+	) (*dto.TokenPair, *dto.TwoFactorChallenge, error)
 	Refresh(
 		ctx context.Context,
 		d *dto.DeviceRequest,
 		req *dto.RefreshRequest,
 	) (*dto.TokenPair, error)
-	Logout(ctx context.Context, uid uuid.UUID) error
+	Logout(ctx context.Context, claims jwt.Claims, deviceID string) error
+	LogoutAll(ctx context.Context, uid uuid.UUID) error
+	LogoutOthers(ctx context.Context, uid uuid.UUID, deviceID string) error
+	SendForgotPasswordEmail(ctx context.Context, email string) error
+	CheckForgotPasswordEmail(ctx context.Context, req *dto.CheckForgotPasswordEmailRequest) error
+	SendVerificationEmail(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error
+	SendLoginCode(ctx context.Context, ip string, req *dto.LoginCodeRequest) error
+	CheckLoginCode(
+		ctx context.Context,
+		d *dto.DeviceRequest,
+		req *dto.CheckLoginCodeRequest,
+	) (*dto.TokenPair, *dto.TwoFactorChallenge, error)
 }
 
 type authRepo interface {
@@ -35,16 +50,36 @@ type authRepo interface {
 		userID uuid.UUID,
 		hashedT string,
 		expiresAt time.Time,
+		remember bool,
+		device *md.Device,
+	) error
+	GetToken(ctx context.Context, userID uuid.UUID, hashedT string) (*md.RefreshToken, error)
+	RotateToken(
+		ctx context.Context,
+		parent *md.RefreshToken,
+		hashedT string,
+		expiresAt time.Time,
 		device *md.Device,
 	) error
-	IsTokenValid(ctx context.Context, userID uuid.UUID, d *md.Device, token string) (bool, error)
+	RevokeFamily(ctx context.Context, token *md.RefreshToken) error
 	RevokeAllTokens(ctx context.Context, userID uuid.UUID) error
+	CreateSecurityEvent(ctx context.Context, e *md.SecurityEvent) error
 }
 
+const (
+	recoveryCacheKey       = "recovery:%v"
+	verifyCacheKey         = "verify:%v:%v"
+	verifyCooldownCacheKey = "verify-cooldown:%v"
+	loginCodeCacheKey      = "login-code:%v"
+)
+
+// GenPair starts a new session on the device. Remember selects the long
+// session policy.
 func (c *Controller) GenPair(
 	ctx context.Context,
 	d *dto.DeviceRequest,
 	uid uuid.UUID,
+	remember bool,
 ) (dto.TokenPair, error) {
 	const op = "auth.GenPair.ctrl"
 
@@ -52,55 +87,109 @@ func (c *Controller) GenPair(
 	defer span.Finish()
 
 	var res dto.TokenPair
-	access, refresh, err := c.au.GenPair(ctx, uid)
+	acc, err := c.getAccess(ctx, uid)
 	if err != nil {
 		return res, err
 	}
 
 	device := auth.GenerateDevice(d)
+	access, refresh, err := c.au.GenPair(ctx, uid, device.ID, acc)
+	if err != nil {
+		return res, err
+	}
 
-	err = c.repo.CreateToken(ctx, uid, refresh, c.au.GetRefreshTime(), &device)
+	known, err := c.repo.ListDevices(ctx, uid)
 	if err != nil {
 		return res, err
 	}
 
+	expiresAt := c.sessionExpiry(time.Now(), remember)
+	err = c.repo.CreateToken(ctx, uid, c.hashToken(refresh), expiresAt, remember, &device)
+	if err != nil {
+		return res, err
+	}
+
+	if isNewDevice(known, device.ID) {
+		c.sendNewDeviceEmail(ctx, uid, &device)
+	}
+
 	res.Access = access
 	res.Refresh = refresh
+	res.ExpiresAt = expiresAt
+	res.DeviceID = device.ID
+	res.Remember = remember
 
 	return res, nil
 }
 
+// hashToken keys refresh tokens with AUTH_TOKEN_HASH_KEY so that rows leaked
+// from the database cannot be replayed.
+func (c *Controller) hashToken(token string) string {
+	return auth.HashToken([]byte(c.conf.Auth.TokenHashKey), token)
+}
+
+// Authenticate checks the credentials and starts a session. Users with 2FA
+// enabled get a challenge for VerifyTwoFactor instead of a token pair.
 func (c *Controller) Authenticate(
 	ctx context.Context,
 	d *dto.DeviceRequest,
 	req *dto.EmailAndPasswordRequest,
-) (*dto.TokenPair, error) {
+) (*dto.TokenPair, *dto.TwoFactorChallenge, error) {
 	const op = "auth.Authenticate.ctrl"
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
-	res, err := c.repo.GetUserByEmail(ctx, req.Email)
+	res, err := c.checkCredentials(ctx, d.IP, req.Email, req.Password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.login(ctx, d, res.ID, req.Remember)
+}
+
+// checkCredentials returns the user if the password matches and, when
+// AUTH_REQUIRE_VERIFIED_EMAIL is set, the email is verified. Failed checks
+// count towards the lockout of the account and of ip, which is empty when
+// the caller doesn't know it.
+func (c *Controller) checkCredentials(ctx context.Context, ip, email, password string) (*md.User, error) {
+	const op = "auth.checkCredentials.ctrl"
+
+	res, err := c.repo.GetUserByEmail(ctx, email)
 	if err != nil && errors.Is(err, repo.ErrNotFound) {
-		return nil, ErrNotFound
+		res = nil
 	} else if err != nil {
 		return nil, err
 	}
 
-	err = c.au.ComparePasswords([]byte(res.Password), []byte(req.Password))
+	if err = c.checkLoginAllowed(ctx, ip, res); err != nil {
+		return nil, err
+	}
+
+	if res == nil {
+		c.recordLoginFailure(ctx, ip, nil)
+		return nil, ErrNotFound
+	}
+
+	err = c.au.ComparePasswords([]byte(res.Password), []byte(password))
 	if err != nil {
+		c.recordLoginFailure(ctx, ip, res)
 		return nil, auth.ErrInvalidCredentials
 	}
 
-	pair, err := c.GenPair(ctx, d, res.ID)
-	if err != nil {
-		return nil, err
+	c.clearLoginFailures(ctx, res)
+
+	if c.conf.Auth.RequireVerifiedEmail && !res.IsEmailVerified {
+		zap.L().Debug(
+			"login with unverified email",
+			zap.String("op", op),
+			zap.String("userID", res.ID.String()),
+		)
+
+		return nil, ErrEmailNotVerified
 	}
 
-	return &dto.TokenPair{
-		Access:  pair.Access,
-		Refresh: pair.Refresh,
-	}, nil
+	return res, nil
 }
 
 func (c *Controller) Refresh(
@@ -120,12 +209,18 @@ func (c *Controller) Refresh(
 
 	device := auth.GenerateDevice(d)
 
-	isValid, err := c.repo.IsTokenValid(ctx, claims.UID, &device, req.Refresh)
-	if err != nil {
+	token, err := c.repo.GetToken(ctx, claims.UID, c.hashToken(req.Refresh))
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return nil, auth.ErrTokenRevoked
+	} else if err != nil {
 		return nil, err
 	}
 
-	if !isValid {
+	if token.Rotated {
+		return nil, c.revokeReusedToken(ctx, token, &device)
+	}
+
+	if token.Revoked || token.DeviceID != device.ID || time.Now().After(token.ExpiresAt) {
 		zap.L().Info(
 			"token is invalid",
 			zap.String("op", op),
@@ -135,37 +230,371 @@ func (c *Controller) Refresh(
 		return nil, auth.ErrTokenRevoked
 	}
 
-	access, refresh, err := c.au.GenPair(ctx, claims.UID)
+	if c.sessionExpired(token) {
+		zap.L().Info(
+			"session expired",
+			zap.String("op", op),
+			zap.String("userID", claims.UID.String()),
+			zap.String("deviceID", token.DeviceID),
+		)
+
+		return nil, auth.ErrSessionExpired
+	}
+
+	acc, err := c.getAccess(ctx, claims.UID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.repo.RevokeAllTokens(ctx, claims.UID)
+	access, refresh, err := c.au.GenPair(ctx, claims.UID, device.ID, acc)
 	if err != nil {
 		return nil, err
 	}
 
-	err = c.repo.CreateToken(ctx, claims.UID, refresh, c.au.GetRefreshTime(), &device)
-	if err != nil {
+	expiresAt := c.sessionExpiry(token.SessionStartedAt, token.Remember)
+	err = c.repo.RotateToken(ctx, token, c.hashToken(refresh), expiresAt, &device)
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return nil, auth.ErrTokenRevoked
+	} else if err != nil {
 		return nil, err
 	}
 
 	return &dto.TokenPair{
-		Access:  access,
-		Refresh: refresh,
+		Access:    access,
+		Refresh:   refresh,
+		ExpiresAt: expiresAt,
+		DeviceID:  device.ID,
+		Remember:  token.Remember,
 	}, nil
 }
 
-func (c *Controller) Logout(ctx context.Context, uid uuid.UUID) error {
+// revokeReusedToken handles a refresh token that was already rotated. Either
+// the client or an attacker holds a stale copy, so the whole family and the
+// access tokens of its device are revoked. Other devices are not affected.
+func (c *Controller) revokeReusedToken(ctx context.Context, token *md.RefreshToken, d *md.Device) error {
+	const op = "auth.revokeReusedToken.ctrl"
+
+	zap.L().Warn(
+		"refresh token reuse detected",
+		zap.String("op", op),
+		zap.String("userID", token.UserID.String()),
+		zap.String("familyID", token.FamilyID.String()),
+		zap.String("deviceID", token.DeviceID),
+	)
+
+	if err := c.repo.RevokeFamily(ctx, token); err != nil {
+		return err
+	}
+
+	c.au.RevokeDeviceTokens(ctx, token.UserID, token.DeviceID)
+
+	c.securityEvent(
+		ctx, &md.SecurityEvent{
+			UserID:   token.UserID,
+			Kind:     md.SecurityEventTokenReuse,
+			DeviceID: token.DeviceID,
+			IP:       d.IP,
+		},
+	)
+
+	return auth.ErrTokenReused
+}
+
+// Logout ends the session of a single device of the caller together with its
+// access tokens. When it is the caller's own device, the access token the
+// request was made with is denylisted as well.
+func (c *Controller) Logout(ctx context.Context, claims jwt.Claims, deviceID string) error {
 	const op = "auth.Logout.ctrl"
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
+	err := c.repo.RevokeByDevice(ctx, claims.UID, deviceID)
+	if err != nil {
+		return err
+	}
+
+	c.au.RevokeDeviceTokens(ctx, claims.UID, deviceID)
+	if deviceID == claims.DeviceID {
+		c.au.RevokeToken(ctx, claims)
+	}
+
+	return nil
+}
+
+// LogoutAll ends every session of the user, including the refresh tokens of
+// the OAuth clients they authorized.
+func (c *Controller) LogoutAll(ctx context.Context, uid uuid.UUID) error {
+	const op = "auth.LogoutAll.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
 	err := c.repo.RevokeAllTokens(ctx, uid)
 	if err != nil {
 		return err
 	}
 
+	if err = c.repo.RevokeUserOAuthTokens(ctx, uid); err != nil {
+		return err
+	}
+
+	c.au.RevokeUserTokens(ctx, uid)
+	return nil
+}
+
+// LogoutOthers ends every session of the user except the one on deviceID.
+// Access tokens of all devices are invalidated, so the current device has to
+// refresh its pair.
+func (c *Controller) LogoutOthers(ctx context.Context, uid uuid.UUID, deviceID string) error {
+	const op = "auth.LogoutOthers.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := c.repo.RevokeOtherDevices(ctx, uid, deviceID)
+	if err != nil {
+		return err
+	}
+
+	c.au.RevokeUserTokens(ctx, uid)
+	return nil
+}
+
+func (c *Controller) SendForgotPasswordEmail(ctx context.Context, email string) error {
+	const op = "auth.SendForgotPasswordEmail.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := c.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			// Do not reveal whether the address is registered.
+			zap.L().Debug("recovery requested for unknown email", zap.String("op", op))
+			return nil
+		}
+
+		return err
+	}
+
+	code, err := c.issueCode(ctx, fmt.Sprintf(recoveryCacheKey, res.ID), config.RecoveryCodeDuration)
+	if err != nil {
+		if errors.Is(err, ErrTooManyRequests) {
+			// Unknown addresses are never throttled, so don't tell either.
+			return nil
+		}
+
+		return err
+	}
+
+	return c.smtp.SendForgotPasswordEmail(ctx, code, res.ID, res.Email)
+}
+
+func (c *Controller) CheckForgotPasswordEmail(
+	ctx context.Context,
+	req *dto.CheckForgotPasswordEmailRequest,
+) error {
+	const op = "auth.CheckForgotPasswordEmail.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	if err := c.checkCode(ctx, fmt.Sprintf(recoveryCacheKey, req.ID), req.Code); err != nil {
+		return err
+	}
+
+	hashed, err := c.au.Hash(ctx, req.Password)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.UpdatePassword(ctx, req.ID, hashed)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	if err = c.repo.RevokeAllTokens(ctx, req.ID); err != nil {
+		return err
+	}
+
+	if err = c.repo.RevokeUserOAuthTokens(ctx, req.ID); err != nil {
+		return err
+	}
+
+	c.au.RevokeUserTokens(ctx, req.ID)
+
+	c.cache.Delete(ctx, fmt.Sprintf(userCacheKey, req.ID))
 	return nil
 }
+
+// SendVerificationEmail resends the verification code. Requests for the same
+// address are throttled by config.VerifyResendCooldown whether or not it is
+// registered, so the response doesn't tell which addresses have accounts.
+func (c *Controller) SendVerificationEmail(ctx context.Context, email string) error {
+	const op = "auth.SendVerificationEmail.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	sent, err := c.cache.Incr(ctx, config.VerifyResendCooldown, fmt.Sprintf(verifyCooldownCacheKey, email))
+	if err != nil {
+		return err
+	}
+
+	if sent > 1 {
+		return ErrTooManyRequests
+	}
+
+	res, err := c.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			// Do not reveal whether the address is registered.
+			zap.L().Debug("verification requested for unknown email", zap.String("op", op))
+			return nil
+		}
+
+		return err
+	}
+
+	if res.IsEmailVerified {
+		return nil
+	}
+
+	err = c.sendVerificationEmail(ctx, res.ID, res.Email)
+	if errors.Is(err, ErrTooManyRequests) {
+		// The code was just sent on registration.
+		return nil
+	}
+
+	return err
+}
+
+func (c *Controller) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error {
+	const op = "auth.VerifyEmail.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	u, err := c.repo.GetUserByID(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	// Codes are bound to the address they were sent to, so a code sent
+	// before an email change can't verify the new address.
+	if err = c.checkCode(ctx, fmt.Sprintf(verifyCacheKey, u.ID, u.Email), req.Code); err != nil {
+		return err
+	}
+
+	err = c.repo.VerifyEmail(ctx, u.ID, u.Email)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	c.cache.Delete(ctx, fmt.Sprintf(userCacheKey, req.ID))
+	go c.cache.InvalidateKeysByPattern(ctx, userPattern)
+	return nil
+}
+
+// SendLoginCode checks the credentials and emails a one-time code that can be
+// exchanged for a token pair with CheckLoginCode.
+func (c *Controller) SendLoginCode(ctx context.Context, ip string, req *dto.LoginCodeRequest) error {
+	const op = "auth.SendLoginCode.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := c.checkCredentials(ctx, ip, req.Email, req.Password)
+	if err != nil {
+		return err
+	}
+
+	code, err := c.issueCode(ctx, fmt.Sprintf(loginCodeCacheKey, res.Email), config.LoginCodeDuration)
+	if err != nil {
+		return err
+	}
+
+	return c.smtp.SendLoginCodeEmail(ctx, code, res.Email)
+}
+
+func (c *Controller) CheckLoginCode(
+	ctx context.Context,
+	d *dto.DeviceRequest,
+	req *dto.CheckLoginCodeRequest,
+) (*dto.TokenPair, *dto.TwoFactorChallenge, error) {
+	const op = "auth.CheckLoginCode.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	if err := c.checkCode(ctx, fmt.Sprintf(loginCodeCacheKey, req.Email), req.Code); err != nil {
+		return nil, nil, err
+	}
+
+	res, err := c.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, nil, ErrNotFound
+		}
+
+		return nil, nil, err
+	}
+
+	return c.login(ctx, d, res.ID, req.Remember)
+}
+
+func (c *Controller) sendVerificationEmail(ctx context.Context, uid uuid.UUID, email string) error {
+	code, err := c.issueCode(ctx, fmt.Sprintf(verifyCacheKey, uid, email), config.VerifyCodeDuration)
+	if err != nil {
+		return err
+	}
+
+	return c.smtp.SendVerificationEmail(ctx, code, uid, email)
+}
+
+// isNewDevice reports whether id is missing from the user's known devices.
+// The very first login is not considered a new device.
+func isNewDevice(known []md.Device, id string) bool {
+	if len(known) == 0 {
+		return false
+	}
+
+	for i := range known {
+		if known[i].ID == id {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Controller) sendNewDeviceEmail(ctx context.Context, uid uuid.UUID, d *md.Device) {
+	const op = "auth.sendNewDeviceEmail.ctrl"
+
+	u, err := c.repo.GetUserByID(ctx, uid)
+	if err == nil {
+		err = c.smtp.SendNewDeviceEmail(ctx, d, u.Email)
+	}
+
+	if err != nil {
+		zap.L().Error(
+			"failed to send new device email",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+	}
+}
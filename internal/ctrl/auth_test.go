@@ -3,8 +3,11 @@ package ctrl
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/JMURv/golang-clean-template/internal/auth"
 	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/cache"
+	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/dto"
 	"github.com/JMURv/golang-clean-template/internal/models"
 	"github.com/JMURv/golang-clean-template/internal/repo"
@@ -26,7 +29,7 @@ func TestController_Authenticate(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
 	testDevice := &dto.DeviceRequest{
@@ -38,9 +41,12 @@ func TestController_Authenticate(t *testing.T) {
 		Email:    "test@example.com",
 		Password: "validpassword123!",
 	}
+	testRefreshTime := time.Now().Add(time.Hour)
 	testTokenPair := &dto.TokenPair{
-		Access:  "access-token",
-		Refresh: "refresh-token",
+		Access:    "access-token",
+		Refresh:   "refresh-token",
+		ExpiresAt: testRefreshTime,
+		DeviceID:  auth.GenerateDevice(testDevice).ID,
 	}
 
 	testUser := &models.User{
@@ -54,6 +60,7 @@ func TestController_Authenticate(t *testing.T) {
 		setup    func()
 		input    *dto.EmailAndPasswordRequest
 		expected *dto.TokenPair
+		twoStep  bool
 		wantErr  bool
 		err      error
 	}{
@@ -66,20 +73,49 @@ func TestController_Authenticate(t *testing.T) {
 				mockAuth.EXPECT().
 					ComparePasswords([]byte(testUser.Password), []byte(testRequest.Password)).
 					Return(nil)
+				mockRepo.EXPECT().
+					GetTOTP(gomock.Any(), testUserID).
+					Return(nil, repo.ErrNotFound)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUserID).
+					Return(nil, nil)
 				mockAuth.EXPECT().
-					GenPair(gomock.Any(), testUserID).
+					GenPair(gomock.Any(), testUserID, gomock.Any(), gomock.Any()).
 					Return(testTokenPair.Access, testTokenPair.Refresh, nil)
+				mockRepo.EXPECT().
+					ListDevices(gomock.Any(), testUserID).
+					Return(nil, nil)
 				mockAuth.EXPECT().
 					GetRefreshTime().
-					Return(time.Now())
+					Return(testRefreshTime)
 				mockRepo.EXPECT().
-					CreateToken(gomock.Any(), testUserID, testTokenPair.Refresh, gomock.Any(), gomock.Any()).
+					CreateToken(gomock.Any(), testUserID, auth.HashToken(nil, testTokenPair.Refresh), gomock.Any(), false, gomock.Any()).
 					Return(nil)
 			},
 			input:    testRequest,
 			expected: testTokenPair,
 			wantErr:  false,
 		},
+		{
+			name: "TwoFactorRequired",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testRequest.Email).
+					Return(testUser, nil)
+				mockAuth.EXPECT().
+					ComparePasswords([]byte(testUser.Password), []byte(testRequest.Password)).
+					Return(nil)
+				mockRepo.EXPECT().
+					GetTOTP(gomock.Any(), testUserID).
+					Return(&models.TOTP{UserID: testUserID, Confirmed: true}, nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.TwoFactorDuration, gomock.Any(), gomock.Any()).
+					Return()
+			},
+			input:   testRequest,
+			twoStep: true,
+			wantErr: false,
+		},
 		{
 			name: "UserNotFound",
 			setup: func() {
@@ -124,8 +160,14 @@ func TestController_Authenticate(t *testing.T) {
 				mockAuth.EXPECT().
 					ComparePasswords([]byte(testUser.Password), []byte(testRequest.Password)).
 					Return(nil)
+				mockRepo.EXPECT().
+					GetTOTP(gomock.Any(), testUserID).
+					Return(nil, repo.ErrNotFound)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUserID).
+					Return(nil, nil)
 				mockAuth.EXPECT().
-					GenPair(gomock.Any(), testUserID).
+					GenPair(gomock.Any(), testUserID, gomock.Any(), gomock.Any()).
 					Return("", "", errors.New("token error"))
 			},
 			input:   testRequest,
@@ -139,15 +181,20 @@ func TestController_Authenticate(t *testing.T) {
 				tt.setup()
 			}
 
-			result, err := ctrl.Authenticate(ctx, testDevice, tt.input)
+			result, challenge, err := ctrl.Authenticate(ctx, testDevice, tt.input)
 
 			if tt.wantErr {
 				assert.Error(t, err)
 				if tt.err != nil {
 					assert.ErrorIs(t, err, tt.err)
 				}
+			} else if tt.twoStep {
+				assert.NoError(t, err)
+				assert.Nil(t, result)
+				assert.NotEmpty(t, challenge.Challenge)
 			} else {
 				assert.NoError(t, err)
+				assert.Nil(t, challenge)
 				assert.Equal(t, tt.expected, result)
 			}
 		})
@@ -164,7 +211,7 @@ func TestController_Refresh(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
 	testDevice := &dto.DeviceRequest{
@@ -175,15 +222,31 @@ func TestController_Refresh(t *testing.T) {
 	testRequest := &dto.RefreshRequest{
 		Refresh: testRefreshToken,
 	}
+	testRefreshTime := time.Now().Add(time.Hour)
 	testTokenPair := &dto.TokenPair{
-		Access:  "new-access-token",
-		Refresh: "new-refresh-token",
+		Access:    "new-access-token",
+		Refresh:   "new-refresh-token",
+		ExpiresAt: testRefreshTime,
+		DeviceID:  auth.GenerateDevice(testDevice).ID,
 	}
 
 	testClaims := jwt.Claims{
 		UID: testUserID,
 	}
 
+	testHash := auth.HashToken(nil, testRefreshToken)
+	device := auth.GenerateDevice(testDevice)
+	newToken := func() *models.RefreshToken {
+		return &models.RefreshToken{
+			ID:        1,
+			UserID:    testUserID,
+			TokenHash: testHash,
+			FamilyID:  uuid.New(),
+			ExpiresAt: time.Now().Add(time.Hour),
+			DeviceID:  device.ID,
+		}
+	}
+
 	tests := []struct {
 		name     string
 		setup    func()
@@ -195,23 +258,24 @@ func TestController_Refresh(t *testing.T) {
 		{
 			name: "Success",
 			setup: func() {
+				token := newToken()
 				mockAuth.EXPECT().
 					ParseClaims(gomock.Any(), testRefreshToken).
 					Return(testClaims, nil)
 				mockRepo.EXPECT().
-					IsTokenValid(gomock.Any(), testUserID, gomock.Any(), testRefreshToken).
-					Return(true, nil)
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(token, nil)
 				mockAuth.EXPECT().
 					GetRefreshTime().
-					Return(time.Now())
+					Return(testRefreshTime)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUserID).
+					Return(nil, nil)
 				mockAuth.EXPECT().
-					GenPair(gomock.Any(), testUserID).
+					GenPair(gomock.Any(), testUserID, gomock.Any(), gomock.Any()).
 					Return(testTokenPair.Access, testTokenPair.Refresh, nil)
 				mockRepo.EXPECT().
-					RevokeAllTokens(gomock.Any(), testUserID).
-					Return(nil)
-				mockRepo.EXPECT().
-					CreateToken(gomock.Any(), testUserID, testTokenPair.Refresh, gomock.Any(), gomock.Any()).
+					RotateToken(gomock.Any(), token, auth.HashToken(nil, testTokenPair.Refresh), gomock.Any(), &device).
 					Return(nil)
 			},
 			input:    testRequest,
@@ -229,29 +293,106 @@ func TestController_Refresh(t *testing.T) {
 			wantErr: true,
 			err:     auth.ErrInvalidToken,
 		},
+		{
+			name: "TokenNotFound",
+			setup: func() {
+				mockAuth.EXPECT().
+					ParseClaims(gomock.Any(), testRefreshToken).
+					Return(testClaims, nil)
+				mockRepo.EXPECT().
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(nil, repo.ErrNotFound)
+			},
+			input:   testRequest,
+			wantErr: true,
+			err:     auth.ErrTokenRevoked,
+		},
 		{
 			name: "TokenRevoked",
 			setup: func() {
+				token := newToken()
+				token.Revoked = true
+				mockAuth.EXPECT().
+					ParseClaims(gomock.Any(), testRefreshToken).
+					Return(testClaims, nil)
+				mockRepo.EXPECT().
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(token, nil)
+			},
+			input:   testRequest,
+			wantErr: true,
+			err:     auth.ErrTokenRevoked,
+		},
+		{
+			name: "OtherDevice",
+			setup: func() {
+				token := newToken()
+				token.DeviceID = "other-device"
 				mockAuth.EXPECT().
 					ParseClaims(gomock.Any(), testRefreshToken).
 					Return(testClaims, nil)
 				mockRepo.EXPECT().
-					IsTokenValid(gomock.Any(), testUserID, gomock.Any(), testRefreshToken).
-					Return(false, nil)
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(token, nil)
 			},
 			input:   testRequest,
 			wantErr: true,
 			err:     auth.ErrTokenRevoked,
 		},
 		{
-			name: "TokenValidationError",
+			name: "TokenReused",
+			setup: func() {
+				token := newToken()
+				token.Rotated = true
+				mockAuth.EXPECT().
+					ParseClaims(gomock.Any(), testRefreshToken).
+					Return(testClaims, nil)
+				mockRepo.EXPECT().
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(token, nil)
+				mockRepo.EXPECT().
+					RevokeFamily(gomock.Any(), token).
+					Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), testUserID, token.DeviceID)
+				mockRepo.EXPECT().
+					CreateSecurityEvent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, e *models.SecurityEvent) error {
+						assert.Equal(t, models.SecurityEventTokenReuse, e.Kind)
+						assert.Equal(t, testUserID, e.UserID)
+						return nil
+					})
+			},
+			input:   testRequest,
+			wantErr: true,
+			err:     auth.ErrTokenReused,
+		},
+		{
+			name: "RevokeFamilyError",
+			setup: func() {
+				token := newToken()
+				token.Rotated = true
+				mockAuth.EXPECT().
+					ParseClaims(gomock.Any(), testRefreshToken).
+					Return(testClaims, nil)
+				mockRepo.EXPECT().
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(token, nil)
+				mockRepo.EXPECT().
+					RevokeFamily(gomock.Any(), token).
+					Return(errors.New("revoke error"))
+			},
+			input:   testRequest,
+			wantErr: true,
+		},
+		{
+			name: "GetTokenError",
 			setup: func() {
 				mockAuth.EXPECT().
 					ParseClaims(gomock.Any(), testRefreshToken).
 					Return(testClaims, nil)
 				mockRepo.EXPECT().
-					IsTokenValid(gomock.Any(), testUserID, gomock.Any(), testRefreshToken).
-					Return(false, errors.New("db error"))
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(nil, errors.New("db error"))
 			},
 			input:   testRequest,
 			wantErr: true,
@@ -263,54 +404,64 @@ func TestController_Refresh(t *testing.T) {
 					ParseClaims(gomock.Any(), testRefreshToken).
 					Return(testClaims, nil)
 				mockRepo.EXPECT().
-					IsTokenValid(gomock.Any(), testUserID, gomock.Any(), testRefreshToken).
-					Return(true, nil)
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(newToken(), nil)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUserID).
+					Return(nil, nil)
 				mockAuth.EXPECT().
-					GenPair(gomock.Any(), testUserID).
+					GenPair(gomock.Any(), testUserID, gomock.Any(), gomock.Any()).
 					Return("", "", errors.New("token error"))
 			},
 			input:   testRequest,
 			wantErr: true,
 		},
 		{
-			name: "RevokeTokensError",
+			name: "RotatedConcurrently",
 			setup: func() {
 				mockAuth.EXPECT().
 					ParseClaims(gomock.Any(), testRefreshToken).
 					Return(testClaims, nil)
 				mockRepo.EXPECT().
-					IsTokenValid(gomock.Any(), testUserID, gomock.Any(), testRefreshToken).
-					Return(true, nil)
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(newToken(), nil)
+				mockAuth.EXPECT().
+					GetRefreshTime().
+					Return(testRefreshTime)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUserID).
+					Return(nil, nil)
 				mockAuth.EXPECT().
-					GenPair(gomock.Any(), testUserID).
+					GenPair(gomock.Any(), testUserID, gomock.Any(), gomock.Any()).
 					Return(testTokenPair.Access, testTokenPair.Refresh, nil)
 				mockRepo.EXPECT().
-					RevokeAllTokens(gomock.Any(), testUserID).
-					Return(errors.New("revoke error"))
+					RotateToken(gomock.Any(), gomock.Any(), auth.HashToken(nil, testTokenPair.Refresh), gomock.Any(), gomock.Any()).
+					Return(repo.ErrNotFound)
 			},
 			input:   testRequest,
 			wantErr: true,
+			err:     auth.ErrTokenRevoked,
 		},
 		{
-			name: "CreateTokenError",
+			name: "RotateTokenError",
 			setup: func() {
 				mockAuth.EXPECT().
 					ParseClaims(gomock.Any(), testRefreshToken).
 					Return(testClaims, nil)
 				mockRepo.EXPECT().
-					IsTokenValid(gomock.Any(), testUserID, gomock.Any(), testRefreshToken).
-					Return(true, nil)
+					GetToken(gomock.Any(), testUserID, testHash).
+					Return(newToken(), nil)
 				mockAuth.EXPECT().
 					GetRefreshTime().
-					Return(time.Now())
+					Return(testRefreshTime)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUserID).
+					Return(nil, nil)
 				mockAuth.EXPECT().
-					GenPair(gomock.Any(), testUserID).
+					GenPair(gomock.Any(), testUserID, gomock.Any(), gomock.Any()).
 					Return(testTokenPair.Access, testTokenPair.Refresh, nil)
 				mockRepo.EXPECT().
-					RevokeAllTokens(gomock.Any(), testUserID).
-					Return(nil)
-				mockRepo.EXPECT().
-					CreateToken(gomock.Any(), testUserID, testTokenPair.Refresh, gomock.Any(), gomock.Any()).
+					RotateToken(gomock.Any(), gomock.Any(), auth.HashToken(nil, testTokenPair.Refresh), gomock.Any(), gomock.Any()).
 					Return(errors.New("create error"))
 			},
 			input:   testRequest,
@@ -349,35 +500,222 @@ func TestController_Logout(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
+	testDeviceID := "device123"
+	testClaims := jwt.Claims{UID: testUserID, DeviceID: testDeviceID}
+	testErr := errors.New("database error")
 
 	tests := []struct {
 		name    string
 		setup   func()
-		input   uuid.UUID
+		call    func() error
 		wantErr bool
-		err     error
 	}{
 		{
-			name: "Success",
+			name: "Logout",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeByDevice(gomock.Any(), testUserID, testDeviceID).
+					Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), testUserID, testDeviceID)
+				mockAuth.EXPECT().RevokeToken(gomock.Any(), testClaims)
+			},
+			call: func() error {
+				return ctrl.Logout(ctx, testClaims, testDeviceID)
+			},
+		},
+		{
+			name: "LogoutOtherDevice",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeByDevice(gomock.Any(), testUserID, "other").
+					Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), testUserID, "other")
+			},
+			call: func() error {
+				return ctrl.Logout(ctx, testClaims, "other")
+			},
+		},
+		{
+			name: "LogoutError",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeByDevice(gomock.Any(), testUserID, testDeviceID).
+					Return(testErr)
+			},
+			call: func() error {
+				return ctrl.Logout(ctx, testClaims, testDeviceID)
+			},
+			wantErr: true,
+		},
+		{
+			name: "LogoutAll",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeAllTokens(gomock.Any(), testUserID).
+					Return(nil)
+				mockRepo.EXPECT().RevokeUserOAuthTokens(gomock.Any(), testUserID).Return(nil)
+				mockAuth.EXPECT().RevokeUserTokens(gomock.Any(), testUserID)
+			},
+			call: func() error {
+				return ctrl.LogoutAll(ctx, testUserID)
+			},
+		},
+		{
+			name: "LogoutAllOAuthError",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeAllTokens(gomock.Any(), testUserID).
+					Return(nil)
+				mockRepo.EXPECT().RevokeUserOAuthTokens(gomock.Any(), testUserID).Return(testErr)
+			},
+			call: func() error {
+				return ctrl.LogoutAll(ctx, testUserID)
+			},
+			wantErr: true,
+		},
+		{
+			name: "LogoutAllError",
 			setup: func() {
 				mockRepo.EXPECT().
 					RevokeAllTokens(gomock.Any(), testUserID).
+					Return(testErr)
+			},
+			call: func() error {
+				return ctrl.LogoutAll(ctx, testUserID)
+			},
+			wantErr: true,
+		},
+		{
+			name: "LogoutOthers",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeOtherDevices(gomock.Any(), testUserID, testDeviceID).
+					Return(nil)
+				mockAuth.EXPECT().RevokeUserTokens(gomock.Any(), testUserID)
+			},
+			call: func() error {
+				return ctrl.LogoutOthers(ctx, testUserID, testDeviceID)
+			},
+		},
+		{
+			name: "LogoutOthersError",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeOtherDevices(gomock.Any(), testUserID, testDeviceID).
+					Return(testErr)
+			},
+			call: func() error {
+				return ctrl.LogoutOthers(ctx, testUserID, testDeviceID)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			err := tt.call()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, testErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestController_SendForgotPasswordEmail(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+	mockSmtp := mocks.NewMockEmailService(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, mockSmtp)
+
+	testUser := &models.User{
+		ID:    uuid.New(),
+		Email: "test@example.com",
+	}
+	cacheKey := fmt.Sprintf(recoveryCacheKey, testUser.ID)
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.RecoveryCodeDuration, cacheKey, gomock.Any()).
+					Return()
+				mockSmtp.EXPECT().
+					SendForgotPasswordEmail(gomock.Any(), gomock.Any(), testUser.ID, testUser.Email).
 					Return(nil)
 			},
-			input:   testUserID,
+			wantErr: false,
+		},
+		{
+			name: "UnknownEmail",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(nil, repo.ErrNotFound)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Cooldown",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, cacheKey+":cooldown").
+					Return(int64(2), nil)
+			},
 			wantErr: false,
 		},
 		{
 			name: "RepositoryError",
 			setup: func() {
 				mockRepo.EXPECT().
-					RevokeAllTokens(gomock.Any(), testUserID).
-					Return(errors.New("database error"))
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(nil, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "SendError",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.RecoveryCodeDuration, cacheKey, gomock.Any()).
+					Return()
+				mockSmtp.EXPECT().
+					SendForgotPasswordEmail(gomock.Any(), gomock.Any(), testUser.ID, testUser.Email).
+					Return(errors.New("smtp error"))
 			},
-			input:   testUserID,
 			wantErr: true,
 		},
 	}
@@ -388,15 +726,935 @@ func TestController_Logout(t *testing.T) {
 				tt.setup()
 			}
 
-			err := ctrl.Logout(ctx, tt.input)
-
+			err := ctrl.SendForgotPasswordEmail(ctx, testUser.Email)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestController_CheckForgotPasswordEmail(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	testUserID := uuid.New()
+	testCode := 123456
+	testHash := "hashed-password"
+	cacheKey := fmt.Sprintf(recoveryCacheKey, testUserID)
+	testRequest := &dto.CheckForgotPasswordEmailRequest{
+		Password: "new-password",
+		ID:       testUserID,
+		Code:     testCode,
+	}
+
+	storedCode := func(ctx context.Context, key string, dest any) error {
+		*dest.(*oneTimeCode) = oneTimeCode{Code: testCode, ExpiresAt: time.Now().Add(time.Minute)}
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr bool
+		err     error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockAuth.EXPECT().Hash(gomock.Any(), testRequest.Password).Return(testHash, nil)
+				mockRepo.EXPECT().UpdatePassword(gomock.Any(), testUserID, testHash).Return(nil)
+				mockRepo.EXPECT().RevokeAllTokens(gomock.Any(), testUserID).Return(nil)
+				mockRepo.EXPECT().RevokeUserOAuthTokens(gomock.Any(), testUserID).Return(nil)
+				mockAuth.EXPECT().RevokeUserTokens(gomock.Any(), testUserID)
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(userCacheKey, testUserID)).Return()
+			},
+			wantErr: false,
+		},
+		{
+			name: "CodeExpired",
+			setup: func() {
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrNotFoundInCache)
+			},
+			wantErr: true,
+			err:     ErrCodeIsNotValid,
+		},
+		{
+			name: "UserNotFound",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockAuth.EXPECT().Hash(gomock.Any(), testRequest.Password).Return(testHash, nil)
+				mockRepo.EXPECT().UpdatePassword(gomock.Any(), testUserID, testHash).Return(repo.ErrNotFound)
+			},
+			wantErr: true,
+			err:     ErrNotFound,
+		},
+		{
+			name: "HashError",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockAuth.EXPECT().Hash(gomock.Any(), testRequest.Password).Return("", errors.New("hash error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "RevokeError",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockAuth.EXPECT().Hash(gomock.Any(), testRequest.Password).Return(testHash, nil)
+				mockRepo.EXPECT().UpdatePassword(gomock.Any(), testUserID, testHash).Return(nil)
+				mockRepo.EXPECT().RevokeAllTokens(gomock.Any(), testUserID).Return(errors.New("revoke error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			err := ctrl.CheckForgotPasswordEmail(ctx, testRequest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestController_Authenticate_RequireVerifiedEmail(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	conf := config.Config{}
+	conf.Auth.RequireVerifiedEmail = true
+
+	ctx := context.Background()
+	ctrl := New(conf, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	testDevice := &dto.DeviceRequest{
+		IP: "192.168.1.1",
+		UA: "test-user-agent",
+	}
+	testRequest := &dto.EmailAndPasswordRequest{
+		Email:    "test@example.com",
+		Password: "validpassword123!",
+	}
+	testUser := &models.User{
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: "$2a$10$hashedpassword",
+	}
+
+	tests := []struct {
+		name     string
+		verified bool
+		setup    func()
+		wantErr  bool
+		err      error
+	}{
+		{
+			name:     "Unverified",
+			verified: false,
+			setup:    func() {},
+			wantErr:  true,
+			err:      ErrEmailNotVerified,
+		},
+		{
+			name:     "Verified",
+			verified: true,
+			setup: func() {
+				mockRepo.EXPECT().
+					GetTOTP(gomock.Any(), testUser.ID).
+					Return(nil, repo.ErrNotFound)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUser.ID).
+					Return(nil, nil)
+				mockAuth.EXPECT().
+					GenPair(gomock.Any(), testUser.ID, gomock.Any(), gomock.Any()).
+					Return("access", "refresh", nil)
+				mockRepo.EXPECT().
+					ListDevices(gomock.Any(), testUser.ID).
+					Return(nil, nil)
+				mockAuth.EXPECT().
+					GetRefreshTime().
+					Return(time.Now())
+				mockRepo.EXPECT().
+					CreateToken(gomock.Any(), testUser.ID, auth.HashToken(nil, "refresh"), gomock.Any(), false, gomock.Any()).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := *testUser
+			user.IsEmailVerified = tt.verified
+
+			mockRepo.EXPECT().
+				GetUserByEmail(gomock.Any(), testRequest.Email).
+				Return(&user, nil)
+			mockAuth.EXPECT().
+				ComparePasswords([]byte(testUser.Password), []byte(testRequest.Password)).
+				Return(nil)
+			tt.setup()
+
+			_, _, err := ctrl.Authenticate(ctx, testDevice, testRequest)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestController_SendVerificationEmail(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+	mockSmtp := mocks.NewMockEmailService(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, mockSmtp)
+
+	testUser := &models.User{
+		ID:    uuid.New(),
+		Email: "test@example.com",
+	}
+	verifiedUser := &models.User{
+		ID:              testUser.ID,
+		Email:           testUser.Email,
+		IsEmailVerified: true,
+	}
+	cooldownKey := fmt.Sprintf(verifyCooldownCacheKey, testUser.Email)
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr bool
+		err     error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.VerifyResendCooldown, cooldownKey).
+					Return(int64(1), nil)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.VerifyCodeDuration, fmt.Sprintf(verifyCacheKey, testUser.ID, testUser.Email), gomock.Any()).
+					Return()
+				mockSmtp.EXPECT().
+					SendVerificationEmail(gomock.Any(), gomock.Any(), testUser.ID, testUser.Email).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Cooldown",
+			setup: func() {
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.VerifyResendCooldown, cooldownKey).
+					Return(int64(2), nil)
+			},
+			wantErr: true,
+			err:     ErrTooManyRequests,
+		},
+		{
+			name: "UnknownEmail",
+			setup: func() {
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.VerifyResendCooldown, cooldownKey).
+					Return(int64(1), nil)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(nil, repo.ErrNotFound)
+			},
+			wantErr: false,
+		},
+		{
+			name: "CodeJustSent",
+			setup: func() {
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.VerifyResendCooldown, cooldownKey).
+					Return(int64(1), nil)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(2), nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "AlreadyVerified",
+			setup: func() {
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.VerifyResendCooldown, cooldownKey).
+					Return(int64(1), nil)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(verifiedUser, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "RepositoryError",
+			setup: func() {
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.VerifyResendCooldown, cooldownKey).
+					Return(int64(1), nil)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(nil, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "SendError",
+			setup: func() {
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.VerifyResendCooldown, cooldownKey).
+					Return(int64(1), nil)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return()
+				mockSmtp.EXPECT().
+					SendVerificationEmail(gomock.Any(), gomock.Any(), testUser.ID, testUser.Email).
+					Return(errors.New("smtp error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			err := ctrl.SendVerificationEmail(ctx, testUser.Email)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestController_VerifyEmail(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	testUserID := uuid.New()
+	testCode := 123456
+	testUser := &models.User{ID: testUserID, Email: "test@example.com"}
+	cacheKey := fmt.Sprintf(verifyCacheKey, testUserID, testUser.Email)
+	testRequest := &dto.VerifyEmailRequest{
+		ID:   testUserID,
+		Code: testCode,
+	}
+
+	storedCode := func(ctx context.Context, key string, dest any) error {
+		*dest.(*oneTimeCode) = oneTimeCode{Code: testCode, ExpiresAt: time.Now().Add(time.Minute)}
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr bool
+		err     error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockRepo.EXPECT().VerifyEmail(gomock.Any(), testUserID, testUser.Email).Return(nil)
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(userCacheKey, testUserID)).Return()
+				mockCache.EXPECT().InvalidateKeysByPattern(gomock.Any(), userPattern).AnyTimes().Return()
+			},
+			wantErr: false,
+		},
+		{
+			name: "InvalidCode",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrNotFoundInCache)
+			},
+			wantErr: true,
+			err:     ErrCodeIsNotValid,
+		},
+		{
+			name: "EmailChanged",
+			setup: func() {
+				changed := &models.User{ID: testUserID, Email: "new@example.com"}
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(changed, nil)
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), fmt.Sprintf(verifyCacheKey, testUserID, changed.Email), gomock.Any()).
+					Return(cache.ErrNotFoundInCache)
+			},
+			wantErr: true,
+			err:     ErrCodeIsNotValid,
+		},
+		{
+			name: "UserNotFound",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockRepo.EXPECT().VerifyEmail(gomock.Any(), testUserID, testUser.Email).Return(repo.ErrNotFound)
+			},
+			wantErr: true,
+			err:     ErrNotFound,
+		},
+		{
+			name: "RepositoryError",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockRepo.EXPECT().VerifyEmail(gomock.Any(), testUserID, testUser.Email).Return(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			err := ctrl.VerifyEmail(ctx, testRequest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestController_SendLoginCode(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+	mockSmtp := mocks.NewMockEmailService(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, mockSmtp)
+
+	testRequest := &dto.LoginCodeRequest{
+		Email:    "test@example.com",
+		Password: "validpassword123!",
+	}
+	testUser := &models.User{
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Password: "$2a$10$hashedpassword",
+	}
+	cacheKey := fmt.Sprintf(loginCodeCacheKey, testUser.Email)
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr bool
+		err     error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testRequest.Email).
+					Return(testUser, nil)
+				mockAuth.EXPECT().
+					ComparePasswords([]byte(testUser.Password), []byte(testRequest.Password)).
+					Return(nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.LoginCodeDuration, cacheKey, gomock.Any()).
+					Return()
+				mockSmtp.EXPECT().
+					SendLoginCodeEmail(gomock.Any(), gomock.Any(), testUser.Email).
+					Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "UserNotFound",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testRequest.Email).
+					Return(nil, repo.ErrNotFound)
+			},
+			wantErr: true,
+			err:     ErrNotFound,
+		},
+		{
+			name: "InvalidCredentials",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testRequest.Email).
+					Return(testUser, nil)
+				mockAuth.EXPECT().
+					ComparePasswords([]byte(testUser.Password), []byte(testRequest.Password)).
+					Return(auth.ErrInvalidCredentials)
+			},
+			wantErr: true,
+			err:     auth.ErrInvalidCredentials,
+		},
+		{
+			name: "SendError",
+			setup: func() {
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testRequest.Email).
+					Return(testUser, nil)
+				mockAuth.EXPECT().
+					ComparePasswords([]byte(testUser.Password), []byte(testRequest.Password)).
+					Return(nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.LoginCodeDuration, cacheKey, gomock.Any()).
+					Return()
+				mockSmtp.EXPECT().
+					SendLoginCodeEmail(gomock.Any(), gomock.Any(), testUser.Email).
+					Return(errors.New("smtp error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			err := ctrl.SendLoginCode(ctx, "", testRequest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestController_CheckLoginCode(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	testCode := 123456
+	testDevice := &dto.DeviceRequest{
+		IP: "192.168.1.1",
+		UA: "test-user-agent",
+	}
+	testUser := &models.User{
+		ID:    uuid.New(),
+		Email: "test@example.com",
+	}
+	testRequest := &dto.CheckLoginCodeRequest{
+		Email: testUser.Email,
+		Code:  testCode,
+	}
+	testRefreshTime := time.Now().Add(time.Hour)
+	testTokenPair := &dto.TokenPair{
+		Access:    "access-token",
+		Refresh:   "refresh-token",
+		ExpiresAt: testRefreshTime,
+		DeviceID:  auth.GenerateDevice(testDevice).ID,
+	}
+	cacheKey := fmt.Sprintf(loginCodeCacheKey, testUser.Email)
+
+	storedCode := func(ctx context.Context, key string, dest any) error {
+		*dest.(*oneTimeCode) = oneTimeCode{Code: testCode, ExpiresAt: time.Now().Add(time.Minute)}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		setup    func()
+		expected *dto.TokenPair
+		wantErr  bool
+		err      error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockRepo.EXPECT().
+					GetTOTP(gomock.Any(), testUser.ID).
+					Return(nil, repo.ErrNotFound)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUser.ID).
+					Return(nil, nil)
+				mockAuth.EXPECT().
+					GenPair(gomock.Any(), testUser.ID, gomock.Any(), gomock.Any()).
+					Return(testTokenPair.Access, testTokenPair.Refresh, nil)
+				mockRepo.EXPECT().
+					ListDevices(gomock.Any(), testUser.ID).
+					Return(nil, nil)
+				mockAuth.EXPECT().
+					GetRefreshTime().
+					Return(testRefreshTime)
+				mockRepo.EXPECT().
+					CreateToken(gomock.Any(), testUser.ID, auth.HashToken(nil, testTokenPair.Refresh), gomock.Any(), false, gomock.Any()).
+					Return(nil)
+			},
+			expected: testTokenPair,
+			wantErr:  false,
+		},
+		{
+			name: "TwoFactorRequired",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockRepo.EXPECT().
+					GetTOTP(gomock.Any(), testUser.ID).
+					Return(&models.TOTP{UserID: testUser.ID, Confirmed: true}, nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.TwoFactorDuration, gomock.Any(), gomock.Any()).
+					Return()
+			},
+			wantErr: false,
+		},
+		{
+			name: "InvalidCode",
+			setup: func() {
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), cacheKey, gomock.Any()).
+					Return(cache.ErrNotFoundInCache)
+			},
+			wantErr: true,
+			err:     ErrCodeIsNotValid,
+		},
+		{
+			name: "UserNotFound",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(nil, repo.ErrNotFound)
+			},
+			wantErr: true,
+			err:     ErrNotFound,
+		},
+		{
+			name: "TokenGenerationError",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), cacheKey, gomock.Any()).DoAndReturn(storedCode)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), cacheKey+":attempts").Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), cacheKey+":attempts").Return()
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), testUser.Email).
+					Return(testUser, nil)
+				mockRepo.EXPECT().
+					GetTOTP(gomock.Any(), testUser.ID).
+					Return(nil, repo.ErrNotFound)
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUser.ID).
+					Return(nil, nil)
+				mockAuth.EXPECT().
+					GenPair(gomock.Any(), testUser.ID, gomock.Any(), gomock.Any()).
+					Return("", "", errors.New("token error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			result, challenge, err := ctrl.CheckLoginCode(ctx, testDevice, testRequest)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				}
+			} else if tt.expected == nil {
+				assert.NoError(t, err)
+				assert.Nil(t, result)
+				assert.NotEmpty(t, challenge.Challenge)
+			} else {
+				assert.NoError(t, err)
+				assert.Nil(t, challenge)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestController_GenPair(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+	mockSmtp := mocks.NewMockEmailService(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, mockSmtp)
+
+	testUser := &models.User{
+		ID:    uuid.New(),
+		Email: "test@example.com",
+	}
+	testDevice := &dto.DeviceRequest{
+		IP: "192.168.1.1",
+		UA: "test-user-agent",
+	}
+	device := auth.GenerateDevice(testDevice)
+	otherDevice := models.Device{ID: "other-device"}
+	testRefreshTime := time.Now().Add(time.Hour)
+	expected := dto.TokenPair{
+		Access:    "access-token",
+		Refresh:   "refresh-token",
+		ExpiresAt: testRefreshTime,
+		DeviceID:  auth.GenerateDevice(testDevice).ID,
+	}
+
+	issue := func() {
+		mockRepo.EXPECT().
+			ListUserRoles(gomock.Any(), testUser.ID).
+			Return(nil, nil)
+		mockAuth.EXPECT().
+			GenPair(gomock.Any(), testUser.ID, gomock.Any(), gomock.Any()).
+			Return(expected.Access, expected.Refresh, nil)
+	}
+	store := func() {
+		mockAuth.EXPECT().
+			GetRefreshTime().
+			Return(testRefreshTime)
+		mockRepo.EXPECT().
+			CreateToken(gomock.Any(), testUser.ID, auth.HashToken(nil, expected.Refresh), gomock.Any(), false, gomock.Any()).
+			Return(nil)
+	}
+
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr bool
+	}{
+		{
+			name: "FirstDevice",
+			setup: func() {
+				issue()
+				mockRepo.EXPECT().ListDevices(gomock.Any(), testUser.ID).Return(nil, nil)
+				store()
+			},
+			wantErr: false,
+		},
+		{
+			name: "KnownDevice",
+			setup: func() {
+				issue()
+				mockRepo.EXPECT().
+					ListDevices(gomock.Any(), testUser.ID).
+					Return([]models.Device{otherDevice, {ID: device.ID}}, nil)
+				store()
+			},
+			wantErr: false,
+		},
+		{
+			name: "NewDevice",
+			setup: func() {
+				issue()
+				mockRepo.EXPECT().
+					ListDevices(gomock.Any(), testUser.ID).
+					Return([]models.Device{otherDevice}, nil)
+				store()
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				mockSmtp.EXPECT().
+					SendNewDeviceEmail(gomock.Any(), gomock.Any(), testUser.Email).
+					DoAndReturn(func(ctx context.Context, d *models.Device, email string) error {
+						assert.Equal(t, device.ID, d.ID)
+						return nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "NewDeviceEmailError",
+			setup: func() {
+				issue()
+				mockRepo.EXPECT().
+					ListDevices(gomock.Any(), testUser.ID).
+					Return([]models.Device{otherDevice}, nil)
+				store()
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUser.ID).Return(testUser, nil)
+				mockSmtp.EXPECT().
+					SendNewDeviceEmail(gomock.Any(), gomock.Any(), testUser.Email).
+					Return(errors.New("smtp error"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "TokenGenerationError",
+			setup: func() {
+				mockRepo.EXPECT().
+					ListUserRoles(gomock.Any(), testUser.ID).
+					Return(nil, nil)
+				mockAuth.EXPECT().
+					GenPair(gomock.Any(), testUser.ID, gomock.Any(), gomock.Any()).
+					Return("", "", errors.New("token error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "ListDevicesError",
+			setup: func() {
+				issue()
+				mockRepo.EXPECT().
+					ListDevices(gomock.Any(), testUser.ID).
+					Return(nil, errors.New("db error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "CreateTokenError",
+			setup: func() {
+				issue()
+				mockRepo.EXPECT().ListDevices(gomock.Any(), testUser.ID).Return(nil, nil)
+				mockAuth.EXPECT().
+					GetRefreshTime().
+					Return(testRefreshTime)
+				mockRepo.EXPECT().
+					CreateToken(gomock.Any(), testUser.ID, auth.HashToken(nil, expected.Refresh), gomock.Any(), false, gomock.Any()).
+					Return(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			result, err := ctrl.GenPair(ctx, testDevice, testUser.ID, false)
 			if tt.wantErr {
 				assert.Error(t, err)
-				if tt.err != nil {
-					assert.ErrorIs(t, err, tt.err)
-				}
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, expected, result)
 			}
 		})
 	}
@@ -0,0 +1,127 @@
+package ctrl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/goccy/go-json"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+const (
+	codeMin = 100000
+	codeMax = 999999
+)
+
+const (
+	codeAttemptsCacheKey = "%v:attempts"
+	codeCooldownCacheKey = "%v:cooldown"
+)
+
+// oneTimeCode is a short numeric code stored in the cache until it is used,
+// expires or runs out of attempts.
+type oneTimeCode struct {
+	Code      int       `json:"code"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func generateCode() (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(codeMax-codeMin+1))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n.Int64()) + codeMin, nil
+}
+
+// issueCode stores a new code under key, replacing the previous one. A code
+// for the same key is issued at most once per config.CodeResendCooldown,
+// otherwise ErrTooManyRequests is returned. Attempts made against earlier
+// codes still count for the new one.
+func (c *Controller) issueCode(ctx context.Context, key string, ttl time.Duration) (int, error) {
+	const op = "codes.issueCode.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	issued, err := c.cache.Incr(ctx, config.CodeResendCooldown, fmt.Sprintf(codeCooldownCacheKey, key))
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		return 0, err
+	}
+
+	if issued > 1 {
+		zap.L().Debug("code requested during cooldown", zap.String("op", op), zap.String("key", key))
+		return 0, ErrTooManyRequests
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to generate code", zap.String("op", op), zap.Error(err))
+		return 0, err
+	}
+
+	bytes, err := json.Marshal(
+		&oneTimeCode{
+			Code:      code,
+			ExpiresAt: time.Now().Add(ttl),
+		},
+	)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to marshal code", zap.String("op", op), zap.Error(err))
+		return 0, err
+	}
+
+	c.cache.Set(ctx, ttl, key, bytes)
+	return code, nil
+}
+
+// checkCode consumes the code stored under key. Every check counts as an
+// attempt, the counter is incremented atomically so concurrent guesses can't
+// exceed config.MaxCodeAttempts. It starts with the first attempt and lives
+// as long as the code it was made against.
+func (c *Controller) checkCode(ctx context.Context, key string, code int) error {
+	const op = "codes.checkCode.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	stored := &oneTimeCode{}
+	if err := c.cache.GetToStruct(ctx, key, stored); err != nil {
+		return ErrCodeIsNotValid
+	}
+
+	ttl := time.Until(stored.ExpiresAt)
+	if ttl <= 0 {
+		return ErrCodeIsNotValid
+	}
+
+	attemptsKey := fmt.Sprintf(codeAttemptsCacheKey, key)
+	attempts, err := c.cache.Incr(ctx, ttl, attemptsKey)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		return err
+	}
+
+	if attempts > config.MaxCodeAttempts {
+		zap.L().Debug("code attempts exhausted", zap.String("op", op), zap.String("key", key))
+		c.cache.Delete(ctx, key)
+		return ErrCodeIsNotValid
+	}
+
+	if subtle.ConstantTimeEq(int32(stored.Code), int32(code)) != 1 { //nolint:gosec
+		return ErrCodeIsNotValid
+	}
+
+	c.cache.Delete(ctx, key)
+	c.cache.Delete(ctx, attemptsKey)
+	return nil
+}
@@ -0,0 +1,150 @@
+package ctrl
+
+import (
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/cache"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestController_IssueCode(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, nil, nil, mockCache, nil, nil)
+
+	const key = "code:test"
+
+	t.Run("Success", func(t *testing.T) {
+		mockCache.EXPECT().Incr(gomock.Any(), config.CodeResendCooldown, key+":cooldown").Return(int64(1), nil)
+		mockCache.EXPECT().
+			Set(gomock.Any(), time.Minute, key, gomock.Any()).
+			Do(func(ctx context.Context, ttl time.Duration, key string, val any) {
+				res := &oneTimeCode{}
+				assert.NoError(t, json.Unmarshal(val.([]byte), res))
+				assert.WithinDuration(t, time.Now().Add(time.Minute), res.ExpiresAt, time.Second)
+			})
+
+		code, err := ctrl.issueCode(ctx, key, time.Minute)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, code, codeMin)
+	})
+
+	t.Run("Cooldown", func(t *testing.T) {
+		mockCache.EXPECT().Incr(gomock.Any(), config.CodeResendCooldown, key+":cooldown").Return(int64(2), nil)
+
+		_, err := ctrl.issueCode(ctx, key, time.Minute)
+		assert.ErrorIs(t, err, ErrTooManyRequests)
+	})
+}
+
+func TestController_CheckCode(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	const key = "code:test"
+	const attemptsKey = key + ":attempts"
+	const testCode = 123456
+
+	stored := func(ctx context.Context, key string, dest any) error {
+		*dest.(*oneTimeCode) = oneTimeCode{
+			Code:      testCode,
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name  string
+		setup func()
+		code  int
+		err   error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).DoAndReturn(stored)
+				mockCache.EXPECT().Incr(gomock.Any(), gomock.Any(), attemptsKey).Return(int64(1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), key).Return()
+				mockCache.EXPECT().Delete(gomock.Any(), attemptsKey).Return()
+			},
+			code: testCode,
+			err:  nil,
+		},
+		{
+			name: "Missing",
+			setup: func() {
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), key, gomock.Any()).
+					Return(cache.ErrNotFoundInCache)
+			},
+			code: testCode,
+			err:  ErrCodeIsNotValid,
+		},
+		{
+			name: "WrongCodeCountsAttempt",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).DoAndReturn(stored)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), gomock.Any(), attemptsKey).
+					DoAndReturn(func(ctx context.Context, ttl time.Duration, key string) (int64, error) {
+						assert.Greater(t, ttl, time.Duration(0))
+						assert.LessOrEqual(t, ttl, time.Minute)
+						return 1, nil
+					})
+			},
+			code: testCode + 1,
+			err:  ErrCodeIsNotValid,
+		},
+		{
+			name: "AttemptsExhausted",
+			setup: func() {
+				mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).DoAndReturn(stored)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), gomock.Any(), attemptsKey).
+					Return(int64(config.MaxCodeAttempts+1), nil)
+				mockCache.EXPECT().Delete(gomock.Any(), key).Return()
+			},
+			code: testCode,
+			err:  ErrCodeIsNotValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			err := ctrl.checkCode(ctx, key, tt.code)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	for range 100 {
+		code, err := generateCode()
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, code, codeMin)
+		assert.LessOrEqual(t, code, codeMax)
+	}
+}
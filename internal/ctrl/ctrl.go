@@ -5,19 +5,36 @@ import (
 	"time"
 
 	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
 	"github.com/JMURv/golang-clean-template/internal/repo/s3"
+	"github.com/google/uuid"
 )
 
 type AppRepo interface {
 	authRepo
 	deviceRepo
+	oauthRepo
+	roleRepo
+	serviceAccountRepo
+	totpRepo
 	userRepo
+	webAuthnRepo
 }
 
 type AppCtrl interface {
 	authCtrl
 	deviceCtrl
+	lockoutCtrl
+	oauthCtrl
+	oauthDeviceCtrl
+	oidcCtrl
+	roleCtrl
+	serviceAccountCtrl
+	tokenCtrl
+	totpCtrl
 	userCtrl
+	webAuthnCtrl
 }
 
 type S3Service interface {
@@ -27,14 +44,23 @@ type S3Service interface {
 type CacheService interface {
 	Close(ctx context.Context) error
 	GetToStruct(ctx context.Context, key string, dest any) error
+	GetDelToStruct(ctx context.Context, key string, dest any) error
 	Set(ctx context.Context, t time.Duration, key string, val any)
+	Incr(ctx context.Context, t time.Duration, key string) (int64, error)
 	Delete(ctx context.Context, key string)
 	InvalidateKeysByPattern(ctx context.Context, pattern string)
 }
 
-type EmailService any
+type EmailService interface {
+	SendForgotPasswordEmail(ctx context.Context, code int, uid uuid.UUID, toEmail string) error
+	SendVerificationEmail(ctx context.Context, code int, uid uuid.UUID, toEmail string) error
+	SendLoginCodeEmail(ctx context.Context, code int, toEmail string) error
+	SendNewDeviceEmail(ctx context.Context, d *md.Device, toEmail string) error
+	SendAccountLockedEmail(ctx context.Context, ip string, until time.Time, toEmail string) error
+}
 
 type Controller struct {
+	conf  config.Config
 	au    auth.Core
 	repo  AppRepo
 	cache CacheService
@@ -43,6 +69,7 @@ type Controller struct {
 }
 
 func New(
+	conf config.Config,
 	au auth.Core,
 	repo AppRepo,
 	cache CacheService,
@@ -50,6 +77,7 @@ func New(
 	smtp EmailService,
 ) *Controller {
 	return &Controller{
+		conf:  conf,
 		au:    au,
 		repo:  repo,
 		cache: cache,
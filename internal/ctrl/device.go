@@ -22,6 +22,7 @@ type deviceCtrl interface {
 type deviceRepo interface {
 	GetByDevice(ctx context.Context, userID uuid.UUID, deviceID string) (*md.RefreshToken, error)
 	RevokeByDevice(ctx context.Context, userID uuid.UUID, deviceID string) error
+	RevokeOtherDevices(ctx context.Context, userID uuid.UUID, deviceID string) error
 
 	ListDevices(ctx context.Context, uid uuid.UUID) ([]md.Device, error)
 	GetDevice(ctx context.Context, uid uuid.UUID, dID string) (*md.Device, error)
@@ -109,7 +110,12 @@ func (c *Controller) DeleteDevice(ctx context.Context, uid uuid.UUID, dID string
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
-	err := c.repo.DeleteDevice(ctx, uid, dID)
+	err := c.repo.RevokeByDevice(ctx, uid, dID)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.DeleteDevice(ctx, uid, dID)
 	if err != nil {
 		if errors.Is(err, repo.ErrNotFound) {
 			return ErrNotFound
@@ -118,5 +124,6 @@ func (c *Controller) DeleteDevice(ctx context.Context, uid uuid.UUID, dID string
 		return err
 	}
 
+	c.au.RevokeDeviceTokens(ctx, uid, dID)
 	return nil
 }
@@ -3,6 +3,7 @@ package ctrl
 import (
 	"context"
 	"errors"
+	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/dto"
 	md "github.com/JMURv/golang-clean-template/internal/models"
 	"github.com/JMURv/golang-clean-template/internal/repo"
@@ -23,7 +24,7 @@ func TestController_ListDevices(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
 	testDevices := []md.Device{
@@ -114,7 +115,7 @@ func TestController_GetDevice(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
 	testDeviceID := uuid.New().String()
@@ -202,7 +203,7 @@ func TestController_GetDeviceByID(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testDeviceID := uuid.New().String()
 	testDevice := &md.Device{
@@ -285,7 +286,7 @@ func TestController_UpdateDevice(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
 	testDeviceID := uuid.New().String()
@@ -371,7 +372,7 @@ func TestController_DeleteDevice(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
 	testDeviceID := uuid.New().String()
@@ -387,17 +388,35 @@ func TestController_DeleteDevice(t *testing.T) {
 		{
 			name: "Success",
 			setup: func() {
+				mockRepo.EXPECT().
+					RevokeByDevice(gomock.Any(), testUserID, testDeviceID).
+					Return(nil)
 				mockRepo.EXPECT().
 					DeleteDevice(gomock.Any(), testUserID, testDeviceID).
 					Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), testUserID, testDeviceID)
 			},
 			userID:   testUserID,
 			deviceID: testDeviceID,
 			wantErr:  false,
 		},
+		{
+			name: "RevokeError",
+			setup: func() {
+				mockRepo.EXPECT().
+					RevokeByDevice(gomock.Any(), testUserID, testDeviceID).
+					Return(errors.New("database error"))
+			},
+			userID:   testUserID,
+			deviceID: testDeviceID,
+			wantErr:  true,
+		},
 		{
 			name: "DeviceNotFound",
 			setup: func() {
+				mockRepo.EXPECT().
+					RevokeByDevice(gomock.Any(), testUserID, testDeviceID).
+					Return(nil)
 				mockRepo.EXPECT().
 					DeleteDevice(gomock.Any(), testUserID, testDeviceID).
 					Return(repo.ErrNotFound)
@@ -410,6 +429,9 @@ func TestController_DeleteDevice(t *testing.T) {
 		{
 			name: "RepositoryError",
 			setup: func() {
+				mockRepo.EXPECT().
+					RevokeByDevice(gomock.Any(), testUserID, testDeviceID).
+					Return(nil)
 				mockRepo.EXPECT().
 					DeleteDevice(gomock.Any(), testUserID, testDeviceID).
 					Return(errors.New("database error"))
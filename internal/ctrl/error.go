@@ -8,5 +8,26 @@ var ErrNotFound = errors.New("not found")
 // ErrAlreadyExists is returned when a resource already exists.
 var ErrAlreadyExists = errors.New("already exists")
 
-// ErrCodeIsNotValid is returned when login code is not valid.
+// ErrEmailNotVerified is returned when a user with an unverified email tries to log in.
+var ErrEmailNotVerified = errors.New("email is not verified")
+
+// ErrTooManyRequests is returned when an action is repeated before its cooldown expires.
+var ErrTooManyRequests = errors.New("too many requests")
+
+// ErrAccountLocked is returned when a user signs in to an account locked after too many failed logins.
+var ErrAccountLocked = errors.New("account is temporarily locked")
+
+// ErrCodeIsNotValid is returned when a one-time code is not valid.
 var ErrCodeIsNotValid = errors.New("code is not valid")
+
+// ErrChallengeIsNotValid is returned when a 2FA challenge is unknown, expired or bound to another device.
+var ErrChallengeIsNotValid = errors.New("challenge is not valid")
+
+// ErrCredentialIsNotValid is returned when a passkey registration or assertion fails verification.
+var ErrCredentialIsNotValid = errors.New("credential is not valid")
+
+// ErrInvalidOAuthClient is returned when an OAuth client registration has invalid redirect URIs or grant types.
+var ErrInvalidOAuthClient = errors.New("invalid oauth client")
+
+// ErrInvalidAPIKey is returned when an API key is requested with an expiry in the past.
+var ErrInvalidAPIKey = errors.New("invalid api key")
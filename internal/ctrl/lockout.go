@@ -0,0 +1,214 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type lockoutCtrl interface {
+	UnlockUser(ctx context.Context, uid uuid.UUID) error
+}
+
+const (
+	loginFailuresCacheKey = "login-failures:%v"
+	loginDelayCacheKey    = "login-delay:%v"
+	loginLockCacheKey     = "login-lock:%v"
+)
+
+// loginBlock is the cached state of a login delay or lockout.
+type loginBlock struct {
+	Until time.Time `json:"until"`
+}
+
+// accountSubject and ipSubject name whose failed logins a counter tracks.
+func accountSubject(uid uuid.UUID) string {
+	return "user:" + uid.String()
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAllowed rejects a password check of the account u, or of an
+// unknown account when u is nil, from ip. A locked account gets
+// ErrAccountLocked, an account inside its delay or an IP over
+// AUTH_LOCKOUT_IP_THRESHOLD gets ErrTooManyRequests.
+func (c *Controller) checkLoginAllowed(ctx context.Context, ip string, u *md.User) error {
+	const op = "lockout.checkLoginAllowed.ctrl"
+
+	conf := c.conf.Auth.Lockout
+	if conf.IPThreshold > 0 && ip != "" {
+		if locked, _ := c.loginBlocked(ctx, ipSubject(ip)); locked {
+			zap.L().Info("login attempts from ip exhausted", zap.String("op", op), zap.String("ip", ip))
+			return ErrTooManyRequests
+		}
+	}
+
+	if conf.Threshold <= 0 || u == nil {
+		return nil
+	}
+
+	locked, delayed := c.loginBlocked(ctx, accountSubject(u.ID))
+	if locked {
+		return ErrAccountLocked
+	}
+
+	if delayed {
+		return ErrTooManyRequests
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a wrong password for the account u, or for an
+// unknown account when u is nil, from ip. Every failure of an account delays
+// its next attempt twice as long as the previous one, up to
+// AUTH_LOCKOUT_MAX_DELAY. Reaching AUTH_LOCKOUT_THRESHOLD locks the account
+// for AUTH_LOCKOUT_DURATION and emails its owner. IPs are not delayed, so
+// users behind a shared address aren't slowed down by each other, but are
+// blocked once they reach AUTH_LOCKOUT_IP_THRESHOLD.
+func (c *Controller) recordLoginFailure(ctx context.Context, ip string, u *md.User) {
+	const op = "lockout.recordLoginFailure.ctrl"
+
+	conf := c.conf.Auth.Lockout
+	if conf.IPThreshold > 0 && ip != "" {
+		subject := ipSubject(ip)
+		n, err := c.cache.Incr(ctx, conf.Window, fmt.Sprintf(loginFailuresCacheKey, subject))
+		if err != nil {
+			zap.L().Error("failed to count login failure", zap.String("op", op), zap.Error(err))
+		} else if n >= int64(conf.IPThreshold) {
+			c.lockLogin(ctx, subject)
+		}
+	}
+
+	if conf.Threshold <= 0 || u == nil {
+		return
+	}
+
+	subject := accountSubject(u.ID)
+	n, err := c.cache.Incr(ctx, conf.Window, fmt.Sprintf(loginFailuresCacheKey, subject))
+	if err != nil {
+		zap.L().Error("failed to count login failure", zap.String("op", op), zap.Error(err))
+		return
+	}
+
+	if n < int64(conf.Threshold) {
+		if delay := c.loginDelay(n); delay > 0 {
+			c.setLoginBlock(ctx, fmt.Sprintf(loginDelayCacheKey, subject), time.Now().Add(delay))
+		}
+		return
+	}
+
+	until := c.lockLogin(ctx, subject)
+	zap.L().Info("account locked", zap.String("op", op), zap.String("userID", u.ID.String()))
+
+	if err = c.smtp.SendAccountLockedEmail(ctx, ip, until, u.Email); err != nil {
+		zap.L().Error(
+			"failed to send account locked email",
+			zap.String("op", op),
+			zap.String("userID", u.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// clearLoginFailures forgets the failures of the account after a successful
+// password check. Failures of the IP are kept, or an attacker could reset
+// them by signing in to an account of their own.
+func (c *Controller) clearLoginFailures(ctx context.Context, u *md.User) {
+	if c.conf.Auth.Lockout.Threshold <= 0 {
+		return
+	}
+
+	subject := accountSubject(u.ID)
+	c.cache.Delete(ctx, fmt.Sprintf(loginFailuresCacheKey, subject))
+	c.cache.Delete(ctx, fmt.Sprintf(loginDelayCacheKey, subject))
+}
+
+// UnlockUser lifts the lockout of the user and forgets their failed logins.
+func (c *Controller) UnlockUser(ctx context.Context, uid uuid.UUID) error {
+	const op = "lockout.UnlockUser.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	if _, err := c.repo.GetUserByID(ctx, uid); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	subject := accountSubject(uid)
+	c.cache.Delete(ctx, fmt.Sprintf(loginLockCacheKey, subject))
+	c.cache.Delete(ctx, fmt.Sprintf(loginFailuresCacheKey, subject))
+	c.cache.Delete(ctx, fmt.Sprintf(loginDelayCacheKey, subject))
+	return nil
+}
+
+// lockLogin locks subject for AUTH_LOCKOUT_DURATION and restarts its count.
+func (c *Controller) lockLogin(ctx context.Context, subject string) time.Time {
+	until := time.Now().Add(c.conf.Auth.Lockout.Duration)
+	c.setLoginBlock(ctx, fmt.Sprintf(loginLockCacheKey, subject), until)
+	c.cache.Delete(ctx, fmt.Sprintf(loginFailuresCacheKey, subject))
+	c.cache.Delete(ctx, fmt.Sprintf(loginDelayCacheKey, subject))
+	return until
+}
+
+// loginBlocked reports whether subject is locked or still waiting out the
+// delay of its last failure.
+func (c *Controller) loginBlocked(ctx context.Context, subject string) (locked, delayed bool) {
+	now := time.Now()
+
+	block := &loginBlock{}
+	if c.cache.GetToStruct(ctx, fmt.Sprintf(loginLockCacheKey, subject), block) == nil && now.Before(block.Until) {
+		return true, false
+	}
+
+	block = &loginBlock{}
+	if c.cache.GetToStruct(ctx, fmt.Sprintf(loginDelayCacheKey, subject), block) == nil && now.Before(block.Until) {
+		return false, true
+	}
+
+	return false, false
+}
+
+func (c *Controller) setLoginBlock(ctx context.Context, key string, until time.Time) {
+	bytes, err := json.Marshal(&loginBlock{Until: until})
+	if err != nil {
+		zap.L().Error("failed to marshal login block", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	c.cache.Set(ctx, time.Until(until), key, bytes)
+}
+
+// loginDelay returns how long the account waits after its nth failure:
+// AUTH_LOCKOUT_BASE_DELAY doubled for every earlier failure.
+func (c *Controller) loginDelay(n int64) time.Duration {
+	conf := c.conf.Auth.Lockout
+	if conf.BaseDelay <= 0 {
+		return 0
+	}
+
+	d := conf.BaseDelay
+	for i := int64(1); i < n && d < conf.MaxDelay; i++ {
+		d *= 2
+	}
+
+	if conf.MaxDelay > 0 && d > conf.MaxDelay {
+		d = conf.MaxDelay
+	}
+
+	return d
+}
@@ -0,0 +1,239 @@
+package ctrl
+
+import (
+	"context"
+	"fmt"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/cache"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestController_AuthenticateLockout(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockSmtp := mocks.NewMockEmailService(ctrlMock)
+
+	conf := config.Config{}
+	conf.Auth.Lockout.Threshold = 3
+	conf.Auth.Lockout.IPThreshold = 10
+	conf.Auth.Lockout.Window = time.Hour
+	conf.Auth.Lockout.Duration = time.Minute * 15
+	conf.Auth.Lockout.BaseDelay = time.Second
+	conf.Auth.Lockout.MaxDelay = time.Second * 30
+	ctrl := New(conf, mockAuth, mockRepo, mockCache, nil, mockSmtp)
+
+	ctx := context.Background()
+	testUser := &models.User{ID: uuid.New(), Email: "test@example.com", Password: "hash"}
+	d := &dto.DeviceRequest{IP: "10.0.0.1", UA: "test-user-agent"}
+	req := &dto.EmailAndPasswordRequest{Email: testUser.Email, Password: "wrong"}
+
+	account, ip := accountSubject(testUser.ID), ipSubject(d.IP)
+	blocked := func(_ context.Context, _ string, dest any) error {
+		*dest.(*loginBlock) = loginBlock{Until: time.Now().Add(time.Minute)}
+		return nil
+	}
+	miss := func(subject string, keys ...string) {
+		for _, k := range keys {
+			mockCache.EXPECT().
+				GetToStruct(gomock.Any(), fmt.Sprintf(k, subject), gomock.Any()).
+				Return(cache.ErrNotFoundInCache)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		setup func()
+		err   error
+	}{
+		{
+			name: "IPBlocked",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(testUser, nil)
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), fmt.Sprintf(loginLockCacheKey, ip), gomock.Any()).
+					DoAndReturn(blocked)
+			},
+			err: ErrTooManyRequests,
+		},
+		{
+			name: "AccountLocked",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(testUser, nil)
+				miss(ip, loginLockCacheKey, loginDelayCacheKey)
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), fmt.Sprintf(loginLockCacheKey, account), gomock.Any()).
+					DoAndReturn(blocked)
+			},
+			err: ErrAccountLocked,
+		},
+		{
+			name: "AccountDelayed",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(testUser, nil)
+				miss(ip, loginLockCacheKey, loginDelayCacheKey)
+				miss(account, loginLockCacheKey)
+				mockCache.EXPECT().
+					GetToStruct(gomock.Any(), fmt.Sprintf(loginDelayCacheKey, account), gomock.Any()).
+					DoAndReturn(blocked)
+			},
+			err: ErrTooManyRequests,
+		},
+		{
+			name: "FailureDelaysNextAttempt",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(testUser, nil)
+				miss(ip, loginLockCacheKey, loginDelayCacheKey)
+				miss(account, loginLockCacheKey, loginDelayCacheKey)
+				mockAuth.EXPECT().ComparePasswords(gomock.Any(), gomock.Any()).Return(auth.ErrInvalidCredentials)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), time.Hour, fmt.Sprintf(loginFailuresCacheKey, ip)).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), time.Hour, fmt.Sprintf(loginFailuresCacheKey, account)).
+					Return(int64(2), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), gomock.Any(), fmt.Sprintf(loginDelayCacheKey, account), gomock.Any()).
+					Do(
+						func(_ context.Context, ttl time.Duration, _ string, _ any) {
+							assert.InDelta(t, float64(time.Second*2), float64(ttl), float64(time.Second))
+						},
+					)
+			},
+			err: auth.ErrInvalidCredentials,
+		},
+		{
+			name: "FailureLocksAccount",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(testUser, nil)
+				miss(ip, loginLockCacheKey, loginDelayCacheKey)
+				miss(account, loginLockCacheKey, loginDelayCacheKey)
+				mockAuth.EXPECT().ComparePasswords(gomock.Any(), gomock.Any()).Return(auth.ErrInvalidCredentials)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), time.Hour, fmt.Sprintf(loginFailuresCacheKey, ip)).
+					Return(int64(3), nil)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), time.Hour, fmt.Sprintf(loginFailuresCacheKey, account)).
+					Return(int64(3), nil)
+				mockCache.EXPECT().Set(gomock.Any(), gomock.Any(), fmt.Sprintf(loginLockCacheKey, account), gomock.Any())
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(loginFailuresCacheKey, account))
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(loginDelayCacheKey, account))
+				mockSmtp.EXPECT().SendAccountLockedEmail(gomock.Any(), d.IP, gomock.Any(), testUser.Email).Return(nil)
+			},
+			err: auth.ErrInvalidCredentials,
+		},
+		{
+			name: "UnknownEmailBlocksIP",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(nil, repo.ErrNotFound)
+				miss(ip, loginLockCacheKey, loginDelayCacheKey)
+				mockCache.EXPECT().
+					Incr(gomock.Any(), time.Hour, fmt.Sprintf(loginFailuresCacheKey, ip)).
+					Return(int64(10), nil)
+				mockCache.EXPECT().Set(gomock.Any(), gomock.Any(), fmt.Sprintf(loginLockCacheKey, ip), gomock.Any())
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(loginFailuresCacheKey, ip))
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(loginDelayCacheKey, ip))
+			},
+			err: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.setup()
+
+				_, _, err := ctrl.Authenticate(ctx, d, req)
+				assert.ErrorIs(t, err, tt.err)
+			},
+		)
+	}
+
+	t.Run(
+		"LoginCodeIPBlocked", func(t *testing.T) {
+			mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(testUser, nil)
+			mockCache.EXPECT().
+				GetToStruct(gomock.Any(), fmt.Sprintf(loginLockCacheKey, ip), gomock.Any()).
+				DoAndReturn(blocked)
+
+			err := ctrl.SendLoginCode(ctx, d.IP, &dto.LoginCodeRequest{Email: req.Email, Password: req.Password})
+			assert.ErrorIs(t, err, ErrTooManyRequests)
+		},
+	)
+
+	t.Run(
+		"LoginCodeFailureCountsIP", func(t *testing.T) {
+			mockRepo.EXPECT().GetUserByEmail(gomock.Any(), req.Email).Return(nil, repo.ErrNotFound)
+			miss(ip, loginLockCacheKey, loginDelayCacheKey)
+			mockCache.EXPECT().
+				Incr(gomock.Any(), time.Hour, fmt.Sprintf(loginFailuresCacheKey, ip)).
+				Return(int64(1), nil)
+
+			err := ctrl.SendLoginCode(ctx, d.IP, &dto.LoginCodeRequest{Email: req.Email, Password: req.Password})
+			assert.ErrorIs(t, err, ErrNotFound)
+		},
+	)
+}
+
+func TestController_UnlockUser(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, mockCache, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	account := accountSubject(uid)
+
+	tests := []struct {
+		name  string
+		setup func()
+		err   error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(&models.User{ID: uid}, nil)
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(loginLockCacheKey, account))
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(loginFailuresCacheKey, account))
+				mockCache.EXPECT().Delete(gomock.Any(), fmt.Sprintf(loginDelayCacheKey, account))
+			},
+		},
+		{
+			name: "NotFound",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(nil, repo.ErrNotFound)
+			},
+			err: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.setup()
+
+				err := ctrl.UnlockUser(ctx, uid)
+				if tt.err != nil {
+					assert.ErrorIs(t, err, tt.err)
+				} else {
+					assert.NoError(t, err)
+				}
+			},
+		)
+	}
+}
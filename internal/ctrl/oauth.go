@@ -0,0 +1,567 @@
+package ctrl
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type oauthCtrl interface {
+	CreateOAuthClient(ctx context.Context, req *dto.CreateOAuthClientRequest) (*dto.CreateOAuthClientResponse, error)
+	ListOAuthClients(ctx context.Context) ([]md.OAuthClient, error)
+	DeleteOAuthClient(ctx context.Context, id uuid.UUID) error
+	Authorize(ctx context.Context, uid uuid.UUID, req *dto.AuthorizeRequest) (*dto.AuthorizeResponse, error)
+	Consent(ctx context.Context, uid uuid.UUID, req *dto.ConsentRequest) (*dto.AuthorizeResponse, error)
+	Token(ctx context.Context, req *dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error)
+}
+
+type oauthRepo interface {
+	CreateOAuthClient(ctx context.Context, c *md.OAuthClient) error
+	GetOAuthClient(ctx context.Context, id uuid.UUID) (*md.OAuthClient, error)
+	ListOAuthClients(ctx context.Context) ([]md.OAuthClient, error)
+	DeleteOAuthClient(ctx context.Context, id uuid.UUID) error
+	GetOAuthConsent(ctx context.Context, uid, clientID uuid.UUID) (*md.OAuthConsent, error)
+	SaveOAuthConsent(ctx context.Context, c *md.OAuthConsent) error
+	CreateOAuthToken(ctx context.Context, t *md.OAuthToken) error
+	GetOAuthToken(ctx context.Context, hashedT string) (*md.OAuthToken, error)
+	RotateOAuthToken(ctx context.Context, parent, t *md.OAuthToken) error
+	RevokeOAuthTokens(ctx context.Context, uid, clientID uuid.UUID) error
+	RevokeUserOAuthTokens(ctx context.Context, uid uuid.UUID) error
+	RevokeOAuthToken(ctx context.Context, id uint64) error
+}
+
+const oauthCodeCacheKey = "oauth-code:%v"
+
+// oauthCode is the cached state of an authorization code. RedirectURI is
+// empty when the authorization request didn't name one.
+type oauthCode struct {
+	ClientID    uuid.UUID `json:"clientId"`
+	UserID      uuid.UUID `json:"userId"`
+	RedirectURI string    `json:"redirectUri"`
+	Scope       string    `json:"scope"`
+	Challenge   string    `json:"challenge"`
+	Nonce       string    `json:"nonce,omitempty"`
+}
+
+// authorization is a validated authorization request.
+type authorization struct {
+	client      *md.OAuthClient
+	redirectURI string
+	scope       []string
+}
+
+// CreateOAuthClient registers a client. Confidential clients get a secret
+// that is returned once and stored as a keyed hash.
+func (c *Controller) CreateOAuthClient(
+	ctx context.Context,
+	req *dto.CreateOAuthClientRequest,
+) (*dto.CreateOAuthClientResponse, error) {
+	const op = "oauth.CreateOAuthClient.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	for _, uri := range req.RedirectURIs {
+		if !oauth.ValidRedirectURI(uri) {
+			return nil, ErrInvalidOAuthClient
+		}
+	}
+
+	grants := oauth.ParseScope(strings.Join(req.GrantTypes, " "))
+	if slices.Contains(grants, oauth.GrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return nil, ErrInvalidOAuthClient
+	}
+
+	if req.Public && slices.Contains(grants, oauth.GrantClientCredentials) {
+		return nil, ErrInvalidOAuthClient
+	}
+
+	client := &md.OAuthClient{
+		Name:         req.Name,
+		Public:       req.Public,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		Scopes:       oauth.JoinScope(oauth.ParseScope(strings.Join(req.Scopes, " "))),
+		GrantTypes:   strings.Join(grants, " "),
+	}
+
+	var secret string
+	if !req.Public {
+		var err error
+		secret, err = oauth.GenerateToken()
+		if err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error("failed to generate client secret", zap.String("op", op), zap.Error(err))
+			return nil, err
+		}
+
+		client.SecretHash = c.hashToken(secret)
+	}
+
+	if err := c.repo.CreateOAuthClient(ctx, client); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateOAuthClientResponse{Client: client, Secret: secret}, nil
+}
+
+func (c *Controller) ListOAuthClients(ctx context.Context) ([]md.OAuthClient, error) {
+	const op = "oauth.ListOAuthClients.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return c.repo.ListOAuthClients(ctx)
+}
+
+// DeleteOAuthClient removes the client with its consents and refresh tokens.
+func (c *Controller) DeleteOAuthClient(ctx context.Context, id uuid.UUID) error {
+	const op = "oauth.DeleteOAuthClient.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := c.repo.DeleteOAuthClient(ctx, id)
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// Authorize validates an authorization request of the logged in user. If the
+// user already granted the requested scopes, the response redirects back to
+// the client with a code. Otherwise it describes the consent to ask for.
+// Errors are returned only while the redirect URI can't be trusted, later
+// ones are sent to the client in the redirect.
+func (c *Controller) Authorize(
+	ctx context.Context,
+	uid uuid.UUID,
+	req *dto.AuthorizeRequest,
+) (*dto.AuthorizeResponse, error) {
+	const op = "oauth.Authorize.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	a, oerr, err := c.checkAuthorize(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if oerr != nil {
+		return &dto.AuthorizeResponse{RedirectTo: oauth.ErrorRedirect(a.redirectURI, oerr, req.State)}, nil
+	}
+
+	if req.Prompt != oauth.PromptConsent {
+		consent, err := c.repo.GetOAuthConsent(ctx, uid, a.client.ID)
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return nil, err
+		}
+
+		if err == nil && oauth.Covers(oauth.ParseScope(consent.Scope), a.scope) {
+			return c.issueAuthorizationCode(ctx, uid, a, req)
+		}
+	}
+
+	return &dto.AuthorizeResponse{
+		ClientID:   a.client.ID,
+		ClientName: a.client.Name,
+		Scope:      oauth.JoinScope(a.scope),
+	}, nil
+}
+
+// Consent records the user's decision on an authorization request and
+// redirects back to the client with a code or access_denied.
+func (c *Controller) Consent(
+	ctx context.Context,
+	uid uuid.UUID,
+	req *dto.ConsentRequest,
+) (*dto.AuthorizeResponse, error) {
+	const op = "oauth.Consent.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	a, oerr, err := c.checkAuthorize(ctx, &req.AuthorizeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if oerr == nil && !req.Approve {
+		oerr = oauth.ErrAccessDenied
+	}
+
+	if oerr != nil {
+		return &dto.AuthorizeResponse{RedirectTo: oauth.ErrorRedirect(a.redirectURI, oerr, req.State)}, nil
+	}
+
+	scope := a.scope
+	consent, err := c.repo.GetOAuthConsent(ctx, uid, a.client.ID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	if err == nil {
+		scope = oauth.Merge(oauth.ParseScope(consent.Scope), scope)
+	}
+
+	err = c.repo.SaveOAuthConsent(
+		ctx, &md.OAuthConsent{
+			UserID:   uid,
+			ClientID: a.client.ID,
+			Scope:    oauth.JoinScope(scope),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.issueAuthorizationCode(ctx, uid, a, &req.AuthorizeRequest)
+}
+
+// checkAuthorize validates req. An unknown client or redirect URI is
+// returned as err, other problems as an OAuth error for the redirect.
+func (c *Controller) checkAuthorize(
+	ctx context.Context,
+	req *dto.AuthorizeRequest,
+) (*authorization, *oauth.Error, error) {
+	id, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, nil, oauth.ErrInvalidRequest.WithDescription("unknown client")
+	}
+
+	client, err := c.repo.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, nil, oauth.ErrInvalidRequest.WithDescription("unknown client")
+		}
+
+		return nil, nil, err
+	}
+
+	uri, ok := oauth.ResolveRedirectURI(strings.Fields(client.RedirectURIs), req.RedirectURI)
+	if !ok {
+		return nil, nil, oauth.ErrInvalidRequest.WithDescription("redirect_uri is not registered")
+	}
+
+	a := &authorization{client: client, redirectURI: uri}
+	if req.ResponseType != oauth.ResponseTypeCode {
+		return a, oauth.ErrUnsupportedResponseType, nil
+	}
+
+	if !hasGrant(client, oauth.GrantAuthorizationCode) {
+		return a, oauth.ErrUnauthorizedClient, nil
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != oauth.MethodS256 {
+		return a, oauth.ErrInvalidRequest.WithDescription("code_challenge with method S256 is required"), nil
+	}
+
+	a.scope, ok = grantedScope(client, req.Scope)
+	if !ok {
+		return a, oauth.ErrInvalidScope, nil
+	}
+
+	return a, nil, nil
+}
+
+// issueAuthorizationCode stores a single use code and redirects to the
+// client with it.
+func (c *Controller) issueAuthorizationCode(
+	ctx context.Context,
+	uid uuid.UUID,
+	a *authorization,
+	req *dto.AuthorizeRequest,
+) (*dto.AuthorizeResponse, error) {
+	const op = "oauth.issueAuthorizationCode.ctrl"
+
+	code, err := oauth.GenerateToken()
+	if err != nil {
+		zap.L().Error("failed to generate authorization code", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	bytes, err := json.Marshal(
+		&oauthCode{
+			ClientID:    a.client.ID,
+			UserID:      uid,
+			RedirectURI: req.RedirectURI,
+			Scope:       oauth.JoinScope(a.scope),
+			Challenge:   req.CodeChallenge,
+			Nonce:       req.Nonce,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.Set(ctx, config.OAuthCodeDuration, fmt.Sprintf(oauthCodeCacheKey, c.hashToken(code)), bytes)
+
+	params := url.Values{}
+	params.Set("code", code)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	return &dto.AuthorizeResponse{RedirectTo: oauth.RedirectURI(a.redirectURI, params)}, nil
+}
+
+// Token serves the token endpoint for the authorization_code,
+// refresh_token, client_credentials and device_code grants. Failures are
+// *oauth.Error unless something unexpected broke.
+func (c *Controller) Token(ctx context.Context, req *dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	const op = "oauth.Token.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	client, err := c.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case oauth.GrantAuthorizationCode, oauth.GrantRefreshToken, oauth.GrantClientCredentials, oauth.GrantDeviceCode:
+	default:
+		return nil, oauth.ErrUnsupportedGrantType
+	}
+
+	if !hasGrant(client, req.GrantType) {
+		return nil, oauth.ErrUnauthorizedClient
+	}
+
+	switch req.GrantType {
+	case oauth.GrantAuthorizationCode:
+		return c.exchangeCode(ctx, client, req)
+	case oauth.GrantRefreshToken:
+		return c.refreshOAuthToken(ctx, client, req)
+	case oauth.GrantDeviceCode:
+		return c.exchangeDeviceCode(ctx, client, req)
+	default:
+		scope, ok := grantedScope(client, req.Scope)
+		if !ok {
+			return nil, oauth.ErrInvalidScope
+		}
+
+		return c.issueOAuthTokens(ctx, client, uuid.Nil, scope, "", nil)
+	}
+}
+
+// authenticateClient checks the secret of confidential clients. Public
+// clients are identified by their ID only and must not send a secret.
+func (c *Controller) authenticateClient(ctx context.Context, clientID, secret string) (*md.OAuthClient, error) {
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, oauth.ErrInvalidClient
+	}
+
+	client, err := c.repo.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, oauth.ErrInvalidClient
+		}
+
+		return nil, err
+	}
+
+	if client.Public {
+		if secret != "" {
+			return nil, oauth.ErrInvalidClient
+		}
+
+		return client, nil
+	}
+
+	if secret == "" || subtle.ConstantTimeCompare([]byte(c.hashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, oauth.ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+func (c *Controller) exchangeCode(
+	ctx context.Context,
+	client *md.OAuthClient,
+	req *dto.OAuthTokenRequest,
+) (*dto.OAuthTokenResponse, error) {
+	const op = "oauth.exchangeCode.ctrl"
+
+	key := fmt.Sprintf(oauthCodeCacheKey, c.hashToken(req.Code))
+	code := &oauthCode{}
+	if err := c.cache.GetDelToStruct(ctx, key, code); err != nil {
+		return nil, oauth.ErrInvalidGrant.WithDescription("unknown or expired code")
+	}
+
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI {
+		return nil, oauth.ErrInvalidGrant
+	}
+
+	if !oauth.VerifyPKCE(req.CodeVerifier, code.Challenge) {
+		zap.L().Debug(
+			"pkce verification failed",
+			zap.String("op", op),
+			zap.String("clientID", client.ID.String()),
+		)
+
+		return nil, oauth.ErrInvalidGrant.WithDescription("code_verifier does not match")
+	}
+
+	return c.issueOAuthTokens(ctx, client, code.UserID, oauth.ParseScope(code.Scope), code.Nonce, nil)
+}
+
+// refreshOAuthToken rotates the refresh token. A revoked token being used
+// again means it leaked, so every token of the client for the user is
+// revoked.
+func (c *Controller) refreshOAuthToken(
+	ctx context.Context,
+	client *md.OAuthClient,
+	req *dto.OAuthTokenRequest,
+) (*dto.OAuthTokenResponse, error) {
+	const op = "oauth.refreshOAuthToken.ctrl"
+
+	token, err := c.repo.GetOAuthToken(ctx, c.hashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, oauth.ErrInvalidGrant
+		}
+
+		return nil, err
+	}
+
+	if token.ClientID != client.ID {
+		return nil, oauth.ErrInvalidGrant
+	}
+
+	if token.Revoked {
+		zap.L().Warn(
+			"oauth refresh token reused",
+			zap.String("op", op),
+			zap.String("userID", token.UserID.String()),
+			zap.String("clientID", client.ID.String()),
+		)
+
+		if err = c.repo.RevokeOAuthTokens(ctx, token.UserID, client.ID); err != nil {
+			return nil, err
+		}
+
+		return nil, oauth.ErrInvalidGrant
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, oauth.ErrInvalidGrant.WithDescription("refresh token expired")
+	}
+
+	granted := oauth.ParseScope(token.Scope)
+	scope := granted
+	if req.Scope != "" {
+		scope = oauth.ParseScope(req.Scope)
+		if !oauth.Covers(granted, scope) {
+			return nil, oauth.ErrInvalidScope
+		}
+	}
+
+	return c.issueOAuthTokens(ctx, client, token.UserID, scope, "", token)
+}
+
+// issueOAuthTokens signs an access token for scope. Tokens on behalf of a
+// user come with an ID token carrying nonce when scope includes openid, and
+// with a refresh token if the client may use it, parent is the refresh token
+// being rotated. The refresh token keeps the scope of the original grant.
+func (c *Controller) issueOAuthTokens(
+	ctx context.Context,
+	client *md.OAuthClient,
+	uid uuid.UUID,
+	scope []string,
+	nonce string,
+	parent *md.OAuthToken,
+) (*dto.OAuthTokenResponse, error) {
+	const op = "oauth.issueOAuthTokens.ctrl"
+
+	access, err := c.au.NewClientToken(ctx, uid, client.ID.String(), oauth.JoinScope(scope), config.AccessTokenDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.OAuthTokenResponse{
+		AccessToken: access,
+		TokenType:   oauth.TokenTypeBearer,
+		ExpiresIn:   int64(config.AccessTokenDuration.Seconds()),
+		Scope:       oauth.JoinScope(scope),
+	}
+
+	if uid == uuid.Nil {
+		return res, nil
+	}
+
+	if slices.Contains(scope, oauth.ScopeOpenID) {
+		if res.IDToken, err = c.issueIDToken(ctx, client, uid, scope, nonce); err != nil {
+			return nil, err
+		}
+	}
+
+	if !hasGrant(client, oauth.GrantRefreshToken) {
+		return res, nil
+	}
+
+	refresh, err := oauth.GenerateToken()
+	if err != nil {
+		zap.L().Error("failed to generate refresh token", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	token := &md.OAuthToken{
+		TokenHash: c.hashToken(refresh),
+		ClientID:  client.ID,
+		UserID:    uid,
+		Scope:     oauth.JoinScope(scope),
+		ExpiresAt: time.Now().Add(config.OAuthRefreshTokenDuration),
+	}
+
+	if parent == nil {
+		err = c.repo.CreateOAuthToken(ctx, token)
+	} else {
+		token.Scope = parent.Scope
+		err = c.repo.RotateOAuthToken(ctx, parent, token)
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, oauth.ErrInvalidGrant
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.RefreshToken = refresh
+	return res, nil
+}
+
+func hasGrant(client *md.OAuthClient, grant string) bool {
+	return slices.Contains(strings.Fields(client.GrantTypes), grant)
+}
+
+// grantedScope resolves the requested scope against the client's scopes.
+// An empty request gets all of them.
+func grantedScope(client *md.OAuthClient, requested string) ([]string, bool) {
+	allowed := oauth.ParseScope(client.Scopes)
+	if requested == "" {
+		return allowed, true
+	}
+
+	scope := oauth.ParseScope(requested)
+	return scope, oauth.Covers(allowed, scope)
+}
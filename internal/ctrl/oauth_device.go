@@ -0,0 +1,262 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type oauthDeviceCtrl interface {
+	DeviceAuthorization(ctx context.Context, req *dto.DeviceCodeRequest) (*dto.DeviceCodeResponse, error)
+	GetDeviceConsent(ctx context.Context, uid uuid.UUID, userCode string) (*dto.DeviceConsentResponse, error)
+	ApproveDevice(ctx context.Context, uid uuid.UUID, req *dto.DeviceApprovalRequest) error
+}
+
+const (
+	deviceCodeCacheKey     = "oauth-device:%v"
+	devicePollCacheKey     = "oauth-device-poll:%v"
+	userCodeCacheKey       = "oauth-user-code:%v"
+	userCodeChecksCacheKey = "oauth-user-code-checks:%v"
+)
+
+// deviceGrant is the cached state of a device authorization, keyed by the
+// hash of its device code. UserID is set once a user approved it.
+type deviceGrant struct {
+	ClientID  uuid.UUID `json:"clientId"`
+	Scope     string    `json:"scope"`
+	UserID    uuid.UUID `json:"userId"`
+	Denied    bool      `json:"denied,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// userCodeRef points from a user code to the device code hash of its grant.
+type userCodeRef struct {
+	DeviceKey string `json:"deviceKey"`
+}
+
+// DeviceAuthorization starts the device authorization grant (RFC 8628): the
+// device shows the user code and polls the token endpoint while the user
+// approves it from an authenticated session.
+func (c *Controller) DeviceAuthorization(
+	ctx context.Context,
+	req *dto.DeviceCodeRequest,
+) (*dto.DeviceCodeResponse, error) {
+	const op = "oauth.DeviceAuthorization.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	client, err := c.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasGrant(client, oauth.GrantDeviceCode) {
+		return nil, oauth.ErrUnauthorizedClient
+	}
+
+	scope, ok := grantedScope(client, req.Scope)
+	if !ok {
+		return nil, oauth.ErrInvalidScope
+	}
+
+	deviceCode, err := oauth.GenerateToken()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to generate device code", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	userCode, err := oauth.GenerateUserCode()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to generate user code", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	deviceKey := fmt.Sprintf(deviceCodeCacheKey, c.hashToken(deviceCode))
+	grant := &deviceGrant{
+		ClientID:  client.ID,
+		Scope:     oauth.JoinScope(scope),
+		ExpiresAt: time.Now().Add(config.DeviceCodeDuration),
+	}
+	if err = c.saveDeviceGrant(ctx, deviceKey, grant); err != nil {
+		return nil, err
+	}
+
+	ref, err := json.Marshal(&userCodeRef{DeviceKey: deviceKey})
+	if err != nil {
+		return nil, err
+	}
+	c.cache.Set(ctx, config.DeviceCodeDuration, fmt.Sprintf(userCodeCacheKey, oauth.NormalizeUserCode(userCode)), ref)
+
+	uri := fmt.Sprintf("%s://%s/device", c.conf.Server.Scheme, c.conf.Server.Domain)
+	return &dto.DeviceCodeResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         uri,
+		VerificationURIComplete: oauth.RedirectURI(uri, url.Values{"user_code": {userCode}}),
+		ExpiresIn:               int64(config.DeviceCodeDuration.Seconds()),
+		Interval:                int64(config.DeviceCodeInterval.Seconds()),
+	}, nil
+}
+
+// GetDeviceConsent describes the pending device authorization of userCode so
+// the user can check it before approving.
+func (c *Controller) GetDeviceConsent(
+	ctx context.Context,
+	uid uuid.UUID,
+	userCode string,
+) (*dto.DeviceConsentResponse, error) {
+	const op = "oauth.GetDeviceConsent.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, grant, err := c.pendingDeviceGrant(ctx, uid, userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := c.repo.GetOAuthClient(ctx, grant.ClientID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &dto.DeviceConsentResponse{
+		ClientID:   client.ID,
+		ClientName: client.Name,
+		Scope:      grant.Scope,
+	}, nil
+}
+
+// ApproveDevice records the user's decision on the device authorization of
+// the user code. The code can only be answered once.
+func (c *Controller) ApproveDevice(ctx context.Context, uid uuid.UUID, req *dto.DeviceApprovalRequest) error {
+	const op = "oauth.ApproveDevice.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	ref, grant, err := c.pendingDeviceGrant(ctx, uid, req.UserCode)
+	if err != nil {
+		return err
+	}
+	c.cache.Delete(ctx, fmt.Sprintf(userCodeCacheKey, oauth.NormalizeUserCode(req.UserCode)))
+
+	if req.Approve {
+		grant.UserID = uid
+	} else {
+		grant.Denied = true
+	}
+
+	return c.saveDeviceGrant(ctx, ref.DeviceKey, grant)
+}
+
+// pendingDeviceGrant resolves a user code typed by uid. Every check counts
+// towards config.MaxUserCodeChecks, so codes can't be guessed. The counter
+// is not reset on a match, or a code of the attacker's own device would
+// reset it.
+func (c *Controller) pendingDeviceGrant(
+	ctx context.Context,
+	uid uuid.UUID,
+	userCode string,
+) (*userCodeRef, *deviceGrant, error) {
+	const op = "oauth.pendingDeviceGrant.ctrl"
+
+	n, err := c.cache.Incr(ctx, config.DeviceCodeDuration, fmt.Sprintf(userCodeChecksCacheKey, uid))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if n > config.MaxUserCodeChecks {
+		zap.L().Info("user code checks exhausted", zap.String("op", op), zap.String("userID", uid.String()))
+		return nil, nil, ErrTooManyRequests
+	}
+
+	ref := &userCodeRef{}
+	err = c.cache.GetToStruct(ctx, fmt.Sprintf(userCodeCacheKey, oauth.NormalizeUserCode(userCode)), ref)
+	if err != nil {
+		return nil, nil, ErrNotFound
+	}
+
+	grant := &deviceGrant{}
+	if err = c.cache.GetToStruct(ctx, ref.DeviceKey, grant); err != nil {
+		return nil, nil, ErrNotFound
+	}
+
+	return ref, grant, nil
+}
+
+// saveDeviceGrant stores grant until it expires.
+func (c *Controller) saveDeviceGrant(ctx context.Context, key string, grant *deviceGrant) error {
+	ttl := time.Until(grant.ExpiresAt)
+	if ttl <= 0 {
+		return ErrNotFound
+	}
+
+	bytes, err := json.Marshal(grant)
+	if err != nil {
+		return err
+	}
+
+	c.cache.Set(ctx, ttl, key, bytes)
+	return nil
+}
+
+// exchangeDeviceCode answers a poll of the device. Polls faster than
+// config.DeviceCodeInterval get slow_down, polls before the user answered get
+// authorization_pending. The grant is consumed once answered.
+func (c *Controller) exchangeDeviceCode(
+	ctx context.Context,
+	client *md.OAuthClient,
+	req *dto.OAuthTokenRequest,
+) (*dto.OAuthTokenResponse, error) {
+	hash := c.hashToken(req.DeviceCode)
+	key := fmt.Sprintf(deviceCodeCacheKey, hash)
+	grant := &deviceGrant{}
+	if err := c.cache.GetToStruct(ctx, key, grant); err != nil {
+		return nil, oauth.ErrExpiredToken
+	}
+
+	if grant.ClientID != client.ID {
+		return nil, oauth.ErrInvalidGrant
+	}
+
+	n, err := c.cache.Incr(ctx, config.DeviceCodeInterval, fmt.Sprintf(devicePollCacheKey, hash))
+	if err != nil {
+		return nil, err
+	}
+
+	if n > 1 {
+		return nil, oauth.ErrSlowDown
+	}
+
+	if grant.Denied {
+		c.cache.Delete(ctx, key)
+		return nil, oauth.ErrAccessDenied
+	}
+
+	if grant.UserID == uuid.Nil {
+		return nil, oauth.ErrAuthorizationPending
+	}
+
+	c.cache.Delete(ctx, key)
+	return c.issueOAuthTokens(ctx, client, grant.UserID, oauth.ParseScope(grant.Scope), "", nil)
+}
@@ -0,0 +1,134 @@
+package ctrl
+
+import (
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestController_DeviceAuthorization(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	conf := config.Config{}
+	conf.Server.Scheme = "https"
+	conf.Server.Domain = "sso.example.com"
+	ctrl := New(conf, mockAuth, mockRepo, mockCache, nil, nil)
+	fakeCache(mockCache)
+
+	counters := make(map[string]int64)
+	mockCache.EXPECT().
+		Incr(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(
+			func(_ context.Context, _ time.Duration, key string) (int64, error) {
+				counters[key]++
+				return counters[key], nil
+			},
+		).AnyTimes()
+	// resetPolls lets the next poll through as if the interval passed.
+	resetPolls := func() {
+		for key := range counters {
+			if strings.HasPrefix(key, "oauth-device-poll:") {
+				delete(counters, key)
+			}
+		}
+	}
+
+	ctx := context.Background()
+	uid := uuid.New()
+	client := newOAuthClient(true)
+	client.GrantTypes = oauth.GrantDeviceCode
+	mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil).AnyTimes()
+
+	start := func(t *testing.T) *dto.DeviceCodeResponse {
+		res, err := ctrl.DeviceAuthorization(ctx, &dto.DeviceCodeRequest{ClientID: client.ID.String(), Scope: "profile"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://sso.example.com/device", res.VerificationURI)
+		assert.Equal(t, "https://sso.example.com/device?user_code="+res.UserCode, res.VerificationURIComplete)
+		assert.Equal(t, int64(config.DeviceCodeInterval.Seconds()), res.Interval)
+		return res
+	}
+	poll := func(deviceCode string) (*dto.OAuthTokenResponse, error) {
+		return ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:  oauth.GrantDeviceCode,
+				DeviceCode: deviceCode,
+				ClientID:   client.ID.String(),
+			},
+		)
+	}
+
+	t.Run("Approved", func(t *testing.T) {
+		res := start(t)
+
+		_, err := poll(res.DeviceCode)
+		assert.ErrorIs(t, err, oauth.ErrAuthorizationPending)
+
+		_, err = poll(res.DeviceCode)
+		assert.ErrorIs(t, err, oauth.ErrSlowDown)
+
+		consent, err := ctrl.GetDeviceConsent(ctx, uid, res.UserCode)
+		require.NoError(t, err)
+		assert.Equal(t, client.Name, consent.ClientName)
+		assert.Equal(t, "profile", consent.Scope)
+
+		require.NoError(t, ctrl.ApproveDevice(ctx, uid, &dto.DeviceApprovalRequest{UserCode: res.UserCode, Approve: true}))
+
+		// The user code is answered once
+		err = ctrl.ApproveDevice(ctx, uid, &dto.DeviceApprovalRequest{UserCode: res.UserCode, Approve: true})
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		resetPolls()
+		mockAuth.EXPECT().
+			NewClientToken(gomock.Any(), uid, client.ID.String(), "profile", config.AccessTokenDuration).
+			Return("access", nil)
+
+		tokens, err := poll(res.DeviceCode)
+		require.NoError(t, err)
+		assert.Equal(t, "access", tokens.AccessToken)
+
+		resetPolls()
+		_, err = poll(res.DeviceCode)
+		assert.ErrorIs(t, err, oauth.ErrExpiredToken)
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		res := start(t)
+		require.NoError(t, ctrl.ApproveDevice(ctx, uid, &dto.DeviceApprovalRequest{UserCode: res.UserCode}))
+
+		resetPolls()
+		_, err := poll(res.DeviceCode)
+		assert.ErrorIs(t, err, oauth.ErrAccessDenied)
+	})
+
+	t.Run("UnknownUserCode", func(t *testing.T) {
+		other := uuid.New()
+		for range config.MaxUserCodeChecks {
+			_, err := ctrl.GetDeviceConsent(ctx, other, "BCDF-GHJK")
+			assert.ErrorIs(t, err, ErrNotFound)
+		}
+
+		_, err := ctrl.GetDeviceConsent(ctx, other, "BCDF-GHJK")
+		assert.ErrorIs(t, err, ErrTooManyRequests)
+	})
+
+	t.Run("UnauthorizedClient", func(t *testing.T) {
+		other := newOAuthClient(true)
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), other.ID).Return(other, nil)
+
+		_, err := ctrl.DeviceAuthorization(ctx, &dto.DeviceCodeRequest{ClientID: other.ID.String()})
+		assert.ErrorIs(t, err, oauth.ErrUnauthorizedClient)
+	})
+}
@@ -0,0 +1,481 @@
+package ctrl
+
+import (
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testVerifier = "dBjftJeZ4CVP-mJ92K9f4ydP2UWv0sZRwn3X2dcoqi0"
+
+func newOAuthClient(public bool) *models.OAuthClient {
+	return &models.OAuthClient{
+		ID:           uuid.New(),
+		Name:         "app",
+		Public:       public,
+		RedirectURIs: "https://app.example.com/cb",
+		Scopes:       "profile email",
+		GrantTypes:   "authorization_code refresh_token",
+	}
+}
+
+func TestController_CreateOAuthClient(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+
+	t.Run("Confidential", func(t *testing.T) {
+		mockRepo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Return(nil)
+
+		res, err := ctrl.CreateOAuthClient(
+			ctx, &dto.CreateOAuthClientRequest{
+				Name:       "backend",
+				Scopes:     []string{"profile", "profile"},
+				GrantTypes: []string{oauth.GrantClientCredentials},
+			},
+		)
+		require.NoError(t, err)
+		assert.NotEmpty(t, res.Secret)
+		assert.Equal(t, auth.HashToken(nil, res.Secret), res.Client.SecretHash)
+		assert.Equal(t, "profile", res.Client.Scopes)
+	})
+
+	t.Run("Public", func(t *testing.T) {
+		mockRepo.EXPECT().CreateOAuthClient(gomock.Any(), gomock.Any()).Return(nil)
+
+		res, err := ctrl.CreateOAuthClient(
+			ctx, &dto.CreateOAuthClientRequest{
+				Name:         "spa",
+				Public:       true,
+				RedirectURIs: []string{"https://app.example.com/cb", "com.example.app:/cb"},
+				GrantTypes:   []string{oauth.GrantAuthorizationCode},
+			},
+		)
+		require.NoError(t, err)
+		assert.Empty(t, res.Secret)
+		assert.Empty(t, res.Client.SecretHash)
+		assert.Equal(t, "https://app.example.com/cb com.example.app:/cb", res.Client.RedirectURIs)
+	})
+
+	t.Run("InvalidRedirectURI", func(t *testing.T) {
+		_, err := ctrl.CreateOAuthClient(
+			ctx, &dto.CreateOAuthClientRequest{
+				Name:         "spa",
+				RedirectURIs: []string{"javascript:alert(1)"},
+				GrantTypes:   []string{oauth.GrantAuthorizationCode},
+			},
+		)
+		assert.ErrorIs(t, err, ErrInvalidOAuthClient)
+	})
+
+	t.Run("CodeWithoutRedirectURI", func(t *testing.T) {
+		_, err := ctrl.CreateOAuthClient(
+			ctx, &dto.CreateOAuthClientRequest{
+				Name:       "spa",
+				GrantTypes: []string{oauth.GrantAuthorizationCode},
+			},
+		)
+		assert.ErrorIs(t, err, ErrInvalidOAuthClient)
+	})
+
+	t.Run("PublicClientCredentials", func(t *testing.T) {
+		_, err := ctrl.CreateOAuthClient(
+			ctx, &dto.CreateOAuthClientRequest{
+				Name:       "spa",
+				Public:     true,
+				GrantTypes: []string{oauth.GrantClientCredentials},
+			},
+		)
+		assert.ErrorIs(t, err, ErrInvalidOAuthClient)
+	})
+}
+
+func TestController_Authorize(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, mockCache, nil, nil)
+	fakeCache(mockCache)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	client := newOAuthClient(true)
+	request := func() *dto.AuthorizeRequest {
+		return &dto.AuthorizeRequest{
+			ResponseType:        oauth.ResponseTypeCode,
+			ClientID:            client.ID.String(),
+			Scope:               "profile",
+			State:               "xyz",
+			CodeChallenge:       oauth.S256Challenge(testVerifier),
+			CodeChallengeMethod: oauth.MethodS256,
+		}
+	}
+
+	t.Run("AsksForConsent", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+		mockRepo.EXPECT().GetOAuthConsent(gomock.Any(), uid, client.ID).Return(nil, repo.ErrNotFound)
+
+		res, err := ctrl.Authorize(ctx, uid, request())
+		require.NoError(t, err)
+		assert.Empty(t, res.RedirectTo)
+		assert.Equal(t, client.ID, res.ClientID)
+		assert.Equal(t, "profile", res.Scope)
+	})
+
+	t.Run("ConsentGranted", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+		mockRepo.EXPECT().
+			GetOAuthConsent(gomock.Any(), uid, client.ID).
+			Return(&models.OAuthConsent{Scope: "email profile"}, nil)
+
+		res, err := ctrl.Authorize(ctx, uid, request())
+		require.NoError(t, err)
+
+		redirect, err := url.Parse(res.RedirectTo)
+		require.NoError(t, err)
+		assert.Equal(t, "app.example.com", redirect.Host)
+		assert.NotEmpty(t, redirect.Query().Get("code"))
+		assert.Equal(t, "xyz", redirect.Query().Get("state"))
+	})
+
+	t.Run("PromptConsent", func(t *testing.T) {
+		req := request()
+		req.Prompt = oauth.PromptConsent
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+
+		res, err := ctrl.Authorize(ctx, uid, req)
+		require.NoError(t, err)
+		assert.Empty(t, res.RedirectTo)
+	})
+
+	t.Run("MissingChallenge", func(t *testing.T) {
+		req := request()
+		req.CodeChallenge = ""
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+
+		res, err := ctrl.Authorize(ctx, uid, req)
+		require.NoError(t, err)
+
+		redirect, err := url.Parse(res.RedirectTo)
+		require.NoError(t, err)
+		assert.Equal(t, "invalid_request", redirect.Query().Get("error"))
+		assert.Equal(t, "xyz", redirect.Query().Get("state"))
+	})
+
+	t.Run("InvalidScope", func(t *testing.T) {
+		req := request()
+		req.Scope = "admin"
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+
+		res, err := ctrl.Authorize(ctx, uid, req)
+		require.NoError(t, err)
+		assert.Contains(t, res.RedirectTo, "error=invalid_scope")
+	})
+
+	t.Run("UnknownRedirectURI", func(t *testing.T) {
+		req := request()
+		req.RedirectURI = "https://evil.example.com/cb"
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+
+		_, err := ctrl.Authorize(ctx, uid, req)
+		assert.ErrorIs(t, err, oauth.ErrInvalidRequest)
+	})
+
+	t.Run("UnknownClient", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(nil, repo.ErrNotFound)
+
+		_, err := ctrl.Authorize(ctx, uid, request())
+		assert.ErrorIs(t, err, oauth.ErrInvalidRequest)
+	})
+}
+
+func TestController_Consent(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, mockCache, nil, nil)
+	fakeCache(mockCache)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	client := newOAuthClient(true)
+	request := func(approve bool) *dto.ConsentRequest {
+		return &dto.ConsentRequest{
+			AuthorizeRequest: dto.AuthorizeRequest{
+				ResponseType:        oauth.ResponseTypeCode,
+				ClientID:            client.ID.String(),
+				Scope:               "profile",
+				CodeChallenge:       oauth.S256Challenge(testVerifier),
+				CodeChallengeMethod: oauth.MethodS256,
+			},
+			Approve: approve,
+		}
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+		mockRepo.EXPECT().
+			GetOAuthConsent(gomock.Any(), uid, client.ID).
+			Return(&models.OAuthConsent{Scope: "email"}, nil)
+		mockRepo.EXPECT().
+			SaveOAuthConsent(
+				gomock.Any(), &models.OAuthConsent{UserID: uid, ClientID: client.ID, Scope: "email profile"},
+			).
+			Return(nil)
+
+		res, err := ctrl.Consent(ctx, uid, request(true))
+		require.NoError(t, err)
+		assert.Contains(t, res.RedirectTo, "code=")
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+
+		res, err := ctrl.Consent(ctx, uid, request(false))
+		require.NoError(t, err)
+		assert.Contains(t, res.RedirectTo, "error=access_denied")
+	})
+}
+
+func TestController_Token(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, nil, nil)
+	fakeCache(mockCache)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	public := newOAuthClient(true)
+
+	authorize := func(t *testing.T) string {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+		mockRepo.EXPECT().
+			GetOAuthConsent(gomock.Any(), uid, public.ID).
+			Return(&models.OAuthConsent{Scope: "profile"}, nil)
+
+		res, err := ctrl.Authorize(
+			ctx, uid, &dto.AuthorizeRequest{
+				ResponseType:        oauth.ResponseTypeCode,
+				ClientID:            public.ID.String(),
+				Scope:               "profile",
+				CodeChallenge:       oauth.S256Challenge(testVerifier),
+				CodeChallengeMethod: oauth.MethodS256,
+			},
+		)
+		require.NoError(t, err)
+
+		redirect, err := url.Parse(res.RedirectTo)
+		require.NoError(t, err)
+		return redirect.Query().Get("code")
+	}
+
+	t.Run("AuthorizationCode", func(t *testing.T) {
+		code := authorize(t)
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+		mockAuth.EXPECT().
+			NewClientToken(gomock.Any(), uid, public.ID.String(), "profile", config.AccessTokenDuration).
+			Return("access", nil)
+		mockRepo.EXPECT().CreateOAuthToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		res, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantAuthorizationCode,
+				Code:         code,
+				CodeVerifier: testVerifier,
+				ClientID:     public.ID.String(),
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "access", res.AccessToken)
+		assert.Equal(t, oauth.TokenTypeBearer, res.TokenType)
+		assert.NotEmpty(t, res.RefreshToken)
+		assert.Equal(t, "profile", res.Scope)
+
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+		_, err = ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantAuthorizationCode,
+				Code:         code,
+				CodeVerifier: testVerifier,
+				ClientID:     public.ID.String(),
+			},
+		)
+		assert.ErrorIs(t, err, oauth.ErrInvalidGrant)
+	})
+
+	t.Run("WrongVerifier", func(t *testing.T) {
+		code := authorize(t)
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+
+		_, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantAuthorizationCode,
+				Code:         code,
+				CodeVerifier: "x" + testVerifier[1:],
+				ClientID:     public.ID.String(),
+			},
+		)
+		assert.ErrorIs(t, err, oauth.ErrInvalidGrant)
+	})
+
+	t.Run("RefreshToken", func(t *testing.T) {
+		parent := &models.OAuthToken{
+			ID:        1,
+			ClientID:  public.ID,
+			UserID:    uid,
+			Scope:     "email profile",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+		mockRepo.EXPECT().GetOAuthToken(gomock.Any(), auth.HashToken(nil, "refresh")).Return(parent, nil)
+		mockAuth.EXPECT().
+			NewClientToken(gomock.Any(), uid, public.ID.String(), "profile", config.AccessTokenDuration).
+			Return("access", nil)
+		var rotated *models.OAuthToken
+		mockRepo.EXPECT().
+			RotateOAuthToken(gomock.Any(), parent, gomock.Any()).
+			DoAndReturn(
+				func(_ context.Context, _, t *models.OAuthToken) error {
+					rotated = t
+					return nil
+				},
+			)
+
+		res, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantRefreshToken,
+				RefreshToken: "refresh",
+				Scope:        "profile",
+				ClientID:     public.ID.String(),
+			},
+		)
+		require.NoError(t, err)
+		assert.NotEmpty(t, res.RefreshToken)
+		assert.Equal(t, "profile", res.Scope)
+		assert.Equal(t, "email profile", rotated.Scope)
+	})
+
+	t.Run("RefreshTokenReused", func(t *testing.T) {
+		parent := &models.OAuthToken{
+			ID:        1,
+			ClientID:  public.ID,
+			UserID:    uid,
+			Scope:     "profile",
+			ExpiresAt: time.Now().Add(time.Hour),
+			Revoked:   true,
+		}
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+		mockRepo.EXPECT().GetOAuthToken(gomock.Any(), gomock.Any()).Return(parent, nil)
+		mockRepo.EXPECT().RevokeOAuthTokens(gomock.Any(), uid, public.ID).Return(nil)
+
+		_, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantRefreshToken,
+				RefreshToken: "refresh",
+				ClientID:     public.ID.String(),
+			},
+		)
+		assert.ErrorIs(t, err, oauth.ErrInvalidGrant)
+	})
+
+	t.Run("RefreshTokenOfOtherClient", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+		mockRepo.EXPECT().
+			GetOAuthToken(gomock.Any(), gomock.Any()).
+			Return(&models.OAuthToken{ClientID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		_, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantRefreshToken,
+				RefreshToken: "refresh",
+				ClientID:     public.ID.String(),
+			},
+		)
+		assert.ErrorIs(t, err, oauth.ErrInvalidGrant)
+	})
+
+	t.Run("ClientCredentials", func(t *testing.T) {
+		confidential := newOAuthClient(false)
+		confidential.GrantTypes = oauth.GrantClientCredentials
+		confidential.SecretHash = auth.HashToken(nil, "secret")
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), confidential.ID).Return(confidential, nil)
+		mockAuth.EXPECT().
+			NewClientToken(gomock.Any(), uuid.Nil, confidential.ID.String(), "email", config.AccessTokenDuration).
+			Return("access", nil)
+
+		res, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantClientCredentials,
+				Scope:        "email",
+				ClientID:     confidential.ID.String(),
+				ClientSecret: "secret",
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "access", res.AccessToken)
+		assert.Empty(t, res.RefreshToken)
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		confidential := newOAuthClient(false)
+		confidential.SecretHash = auth.HashToken(nil, "secret")
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), confidential.ID).Return(confidential, nil)
+
+		_, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType:    oauth.GrantClientCredentials,
+				ClientID:     confidential.ID.String(),
+				ClientSecret: "wrong",
+			},
+		)
+		assert.ErrorIs(t, err, oauth.ErrInvalidClient)
+	})
+
+	t.Run("UnauthorizedGrant", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+
+		_, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType: oauth.GrantClientCredentials,
+				ClientID:  public.ID.String(),
+			},
+		)
+		assert.ErrorIs(t, err, oauth.ErrUnauthorizedClient)
+	})
+
+	t.Run("UnsupportedGrant", func(t *testing.T) {
+		mockRepo.EXPECT().GetOAuthClient(gomock.Any(), public.ID).Return(public, nil)
+
+		_, err := ctrl.Token(
+			ctx, &dto.OAuthTokenRequest{
+				GrantType: "password",
+				ClientID:  public.ID.String(),
+			},
+		)
+		assert.ErrorIs(t, err, oauth.ErrUnsupportedGrantType)
+	})
+}
@@ -0,0 +1,174 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type oidcCtrl interface {
+	UserInfo(ctx context.Context, uid uuid.UUID, scope string) (*dto.UserInfoResponse, error)
+	EndSession(ctx context.Context, req *dto.EndSessionRequest, deviceID string) (*dto.EndSessionResponse, error)
+}
+
+// UserInfo returns the claims about uid that scope covers.
+func (c *Controller) UserInfo(ctx context.Context, uid uuid.UUID, scope string) (*dto.UserInfoResponse, error) {
+	const op = "oidc.UserInfo.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	u, err := c.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	id := identity(u, oauth.ParseScope(scope))
+	return &dto.UserInfoResponse{
+		Sub:           u.ID.String(),
+		Email:         id.Email,
+		EmailVerified: id.EmailVerified,
+		Name:          id.Name,
+		Picture:       id.Picture,
+	}, nil
+}
+
+// EndSession serves RP-initiated logout. id_token_hint names the user and
+// the client; the session of the user on deviceID is ended and the user agent
+// is sent back to post_logout_redirect_uri, which must be a redirect URI of
+// that client. Errors are never redirected.
+func (c *Controller) EndSession(
+	ctx context.Context,
+	req *dto.EndSessionRequest,
+	deviceID string,
+) (*dto.EndSessionResponse, error) {
+	const op = "oidc.EndSession.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	if req.IDTokenHint == "" {
+		return nil, oauth.ErrInvalidRequest.WithDescription("id_token_hint is required")
+	}
+
+	claims, err := c.au.ParseIDToken(ctx, req.IDTokenHint)
+	if err != nil {
+		return nil, oauth.ErrInvalidRequest.WithDescription("invalid id_token_hint")
+	}
+
+	uid, err := uuid.Parse(claims.Subject)
+	if err != nil || len(claims.Audience) != 1 {
+		return nil, oauth.ErrInvalidRequest.WithDescription("invalid id_token_hint")
+	}
+
+	clientID := claims.Audience[0]
+	if req.ClientID != "" && req.ClientID != clientID {
+		return nil, oauth.ErrInvalidRequest.WithDescription("client_id does not match id_token_hint")
+	}
+
+	client, err := c.endSessionClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PostLogoutRedirectURI != "" &&
+		!slices.Contains(strings.Fields(client.RedirectURIs), req.PostLogoutRedirectURI) {
+		return nil, oauth.ErrInvalidRequest.WithDescription("post_logout_redirect_uri is not registered")
+	}
+
+	if err = c.repo.RevokeByDevice(ctx, uid, deviceID); err != nil {
+		return nil, err
+	}
+	c.au.RevokeDeviceTokens(ctx, uid, deviceID)
+
+	zap.L().Debug(
+		"rp-initiated logout",
+		zap.String("op", op),
+		zap.String("userID", uid.String()),
+		zap.String("clientID", clientID),
+	)
+
+	if req.PostLogoutRedirectURI == "" {
+		return &dto.EndSessionResponse{}, nil
+	}
+
+	params := url.Values{}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	return &dto.EndSessionResponse{RedirectTo: oauth.RedirectURI(req.PostLogoutRedirectURI, params)}, nil
+}
+
+func (c *Controller) endSessionClient(ctx context.Context, clientID string) (*md.OAuthClient, error) {
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, oauth.ErrInvalidRequest.WithDescription("unknown client")
+	}
+
+	client, err := c.repo.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, oauth.ErrInvalidRequest.WithDescription("unknown client")
+		}
+
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// issueIDToken signs an ID token about uid for client with the claims scope
+// covers.
+func (c *Controller) issueIDToken(
+	ctx context.Context,
+	client *md.OAuthClient,
+	uid uuid.UUID,
+	scope []string,
+	nonce string,
+) (string, error) {
+	u, err := c.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return "", oauth.ErrInvalidGrant
+		}
+
+		return "", err
+	}
+
+	return c.au.NewIDToken(ctx, uid, client.ID.String(), nonce, identity(u, scope), config.AccessTokenDuration)
+}
+
+// identity maps u to the standard claims: email and email_verified with the
+// email scope, name and picture with the profile scope.
+func identity(u *md.User, scope []string) jwt.Identity {
+	id := jwt.Identity{}
+	if slices.Contains(scope, oauth.ScopeEmail) {
+		verified := u.IsEmailVerified
+		id.Email = u.Email
+		id.EmailVerified = &verified
+	}
+
+	if slices.Contains(scope, oauth.ScopeProfile) {
+		id.Name = u.Name
+		id.Picture = u.Avatar
+	}
+
+	return id
+}
@@ -0,0 +1,256 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/url"
+	"testing"
+)
+
+func TestController_TokenOpenID(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, nil, nil)
+	fakeCache(mockCache)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	client := newOAuthClient(true)
+	client.Scopes = "openid profile email"
+	user := &models.User{ID: uid, Name: "User", Email: "user@example.com", Avatar: "https://cdn/a.png"}
+
+	mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+	mockRepo.EXPECT().
+		GetOAuthConsent(gomock.Any(), uid, client.ID).
+		Return(&models.OAuthConsent{Scope: "openid email"}, nil)
+
+	res, err := ctrl.Authorize(
+		ctx, uid, &dto.AuthorizeRequest{
+			ResponseType:        oauth.ResponseTypeCode,
+			ClientID:            client.ID.String(),
+			Scope:               "openid email",
+			CodeChallenge:       oauth.S256Challenge(testVerifier),
+			CodeChallengeMethod: oauth.MethodS256,
+			Nonce:               "nonce",
+		},
+	)
+	require.NoError(t, err)
+
+	redirect, err := url.Parse(res.RedirectTo)
+	require.NoError(t, err)
+
+	verified := false
+	mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+	mockAuth.EXPECT().
+		NewClientToken(gomock.Any(), uid, client.ID.String(), "openid email", config.AccessTokenDuration).
+		Return("access", nil)
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+	mockAuth.EXPECT().
+		NewIDToken(
+			gomock.Any(), uid, client.ID.String(), "nonce",
+			jwt.Identity{Email: user.Email, EmailVerified: &verified},
+			config.AccessTokenDuration,
+		).
+		Return("id", nil)
+	mockRepo.EXPECT().CreateOAuthToken(gomock.Any(), gomock.Any()).Return(nil)
+
+	tokens, err := ctrl.Token(
+		ctx, &dto.OAuthTokenRequest{
+			GrantType:    oauth.GrantAuthorizationCode,
+			Code:         redirect.Query().Get("code"),
+			CodeVerifier: testVerifier,
+			ClientID:     client.ID.String(),
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "id", tokens.IDToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+}
+
+func TestController_UserInfo(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	user := &models.User{
+		ID:              uid,
+		Name:            "User",
+		Email:           "user@example.com",
+		Avatar:          "https://cdn/a.png",
+		IsEmailVerified: true,
+	}
+	verified := true
+
+	tests := []struct {
+		name     string
+		scope    string
+		expected *dto.UserInfoResponse
+	}{
+		{
+			name:     "OpenIDOnly",
+			scope:    "openid",
+			expected: &dto.UserInfoResponse{Sub: uid.String()},
+		},
+		{
+			name:     "Email",
+			scope:    "openid email",
+			expected: &dto.UserInfoResponse{Sub: uid.String(), Email: user.Email, EmailVerified: &verified},
+		},
+		{
+			name:     "Profile",
+			scope:    "openid profile",
+			expected: &dto.UserInfoResponse{Sub: uid.String(), Name: user.Name, Picture: user.Avatar},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+
+				res, err := ctrl.UserInfo(ctx, uid, tt.scope)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			},
+		)
+	}
+}
+
+func TestController_EndSession(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	client := newOAuthClient(true)
+	hint := jwt.IDClaims{
+		Type: jwt.TypeID,
+		RegisteredClaims: gojwt.RegisteredClaims{
+			Subject:  uid.String(),
+			Audience: gojwt.ClaimStrings{client.ID.String()},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		req        *dto.EndSessionRequest
+		expect     func()
+		redirectTo string
+		err        error
+	}{
+		{
+			name: "Redirect",
+			req: &dto.EndSessionRequest{
+				IDTokenHint:           "hint",
+				PostLogoutRedirectURI: "https://app.example.com/cb",
+				State:                 "xyz",
+			},
+			expect: func() {
+				mockAuth.EXPECT().ParseIDToken(gomock.Any(), "hint").Return(hint, nil)
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockRepo.EXPECT().RevokeByDevice(gomock.Any(), uid, "device").Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), uid, "device")
+			},
+			redirectTo: "https://app.example.com/cb?state=xyz",
+		},
+		{
+			name: "NoRedirect",
+			req:  &dto.EndSessionRequest{IDTokenHint: "hint", ClientID: client.ID.String()},
+			expect: func() {
+				mockAuth.EXPECT().ParseIDToken(gomock.Any(), "hint").Return(hint, nil)
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockRepo.EXPECT().RevokeByDevice(gomock.Any(), uid, "device").Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), uid, "device")
+			},
+		},
+		{
+			name:   "MissingHint",
+			req:    &dto.EndSessionRequest{},
+			expect: func() {},
+			err:    oauth.ErrInvalidRequest,
+		},
+		{
+			name: "InvalidHint",
+			req:  &dto.EndSessionRequest{IDTokenHint: "hint"},
+			expect: func() {
+				mockAuth.EXPECT().ParseIDToken(gomock.Any(), "hint").Return(jwt.IDClaims{}, jwt.ErrInvalidToken)
+			},
+			err: oauth.ErrInvalidRequest,
+		},
+		{
+			name: "OtherClient",
+			req:  &dto.EndSessionRequest{IDTokenHint: "hint", ClientID: uuid.NewString()},
+			expect: func() {
+				mockAuth.EXPECT().ParseIDToken(gomock.Any(), "hint").Return(hint, nil)
+			},
+			err: oauth.ErrInvalidRequest,
+		},
+		{
+			name: "UnregisteredRedirect",
+			req: &dto.EndSessionRequest{
+				IDTokenHint:           "hint",
+				PostLogoutRedirectURI: "https://evil.example.com",
+			},
+			expect: func() {
+				mockAuth.EXPECT().ParseIDToken(gomock.Any(), "hint").Return(hint, nil)
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+			},
+			err: oauth.ErrInvalidRequest,
+		},
+		{
+			name: "RepoError",
+			req:  &dto.EndSessionRequest{IDTokenHint: "hint"},
+			expect: func() {
+				mockAuth.EXPECT().ParseIDToken(gomock.Any(), "hint").Return(hint, nil)
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockRepo.EXPECT().RevokeByDevice(gomock.Any(), uid, "device").Return(errors.New("db down"))
+			},
+			err: errors.New("db down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				res, err := ctrl.EndSession(ctx, tt.req, "device")
+				if tt.err != nil {
+					assert.Nil(t, res)
+					if errors.Is(tt.err, oauth.ErrInvalidRequest) {
+						assert.ErrorIs(t, err, tt.err)
+					} else {
+						assert.EqualError(t, err, tt.err.Error())
+					}
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.redirectTo, res.RedirectTo)
+			},
+		)
+	}
+}
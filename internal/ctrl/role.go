@@ -0,0 +1,114 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type roleCtrl interface {
+	ListRoles(ctx context.Context) ([]md.Role, error)
+	ListUserRoles(ctx context.Context, uid uuid.UUID) ([]md.Role, error)
+	AssignRole(ctx context.Context, uid uuid.UUID, role string) error
+	RevokeRole(ctx context.Context, uid uuid.UUID, role string) error
+}
+
+type roleRepo interface {
+	ListRoles(ctx context.Context) ([]md.Role, error)
+	ListUserRoles(ctx context.Context, uid uuid.UUID) ([]md.Role, error)
+	AssignRole(ctx context.Context, uid uuid.UUID, role string) error
+	RevokeRole(ctx context.Context, uid uuid.UUID, role string) error
+}
+
+func (c *Controller) ListRoles(ctx context.Context) ([]md.Role, error) {
+	const op = "roles.ListRoles.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return c.repo.ListRoles(ctx)
+}
+
+func (c *Controller) ListUserRoles(ctx context.Context, uid uuid.UUID) ([]md.Role, error) {
+	const op = "roles.ListUserRoles.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return c.repo.ListUserRoles(ctx, uid)
+}
+
+// AssignRole grants role to the user. Access tokens carry the permissions,
+// so the user's tokens are invalidated and the next refresh picks up the
+// new roles.
+func (c *Controller) AssignRole(ctx context.Context, uid uuid.UUID, role string) error {
+	const op = "roles.AssignRole.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := c.repo.AssignRole(ctx, uid, role)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	c.au.RevokeUserTokens(ctx, uid)
+
+	zap.L().Info("role assigned", zap.String("op", op), zap.String("uid", uid.String()), zap.String("role", role))
+	return nil
+}
+
+// RevokeRole takes role away from the user and invalidates their access
+// tokens, so the permissions of the role are gone from the next refresh on.
+func (c *Controller) RevokeRole(ctx context.Context, uid uuid.UUID, role string) error {
+	const op = "roles.RevokeRole.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := c.repo.RevokeRole(ctx, uid, role)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	c.au.RevokeUserTokens(ctx, uid)
+
+	zap.L().Info("role revoked", zap.String("op", op), zap.String("uid", uid.String()), zap.String("role", role))
+	return nil
+}
+
+// getAccess collects the roles and the union of their permissions for tokens.
+func (c *Controller) getAccess(ctx context.Context, uid uuid.UUID) (jwt.Access, error) {
+	var res jwt.Access
+
+	roles, err := c.repo.ListUserRoles(ctx, uid)
+	if err != nil {
+		return res, err
+	}
+
+	for _, r := range roles {
+		res.Roles = append(res.Roles, r.Name)
+		for _, p := range r.Permissions {
+			if !slices.Contains(res.Permissions, p) {
+				res.Permissions = append(res.Permissions, p)
+			}
+		}
+	}
+
+	return res, nil
+}
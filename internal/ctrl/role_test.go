@@ -0,0 +1,122 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestController_AssignRole(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	testUserID := uuid.New()
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name  string
+		setup func()
+		err   error
+	}{
+		{
+			name: "Success",
+			setup: func() {
+				mockRepo.EXPECT().AssignRole(gomock.Any(), testUserID, "admin").Return(nil)
+				mockAuth.EXPECT().RevokeUserTokens(gomock.Any(), testUserID)
+			},
+			err: nil,
+		},
+		{
+			name: "NotFound",
+			setup: func() {
+				mockRepo.EXPECT().AssignRole(gomock.Any(), testUserID, "admin").Return(repo.ErrNotFound)
+			},
+			err: ErrNotFound,
+		},
+		{
+			name: "RepoError",
+			setup: func() {
+				mockRepo.EXPECT().AssignRole(gomock.Any(), testUserID, "admin").Return(testErr)
+			},
+			err: testErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			err := ctrl.AssignRole(ctx, testUserID, "admin")
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestController_RevokeRole(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	testUserID := uuid.New()
+
+	mockRepo.EXPECT().RevokeRole(gomock.Any(), testUserID, "admin").Return(repo.ErrNotFound)
+	assert.ErrorIs(t, ctrl.RevokeRole(ctx, testUserID, "admin"), ErrNotFound)
+
+	mockRepo.EXPECT().RevokeRole(gomock.Any(), testUserID, "admin").Return(nil)
+	mockAuth.EXPECT().RevokeUserTokens(gomock.Any(), testUserID)
+	assert.NoError(t, ctrl.RevokeRole(ctx, testUserID, "admin"))
+}
+
+func TestController_GetAccess(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	mockS3 := mocks.NewMockS3Service(ctrlMock)
+
+	ctx := context.Background()
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
+
+	testUserID := uuid.New()
+	mockRepo.EXPECT().
+		ListUserRoles(gomock.Any(), testUserID).
+		Return(
+			[]md.Role{
+				{Name: "admin", Permissions: []string{"users:delete", "users:list"}},
+				{Name: "support", Permissions: []string{"users:list", "devices:read"}},
+			}, nil,
+		)
+
+	res, err := ctrl.getAccess(ctx, testUserID)
+	assert.NoError(t, err)
+	assert.Equal(
+		t, jwt.Access{
+			Roles:       []string{"admin", "support"},
+			Permissions: []string{"users:delete", "users:list", "devices:read"},
+		}, res,
+	)
+}
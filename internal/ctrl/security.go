@@ -0,0 +1,26 @@
+package ctrl
+
+import (
+	"context"
+
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	metrics "github.com/JMURv/golang-clean-template/internal/observability/metrics/prometheus"
+	"go.uber.org/zap"
+)
+
+// securityEvent records e for auditing. Failures are logged and never
+// surfaced, since the event is a side effect of an already decided outcome.
+func (c *Controller) securityEvent(ctx context.Context, e *md.SecurityEvent) {
+	const op = "security.securityEvent.ctrl"
+
+	metrics.SecurityEvents.WithLabelValues(e.Kind).Inc()
+	if err := c.repo.CreateSecurityEvent(ctx, e); err != nil {
+		zap.L().Error(
+			"failed to record security event",
+			zap.String("op", op),
+			zap.String("userID", e.UserID.String()),
+			zap.String("kind", e.Kind),
+			zap.Error(err),
+		)
+	}
+}
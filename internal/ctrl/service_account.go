@@ -0,0 +1,214 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type serviceAccountRepo interface {
+	CreateServiceAccount(ctx context.Context, sa *md.ServiceAccount) error
+	GetServiceAccount(ctx context.Context, id uuid.UUID) (*md.ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context) ([]md.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, id uuid.UUID) error
+	CreateAPIKey(ctx context.Context, k *md.APIKey) error
+	ListAPIKeys(ctx context.Context, saID uuid.UUID) ([]md.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*md.APIKey, error)
+	DeleteAPIKey(ctx context.Context, saID, id uuid.UUID) error
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+type serviceAccountCtrl interface {
+	CreateServiceAccount(ctx context.Context, req *dto.CreateServiceAccountRequest) (*md.ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context) ([]md.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, id uuid.UUID) error
+	CreateAPIKey(
+		ctx context.Context,
+		caller jwt.Claims,
+		saID uuid.UUID,
+		req *dto.CreateAPIKeyRequest,
+	) (*dto.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, saID uuid.UUID) ([]md.APIKey, error)
+	DeleteAPIKey(ctx context.Context, saID, id uuid.UUID) error
+	AuthenticateAPIKey(ctx context.Context, key string) (jwt.Claims, error)
+}
+
+func (c *Controller) CreateServiceAccount(
+	ctx context.Context,
+	req *dto.CreateServiceAccountRequest,
+) (*md.ServiceAccount, error) {
+	const op = "serviceAccount.CreateServiceAccount.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	sa := &md.ServiceAccount{Name: req.Name, Description: req.Description}
+	if err := c.repo.CreateServiceAccount(ctx, sa); err != nil {
+		if errors.Is(err, repo.ErrAlreadyExists) {
+			return nil, ErrAlreadyExists
+		}
+
+		return nil, err
+	}
+
+	return sa, nil
+}
+
+func (c *Controller) ListServiceAccounts(ctx context.Context) ([]md.ServiceAccount, error) {
+	const op = "serviceAccount.ListServiceAccounts.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return c.repo.ListServiceAccounts(ctx)
+}
+
+// DeleteServiceAccount removes the service account with its API keys.
+func (c *Controller) DeleteServiceAccount(ctx context.Context, id uuid.UUID) error {
+	const op = "serviceAccount.DeleteServiceAccount.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := c.repo.DeleteServiceAccount(ctx, id)
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// CreateAPIKey issues a key of the service account saID. The key can't grant
+// more than caller holds, so managing service accounts doesn't let anyone
+// escalate their own permissions.
+func (c *Controller) CreateAPIKey(
+	ctx context.Context,
+	caller jwt.Claims,
+	saID uuid.UUID,
+	req *dto.CreateAPIKeyRequest,
+) (*dto.CreateAPIKeyResponse, error) {
+	const op = "serviceAccount.CreateAPIKey.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	scopes := oauth.ParseScope(strings.Join(req.Scopes, " "))
+	for _, s := range scopes {
+		if !caller.HasPermission(s) {
+			zap.L().Debug(
+				"api key scope not held by caller",
+				zap.String("op", op),
+				zap.String("uid", caller.UID.String()),
+				zap.String("scope", s),
+			)
+			return nil, auth.ErrPermissionDenied
+		}
+	}
+
+	if _, err := c.repo.GetServiceAccount(ctx, saID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to generate api key", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	k := &md.APIKey{
+		ServiceAccountID: saID,
+		Name:             req.Name,
+		Prefix:           prefix,
+		KeyHash:          c.hashToken(key),
+		Scopes:           oauth.JoinScope(scopes),
+		ExpiresAt:        req.ExpiresAt,
+	}
+	if err = c.repo.CreateAPIKey(ctx, k); err != nil {
+		if errors.Is(err, repo.ErrAlreadyExists) {
+			return nil, ErrAlreadyExists
+		}
+
+		return nil, err
+	}
+
+	return &dto.CreateAPIKeyResponse{APIKey: k, Key: key}, nil
+}
+
+func (c *Controller) ListAPIKeys(ctx context.Context, saID uuid.UUID) ([]md.APIKey, error) {
+	const op = "serviceAccount.ListAPIKeys.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return c.repo.ListAPIKeys(ctx, saID)
+}
+
+// DeleteAPIKey revokes the key. Requests using it are rejected right away.
+func (c *Controller) DeleteAPIKey(ctx context.Context, saID, id uuid.UUID) error {
+	const op = "serviceAccount.DeleteAPIKey.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := c.repo.DeleteAPIKey(ctx, saID, id)
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// AuthenticateAPIKey resolves key to claims of its service account. The
+// claims carry the scopes of the key as permissions and no token ID, so
+// they pass the same checks as an access token of a user.
+func (c *Controller) AuthenticateAPIKey(ctx context.Context, key string) (jwt.Claims, error) {
+	const op = "serviceAccount.AuthenticateAPIKey.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	k, err := c.repo.GetAPIKeyByHash(ctx, c.hashToken(key))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return jwt.Claims{}, auth.ErrInvalidToken
+		}
+
+		return jwt.Claims{}, err
+	}
+
+	if k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt) {
+		zap.L().Debug("api key expired", zap.String("op", op), zap.String("prefix", k.Prefix))
+		return jwt.Claims{}, auth.ErrInvalidToken
+	}
+
+	if err = c.repo.TouchAPIKey(ctx, k.ID); err != nil {
+		zap.L().Warn("failed to record api key use", zap.String("op", op), zap.Error(err))
+	}
+
+	return jwt.Claims{
+		UID:         k.ServiceAccountID,
+		Type:        jwt.TypeAccess,
+		Permissions: oauth.ParseScope(k.Scopes),
+	}, nil
+}
@@ -0,0 +1,182 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestController_CreateAPIKey(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	saID := uuid.New()
+	caller := jwt.Claims{UID: uuid.New(), Permissions: []string{auth.PermServiceAccounts, auth.PermUsersList}}
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		req         *dto.CreateAPIKeyRequest
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			req:  &dto.CreateAPIKeyRequest{Name: "deploy", Scopes: []string{auth.PermUsersList, auth.PermUsersList}},
+			mock: func() {
+				mockRepo.EXPECT().GetServiceAccount(gomock.Any(), saID).Return(&models.ServiceAccount{ID: saID}, nil)
+				mockRepo.EXPECT().
+					CreateAPIKey(gomock.Any(), gomock.Any()).
+					DoAndReturn(
+						func(_ context.Context, k *models.APIKey) error {
+							assert.Equal(t, saID, k.ServiceAccountID)
+							assert.Equal(t, auth.PermUsersList, k.Scopes)
+							assert.True(t, auth.IsAPIKey(k.Prefix))
+							return nil
+						},
+					)
+			},
+		},
+		{
+			name:        "ScopeNotHeld",
+			req:         &dto.CreateAPIKeyRequest{Name: "deploy", Scopes: []string{auth.PermUsersDelete}},
+			mock:        func() {},
+			expectedErr: auth.ErrPermissionDenied,
+		},
+		{
+			name:        "ExpiresInPast",
+			req:         &dto.CreateAPIKeyRequest{Name: "deploy", ExpiresAt: &past},
+			mock:        func() {},
+			expectedErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "UnknownServiceAccount",
+			req:  &dto.CreateAPIKeyRequest{Name: "deploy"},
+			mock: func() {
+				mockRepo.EXPECT().GetServiceAccount(gomock.Any(), saID).Return(nil, repo.ErrNotFound)
+			},
+			expectedErr: ErrNotFound,
+		},
+		{
+			name: "DuplicateName",
+			req:  &dto.CreateAPIKeyRequest{Name: "deploy"},
+			mock: func() {
+				mockRepo.EXPECT().GetServiceAccount(gomock.Any(), saID).Return(&models.ServiceAccount{ID: saID}, nil)
+				mockRepo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(repo.ErrAlreadyExists)
+			},
+			expectedErr: ErrAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mock()
+
+				res, err := ctrl.CreateAPIKey(ctx, caller, saID, tt.req)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.True(t, auth.IsAPIKey(res.Key))
+				assert.Equal(t, ctrl.hashToken(res.Key), res.APIKey.KeyHash)
+			},
+		)
+	}
+}
+
+func TestController_AuthenticateAPIKey(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	const key = "sk_0123abcd_secret"
+	past := time.Now().Add(-time.Minute)
+	k := &models.APIKey{ID: uuid.New(), ServiceAccountID: uuid.New(), Scopes: "users:list roles:read"}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    jwt.Claims
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mockRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), ctrl.hashToken(key)).Return(k, nil)
+				mockRepo.EXPECT().TouchAPIKey(gomock.Any(), k.ID).Return(nil)
+			},
+			expected: jwt.Claims{
+				UID:         k.ServiceAccountID,
+				Type:        jwt.TypeAccess,
+				Permissions: []string{auth.PermUsersList, auth.PermRolesRead},
+			},
+		},
+		{
+			name: "TouchFails",
+			mock: func() {
+				mockRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), ctrl.hashToken(key)).Return(k, nil)
+				mockRepo.EXPECT().TouchAPIKey(gomock.Any(), k.ID).Return(errors.New("db"))
+			},
+			expected: jwt.Claims{
+				UID:         k.ServiceAccountID,
+				Type:        jwt.TypeAccess,
+				Permissions: []string{auth.PermUsersList, auth.PermRolesRead},
+			},
+		},
+		{
+			name: "Expired",
+			mock: func() {
+				expired := *k
+				expired.ExpiresAt = &past
+				mockRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), ctrl.hashToken(key)).Return(&expired, nil)
+			},
+			expectedErr: auth.ErrInvalidToken,
+		},
+		{
+			name: "Unknown",
+			mock: func() {
+				mockRepo.EXPECT().GetAPIKeyByHash(gomock.Any(), ctrl.hashToken(key)).Return(nil, repo.ErrNotFound)
+			},
+			expectedErr: auth.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mock()
+
+				res, err := ctrl.AuthenticateAPIKey(ctx, key)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			},
+		)
+	}
+}
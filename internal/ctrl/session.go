@@ -0,0 +1,51 @@
+package ctrl
+
+import (
+	"time"
+
+	md "github.com/JMURv/golang-clean-template/internal/models"
+)
+
+// sessionPolicy returns the idle and absolute timeouts of a session. A zero
+// timeout is not enforced.
+func (c *Controller) sessionPolicy(remember bool) (idle, absolute time.Duration) {
+	conf := c.conf.Auth.Session
+	if remember {
+		return conf.RememberIdleTimeout, conf.RememberAbsoluteTimeout
+	}
+
+	return conf.IdleTimeout, conf.AbsoluteTimeout
+}
+
+// sessionExpiry returns when a refresh token issued now for a session started
+// at start expires: after the idle timeout, but never past the absolute
+// lifetime of the session or the lifetime of the refresh token itself.
+func (c *Controller) sessionExpiry(start time.Time, remember bool) time.Time {
+	exp := c.au.GetRefreshTime()
+	idle, absolute := c.sessionPolicy(remember)
+	if idle > 0 {
+		if t := time.Now().Add(idle); t.Before(exp) {
+			exp = t
+		}
+	}
+	if absolute > 0 {
+		if t := start.Add(absolute); t.Before(exp) {
+			exp = t
+		}
+	}
+
+	return exp
+}
+
+// sessionExpired reports whether the session of token was idle or alive for
+// longer than the current policy allows. Expiry is already stored on the
+// token, this catches sessions issued before the policy was tightened.
+func (c *Controller) sessionExpired(token *md.RefreshToken) bool {
+	now := time.Now()
+	idle, absolute := c.sessionPolicy(token.Remember)
+	if idle > 0 && now.Sub(token.LastUsedAt) > idle {
+		return true
+	}
+
+	return absolute > 0 && now.Sub(token.SessionStartedAt) > absolute
+}
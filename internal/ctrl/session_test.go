@@ -0,0 +1,105 @@
+package ctrl
+
+import (
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestController_SessionExpiry(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	conf := config.Config{}
+	conf.Auth.Session.IdleTimeout = time.Hour
+	conf.Auth.Session.AbsoluteTimeout = 8 * time.Hour
+	conf.Auth.Session.RememberIdleTimeout = 24 * time.Hour
+	ctrl := New(conf, mockAuth, nil, nil, nil, nil)
+
+	now := time.Now()
+	refreshTime := now.Add(config.RefreshTokenDuration)
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		remember bool
+		expected time.Duration
+	}{
+		{
+			name:     "Idle",
+			start:    now,
+			expected: time.Hour,
+		},
+		{
+			name:     "CappedByAbsolute",
+			start:    now.Add(-7*time.Hour - 30*time.Minute),
+			expected: 30 * time.Minute,
+		},
+		{
+			name:     "Remember",
+			start:    now.Add(-30 * 24 * time.Hour),
+			remember: true,
+			expected: 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuth.EXPECT().GetRefreshTime().Return(refreshTime)
+
+			exp := ctrl.sessionExpiry(tt.start, tt.remember)
+			assert.WithinDuration(t, now.Add(tt.expected), exp, time.Second)
+		})
+	}
+
+	t.Run("CappedByRefreshTime", func(t *testing.T) {
+		mockAuth.EXPECT().GetRefreshTime().Return(now.Add(time.Minute))
+		assert.Equal(t, now.Add(time.Minute), ctrl.sessionExpiry(now, false))
+	})
+}
+
+func TestController_SessionExpired(t *testing.T) {
+	conf := config.Config{}
+	conf.Auth.Session.IdleTimeout = time.Hour
+	conf.Auth.Session.AbsoluteTimeout = 8 * time.Hour
+	ctrl := New(conf, nil, nil, nil, nil, nil)
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		token    *models.RefreshToken
+		expected bool
+	}{
+		{
+			name:     "Active",
+			token:    &models.RefreshToken{SessionStartedAt: now.Add(-time.Hour), LastUsedAt: now.Add(-time.Minute)},
+			expected: false,
+		},
+		{
+			name:     "Idle",
+			token:    &models.RefreshToken{SessionStartedAt: now.Add(-2 * time.Hour), LastUsedAt: now.Add(-2 * time.Hour)},
+			expected: true,
+		},
+		{
+			name:     "Absolute",
+			token:    &models.RefreshToken{SessionStartedAt: now.Add(-9 * time.Hour), LastUsedAt: now.Add(-time.Minute)},
+			expected: true,
+		},
+		{
+			name:     "RememberIsUnlimited",
+			token:    &models.RefreshToken{Remember: true, SessionStartedAt: now.Add(-9 * time.Hour), LastUsedAt: now.Add(-2 * time.Hour)},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ctrl.sessionExpired(tt.token))
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type tokenCtrl interface {
+	Introspect(ctx context.Context, req *dto.IntrospectRequest) (*dto.IntrospectionResponse, error)
+	IntrospectToken(ctx context.Context, token string) (*dto.IntrospectionResponse, error)
+	RevokeClientToken(ctx context.Context, req *dto.RevokeTokenRequest) error
+	RevokeToken(ctx context.Context, caller jwt.Claims, token string) error
+}
+
+// Values of token_type in introspection responses.
+const (
+	tokenTypeAccess  = "access_token"
+	tokenTypeRefresh = "refresh_token"
+)
+
+// tokenInfo is what the server knows about a presented token. revoke is nil
+// for tokens that are already inactive.
+type tokenInfo struct {
+	res      *dto.IntrospectionResponse
+	userID   uuid.UUID
+	clientID string
+	revoke   func(ctx context.Context) error
+}
+
+// Introspect serves token introspection (RFC 7662) to confidential clients.
+func (c *Controller) Introspect(
+	ctx context.Context,
+	req *dto.IntrospectRequest,
+) (*dto.IntrospectionResponse, error) {
+	const op = "token.Introspect.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	client, err := c.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Public {
+		return nil, oauth.ErrInvalidClient.WithDescription("public clients can't introspect tokens")
+	}
+
+	return c.IntrospectToken(ctx, req.Token)
+}
+
+// IntrospectToken describes token. Access tokens are active until they
+// expire or are denylisted, refresh tokens while their stored state allows a
+// refresh. Unknown tokens are reported inactive.
+func (c *Controller) IntrospectToken(ctx context.Context, token string) (*dto.IntrospectionResponse, error) {
+	const op = "token.IntrospectToken.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	info, err := c.inspectToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.res, nil
+}
+
+// RevokeClientToken serves token revocation (RFC 7009). A client can only
+// revoke the tokens issued to it; unknown and inactive tokens are ignored.
+func (c *Controller) RevokeClientToken(ctx context.Context, req *dto.RevokeTokenRequest) error {
+	const op = "token.RevokeClientToken.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	client, err := c.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	info, err := c.inspectToken(ctx, req.Token)
+	if err != nil {
+		return err
+	}
+
+	if info.revoke == nil {
+		return nil
+	}
+
+	if info.clientID != client.ID.String() {
+		zap.L().Info(
+			"token was issued to another client",
+			zap.String("op", op),
+			zap.String("clientID", client.ID.String()),
+		)
+		return oauth.ErrInvalidRequest.WithDescription("token was issued to another client")
+	}
+
+	return info.revoke(ctx)
+}
+
+// RevokeToken revokes token on behalf of caller, who must own it or hold
+// auth.PermTokensRevoke. Unknown and inactive tokens are ignored.
+func (c *Controller) RevokeToken(ctx context.Context, caller jwt.Claims, token string) error {
+	const op = "token.RevokeToken.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	info, err := c.inspectToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if info.revoke == nil {
+		return nil
+	}
+
+	if (info.userID == uuid.Nil || info.userID != caller.UID) && !caller.HasPermission(auth.PermTokensRevoke) {
+		return auth.ErrPermissionDenied
+	}
+
+	return info.revoke(ctx)
+}
+
+// inspectToken looks token up by its shape: JWTs are access or session
+// refresh tokens, anything else is an opaque OAuth refresh token.
+func (c *Controller) inspectToken(ctx context.Context, token string) (*tokenInfo, error) {
+	if token == "" {
+		return nil, oauth.ErrInvalidRequest.WithDescription("token is required")
+	}
+
+	if strings.Count(token, ".") == 2 {
+		return c.inspectJWT(ctx, token)
+	}
+
+	return c.inspectOAuthToken(ctx, token)
+}
+
+func (c *Controller) inspectJWT(ctx context.Context, token string) (*tokenInfo, error) {
+	inactive := &tokenInfo{res: &dto.IntrospectionResponse{}}
+
+	claims, err := c.au.ParseClaims(ctx, token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	res := &dto.IntrospectionResponse{
+		Active:   true,
+		Scope:    claims.Scope,
+		ClientID: claims.ClientID,
+		Sub:      claims.Subject,
+		Iss:      claims.Issuer,
+		Jti:      claims.ID,
+		DeviceID: claims.DeviceID,
+	}
+	if res.Sub == "" {
+		res.Sub = claims.UID.String()
+	}
+	if claims.ExpiresAt != nil {
+		res.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		res.Iat = claims.IssuedAt.Unix()
+	}
+
+	switch claims.Type {
+	case jwt.TypeAccess:
+		if c.au.CheckRevoked(ctx, claims) != nil {
+			return inactive, nil
+		}
+
+		res.TokenType = tokenTypeAccess
+		return &tokenInfo{
+			res:      res,
+			userID:   claims.UID,
+			clientID: claims.ClientID,
+			revoke: func(ctx context.Context) error {
+				c.au.RevokeToken(ctx, claims)
+				return nil
+			},
+		}, nil
+	case jwt.TypeRefresh:
+		t, err := c.repo.GetToken(ctx, claims.UID, c.hashToken(token))
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return inactive, nil
+			}
+
+			return nil, err
+		}
+
+		if t.Revoked || t.Rotated || time.Now().After(t.ExpiresAt) || c.sessionExpired(t) {
+			return inactive, nil
+		}
+
+		res.TokenType = tokenTypeRefresh
+		res.DeviceID = t.DeviceID
+		return &tokenInfo{
+			res:    res,
+			userID: claims.UID,
+			revoke: func(ctx context.Context) error {
+				return c.revokeSession(ctx, t)
+			},
+		}, nil
+	default:
+		return inactive, nil
+	}
+}
+
+func (c *Controller) inspectOAuthToken(ctx context.Context, token string) (*tokenInfo, error) {
+	inactive := &tokenInfo{res: &dto.IntrospectionResponse{}}
+
+	t, err := c.repo.GetOAuthToken(ctx, c.hashToken(token))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return inactive, nil
+		}
+
+		return nil, err
+	}
+
+	if t.Revoked || time.Now().After(t.ExpiresAt) {
+		return inactive, nil
+	}
+
+	return &tokenInfo{
+		res: &dto.IntrospectionResponse{
+			Active:    true,
+			Scope:     t.Scope,
+			ClientID:  t.ClientID.String(),
+			TokenType: tokenTypeRefresh,
+			Exp:       t.ExpiresAt.Unix(),
+			Iat:       t.CreatedAt.Unix(),
+			Sub:       t.UserID.String(),
+		},
+		userID:   t.UserID,
+		clientID: t.ClientID.String(),
+		revoke: func(ctx context.Context) error {
+			return c.repo.RevokeOAuthToken(ctx, t.ID)
+		},
+	}, nil
+}
+
+// revokeSession ends the session t belongs to, together with the access
+// tokens of its device.
+func (c *Controller) revokeSession(ctx context.Context, t *md.RefreshToken) error {
+	if err := c.repo.RevokeByDevice(ctx, t.UserID, t.DeviceID); err != nil {
+		return err
+	}
+
+	c.au.RevokeDeviceTokens(ctx, t.UserID, t.DeviceID)
+	return nil
+}
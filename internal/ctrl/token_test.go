@@ -0,0 +1,321 @@
+package ctrl
+
+import (
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+const testJWT = "header.payload.signature"
+
+func newAccessClaims(uid uuid.UUID, clientID string) jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		UID:      uid,
+		Type:     jwt.TypeAccess,
+		ClientID: clientID,
+		Scope:    "profile",
+		RegisteredClaims: gojwt.RegisteredClaims{
+			ID:        "jti",
+			Issuer:    "iss",
+			IssuedAt:  gojwt.NewNumericDate(now),
+			ExpiresAt: gojwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	}
+}
+
+func TestController_Introspect(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	client := newOAuthClient(false)
+	client.SecretHash = ctrl.hashToken("secret")
+	claims := newAccessClaims(uid, client.ID.String())
+	refreshClaims := jwt.Claims{UID: uid, Type: jwt.TypeRefresh}
+	oauthToken := &models.OAuthToken{
+		ID:        1,
+		ClientID:  client.ID,
+		UserID:    uid,
+		Scope:     "profile",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name        string
+		secret      string
+		token       string
+		mock        func()
+		expected    *dto.IntrospectionResponse
+		expectedErr error
+	}{
+		{
+			name:        "PublicClient",
+			token:       testJWT,
+			mock:        func() { mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(newOAuthClient(true), nil) },
+			expectedErr: oauth.ErrInvalidClient,
+		},
+		{
+			name:   "ActiveAccessToken",
+			secret: "secret",
+			token:  testJWT,
+			mock: func() {
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockAuth.EXPECT().ParseClaims(gomock.Any(), testJWT).Return(claims, nil)
+				mockAuth.EXPECT().CheckRevoked(gomock.Any(), claims).Return(nil)
+			},
+			expected: &dto.IntrospectionResponse{
+				Active:    true,
+				Scope:     "profile",
+				ClientID:  client.ID.String(),
+				TokenType: "access_token",
+				Exp:       claims.ExpiresAt.Unix(),
+				Iat:       claims.IssuedAt.Unix(),
+				Sub:       uid.String(),
+				Iss:       "iss",
+				Jti:       "jti",
+			},
+		},
+		{
+			name:   "DenylistedAccessToken",
+			secret: "secret",
+			token:  testJWT,
+			mock: func() {
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockAuth.EXPECT().ParseClaims(gomock.Any(), testJWT).Return(claims, nil)
+				mockAuth.EXPECT().CheckRevoked(gomock.Any(), claims).Return(auth.ErrTokenRevoked)
+			},
+			expected: &dto.IntrospectionResponse{},
+		},
+		{
+			name:   "RotatedRefreshToken",
+			secret: "secret",
+			token:  testJWT,
+			mock: func() {
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockAuth.EXPECT().ParseClaims(gomock.Any(), testJWT).Return(refreshClaims, nil)
+				mockRepo.EXPECT().
+					GetToken(gomock.Any(), uid, ctrl.hashToken(testJWT)).
+					Return(&models.RefreshToken{Rotated: true, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+			},
+			expected: &dto.IntrospectionResponse{},
+		},
+		{
+			name:   "ActiveOAuthRefreshToken",
+			secret: "secret",
+			token:  "opaque",
+			mock: func() {
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockRepo.EXPECT().GetOAuthToken(gomock.Any(), ctrl.hashToken("opaque")).Return(oauthToken, nil)
+			},
+			expected: &dto.IntrospectionResponse{
+				Active:    true,
+				Scope:     "profile",
+				ClientID:  client.ID.String(),
+				TokenType: "refresh_token",
+				Exp:       oauthToken.ExpiresAt.Unix(),
+				Iat:       oauthToken.CreatedAt.Unix(),
+				Sub:       uid.String(),
+			},
+		},
+		{
+			name:   "UnknownToken",
+			secret: "secret",
+			token:  "opaque",
+			mock: func() {
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				mockRepo.EXPECT().GetOAuthToken(gomock.Any(), ctrl.hashToken("opaque")).Return(nil, repo.ErrNotFound)
+			},
+			expected: &dto.IntrospectionResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.mock()
+
+				res, err := ctrl.Introspect(
+					ctx, &dto.IntrospectRequest{
+						Token:        tt.token,
+						ClientID:     client.ID.String(),
+						ClientSecret: tt.secret,
+					},
+				)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			},
+		)
+	}
+}
+
+func TestController_RevokeClientToken(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	client := newOAuthClient(true)
+	claims := newAccessClaims(uid, client.ID.String())
+
+	tests := []struct {
+		name        string
+		token       string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name:  "AccessToken",
+			token: testJWT,
+			mock: func() {
+				mockAuth.EXPECT().ParseClaims(gomock.Any(), testJWT).Return(claims, nil)
+				mockAuth.EXPECT().CheckRevoked(gomock.Any(), claims).Return(nil)
+				mockAuth.EXPECT().RevokeToken(gomock.Any(), claims)
+			},
+		},
+		{
+			name:  "AccessTokenOfAnotherClient",
+			token: testJWT,
+			mock: func() {
+				other := newAccessClaims(uid, uuid.NewString())
+				mockAuth.EXPECT().ParseClaims(gomock.Any(), testJWT).Return(other, nil)
+				mockAuth.EXPECT().CheckRevoked(gomock.Any(), other).Return(nil)
+			},
+			expectedErr: oauth.ErrInvalidRequest,
+		},
+		{
+			name:  "SessionRefreshToken",
+			token: testJWT,
+			mock: func() {
+				mockAuth.EXPECT().ParseClaims(gomock.Any(), testJWT).Return(jwt.Claims{UID: uid, Type: jwt.TypeRefresh}, nil)
+				mockRepo.EXPECT().
+					GetToken(gomock.Any(), uid, ctrl.hashToken(testJWT)).
+					Return(&models.RefreshToken{UserID: uid, DeviceID: "device", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+			},
+			expectedErr: oauth.ErrInvalidRequest,
+		},
+		{
+			name:  "OAuthRefreshToken",
+			token: "opaque",
+			mock: func() {
+				mockRepo.EXPECT().
+					GetOAuthToken(gomock.Any(), ctrl.hashToken("opaque")).
+					Return(&models.OAuthToken{ID: 1, ClientID: client.ID, UserID: uid, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				mockRepo.EXPECT().RevokeOAuthToken(gomock.Any(), uint64(1)).Return(nil)
+			},
+		},
+		{
+			name:  "RevokedToken",
+			token: "opaque",
+			mock: func() {
+				mockRepo.EXPECT().
+					GetOAuthToken(gomock.Any(), ctrl.hashToken("opaque")).
+					Return(&models.OAuthToken{ID: 1, ClientID: client.ID, Revoked: true}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockRepo.EXPECT().GetOAuthClient(gomock.Any(), client.ID).Return(client, nil)
+				tt.mock()
+
+				err := ctrl.RevokeClientToken(ctx, &dto.RevokeTokenRequest{Token: tt.token, ClientID: client.ID.String()})
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.NoError(t, err)
+				}
+			},
+		)
+	}
+}
+
+func TestController_RevokeToken(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	session := &models.RefreshToken{UserID: uid, DeviceID: "device", ExpiresAt: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name        string
+		caller      jwt.Claims
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name:   "Owner",
+			caller: jwt.Claims{UID: uid},
+			mock: func() {
+				mockRepo.EXPECT().RevokeByDevice(gomock.Any(), uid, "device").Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), uid, "device")
+			},
+		},
+		{
+			name:        "OtherUser",
+			caller:      jwt.Claims{UID: uuid.New()},
+			mock:        func() {},
+			expectedErr: auth.ErrPermissionDenied,
+		},
+		{
+			name:   "Admin",
+			caller: jwt.Claims{UID: uuid.New(), Permissions: []string{auth.PermTokensRevoke}},
+			mock: func() {
+				mockRepo.EXPECT().RevokeByDevice(gomock.Any(), uid, "device").Return(nil)
+				mockAuth.EXPECT().RevokeDeviceTokens(gomock.Any(), uid, "device")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockAuth.EXPECT().ParseClaims(gomock.Any(), testJWT).Return(jwt.Claims{UID: uid, Type: jwt.TypeRefresh}, nil)
+				mockRepo.EXPECT().GetToken(gomock.Any(), uid, ctrl.hashToken(testJWT)).Return(session, nil)
+				tt.mock()
+
+				err := ctrl.RevokeToken(ctx, tt.caller, testJWT)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.NoError(t, err)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,446 @@
+package ctrl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/totp"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type totpCtrl interface {
+	EnrollTOTP(ctx context.Context, uid uuid.UUID) (*dto.TOTPEnrollResponse, error)
+	ConfirmTOTP(ctx context.Context, uid uuid.UUID, req *dto.TOTPCodeRequest) (*dto.RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, uid uuid.UUID, req *dto.SecondFactorRequest) error
+	VerifyTwoFactor(
+		ctx context.Context,
+		d *dto.DeviceRequest,
+		req *dto.VerifyTwoFactorRequest,
+	) (*dto.TokenPair, error)
+}
+
+type totpRepo interface {
+	GetTOTP(ctx context.Context, uid uuid.UUID) (*md.TOTP, error)
+	CreateTOTP(ctx context.Context, uid uuid.UUID, secret string) error
+	ConfirmTOTP(ctx context.Context, uid uuid.UUID, step int64, hashedCodes []string) error
+	UseTOTPStep(ctx context.Context, uid uuid.UUID, step int64) error
+	UseRecoveryCode(ctx context.Context, uid uuid.UUID, hashedCode string) error
+	DeleteTOTP(ctx context.Context, uid uuid.UUID) error
+}
+
+const (
+	twoFactorCacheKey    = "2fa-challenge:%v"
+	totpFailuresCacheKey = "totp-failures:%v"
+)
+
+const (
+	challengeSize      = 32
+	recoveryCodeLength = 8
+	recoveryCodeChars  = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// twoFactorChallenge is the cached state of a login that passed the first
+// factor. It is bound to the device that started the login.
+type twoFactorChallenge struct {
+	UserID    uuid.UUID `json:"userId"`
+	DeviceID  string    `json:"deviceId"`
+	Remember  bool      `json:"remember"`
+	Attempts  int       `json:"attempts"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// EnrollTOTP generates a new pending secret. It replaces an unconfirmed one
+// and returns ErrAlreadyExists if 2FA is already enabled.
+func (c *Controller) EnrollTOTP(ctx context.Context, uid uuid.UUID) (*dto.TOTPEnrollResponse, error) {
+	const op = "totp.EnrollTOTP.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	u, err := c.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to generate totp secret", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	encrypted, err := auth.Encrypt(c.encryptionKey(), secret)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to encrypt totp secret", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	if err = c.repo.CreateTOTP(ctx, uid, encrypted); err != nil {
+		if errors.Is(err, repo.ErrAlreadyExists) {
+			return nil, ErrAlreadyExists
+		}
+
+		return nil, err
+	}
+
+	return &dto.TOTPEnrollResponse{
+		Secret: secret,
+		URI:    totp.URI(c.conf.ServiceName, u.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables 2FA once the first code from the authenticator app is
+// valid and returns the recovery codes. They are only shown this once.
+func (c *Controller) ConfirmTOTP(
+	ctx context.Context,
+	uid uuid.UUID,
+	req *dto.TOTPCodeRequest,
+) (*dto.RecoveryCodesResponse, error) {
+	const op = "totp.ConfirmTOTP.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	t, err := c.repo.GetTOTP(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if t.Confirmed {
+		return nil, ErrAlreadyExists
+	}
+
+	var step int64
+	err = c.limitTOTP(ctx, uid, func() error {
+		step, err = c.validateTOTP(t, req.Code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashed, err := c.generateRecoveryCodes()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to generate recovery codes", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	if err = c.repo.ConfirmTOTP(ctx, uid, step, hashed); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrCodeIsNotValid
+		}
+
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{Codes: codes}, nil
+}
+
+// DisableTOTP turns 2FA off after checking a code or a recovery code.
+func (c *Controller) DisableTOTP(ctx context.Context, uid uuid.UUID, req *dto.SecondFactorRequest) error {
+	const op = "totp.DisableTOTP.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	t, err := c.repo.GetTOTP(ctx, uid)
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if !t.Confirmed {
+		return ErrNotFound
+	}
+
+	if err = c.limitTOTP(ctx, uid, func() error { return c.checkSecondFactor(ctx, t, req) }); err != nil {
+		return err
+	}
+
+	if err = c.repo.DeleteTOTP(ctx, uid); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// VerifyTwoFactor completes a login started by Authenticate or
+// CheckLoginCode. A wrong code counts as an attempt, and the challenge is
+// dropped once config.MaxCodeAttempts is reached. Since every password login
+// gets a fresh challenge, codes are also limited per user by limitTOTP.
+func (c *Controller) VerifyTwoFactor(
+	ctx context.Context,
+	d *dto.DeviceRequest,
+	req *dto.VerifyTwoFactorRequest,
+) (*dto.TokenPair, error) {
+	const op = "totp.VerifyTwoFactor.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	key := fmt.Sprintf(twoFactorCacheKey, c.hashToken(req.Challenge))
+	stored := &twoFactorChallenge{}
+	if err := c.cache.GetToStruct(ctx, key, stored); err != nil {
+		return nil, ErrChallengeIsNotValid
+	}
+
+	if device := auth.GenerateDevice(d); device.ID != stored.DeviceID {
+		zap.L().Info(
+			"2fa challenge used from another device",
+			zap.String("op", op),
+			zap.String("userID", stored.UserID.String()),
+		)
+
+		return nil, ErrChallengeIsNotValid
+	}
+
+	t, err := c.repo.GetTOTP(ctx, stored.UserID)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	if err == nil && t.Confirmed {
+		err = c.limitTOTP(ctx, t.UserID, func() error { return c.checkSecondFactor(ctx, t, &req.SecondFactorRequest) })
+	} else {
+		err = ErrCodeIsNotValid
+	}
+
+	if errors.Is(err, ErrCodeIsNotValid) {
+		stored.Attempts++
+		ttl := time.Until(stored.ExpiresAt)
+		if stored.Attempts >= config.MaxCodeAttempts || ttl <= 0 {
+			zap.L().Debug(
+				"2fa attempts exhausted",
+				zap.String("op", op),
+				zap.String("userID", stored.UserID.String()),
+			)
+			c.cache.Delete(ctx, key)
+			return nil, err
+		}
+
+		if bytes, err := json.Marshal(stored); err == nil {
+			c.cache.Set(ctx, ttl, key, bytes)
+		}
+
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+
+	c.cache.Delete(ctx, key)
+
+	pair, err := c.GenPair(ctx, d, stored.UserID, stored.Remember)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pair, nil
+}
+
+// login finishes the first login step. Users with 2FA get a challenge
+// instead of a token pair.
+func (c *Controller) login(
+	ctx context.Context,
+	d *dto.DeviceRequest,
+	uid uuid.UUID,
+	remember bool,
+) (*dto.TokenPair, *dto.TwoFactorChallenge, error) {
+	t, err := c.repo.GetTOTP(ctx, uid)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	if err == nil && t.Confirmed {
+		challenge, err := c.issueChallenge(ctx, d, uid, remember)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, challenge, nil
+	}
+
+	pair, err := c.GenPair(ctx, d, uid, remember)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &pair, nil, nil
+}
+
+func (c *Controller) issueChallenge(
+	ctx context.Context,
+	d *dto.DeviceRequest,
+	uid uuid.UUID,
+	remember bool,
+) (*dto.TwoFactorChallenge, error) {
+	const op = "totp.issueChallenge.ctrl"
+
+	b := make([]byte, challengeSize)
+	if _, err := rand.Read(b); err != nil {
+		zap.L().Error("failed to generate challenge", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	stored := &twoFactorChallenge{
+		UserID:    uid,
+		DeviceID:  auth.GenerateDevice(d).ID,
+		Remember:  remember,
+		ExpiresAt: time.Now().Add(config.TwoFactorDuration),
+	}
+
+	bytes, err := json.Marshal(stored)
+	if err != nil {
+		zap.L().Error("failed to marshal challenge", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	c.cache.Set(ctx, config.TwoFactorDuration, fmt.Sprintf(twoFactorCacheKey, c.hashToken(token)), bytes)
+	return &dto.TwoFactorChallenge{
+		Challenge: token,
+		ExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// limitTOTP runs check unless uid made config.MaxTOTPFailures second factor
+// checks without a success within config.TOTPLockDuration, in which case
+// ErrTooManyRequests is returned. The counter is shared by enrollment,
+// disabling and every login challenge, and is cleared by a successful check.
+func (c *Controller) limitTOTP(ctx context.Context, uid uuid.UUID, check func() error) error {
+	const op = "totp.limitTOTP.ctrl"
+
+	key := fmt.Sprintf(totpFailuresCacheKey, uid)
+	n, err := c.cache.Incr(ctx, config.TOTPLockDuration, key)
+	if err != nil {
+		return err
+	}
+
+	if n > config.MaxTOTPFailures {
+		zap.L().Info(
+			"totp checks exhausted",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+		)
+
+		return ErrTooManyRequests
+	}
+
+	if err = check(); err != nil {
+		return err
+	}
+
+	c.cache.Delete(ctx, key)
+	return nil
+}
+
+// checkSecondFactor consumes a recovery code or a TOTP code. A TOTP code is
+// accepted once, so a code seen by an attacker can't be replayed.
+func (c *Controller) checkSecondFactor(ctx context.Context, t *md.TOTP, req *dto.SecondFactorRequest) error {
+	if req.RecoveryCode != "" {
+		err := c.repo.UseRecoveryCode(ctx, t.UserID, c.hashToken(normalizeRecoveryCode(req.RecoveryCode)))
+		if err != nil && errors.Is(err, repo.ErrNotFound) {
+			return ErrCodeIsNotValid
+		}
+
+		return err
+	}
+
+	step, err := c.validateTOTP(t, req.Code)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.UseTOTPStep(ctx, t.UserID, step)
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return ErrCodeIsNotValid
+	}
+
+	return err
+}
+
+func (c *Controller) validateTOTP(t *md.TOTP, code string) (int64, error) {
+	const op = "totp.validateTOTP.ctrl"
+
+	secret, err := auth.Decrypt(c.encryptionKey(), t.Secret)
+	if err != nil {
+		zap.L().Error(
+			"failed to decrypt totp secret",
+			zap.String("op", op),
+			zap.String("userID", t.UserID.String()),
+			zap.Error(err),
+		)
+
+		return 0, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), config.TOTPSkew)
+	if !ok || step <= t.LastStep {
+		return 0, ErrCodeIsNotValid
+	}
+
+	return step, nil
+}
+
+// generateRecoveryCodes returns the codes to show and their hashes to store.
+func (c *Controller) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, config.RecoveryCodesCount)
+	hashed := make([]string, 0, config.RecoveryCodesCount)
+	alphabet := big.NewInt(int64(len(recoveryCodeChars)))
+
+	for range config.RecoveryCodesCount {
+		b := make([]byte, recoveryCodeLength)
+		for i := range b {
+			n, err := rand.Int(rand.Reader, alphabet)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			b[i] = recoveryCodeChars[n.Int64()]
+		}
+
+		code := string(b[:recoveryCodeLength/2]) + "-" + string(b[recoveryCodeLength/2:])
+		codes = append(codes, code)
+		hashed = append(hashed, c.hashToken(normalizeRecoveryCode(code)))
+	}
+
+	return codes, hashed, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// encryptionKey is AUTH_ENCRYPTION_KEY, which protects TOTP secrets at rest.
+func (c *Controller) encryptionKey() []byte {
+	return []byte(c.conf.Auth.EncryptionKey)
+}
@@ -0,0 +1,352 @@
+package ctrl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/totp"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestTOTP(t *testing.T, uid uuid.UUID, confirmed bool) (*models.TOTP, string) {
+	t.Helper()
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	encrypted, err := auth.Encrypt(nil, secret)
+	require.NoError(t, err)
+
+	return &models.TOTP{UserID: uid, Secret: encrypted, Confirmed: confirmed}, secret
+}
+
+// currentCode returns the code for the current step along with the step, so
+// expectations don't race the step boundary.
+func currentCode(t *testing.T, secret string) (string, int64) {
+	t.Helper()
+
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	require.NoError(t, err)
+	return code, step
+}
+
+func TestController_EnrollTOTP(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{ServiceName: "sso"}, nil, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	user := &models.User{ID: uid, Email: "test@example.com"}
+
+	t.Run("Success", func(t *testing.T) {
+		var stored string
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+		mockRepo.EXPECT().
+			CreateTOTP(gomock.Any(), uid, gomock.Any()).
+			DoAndReturn(
+				func(_ context.Context, _ uuid.UUID, secret string) error {
+					stored = secret
+					return nil
+				},
+			)
+
+		res, err := ctrl.EnrollTOTP(ctx, uid)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(res.URI, "otpauth://totp/sso:test@example.com?"))
+		assert.NotEqual(t, res.Secret, stored)
+
+		plain, err := auth.Decrypt(nil, stored)
+		require.NoError(t, err)
+		assert.Equal(t, res.Secret, plain)
+	})
+
+	t.Run("AlreadyEnabled", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+		mockRepo.EXPECT().CreateTOTP(gomock.Any(), uid, gomock.Any()).Return(repo.ErrAlreadyExists)
+
+		_, err := ctrl.EnrollTOTP(ctx, uid)
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(nil, repo.ErrNotFound)
+
+		_, err := ctrl.EnrollTOTP(ctx, uid)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestController_ConfirmTOTP(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, mockCache, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	pending, secret := newTestTOTP(t, uid, false)
+	failures := fmt.Sprintf(totpFailuresCacheKey, uid)
+
+	t.Run("Success", func(t *testing.T) {
+		var hashed []string
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(pending, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(1), nil)
+		mockCache.EXPECT().Delete(gomock.Any(), failures).Return()
+		mockRepo.EXPECT().
+			ConfirmTOTP(gomock.Any(), uid, step, gomock.Any()).
+			DoAndReturn(
+				func(_ context.Context, _ uuid.UUID, _ int64, codes []string) error {
+					hashed = codes
+					return nil
+				},
+			)
+
+		res, err := ctrl.ConfirmTOTP(ctx, uid, &dto.TOTPCodeRequest{Code: code})
+		require.NoError(t, err)
+		require.Len(t, res.Codes, config.RecoveryCodesCount)
+		assert.Len(t, res.Codes[0], recoveryCodeLength+1)
+		assert.Equal(t, auth.HashToken(nil, normalizeRecoveryCode(res.Codes[0])), hashed[0])
+	})
+
+	t.Run("InvalidCode", func(t *testing.T) {
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(pending, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(1), nil)
+
+		_, err := ctrl.ConfirmTOTP(ctx, uid, &dto.TOTPCodeRequest{Code: "000000"})
+		assert.ErrorIs(t, err, ErrCodeIsNotValid)
+	})
+
+	t.Run("TooManyFailures", func(t *testing.T) {
+		code, _ := currentCode(t, secret)
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(pending, nil)
+		mockCache.EXPECT().
+			Incr(gomock.Any(), config.TOTPLockDuration, failures).
+			Return(int64(config.MaxTOTPFailures+1), nil)
+
+		_, err := ctrl.ConfirmTOTP(ctx, uid, &dto.TOTPCodeRequest{Code: code})
+		assert.ErrorIs(t, err, ErrTooManyRequests)
+	})
+
+	t.Run("AlreadyEnabled", func(t *testing.T) {
+		confirmed := *pending
+		confirmed.Confirmed = true
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(&confirmed, nil)
+
+		_, err := ctrl.ConfirmTOTP(ctx, uid, &dto.TOTPCodeRequest{Code: "000000"})
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+	})
+
+	t.Run("NotEnrolled", func(t *testing.T) {
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(nil, repo.ErrNotFound)
+
+		_, err := ctrl.ConfirmTOTP(ctx, uid, &dto.TOTPCodeRequest{Code: "000000"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestController_DisableTOTP(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, mockCache, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	enabled, secret := newTestTOTP(t, uid, true)
+	failures := fmt.Sprintf(totpFailuresCacheKey, uid)
+
+	t.Run("Code", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(1), nil)
+		mockCache.EXPECT().Delete(gomock.Any(), failures).Return()
+		mockRepo.EXPECT().UseTOTPStep(gomock.Any(), uid, step).Return(nil)
+		mockRepo.EXPECT().DeleteTOTP(gomock.Any(), uid).Return(nil)
+
+		err := ctrl.DisableTOTP(ctx, uid, &dto.SecondFactorRequest{Code: code})
+		assert.NoError(t, err)
+	})
+
+	t.Run("RecoveryCode", func(t *testing.T) {
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(2), nil)
+		mockCache.EXPECT().Delete(gomock.Any(), failures).Return()
+		mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), uid, auth.HashToken(nil, "abcd2345")).Return(nil)
+		mockRepo.EXPECT().DeleteTOTP(gomock.Any(), uid).Return(nil)
+
+		err := ctrl.DisableTOTP(ctx, uid, &dto.SecondFactorRequest{RecoveryCode: " ABCD-2345 "})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ReplayedCode", func(t *testing.T) {
+		code, step := currentCode(t, secret)
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(1), nil)
+		mockRepo.EXPECT().UseTOTPStep(gomock.Any(), uid, step).Return(repo.ErrNotFound)
+
+		err := ctrl.DisableTOTP(ctx, uid, &dto.SecondFactorRequest{Code: code})
+		assert.ErrorIs(t, err, ErrCodeIsNotValid)
+	})
+
+	t.Run("TooManyFailures", func(t *testing.T) {
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().
+			Incr(gomock.Any(), config.TOTPLockDuration, failures).
+			Return(int64(config.MaxTOTPFailures+1), nil)
+
+		err := ctrl.DisableTOTP(ctx, uid, &dto.SecondFactorRequest{RecoveryCode: "abcd-2345"})
+		assert.ErrorIs(t, err, ErrTooManyRequests)
+	})
+
+	t.Run("NotEnabled", func(t *testing.T) {
+		pending := *enabled
+		pending.Confirmed = false
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(&pending, nil)
+
+		err := ctrl.DisableTOTP(ctx, uid, &dto.SecondFactorRequest{Code: "000000"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestController_VerifyTwoFactor(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	enabled, secret := newTestTOTP(t, uid, true)
+	device := &dto.DeviceRequest{IP: "192.168.1.1", UA: "test-user-agent"}
+	key := fmt.Sprintf(twoFactorCacheKey, auth.HashToken(nil, "challenge"))
+	failures := fmt.Sprintf(totpFailuresCacheKey, uid)
+
+	storedChallenge := func(attempts int) func(context.Context, string, any) error {
+		return func(_ context.Context, _ string, dest any) error {
+			*dest.(*twoFactorChallenge) = twoFactorChallenge{
+				UserID:    uid,
+				DeviceID:  auth.GenerateDevice(device).ID,
+				Remember:  true,
+				Attempts:  attempts,
+				ExpiresAt: time.Now().Add(time.Minute),
+			}
+			return nil
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		refreshTime := time.Now().Add(time.Hour)
+		code, step := currentCode(t, secret)
+		mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).DoAndReturn(storedChallenge(0))
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(1), nil)
+		mockRepo.EXPECT().UseTOTPStep(gomock.Any(), uid, step).Return(nil)
+		mockCache.EXPECT().Delete(gomock.Any(), failures).Return()
+		mockCache.EXPECT().Delete(gomock.Any(), key).Return()
+		mockRepo.EXPECT().ListUserRoles(gomock.Any(), uid).Return(nil, nil)
+		mockAuth.EXPECT().GenPair(gomock.Any(), uid, gomock.Any(), gomock.Any()).Return("access", "refresh", nil)
+		mockRepo.EXPECT().ListDevices(gomock.Any(), uid).Return(nil, nil)
+		mockAuth.EXPECT().GetRefreshTime().Return(refreshTime)
+		mockRepo.EXPECT().
+			CreateToken(gomock.Any(), uid, auth.HashToken(nil, "refresh"), gomock.Any(), true, gomock.Any()).
+			Return(nil)
+
+		res, err := ctrl.VerifyTwoFactor(
+			ctx, device, &dto.VerifyTwoFactorRequest{
+				Challenge:           "challenge",
+				SecondFactorRequest: dto.SecondFactorRequest{Code: code},
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "access", res.Access)
+		assert.True(t, res.Remember)
+	})
+
+	t.Run("UnknownChallenge", func(t *testing.T) {
+		mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).Return(errors.New("not found"))
+
+		_, err := ctrl.VerifyTwoFactor(ctx, device, &dto.VerifyTwoFactorRequest{Challenge: "challenge"})
+		assert.ErrorIs(t, err, ErrChallengeIsNotValid)
+	})
+
+	t.Run("OtherDevice", func(t *testing.T) {
+		mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).DoAndReturn(storedChallenge(0))
+
+		other := &dto.DeviceRequest{IP: "10.0.0.1", UA: "other-user-agent"}
+		_, err := ctrl.VerifyTwoFactor(ctx, other, &dto.VerifyTwoFactorRequest{Challenge: "challenge"})
+		assert.ErrorIs(t, err, ErrChallengeIsNotValid)
+	})
+
+	t.Run("InvalidCodeCountsAttempt", func(t *testing.T) {
+		mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).DoAndReturn(storedChallenge(0))
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(1), nil)
+		mockCache.EXPECT().Set(gomock.Any(), gomock.Any(), key, gomock.Any()).Return()
+
+		_, err := ctrl.VerifyTwoFactor(
+			ctx, device, &dto.VerifyTwoFactorRequest{
+				Challenge:           "challenge",
+				SecondFactorRequest: dto.SecondFactorRequest{Code: "000000"},
+			},
+		)
+		assert.ErrorIs(t, err, ErrCodeIsNotValid)
+	})
+
+	t.Run("AttemptsExhausted", func(t *testing.T) {
+		mockCache.EXPECT().
+			GetToStruct(gomock.Any(), key, gomock.Any()).
+			DoAndReturn(storedChallenge(config.MaxCodeAttempts - 1))
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().Incr(gomock.Any(), config.TOTPLockDuration, failures).Return(int64(2), nil)
+		mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), uid, gomock.Any()).Return(repo.ErrNotFound)
+		mockCache.EXPECT().Delete(gomock.Any(), key).Return()
+
+		_, err := ctrl.VerifyTwoFactor(
+			ctx, device, &dto.VerifyTwoFactorRequest{
+				Challenge:           "challenge",
+				SecondFactorRequest: dto.SecondFactorRequest{RecoveryCode: "used-code"},
+			},
+		)
+		assert.ErrorIs(t, err, ErrCodeIsNotValid)
+	})
+	t.Run("FailuresSharedAcrossChallenges", func(t *testing.T) {
+		code, _ := currentCode(t, secret)
+		mockCache.EXPECT().GetToStruct(gomock.Any(), key, gomock.Any()).DoAndReturn(storedChallenge(0))
+		mockRepo.EXPECT().GetTOTP(gomock.Any(), uid).Return(enabled, nil)
+		mockCache.EXPECT().
+			Incr(gomock.Any(), config.TOTPLockDuration, failures).
+			Return(int64(config.MaxTOTPFailures+1), nil)
+
+		_, err := ctrl.VerifyTwoFactor(
+			ctx, device, &dto.VerifyTwoFactorRequest{
+				Challenge:           "challenge",
+				SecondFactorRequest: dto.SecondFactorRequest{Code: code},
+			},
+		)
+		assert.ErrorIs(t, err, ErrTooManyRequests)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
 )
 
 type userCtrl interface {
@@ -48,6 +49,8 @@ type userRepo interface {
 	GetUserByEmail(ctx context.Context, email string) (*md.User, error)
 	CreateUser(ctx context.Context, req *dto.CreateUserRequest) (uuid.UUID, error)
 	UpdateUser(ctx context.Context, id uuid.UUID, req *dto.UpdateUserRequest) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, hashed string) error
+	VerifyEmail(ctx context.Context, id uuid.UUID, email string) error
 	DeleteUser(ctx context.Context, userID uuid.UUID) error
 }
 
@@ -199,11 +202,23 @@ func (c *Controller) CreateUser(
 
 	go c.cache.InvalidateKeysByPattern(ctx, userPattern)
 
+	if err = c.sendVerificationEmail(ctx, id, u.Email); err != nil {
+		// The account is already created, the user can request another email.
+		zap.L().Error(
+			"failed to send verification email",
+			zap.String("op", op),
+			zap.String("userID", id.String()),
+			zap.Error(err),
+		)
+	}
+
 	return &dto.CreateUserResponse{
 		ID: id,
 	}, nil
 }
 
+// UpdateUser changes the profile of the user. A new email is unverified
+// until the user confirms the code sent to it.
 func (c *Controller) UpdateUser(
 	ctx context.Context,
 	id uuid.UUID,
@@ -215,6 +230,14 @@ func (c *Controller) UpdateUser(
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
+	old, err := c.repo.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+
 	if file != nil && len(file.File) > 0 {
 		url, err := c.s3.UploadFile(ctx, file)
 		if err != nil {
@@ -224,7 +247,7 @@ func (c *Controller) UpdateUser(
 		req.Avatar = url
 	}
 
-	err := c.repo.UpdateUser(ctx, id, req)
+	err = c.repo.UpdateUser(ctx, id, req)
 	if err != nil {
 		if errors.Is(err, repo.ErrNotFound) {
 			return ErrNotFound
@@ -234,6 +257,19 @@ func (c *Controller) UpdateUser(
 
 	c.cache.Delete(ctx, fmt.Sprintf(userCacheKey, id))
 	go c.cache.InvalidateKeysByPattern(ctx, userPattern)
+
+	if req.Email != old.Email {
+		if err = c.sendVerificationEmail(ctx, id, req.Email); err != nil {
+			// The email is already changed, the user can request another code.
+			zap.L().Error(
+				"failed to send verification email",
+				zap.String("op", op),
+				zap.String("userID", id.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
 	return nil
 }
 
@@ -251,6 +287,7 @@ func (c *Controller) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 		return err
 	}
 
+	c.au.RevokeUserTokens(ctx, userID)
 	c.cache.Delete(ctx, fmt.Sprintf(userCacheKey, userID))
 	go c.cache.InvalidateKeysByPattern(ctx, userPattern)
 	return nil
@@ -28,7 +28,7 @@ func TestController_IsUserExist(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testEmail := "test@example.com"
 	expectedResponse := &dto.ExistsUserResponse{Exists: true}
@@ -108,7 +108,7 @@ func TestController_ListUsers(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	page := 1
 	size := 10
@@ -216,7 +216,7 @@ func TestController_GetUserByID(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testUserID := uuid.New()
 	cacheKey := fmt.Sprintf(userCacheKey, testUserID)
@@ -331,7 +331,7 @@ func TestController_GetUserByEmail(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	testEmail := "test@example.com"
 	cacheKey := fmt.Sprintf(userCacheKey, testEmail)
@@ -444,9 +444,10 @@ func TestController_CreateUser(t *testing.T) {
 	mockRepo := mocks.NewMockAppRepo(ctrlMock)
 	mockCache := mocks.NewMockCacheService(ctrlMock)
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
+	mockSmtp := mocks.NewMockEmailService(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, mockSmtp)
 
 	// Test data
 	testUserID := uuid.New()
@@ -495,6 +496,18 @@ func TestController_CreateUser(t *testing.T) {
 
 				mockCache.EXPECT().
 					InvalidateKeysByPattern(gomock.Any(), userPattern).AnyTimes().Return()
+
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+
+				mockCache.EXPECT().
+					Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return()
+
+				mockSmtp.EXPECT().
+					SendVerificationEmail(gomock.Any(), gomock.Any(), testUserID, baseRequest.Email).
+					Return(nil)
 			},
 			request: baseRequest,
 			file:    &s3.UploadFileRequest{},
@@ -520,6 +533,18 @@ func TestController_CreateUser(t *testing.T) {
 
 				mockCache.EXPECT().
 					InvalidateKeysByPattern(gomock.Any(), userPattern).AnyTimes().Return()
+
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+
+				mockCache.EXPECT().
+					Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return()
+
+				mockSmtp.EXPECT().
+					SendVerificationEmail(gomock.Any(), gomock.Any(), testUserID, baseRequest.Email).
+					Return(nil)
 			},
 			request: baseRequest,
 			file:    testFile,
@@ -528,6 +553,39 @@ func TestController_CreateUser(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "VerificationEmailError",
+			setup: func() {
+				mockAuth.EXPECT().
+					Hash(gomock.Any(), gomock.Any()).
+					Return(testHash, nil)
+
+				mockRepo.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Return(testUserID, nil)
+
+				mockCache.EXPECT().
+					InvalidateKeysByPattern(gomock.Any(), userPattern).AnyTimes().Return()
+
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+
+				mockCache.EXPECT().
+					Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return()
+
+				mockSmtp.EXPECT().
+					SendVerificationEmail(gomock.Any(), gomock.Any(), testUserID, baseRequest.Email).
+					Return(errors.New("smtp error"))
+			},
+			request: baseRequest,
+			file:    &s3.UploadFileRequest{},
+			expected: &dto.CreateUserResponse{
+				ID: testUserID,
+			},
+			wantErr: false,
+		},
 		{
 			name: "PasswordHashError",
 			setup: func() {
@@ -620,14 +678,21 @@ func TestController_UpdateUser(t *testing.T) {
 	mockRepo := mocks.NewMockAppRepo(ctrlMock)
 	mockCache := mocks.NewMockCacheService(ctrlMock)
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
+	mockSmtp := mocks.NewMockEmailService(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, mockSmtp)
 
 	testUserID := uuid.New()
 	testAvatarURL := "https://example.com/avatar.jpg"
+	testUser := &md.User{ID: testUserID, Email: "test@example.com"}
 	testRequest := &dto.UpdateUserRequest{
-		Name: "Updated Name",
+		Name:  "Updated Name",
+		Email: testUser.Email,
+	}
+	newEmailRequest := &dto.UpdateUserRequest{
+		Name:  "Updated Name",
+		Email: "new@example.com",
 	}
 	testFile := &s3.UploadFileRequest{
 		File:        []byte("testfile"),
@@ -647,6 +712,7 @@ func TestController_UpdateUser(t *testing.T) {
 		{
 			name: "SuccessWithoutAvatar",
 			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
 				mockRepo.EXPECT().
 					UpdateUser(gomock.Any(), testUserID, testRequest).
 					Return(nil)
@@ -667,6 +733,7 @@ func TestController_UpdateUser(t *testing.T) {
 		{
 			name: "SuccessWithAvatar",
 			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
 				mockS3.EXPECT().
 					UploadFile(gomock.Any(), testFile).
 					Return(testAvatarURL, nil)
@@ -691,9 +758,52 @@ func TestController_UpdateUser(t *testing.T) {
 			file:    testFile,
 			wantErr: false,
 		},
+		{
+			name: "EmailChanged",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
+				mockRepo.EXPECT().
+					UpdateUser(gomock.Any(), testUserID, newEmailRequest).
+					Return(nil)
+
+				mockCache.EXPECT().
+					Delete(gomock.Any(), fmt.Sprintf(userCacheKey, testUserID)).
+					Return()
+
+				mockCache.EXPECT().
+					InvalidateKeysByPattern(gomock.Any(), userPattern).
+					Return().AnyTimes()
+
+				mockCache.EXPECT().
+					Incr(gomock.Any(), config.CodeResendCooldown, gomock.Any()).
+					Return(int64(1), nil)
+				mockCache.EXPECT().
+					Set(gomock.Any(), config.VerifyCodeDuration, fmt.Sprintf(verifyCacheKey, testUserID, newEmailRequest.Email), gomock.Any()).
+					Return()
+				mockSmtp.EXPECT().
+					SendVerificationEmail(gomock.Any(), gomock.Any(), testUserID, newEmailRequest.Email).
+					Return(nil)
+			},
+			id:      testUserID,
+			request: newEmailRequest,
+			file:    nil,
+			wantErr: false,
+		},
+		{
+			name: "UnknownUser",
+			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(nil, repo.ErrNotFound)
+			},
+			id:      testUserID,
+			request: testRequest,
+			file:    nil,
+			wantErr: true,
+			err:     ErrNotFound,
+		},
 		{
 			name: "UserNotFound",
 			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
 				mockRepo.EXPECT().
 					UpdateUser(gomock.Any(), testUserID, testRequest).
 					Return(repo.ErrNotFound)
@@ -707,6 +817,7 @@ func TestController_UpdateUser(t *testing.T) {
 		{
 			name: "S3UploadError",
 			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
 				mockS3.EXPECT().
 					UploadFile(gomock.Any(), testFile).
 					Return("", errors.New("upload error"))
@@ -719,6 +830,7 @@ func TestController_UpdateUser(t *testing.T) {
 		{
 			name: "RepositoryError",
 			setup: func() {
+				mockRepo.EXPECT().GetUserByID(gomock.Any(), testUserID).Return(testUser, nil)
 				mockRepo.EXPECT().
 					UpdateUser(gomock.Any(), testUserID, testRequest).
 					Return(errors.New("database error"))
@@ -760,7 +872,7 @@ func TestController_DeleteUser(t *testing.T) {
 	mockS3 := mocks.NewMockS3Service(ctrlMock)
 
 	ctx := context.Background()
-	ctrl := New(mockAuth, mockRepo, mockCache, mockS3, nil)
+	ctrl := New(config.Config{}, mockAuth, mockRepo, mockCache, mockS3, nil)
 
 	// Test data
 	testUserID := uuid.New()
@@ -779,6 +891,7 @@ func TestController_DeleteUser(t *testing.T) {
 				mockRepo.EXPECT().
 					DeleteUser(gomock.Any(), testUserID).
 					Return(nil)
+				mockAuth.EXPECT().RevokeUserTokens(gomock.Any(), testUserID)
 
 				mockCache.EXPECT().
 					Delete(gomock.Any(), cacheKey).
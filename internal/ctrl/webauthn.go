@@ -0,0 +1,385 @@
+package ctrl
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+type webAuthnCtrl interface {
+	BeginWebAuthnRegistration(ctx context.Context, uid uuid.UUID) (*webauthn.CreationOptions, error)
+	FinishWebAuthnRegistration(
+		ctx context.Context,
+		uid uuid.UUID,
+		d *dto.DeviceRequest,
+		req *dto.WebAuthnRegistrationRequest,
+	) (*md.WebAuthnCredential, error)
+	BeginWebAuthnLogin(ctx context.Context, req *dto.WebAuthnLoginRequest) (*webauthn.RequestOptions, error)
+	FinishWebAuthnLogin(
+		ctx context.Context,
+		d *dto.DeviceRequest,
+		req *dto.FinishWebAuthnLoginRequest,
+	) (*dto.TokenPair, error)
+	ListWebAuthnCredentials(ctx context.Context, uid uuid.UUID) ([]md.WebAuthnCredential, error)
+	DeleteWebAuthnCredential(ctx context.Context, uid uuid.UUID, id string) error
+}
+
+type webAuthnRepo interface {
+	ListWebAuthnCredentials(ctx context.Context, uid uuid.UUID) ([]md.WebAuthnCredential, error)
+	GetWebAuthnCredential(ctx context.Context, id string) (*md.WebAuthnCredential, error)
+	CreateWebAuthnCredential(ctx context.Context, c *md.WebAuthnCredential) error
+	UpdateWebAuthnSignCount(ctx context.Context, c *md.WebAuthnCredential) error
+	DeleteWebAuthnCredential(ctx context.Context, uid uuid.UUID, id string) error
+}
+
+const (
+	webAuthnRegisterCacheKey = "webauthn-register:%v"
+	webAuthnLoginCacheKey    = "webauthn-login:%v"
+)
+
+// webAuthnSession is the cached state of a ceremony. Registrations are bound
+// to UserID, logins started with an email to that Email.
+type webAuthnSession struct {
+	UserID    uuid.UUID `json:"userId"`
+	Email     string    `json:"email,omitempty"`
+	Challenge []byte    `json:"challenge"`
+}
+
+func (c *Controller) webAuthn() *webauthn.WebAuthn {
+	return webauthn.New(
+		c.conf.Auth.WebAuthn.RPID,
+		c.conf.Auth.WebAuthn.RPName,
+		c.conf.Auth.WebAuthn.Origins,
+		config.WebAuthnDuration,
+	)
+}
+
+// BeginWebAuthnRegistration starts registering a passkey for the user. Only
+// the latest started registration can be finished.
+func (c *Controller) BeginWebAuthnRegistration(ctx context.Context, uid uuid.UUID) (*webauthn.CreationOptions, error) {
+	const op = "webauthn.BeginWebAuthnRegistration.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	u, err := c.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	creds, err := c.repo.ListWebAuthnCredentials(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := c.storeWebAuthnSession(ctx, fmt.Sprintf(webAuthnRegisterCacheKey, uid), uid)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to start webauthn registration", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	user := webauthn.User{
+		ID:          uid[:],
+		Name:        u.Email,
+		DisplayName: u.Name,
+	}
+	return c.webAuthn().CreationOptions(challenge, user, credentialDescriptors(creds)), nil
+}
+
+// FinishWebAuthnRegistration verifies the new passkey and stores it, linked
+// to the device it was created on if the device is known.
+func (c *Controller) FinishWebAuthnRegistration(
+	ctx context.Context,
+	uid uuid.UUID,
+	d *dto.DeviceRequest,
+	req *dto.WebAuthnRegistrationRequest,
+) (*md.WebAuthnCredential, error) {
+	const op = "webauthn.FinishWebAuthnRegistration.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	session, err := c.takeWebAuthnSession(ctx, fmt.Sprintf(webAuthnRegisterCacheKey, uid))
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := c.webAuthn().VerifyRegistration(session.Challenge, &req.Credential)
+	if err != nil {
+		zap.L().Debug(
+			"invalid webauthn registration",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return nil, ErrCredentialIsNotValid
+	}
+
+	aaguid, err := uuid.FromBytes(cred.AAGUID)
+	if err != nil {
+		return nil, ErrCredentialIsNotValid
+	}
+
+	res := &md.WebAuthnCredential{
+		ID:         base64.RawURLEncoding.EncodeToString(cred.ID),
+		UserID:     uid,
+		Name:       req.Name,
+		PublicKey:  cred.PublicKey,
+		Alg:        cred.Alg,
+		SignCount:  int64(cred.SignCount),
+		AAGUID:     aaguid,
+		Transports: strings.Join(cred.Transports, ","),
+		LastUsedAt: time.Now(),
+		CreatedAt:  time.Now(),
+	}
+
+	device := auth.GenerateDevice(d)
+	if _, err = c.repo.GetDevice(ctx, uid, device.ID); err == nil {
+		res.DeviceID = device.ID
+	}
+
+	if err = c.repo.CreateWebAuthnCredential(ctx, res); err != nil {
+		if errors.Is(err, repo.ErrAlreadyExists) {
+			return nil, ErrAlreadyExists
+		}
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// BeginWebAuthnLogin starts a passkey login. Passkeys are registered as
+// discoverable credentials, so the options never list any and look the same
+// for every email. An email only restricts which account may finish the
+// login.
+func (c *Controller) BeginWebAuthnLogin(
+	ctx context.Context,
+	req *dto.WebAuthnLoginRequest,
+) (*webauthn.RequestOptions, error) {
+	const op = "webauthn.BeginWebAuthnLogin.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to generate challenge", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	key := fmt.Sprintf(webAuthnLoginCacheKey, c.hashToken(string(challenge)))
+	session := &webAuthnSession{Email: strings.ToLower(req.Email), Challenge: challenge}
+	if err = c.setWebAuthnSession(ctx, key, session); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to store webauthn session", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	return c.webAuthn().RequestOptions(challenge, nil), nil
+}
+
+// FinishWebAuthnLogin verifies the assertion and starts a session on the
+// device like any other login.
+func (c *Controller) FinishWebAuthnLogin(
+	ctx context.Context,
+	d *dto.DeviceRequest,
+	req *dto.FinishWebAuthnLoginRequest,
+) (*dto.TokenPair, error) {
+	const op = "webauthn.FinishWebAuthnLogin.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, challenge, err := webauthn.ParseClientData(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ErrCredentialIsNotValid
+	}
+
+	session, err := c.takeWebAuthnSession(ctx, fmt.Sprintf(webAuthnLoginCacheKey, c.hashToken(string(challenge))))
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := c.repo.GetWebAuthnCredential(ctx, req.Credential.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrCredentialIsNotValid
+		}
+
+		return nil, err
+	}
+
+	if session.UserID != uuid.Nil && session.UserID != cred.UserID {
+		return nil, ErrCredentialIsNotValid
+	}
+
+	handle := req.Credential.Response.UserHandle
+	if len(handle) > 0 && string(handle) != string(cred.UserID[:]) {
+		return nil, ErrCredentialIsNotValid
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(cred.ID)
+	if err != nil {
+		return nil, ErrCredentialIsNotValid
+	}
+
+	count, err := c.webAuthn().VerifyAssertion(
+		session.Challenge,
+		&webauthn.Credential{ID: id, PublicKey: cred.PublicKey, SignCount: uint32(cred.SignCount)}, //nolint:gosec
+		&req.Credential,
+	)
+	if err != nil {
+		zap.L().Info(
+			"invalid webauthn assertion",
+			zap.String("op", op),
+			zap.String("userID", cred.UserID.String()),
+			zap.String("credentialID", cred.ID),
+			zap.Error(err),
+		)
+
+		return nil, ErrCredentialIsNotValid
+	}
+
+	u, err := c.repo.GetUserByID(ctx, cred.UserID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	if session.Email != "" && !strings.EqualFold(session.Email, u.Email) {
+		return nil, ErrCredentialIsNotValid
+	}
+
+	// The counter only moves forward, so of two concurrent logins with the
+	// same assertion one is rejected.
+	cred.SignCount = int64(count)
+	if err = c.repo.UpdateWebAuthnSignCount(ctx, cred); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			zap.L().Info(
+				"webauthn signature counter did not increase",
+				zap.String("op", op),
+				zap.String("userID", cred.UserID.String()),
+				zap.String("credentialID", cred.ID),
+			)
+
+			return nil, ErrCredentialIsNotValid
+		}
+
+		return nil, err
+	}
+
+	if c.conf.Auth.RequireVerifiedEmail && !u.IsEmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	pair, err := c.GenPair(ctx, d, u.ID, req.Remember)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pair, nil
+}
+
+func (c *Controller) ListWebAuthnCredentials(ctx context.Context, uid uuid.UUID) ([]md.WebAuthnCredential, error) {
+	const op = "webauthn.ListWebAuthnCredentials.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return c.repo.ListWebAuthnCredentials(ctx, uid)
+}
+
+func (c *Controller) DeleteWebAuthnCredential(ctx context.Context, uid uuid.UUID, id string) error {
+	const op = "webauthn.DeleteWebAuthnCredential.ctrl"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := c.repo.DeleteWebAuthnCredential(ctx, uid, id)
+	if err != nil && errors.Is(err, repo.ErrNotFound) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+func (c *Controller) storeWebAuthnSession(ctx context.Context, key string, uid uuid.UUID) ([]byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, c.setWebAuthnSession(ctx, key, &webAuthnSession{UserID: uid, Challenge: challenge})
+}
+
+func (c *Controller) setWebAuthnSession(ctx context.Context, key string, s *webAuthnSession) error {
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	c.cache.Set(ctx, config.WebAuthnDuration, key, bytes)
+	return nil
+}
+
+// takeWebAuthnSession returns the ceremony state and drops it, so every
+// challenge is answered at most once.
+func (c *Controller) takeWebAuthnSession(ctx context.Context, key string) (*webAuthnSession, error) {
+	session := &webAuthnSession{}
+	if err := c.cache.GetToStruct(ctx, key, session); err != nil {
+		return nil, ErrChallengeIsNotValid
+	}
+
+	c.cache.Delete(ctx, key)
+	return session, nil
+}
+
+func credentialDescriptors(creds []md.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	res := make([]webauthn.CredentialDescriptor, 0, len(creds))
+	for i := range creds {
+		id, err := base64.RawURLEncoding.DecodeString(creds[i].ID)
+		if err != nil {
+			continue
+		}
+
+		var transports []string
+		if creds[i].Transports != "" {
+			transports = strings.Split(creds[i].Transports, ",")
+		}
+
+		res = append(
+			res, webauthn.CredentialDescriptor{
+				Type:       webauthn.PublicKeyType,
+				ID:         id,
+				Transports: transports,
+			},
+		)
+	}
+
+	return res
+}
@@ -0,0 +1,388 @@
+package ctrl
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn"
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn/webauthntest"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func newWebAuthnConfig() config.Config {
+	conf := config.Config{}
+	conf.Auth.WebAuthn.RPID = "localhost"
+	conf.Auth.WebAuthn.RPName = "sso"
+	conf.Auth.WebAuthn.Origins = []string{"http://localhost:8080"}
+	return conf
+}
+
+// fakeCache keeps cached sessions between the begin and finish calls.
+func fakeCache(mockCache *mocks.MockCacheService) {
+	stored := make(map[string][]byte)
+	mockCache.EXPECT().
+		Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(
+			func(_ context.Context, _ time.Duration, key string, val any) {
+				stored[key] = val.([]byte)
+			},
+		).AnyTimes()
+	mockCache.EXPECT().
+		GetToStruct(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(
+			func(_ context.Context, key string, dest any) error {
+				val, ok := stored[key]
+				if !ok {
+					return errors.New("not found")
+				}
+				return json.Unmarshal(val, dest)
+			},
+		).AnyTimes()
+	mockCache.EXPECT().
+		GetDelToStruct(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(
+			func(_ context.Context, key string, dest any) error {
+				val, ok := stored[key]
+				if !ok {
+					return errors.New("not found")
+				}
+				delete(stored, key)
+				return json.Unmarshal(val, dest)
+			},
+		).AnyTimes()
+	mockCache.EXPECT().
+		Delete(gomock.Any(), gomock.Any()).
+		DoAndReturn(
+			func(_ context.Context, key string) {
+				delete(stored, key)
+			},
+		).AnyTimes()
+}
+
+func TestController_WebAuthnRegistration(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(newWebAuthnConfig(), nil, mockRepo, mockCache, nil, nil)
+	fakeCache(mockCache)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	user := &models.User{ID: uid, Name: "Test", Email: "test@example.com"}
+	device := &dto.DeviceRequest{IP: "192.168.1.1", UA: "test-user-agent"}
+	deviceID := auth.GenerateDevice(device).ID
+
+	begin := func(t *testing.T, existing []models.WebAuthnCredential) *webauthn.CreationOptions {
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+		mockRepo.EXPECT().ListWebAuthnCredentials(gomock.Any(), uid).Return(existing, nil)
+
+		opts, err := ctrl.BeginWebAuthnRegistration(ctx, uid)
+		require.NoError(t, err)
+		return opts
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		existing := []models.WebAuthnCredential{{ID: "AQID", Transports: "usb,nfc"}}
+		opts := begin(t, existing)
+		assert.Equal(t, webauthn.Bytes(uid[:]), opts.User.ID)
+		assert.Equal(t, "test@example.com", opts.User.Name)
+		require.Len(t, opts.ExcludeCredentials, 1)
+		assert.Equal(t, webauthn.Bytes{1, 2, 3}, opts.ExcludeCredentials[0].ID)
+		assert.Equal(t, []string{"usb", "nfc"}, opts.ExcludeCredentials[0].Transports)
+
+		a, err := webauthntest.New("localhost", "http://localhost:8080")
+		require.NoError(t, err)
+
+		resp, err := a.Register(opts)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetDevice(gomock.Any(), uid, deviceID).Return(&models.Device{ID: deviceID}, nil)
+		mockRepo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any()).Return(nil)
+
+		res, err := ctrl.FinishWebAuthnRegistration(
+			ctx, uid, device, &dto.WebAuthnRegistrationRequest{
+				Name:       "laptop",
+				Credential: *resp,
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(a.CredentialID), res.ID)
+		assert.Equal(t, uid, res.UserID)
+		assert.Equal(t, deviceID, res.DeviceID)
+		assert.Equal(t, "laptop", res.Name)
+		assert.Equal(t, webauthn.AlgES256, res.Alg)
+		assert.NotEmpty(t, res.PublicKey)
+
+		// The session is gone once used
+		_, err = ctrl.FinishWebAuthnRegistration(ctx, uid, device, &dto.WebAuthnRegistrationRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrChallengeIsNotValid)
+	})
+
+	t.Run("UnknownDevice", func(t *testing.T) {
+		opts := begin(t, nil)
+		a, err := webauthntest.New("localhost", "http://localhost:8080")
+		require.NoError(t, err)
+
+		resp, err := a.Register(opts)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetDevice(gomock.Any(), uid, deviceID).Return(nil, repo.ErrNotFound)
+		mockRepo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any()).Return(nil)
+
+		res, err := ctrl.FinishWebAuthnRegistration(ctx, uid, device, &dto.WebAuthnRegistrationRequest{Credential: *resp})
+		require.NoError(t, err)
+		assert.Empty(t, res.DeviceID)
+	})
+
+	t.Run("WrongOrigin", func(t *testing.T) {
+		opts := begin(t, nil)
+		a, err := webauthntest.New("localhost", "https://evil.example")
+		require.NoError(t, err)
+
+		resp, err := a.Register(opts)
+		require.NoError(t, err)
+
+		_, err = ctrl.FinishWebAuthnRegistration(ctx, uid, device, &dto.WebAuthnRegistrationRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrCredentialIsNotValid)
+	})
+
+	t.Run("AlreadyExists", func(t *testing.T) {
+		opts := begin(t, nil)
+		a, err := webauthntest.New("localhost", "http://localhost:8080")
+		require.NoError(t, err)
+
+		resp, err := a.Register(opts)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetDevice(gomock.Any(), uid, deviceID).Return(nil, repo.ErrNotFound)
+		mockRepo.EXPECT().CreateWebAuthnCredential(gomock.Any(), gomock.Any()).Return(repo.ErrAlreadyExists)
+
+		_, err = ctrl.FinishWebAuthnRegistration(ctx, uid, device, &dto.WebAuthnRegistrationRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+	})
+
+	t.Run("NoSession", func(t *testing.T) {
+		_, err := ctrl.FinishWebAuthnRegistration(
+			ctx, uuid.New(), device, &dto.WebAuthnRegistrationRequest{},
+		)
+		assert.ErrorIs(t, err, ErrChallengeIsNotValid)
+	})
+
+	t.Run("UserNotFound", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(nil, repo.ErrNotFound)
+
+		_, err := ctrl.BeginWebAuthnRegistration(ctx, uid)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestController_WebAuthnLogin(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockAuth := mocks.NewMockCore(ctrlMock)
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	mockCache := mocks.NewMockCacheService(ctrlMock)
+	ctrl := New(newWebAuthnConfig(), mockAuth, mockRepo, mockCache, nil, nil)
+	fakeCache(mockCache)
+
+	ctx := context.Background()
+	uid := uuid.New()
+	user := &models.User{ID: uid, Email: "test@example.com", IsEmailVerified: true}
+	device := &dto.DeviceRequest{IP: "192.168.1.1", UA: "test-user-agent"}
+
+	// Register a passkey with the software authenticator
+	a, err := webauthntest.New("localhost", "http://localhost:8080")
+	require.NoError(t, err)
+
+	rp := webauthn.New("localhost", "sso", []string{"http://localhost:8080"}, time.Minute)
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	reg, err := a.Register(rp.CreationOptions(challenge, webauthn.User{ID: uid[:]}, nil))
+	require.NoError(t, err)
+
+	verified, err := rp.VerifyRegistration(challenge, reg)
+	require.NoError(t, err)
+
+	stored := &models.WebAuthnCredential{
+		ID:        base64.RawURLEncoding.EncodeToString(verified.ID),
+		UserID:    uid,
+		PublicKey: verified.PublicKey,
+		Alg:       verified.Alg,
+	}
+	credential := func() *models.WebAuthnCredential {
+		c := *stored
+		return &c
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		opts, err := ctrl.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{Email: "Test@Example.com"})
+		require.NoError(t, err)
+		assert.Empty(t, opts.AllowCredentials)
+
+		resp, err := a.Login(opts)
+		require.NoError(t, err)
+
+		refreshTime := time.Now().Add(time.Hour)
+		mockRepo.EXPECT().GetWebAuthnCredential(gomock.Any(), stored.ID).Return(credential(), nil)
+		mockRepo.EXPECT().
+			UpdateWebAuthnSignCount(gomock.Any(), gomock.Any()).
+			DoAndReturn(
+				func(_ context.Context, c *models.WebAuthnCredential) error {
+					assert.Equal(t, int64(a.SignCount), c.SignCount)
+					stored.SignCount = c.SignCount
+					return nil
+				},
+			)
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+		mockRepo.EXPECT().ListUserRoles(gomock.Any(), uid).Return(nil, nil)
+		mockAuth.EXPECT().GenPair(gomock.Any(), uid, gomock.Any(), gomock.Any()).Return("access", "refresh", nil)
+		mockRepo.EXPECT().ListDevices(gomock.Any(), uid).Return(nil, nil)
+		mockAuth.EXPECT().GetRefreshTime().Return(refreshTime)
+		mockRepo.EXPECT().
+			CreateToken(gomock.Any(), uid, auth.HashToken(nil, "refresh"), gomock.Any(), false, gomock.Any()).
+			Return(nil)
+
+		res, err := ctrl.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{Credential: *resp})
+		require.NoError(t, err)
+		assert.Equal(t, "access", res.Access)
+		assert.Equal(t, auth.GenerateDevice(device).ID, res.DeviceID)
+
+		// The challenge is single use
+		_, err = ctrl.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrChallengeIsNotValid)
+	})
+
+	t.Run("OptionsDontDependOnEmail", func(t *testing.T) {
+		known, err := ctrl.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{Email: user.Email})
+		require.NoError(t, err)
+
+		unknown, err := ctrl.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{Email: "nobody@example.com"})
+		require.NoError(t, err)
+
+		known.Challenge, unknown.Challenge = nil, nil
+		assert.Equal(t, known, unknown)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		opts, err := ctrl.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{Email: "other@example.com"})
+		require.NoError(t, err)
+
+		resp, err := a.Login(opts)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetWebAuthnCredential(gomock.Any(), stored.ID).Return(credential(), nil)
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+
+		_, err = ctrl.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrCredentialIsNotValid)
+	})
+
+	t.Run("ReplayedCounter", func(t *testing.T) {
+		opts, err := ctrl.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{})
+		require.NoError(t, err)
+
+		resp, err := a.Login(opts)
+		require.NoError(t, err)
+
+		cloned := credential()
+		cloned.SignCount = int64(a.SignCount) + 10
+		mockRepo.EXPECT().GetWebAuthnCredential(gomock.Any(), stored.ID).Return(cloned, nil)
+
+		_, err = ctrl.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrCredentialIsNotValid)
+	})
+
+	t.Run("ConcurrentCounter", func(t *testing.T) {
+		opts, err := ctrl.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{})
+		require.NoError(t, err)
+
+		resp, err := a.Login(opts)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetWebAuthnCredential(gomock.Any(), stored.ID).Return(credential(), nil)
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(user, nil)
+		mockRepo.EXPECT().UpdateWebAuthnSignCount(gomock.Any(), gomock.Any()).Return(repo.ErrNotFound)
+
+		_, err = ctrl.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrCredentialIsNotValid)
+	})
+
+	t.Run("UnknownCredential", func(t *testing.T) {
+		opts, err := ctrl.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{})
+		require.NoError(t, err)
+
+		resp, err := a.Login(opts)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetWebAuthnCredential(gomock.Any(), stored.ID).Return(nil, repo.ErrNotFound)
+
+		_, err = ctrl.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrCredentialIsNotValid)
+	})
+
+	t.Run("EmailNotVerified", func(t *testing.T) {
+		conf := newWebAuthnConfig()
+		conf.Auth.RequireVerifiedEmail = true
+		strict := New(conf, mockAuth, mockRepo, mockCache, nil, nil)
+
+		opts, err := strict.BeginWebAuthnLogin(ctx, &dto.WebAuthnLoginRequest{})
+		require.NoError(t, err)
+
+		resp, err := a.Login(opts)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().GetWebAuthnCredential(gomock.Any(), stored.ID).Return(credential(), nil)
+		mockRepo.EXPECT().UpdateWebAuthnSignCount(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), uid).Return(&models.User{ID: uid}, nil)
+
+		_, err = strict.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{Credential: *resp})
+		assert.ErrorIs(t, err, ErrEmailNotVerified)
+	})
+
+	t.Run("InvalidClientData", func(t *testing.T) {
+		_, err := ctrl.FinishWebAuthnLogin(ctx, device, &dto.FinishWebAuthnLoginRequest{})
+		assert.ErrorIs(t, err, ErrCredentialIsNotValid)
+	})
+}
+
+func TestController_DeleteWebAuthnCredential(t *testing.T) {
+	ctrlMock := gomock.NewController(t)
+	defer ctrlMock.Finish()
+
+	mockRepo := mocks.NewMockAppRepo(ctrlMock)
+	ctrl := New(config.Config{}, nil, mockRepo, nil, nil, nil)
+
+	ctx := context.Background()
+	uid := uuid.New()
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteWebAuthnCredential(gomock.Any(), uid, "AQID").Return(nil)
+		assert.NoError(t, ctrl.DeleteWebAuthnCredential(ctx, uid, "AQID"))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteWebAuthnCredential(gomock.Any(), uid, "AQID").Return(repo.ErrNotFound)
+		assert.ErrorIs(t, ctrl.DeleteWebAuthnCredential(ctx, uid, "AQID"), ErrNotFound)
+	})
+
+	t.Run("RepoError", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteWebAuthnCredential(gomock.Any(), uid, "AQID").Return(errors.New("db"))
+		assert.Error(t, ctrl.DeleteWebAuthnCredential(ctx, uid, "AQID"))
+	})
+}
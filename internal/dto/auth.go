@@ -1,14 +1,21 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type TokenRequest struct {
 	Token string `json:"token" validate:"required"`
 }
 
 type TokenPair struct {
-	Access  string `json:"access"`
-	Refresh string `json:"refresh"`
+	Access    string    `json:"access"`
+	Refresh   string    `json:"refresh"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	DeviceID  string    `json:"deviceId"`
+	Remember  bool      `json:"-"`
 }
 
 type RefreshRequest struct {
@@ -19,6 +26,7 @@ type EmailAndPasswordRequest struct {
 	Email    string `json:"email"    validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 	Token    string `json:"token"    validate:"required"`
+	Remember bool   `json:"remember"`
 }
 
 type LoginCodeRequest struct {
@@ -28,8 +36,9 @@ type LoginCodeRequest struct {
 }
 
 type CheckLoginCodeRequest struct {
-	Email string `json:"email" validate:"required,email"`
-	Code  int    `json:"code"  validate:"required"`
+	Email    string `json:"email"    validate:"required,email"`
+	Code     int    `json:"code"     validate:"required"`
+	Remember bool   `json:"remember"`
 }
 
 type CheckEmailRequest struct {
@@ -50,3 +59,17 @@ type SendForgotPasswordEmail struct {
 	Email string `json:"email" validate:"required,email"`
 	Token string `json:"token" validate:"required"`
 }
+
+type SendVerificationEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Token string `json:"token" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	ID   uuid.UUID `json:"uidb64" validate:"required"`
+	Code int       `json:"token"  validate:"required"`
+}
+
+type RotateKeyResponse struct {
+	KID string `json:"kid"`
+}
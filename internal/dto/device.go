@@ -1,6 +1,7 @@
 package dto
 
 type DeviceRequest struct {
+	ID string `json:"id"`
 	IP string `json:"ip"`
 	UA string `json:"ua"`
 }
@@ -0,0 +1,144 @@
+package dto
+
+import (
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/google/uuid"
+)
+
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name"         validate:"required,max=100"`
+	Public       bool     `json:"public"`
+	RedirectURIs []string `json:"redirectUris" validate:"dive,required"`
+	Scopes       []string `json:"scopes"       validate:"dive,required"`
+	GrantTypes   []string `json:"grantTypes"   validate:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials urn:ietf:params:oauth:grant-type:device_code"`
+}
+
+// CreateOAuthClientResponse carries the client secret. It is only shown
+// once, the server keeps its hash.
+type CreateOAuthClientResponse struct {
+	Client *md.OAuthClient `json:"client"`
+	Secret string          `json:"secret,omitempty"`
+}
+
+// AuthorizeRequest holds the parameters of an authorization request
+// (RFC 6749 section 4.1.1). Only the S256 code challenge method is accepted.
+// Nonce is copied into the ID token of OpenID Connect requests.
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Prompt              string `json:"prompt"`
+	Nonce               string `json:"nonce"`
+}
+
+// ConsentRequest repeats the authorization request with the user's decision.
+type ConsentRequest struct {
+	AuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+// AuthorizeResponse either sends the user agent back to the client with
+// RedirectTo or asks the user to consent to Scope for the client.
+type AuthorizeResponse struct {
+	RedirectTo string    `json:"redirectTo,omitempty"`
+	ClientID   uuid.UUID `json:"clientId,omitempty"`
+	ClientName string    `json:"clientName,omitempty"`
+	Scope      string    `json:"scope,omitempty"`
+}
+
+// OAuthTokenRequest holds the form parameters of a token request. Client
+// credentials come from HTTP Basic auth or the form.
+type OAuthTokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	DeviceCode   string
+	Scope        string
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthTokenResponse is a successful token response (RFC 6749 section 5.1).
+// IDToken is set when the openid scope was granted.
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// DeviceCodeRequest holds the form parameters of a device authorization
+// request (RFC 8628 section 3.1).
+type DeviceCodeRequest struct {
+	Scope        string
+	ClientID     string
+	ClientSecret string
+}
+
+// DeviceCodeResponse tells the device what to show the user and how often
+// to poll the token endpoint (RFC 8628 section 3.2).
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceConsentResponse describes the pending device authorization a user
+// code belongs to.
+type DeviceConsentResponse struct {
+	ClientID   uuid.UUID `json:"clientId"`
+	ClientName string    `json:"clientName"`
+	Scope      string    `json:"scope"`
+}
+
+// DeviceApprovalRequest answers the device authorization of UserCode.
+type DeviceApprovalRequest struct {
+	UserCode string `json:"userCode" validate:"required"`
+	Approve  bool   `json:"approve"`
+}
+
+// IntrospectRequest holds the form parameters of a token introspection
+// request (RFC 7662 section 2.1). TokenTypeHint is only a hint, the token
+// is identified by its shape.
+type IntrospectRequest struct {
+	Token         string
+	TokenTypeHint string
+	ClientID      string
+	ClientSecret  string
+}
+
+// IntrospectionResponse describes a token (RFC 7662 section 2.2). An
+// inactive token only has Active set. DeviceID is the device of session
+// tokens.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	DeviceID  string `json:"device_id,omitempty"`
+}
+
+// RevokeTokenRequest holds the form parameters of a token revocation
+// request (RFC 7009 section 2.1).
+type RevokeTokenRequest struct {
+	Token         string
+	TokenTypeHint string
+	ClientID      string
+	ClientSecret  string
+}
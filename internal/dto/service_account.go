@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"time"
+
+	md "github.com/JMURv/golang-clean-template/internal/models"
+)
+
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name"        validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// CreateAPIKeyRequest names a new key of a service account. Scopes are
+// permissions the caller holds. A nil ExpiresAt creates a key that doesn't
+// expire.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"      validate:"required,max=100"`
+	Scopes    []string   `json:"scopes"    validate:"dive,required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreateAPIKeyResponse carries the API key. It is only shown once, the
+// server keeps its hash.
+type CreateAPIKeyResponse struct {
+	APIKey *md.APIKey `json:"apiKey"`
+	Key    string     `json:"key"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type TOTPEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type RecoveryCodesResponse struct {
+	Codes []string `json:"codes"`
+}
+
+// SecondFactorRequest carries either an authenticator code or a recovery code.
+type SecondFactorRequest struct {
+	Code         string `json:"code"         validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recoveryCode" validate:"required_without=Code"`
+}
+
+// TwoFactorChallenge is returned by the first login step when the user has
+// 2FA enabled. It is exchanged for a TokenPair at /auth/2fa/verify.
+type TwoFactorChallenge struct {
+	Challenge string    `json:"challenge"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type VerifyTwoFactorRequest struct {
+	Challenge string `json:"challenge" validate:"required"`
+	SecondFactorRequest
+}
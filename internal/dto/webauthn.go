@@ -0,0 +1,30 @@
+package dto
+
+import "github.com/JMURv/golang-clean-template/internal/auth/webauthn"
+
+type WebAuthnRegistrationRequest struct {
+	Name       string                        `json:"name"       validate:"max=64"`
+	Credential webauthn.RegistrationResponse `json:"credential"`
+}
+
+// WebAuthnLoginRequest optionally names the account. The authenticator
+// offers any discoverable passkey for the site either way, and only a passkey
+// of that account can finish the login.
+type WebAuthnLoginRequest struct {
+	Email string `json:"email" validate:"omitempty,email"`
+}
+
+type FinishWebAuthnLoginRequest struct {
+	Credential webauthn.LoginResponse `json:"credential"`
+	Remember   bool                   `json:"remember"`
+}
+
+// WebAuthnCreationResponse has the shape of the argument to navigator.credentials.create.
+type WebAuthnCreationResponse struct {
+	PublicKey *webauthn.CreationOptions `json:"publicKey"`
+}
+
+// WebAuthnRequestResponse has the shape of the argument to navigator.credentials.get.
+type WebAuthnRequestResponse struct {
+	PublicKey *webauthn.RequestOptions `json:"publicKey"`
+}
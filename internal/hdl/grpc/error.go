@@ -1 +1,9 @@
 package grpc
+
+import "errors"
+
+var (
+	ErrEmptyRole     = errors.New("role is required")
+	ErrEmptyDeviceID = errors.New("device id is required")
+	ErrEmptyToken    = errors.New("token is required")
+)
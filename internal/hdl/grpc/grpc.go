@@ -11,6 +11,7 @@ import (
 	"github.com/JMURv/golang-clean-template/internal/ctrl"
 	"github.com/JMURv/golang-clean-template/internal/hdl/grpc/interceptors"
 	metrics "github.com/JMURv/golang-clean-template/internal/observability/metrics/prometheus"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
 	pm "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -27,10 +28,18 @@ type Handler struct {
 	au   auth.Core
 }
 
-func New(name string, ctrl ctrl.AppCtrl, au auth.Core) *Handler {
+func New(name string, ctrl ctrl.AppCtrl, au auth.Core, limiter *ratelimit.Limiter) *Handler {
 	srv := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
-			interceptors.Auth(au),
+			interceptors.Auth(au, ctrl),
+			interceptors.RateLimit(limiter, ratelimit.PolicyGRPC),
+			interceptors.RequirePermission(auth.PermRolesRead, gen.App_ListUserRoles_FullMethodName),
+			interceptors.RequirePermission(
+				auth.PermRolesAssign,
+				gen.App_AssignRole_FullMethodName,
+				gen.App_RevokeRole_FullMethodName,
+			),
+			interceptors.RequirePermission(auth.PermTokensIntrospect, gen.App_IntrospectToken_FullMethodName),
 			interceptors.LogTraceMetrics(),
 			metrics.SrvMetrics.UnaryServerInterceptor(
 				pm.WithExemplarFromContext(metrics.Exemplar),
@@ -2,19 +2,29 @@ package interceptors
 
 import (
 	"context"
+	"net"
+	"slices"
 	"time"
 
 	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
 	"github.com/JMURv/golang-clean-template/internal/config"
 	metrics "github.com/JMURv/golang-clean-template/internal/observability/metrics/prometheus"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
+	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
-func Auth(au auth.Core) grpc.UnaryServerInterceptor {
+// Auth puts the caller into the context when the call carries a valid access
+// token or, with keys set, an API key of a service account. Other calls pass
+// through unauthenticated.
+func Auth(au auth.Core, keys auth.APIKeys) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
@@ -33,16 +43,118 @@ func Auth(au auth.Core) grpc.UnaryServerInterceptor {
 			tokenStr = tokenStr[7:]
 		}
 
+		if keys != nil && auth.IsAPIKey(tokenStr) {
+			claims, err := keys.AuthenticateAPIKey(ctx, tokenStr)
+			if err != nil {
+				zap.L().Debug("invalid api key", zap.Error(err))
+				return handler(ctx, req)
+			}
+
+			ctx = context.WithValue(ctx, config.UidKey, claims.UID)
+			ctx = context.WithValue(ctx, config.ClaimsKey, claims)
+			ctx = context.WithValue(ctx, config.APIKeyKey, auth.APIKeyID(tokenStr))
+			return handler(ctx, req)
+		}
+
 		claims, err := au.ParseClaims(ctx, tokenStr)
 		if err != nil {
 			return handler(ctx, req)
 		}
 
+		if !claims.IsAccess() {
+			zap.L().Debug("not an access token", zap.String("uid", claims.UID.String()))
+			return handler(ctx, req)
+		}
+
+		if err = au.CheckRevoked(ctx, claims); err != nil {
+			zap.L().Debug("revoked token", zap.String("uid", claims.UID.String()))
+			return handler(ctx, req)
+		}
+
+		if claims.ClientID != "" {
+			zap.L().Debug("oauth client token", zap.String("clientID", claims.ClientID))
+			return handler(ctx, req)
+		}
+
 		ctx = context.WithValue(ctx, config.UidKey, claims.UID)
+		ctx = context.WithValue(ctx, config.ClaimsKey, claims)
 		return handler(ctx, req)
 	}
 }
 
+// RequirePermission rejects calls to the given methods unless the token
+// parsed by Auth carries perm. Other methods pass through.
+func RequirePermission(perm string, methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !slices.Contains(methods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		claims, ok := ctx.Value(config.ClaimsKey).(jwt.Claims)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, auth.ErrInvalidToken.Error())
+		}
+
+		if !claims.HasPermission(perm) {
+			zap.L().Debug(
+				"permission denied",
+				zap.String("method", info.FullMethod),
+				zap.String("uid", claims.UID.String()),
+				zap.String("permission", perm),
+			)
+			return nil, status.Error(codes.PermissionDenied, auth.ErrPermissionDenied.Error())
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RateLimit counts calls against the policy of l named after the full
+// method, or against name when there is none, and rejects the ones over it
+// with ResourceExhausted. The RateLimit headers of the policy are sent as
+// header metadata. It must be chained after Auth.
+func RateLimit(l *ratelimit.Limiter, name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p, ok := l.Policy(info.FullMethod)
+		if !ok {
+			p, ok = l.Policy(name)
+		}
+
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		uid, _ := ctx.Value(config.UidKey).(uuid.UUID)
+		keyID, _ := ctx.Value(config.APIKeyKey).(string)
+
+		res := l.Allow(ctx, p, ratelimit.Caller(p, peerIP(ctx), uid, keyID))
+		if err := grpc.SetHeader(ctx, metadata.New(ratelimit.Headers(p, res))); err != nil {
+			zap.L().Debug("failed to set rate limit headers", zap.String("method", info.FullMethod), zap.Error(err))
+		}
+
+		if !res.Allowed {
+			return nil, status.Error(codes.ResourceExhausted, ratelimit.ErrRateLimited.Error())
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// peerIP returns the address of the client without its port.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
 func LogTraceMetrics() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		s := time.Now()
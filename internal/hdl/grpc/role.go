@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/api/grpc/v1/gen"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *Handler) ListUserRoles(ctx context.Context, req *gen.UserRequest) (*gen.RolesResponse, error) {
+	uid, err := uuid.Parse(req.GetUid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, hdl.ErrFailedToParseUUID.Error())
+	}
+
+	roles, err := h.ctrl.ListUserRoles(ctx, uid)
+	if err != nil {
+		return nil, status.Error(codes.Internal, hdl.ErrInternal.Error())
+	}
+
+	res := &gen.RolesResponse{Roles: make([]*gen.Role, 0, len(roles))}
+	for _, r := range roles {
+		res.Roles = append(
+			res.Roles, &gen.Role{
+				Id:          r.ID,
+				Name:        r.Name,
+				Description: r.Description,
+				Permissions: r.Permissions,
+			},
+		)
+	}
+
+	return res, nil
+}
+
+func (h *Handler) AssignRole(ctx context.Context, req *gen.RoleRequest) (*gen.Empty, error) {
+	uid, err := uuid.Parse(req.GetUid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, hdl.ErrFailedToParseUUID.Error())
+	}
+
+	if req.GetRole() == "" {
+		return nil, status.Error(codes.InvalidArgument, ErrEmptyRole.Error())
+	}
+
+	if err = h.ctrl.AssignRole(ctx, uid, req.GetRole()); err != nil {
+		return nil, roleErr(err)
+	}
+
+	return &gen.Empty{}, nil
+}
+
+func (h *Handler) RevokeRole(ctx context.Context, req *gen.RoleRequest) (*gen.Empty, error) {
+	uid, err := uuid.Parse(req.GetUid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, hdl.ErrFailedToParseUUID.Error())
+	}
+
+	if req.GetRole() == "" {
+		return nil, status.Error(codes.InvalidArgument, ErrEmptyRole.Error())
+	}
+
+	if err = h.ctrl.RevokeRole(ctx, uid, req.GetRole()); err != nil {
+		return nil, roleErr(err)
+	}
+
+	return &gen.Empty{}, nil
+}
+
+func roleErr(err error) error {
+	if errors.Is(err, ctrl.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return status.Error(codes.Internal, hdl.ErrInternal.Error())
+}
@@ -0,0 +1,96 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/api/grpc/v1/gen"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Session RPCs act on the caller's own sessions. Unlike HTTP, the device is
+// named explicitly since gRPC clients carry no browser fingerprint.
+
+func (h *Handler) Logout(ctx context.Context, req *gen.DeviceRequest) (*gen.Empty, error) {
+	claims, ok := ctx.Value(config.ClaimsKey).(jwt.Claims)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, auth.ErrInvalidToken.Error())
+	}
+
+	if req.GetDeviceId() == "" {
+		return nil, status.Error(codes.InvalidArgument, ErrEmptyDeviceID.Error())
+	}
+
+	if err := h.ctrl.Logout(ctx, claims, req.GetDeviceId()); err != nil {
+		return nil, status.Error(codes.Internal, hdl.ErrInternal.Error())
+	}
+
+	return &gen.Empty{}, nil
+}
+
+func (h *Handler) LogoutAll(ctx context.Context, _ *gen.Empty) (*gen.Empty, error) {
+	uid, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.ctrl.LogoutAll(ctx, uid); err != nil {
+		return nil, status.Error(codes.Internal, hdl.ErrInternal.Error())
+	}
+
+	return &gen.Empty{}, nil
+}
+
+func (h *Handler) LogoutOthers(ctx context.Context, req *gen.DeviceRequest) (*gen.Empty, error) {
+	uid, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetDeviceId() == "" {
+		return nil, status.Error(codes.InvalidArgument, ErrEmptyDeviceID.Error())
+	}
+
+	if err = h.ctrl.LogoutOthers(ctx, uid, req.GetDeviceId()); err != nil {
+		return nil, status.Error(codes.Internal, hdl.ErrInternal.Error())
+	}
+
+	return &gen.Empty{}, nil
+}
+
+func (h *Handler) DeleteDevice(ctx context.Context, req *gen.DeviceRequest) (*gen.Empty, error) {
+	uid, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetDeviceId() == "" {
+		return nil, status.Error(codes.InvalidArgument, ErrEmptyDeviceID.Error())
+	}
+
+	if err = h.ctrl.DeleteDevice(ctx, uid, req.GetDeviceId()); err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, hdl.ErrInternal.Error())
+	}
+
+	return &gen.Empty{}, nil
+}
+
+// callerID returns the user set by the Auth interceptor.
+func callerID(ctx context.Context) (uuid.UUID, error) {
+	uid, ok := ctx.Value(config.UidKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, status.Error(codes.Unauthenticated, auth.ErrInvalidToken.Error())
+	}
+
+	return uid, nil
+}
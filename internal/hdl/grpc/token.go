@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/api/grpc/v1/gen"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IntrospectToken describes any token to holders of tokens:introspect. The
+// hint is ignored, as over HTTP.
+func (h *Handler) IntrospectToken(ctx context.Context, req *gen.TokenRequest) (*gen.IntrospectResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, ErrEmptyToken.Error())
+	}
+
+	res, err := h.ctrl.IntrospectToken(ctx, req.GetToken())
+	if err != nil {
+		return nil, status.Error(codes.Internal, hdl.ErrInternal.Error())
+	}
+
+	return &gen.IntrospectResponse{
+		Active:    res.Active,
+		Scope:     res.Scope,
+		ClientId:  res.ClientID,
+		TokenType: res.TokenType,
+		Exp:       res.Exp,
+		Iat:       res.Iat,
+		Sub:       res.Sub,
+		Iss:       res.Iss,
+		Jti:       res.Jti,
+		DeviceId:  res.DeviceID,
+	}, nil
+}
+
+// RevokeToken revokes a token of the caller, or of anyone with tokens:revoke.
+// Unknown and inactive tokens are accepted.
+func (h *Handler) RevokeToken(ctx context.Context, req *gen.TokenRequest) (*gen.Empty, error) {
+	claims, ok := ctx.Value(config.ClaimsKey).(jwt.Claims)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, auth.ErrInvalidToken.Error())
+	}
+
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, ErrEmptyToken.Error())
+	}
+
+	if err := h.ctrl.RevokeToken(ctx, claims, req.GetToken()); err != nil {
+		if errors.Is(err, auth.ErrPermissionDenied) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, hdl.ErrInternal.Error())
+	}
+
+	return &gen.Empty{}, nil
+}
@@ -6,36 +6,52 @@ import (
 
 	"github.com/JMURv/golang-clean-template/internal/auth"
 	"github.com/JMURv/golang-clean-template/internal/auth/captcha"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
 	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/ctrl"
 	"github.com/JMURv/golang-clean-template/internal/dto"
 	"github.com/JMURv/golang-clean-template/internal/hdl"
 	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
 	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 func (h *Handler) RegisterAuthRoutes() {
-	h.Router.With(mid.Device).Post("/auth/jwt", h.authenticate)
-	h.Router.With(mid.Device).Post("/auth/jwt/refresh", h.refresh)
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{})).Post("/auth/logout", h.logout)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth), h.withDevice()).Post("/auth/jwt", h.authenticate)
+	h.Router.With(h.withDevice()).Post("/auth/jwt/refresh", h.refresh)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth), h.withDevice()).Post("/auth/code", h.sendLoginCode)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth), h.withDevice()).Post("/auth/code/check", h.checkLoginCode)
+	h.Router.With(h.withDevice(), h.withAuth(mid.AuthOpts{})).Post("/auth/logout", h.logout)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Post("/auth/logout/all", h.logoutAll)
+	h.Router.With(h.withDevice(), h.withAuth(mid.AuthOpts{})).Post("/auth/logout/others", h.logoutOthers)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth)).Post("/auth/recovery", h.sendForgotPasswordEmail)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth)).Put("/auth/recovery", h.checkForgotPasswordEmail)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth)).Post("/auth/email/verify", h.verifyEmail)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth)).Post("/auth/email/resend", h.sendVerificationEmail)
 }
 
 // authenticate godoc
 //
 //	@Summary		Authenticate using email & password
-//	@Description	Verify reCAPTCHA, then authenticate and set JWT cookies
+//	@Description	Verify reCAPTCHA, then authenticate and set JWT cookies, or return them with X-Auth-Mode: token. With remember the session uses the long idle and absolute timeouts and the refresh cookie outlives the browser session. Users with 2FA enabled get a challenge for /auth/2fa/verify instead. Failed attempts delay the next one exponentially (429) and lock the account after AUTH_LOCKOUT_THRESHOLD of them (423)
 //	@Tags			Authentication
 //	@Accept			json
 //	@Produce		json
-//	@Param			X-Real-IP	header	string						true	"Client real IP address"
-//	@Param			User-Agent	header	string						true	"Client User-Agent"
-//	@Param			body		body	dto.EmailAndPasswordRequest	true	"Login credentials"
-//	@Success		200			"Successfully authenticated (sets cookies)"
+//	@Param			X-Real-IP	header		string						false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent	header		string						true	"Client User-Agent"
+//	@Param			X-Device-ID	header		string						false	"Stable device ID, the signed device cookie is used otherwise"
+//	@Param			X-Auth-Mode	header		string						false	"Set to 'token' to receive tokens in the body"
+//	@Param			body		body		dto.EmailAndPasswordRequest	true	"Login credentials"
+//	@Success		200			{object}	dto.TokenPair				"Successfully authenticated (sets cookies unless X-Auth-Mode: token)"
+//	@Success		202			{object}	dto.TwoFactorChallenge		"Second factor required"
 //	@Failure		400			{object}	utils.ErrorsResponse
 //	@Failure		401			{object}	utils.ErrorsResponse
+//	@Failure		403			{object}	utils.ErrorsResponse
 //	@Failure		404			{object}	utils.ErrorsResponse
+//	@Failure		423			{object}	utils.ErrorsResponse
+//	@Failure		429			{object}	utils.ErrorsResponse
 //	@Failure		500			{object}	utils.ErrorsResponse
 //	@Router			/auth/jwt [post]
 func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) {
@@ -61,7 +77,7 @@ func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.ctrl.Authenticate(r.Context(), &d, req)
+	res, challenge, err := h.ctrl.Authenticate(r.Context(), &d, req)
 	if err != nil {
 		if errors.Is(err, ctrl.ErrNotFound) {
 			utils.ErrResponse(w, http.StatusNotFound, err)
@@ -73,24 +89,177 @@ func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, ctrl.ErrEmailNotVerified) {
+			utils.ErrResponse(w, http.StatusForbidden, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrAccountLocked) {
+			utils.ErrResponse(w, http.StatusLocked, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrTooManyRequests) {
+			utils.ErrResponse(w, http.StatusTooManyRequests, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	if challenge != nil {
+		utils.SuccessResponse(w, http.StatusAccepted, challenge)
+		return
+	}
+
+	utils.TokensResponse(w, r, res)
+}
+
+// sendLoginCode godoc
+//
+//	@Summary		Request a login code
+//	@Description	Verify reCAPTCHA and credentials, then email a one-time login code. Failed attempts count towards the same delays and lockouts as /auth/jwt
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Real-IP	header	string					false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent	header	string					true	"Client User-Agent"
+//	@Param			body		body	dto.LoginCodeRequest	true	"Login credentials"
+//	@Success		200			"Login code sent"
+//	@Failure		400			{object}	utils.ErrorsResponse
+//	@Failure		401			{object}	utils.ErrorsResponse
+//	@Failure		403			{object}	utils.ErrorsResponse
+//	@Failure		404			{object}	utils.ErrorsResponse
+//	@Failure		423			{object}	utils.ErrorsResponse
+//	@Failure		429			{object}	utils.ErrorsResponse
+//	@Failure		500			{object}	utils.ErrorsResponse
+//	@Router			/auth/code [post]
+func (h *Handler) sendLoginCode(w http.ResponseWriter, r *http.Request) {
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	req := &dto.LoginCodeRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	valid, err := h.au.VerifyRecaptcha(r.Context(), req.Token, captcha.CodeAuth)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	if !valid {
+		utils.ErrResponse(w, http.StatusUnauthorized, captcha.ErrValidationFailed)
+		return
+	}
+
+	err = h.ctrl.SendLoginCode(r.Context(), d.IP, req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrEmailNotVerified) {
+			utils.ErrResponse(w, http.StatusForbidden, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrAccountLocked) {
+			utils.ErrResponse(w, http.StatusLocked, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrTooManyRequests) {
+			utils.ErrResponse(w, http.StatusTooManyRequests, err)
+			return
+		}
+
 		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
 		return
 	}
 
-	utils.SetAuthCookies(w, res.Access, res.Refresh)
 	utils.StatusResponse(w, http.StatusOK)
 }
 
+// checkLoginCode godoc
+//
+//	@Summary		Authenticate using a login code
+//	@Description	Exchange a one-time login code for JWT cookies. Users with 2FA enabled get a challenge for /auth/2fa/verify instead
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Real-IP	header		string						false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent	header		string						true	"Client User-Agent"
+//	@Param			X-Device-ID	header		string						false	"Stable device ID, the signed device cookie is used otherwise"
+//	@Param			X-Auth-Mode	header		string						false	"Set to 'token' to receive tokens in the body"
+//	@Param			body		body		dto.CheckLoginCodeRequest	true	"Email and login code"
+//	@Success		200			{object}	dto.TokenPair				"Successfully authenticated (sets cookies unless X-Auth-Mode: token)"
+//	@Success		202			{object}	dto.TwoFactorChallenge		"Second factor required"
+//	@Failure		400			{object}	utils.ErrorsResponse
+//	@Failure		401			{object}	utils.ErrorsResponse
+//	@Failure		404			{object}	utils.ErrorsResponse
+//	@Failure		500			{object}	utils.ErrorsResponse
+//	@Router			/auth/code/check [post]
+func (h *Handler) checkLoginCode(w http.ResponseWriter, r *http.Request) {
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	req := &dto.CheckLoginCodeRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, challenge, err := h.ctrl.CheckLoginCode(r.Context(), &d, req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrCodeIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	if challenge != nil {
+		utils.SuccessResponse(w, http.StatusAccepted, challenge)
+		return
+	}
+
+	utils.TokensResponse(w, r, res)
+}
+
 // refresh godoc
 //
 //	@Summary		Refresh JWT tokens
-//	@Description	Validate refresh token from cookie and issue new tokens
+//	@Description	Validate refresh token from cookie and issue new tokens. The presented token is rotated; presenting it again revokes its device session. With X-Auth-Mode: token the refresh token is read from and the pair returned in the body
 //	@Tags			Authentication
 //	@Accept			json
 //	@Produce		json
-//	@Param			X-Real-IP	header	string	true	"Client real IP address"
-//	@Param			User-Agent	header	string	true	"Client User-Agent"
-//	@Success		200			"Successfully refreshed tokens (sets cookies)"
+//	@Param			X-Real-IP	header		string				false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent	header		string				true	"Client User-Agent"
+//	@Param			X-Device-ID	header		string				false	"Stable device ID, the signed device cookie is used otherwise"
+//	@Param			X-Auth-Mode	header		string				false	"Set to 'token' to use the body instead of cookies"
+//	@Param			body		body		dto.RefreshRequest	false	"Refresh token (token mode only)"
+//	@Success		200			{object}	dto.TokenPair		"Successfully refreshed tokens (sets cookies unless X-Auth-Mode: token)"
 //	@Failure		400			{object}	utils.ErrorsResponse
 //	@Failure		401			{object}	utils.ErrorsResponse
 //	@Failure		404			{object}	utils.ErrorsResponse
@@ -103,23 +272,30 @@ func (h *Handler) refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cookie, err := r.Cookie(config.RefreshCookieName)
-	if err != nil {
-		zap.L().Debug("failed to get cookies", zap.Error(err))
-		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrDecodeRequest)
-		return
+	req := &dto.RefreshRequest{}
+	if utils.IsTokenMode(r) {
+		if ok = utils.ParseAndValidate(w, r, req); !ok {
+			return
+		}
+	} else {
+		cookie, err := r.Cookie(config.RefreshCookieName)
+		if err != nil {
+			zap.L().Debug("failed to get cookies", zap.Error(err))
+			utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrDecodeRequest)
+			return
+		}
+
+		req.Refresh = cookie.Value
 	}
 
-	res, err := h.ctrl.Refresh(
-		r.Context(), &d, &dto.RefreshRequest{
-			Refresh: cookie.Value,
-		},
-	)
+	res, err := h.ctrl.Refresh(r.Context(), &d, req)
 	if err != nil {
 		if errors.Is(err, ctrl.ErrNotFound) {
 			utils.ErrResponse(w, http.StatusNotFound, err)
 			return
-		} else if errors.Is(err, auth.ErrTokenRevoked) {
+		} else if errors.Is(err, auth.ErrTokenRevoked) ||
+			errors.Is(err, auth.ErrTokenReused) ||
+			errors.Is(err, auth.ErrSessionExpired) {
 			utils.ErrResponse(w, http.StatusUnauthorized, err)
 			return
 		}
@@ -128,22 +304,95 @@ func (h *Handler) refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.SetAuthCookies(w, res.Access, res.Refresh)
-	utils.StatusResponse(w, http.StatusOK)
+	utils.TokensResponse(w, r, res)
 }
 
 // logout godoc
 //
 //	@Summary		Logout user
-//	@Description	Revoke refresh token, clear JWT cookies
+//	@Description	Revoke the refresh token of the current device and the access token in use, clear JWT cookies. Other devices stay signed in
 //	@Tags			Authentication
 //	@Produce		json
 //	@Param			Authorization	header	string	true	"Authorization token"
+//	@Param			X-Real-IP		header	string	false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent		header	string	true	"Client User-Agent"
+//	@Param			X-Device-ID		header	string	false	"Stable device ID, the signed device cookie is used otherwise"
 //	@Success		200				"Revoked refresh token, cleared cookies"
-//	@Failure		404				{object}	utils.ErrorsResponse	"session not found"
+//	@Failure		400				{object}	utils.ErrorsResponse	"no device info"
 //	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
 //	@Router			/auth/logout [post]
 func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(config.ClaimsKey).(jwt.Claims)
+	if !ok {
+		zap.L().Error(
+			"failed to get claims from context",
+			zap.Any("claims", r.Context().Value(config.ClaimsKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	device := auth.GenerateDevice(&d)
+	if err := h.ctrl.Logout(r.Context(), claims, device.ID); err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.ClearAuthCookies(w)
+	utils.StatusResponse(w, http.StatusOK)
+}
+
+// logoutAll godoc
+//
+//	@Summary		Logout everywhere
+//	@Description	Revoke refresh and access tokens of every device and clear JWT cookies
+//	@Tags			Authentication
+//	@Produce		json
+//	@Param			Authorization	header	string	true	"Authorization token"
+//	@Success		200				"Revoked refresh tokens, cleared cookies"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/auth/logout/all [post]
+func (h *Handler) logoutAll(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if !ok {
+		zap.L().Error(
+			hdl.ErrFailedToGetUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	if err := h.ctrl.LogoutAll(r.Context(), uid); err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.ClearAuthCookies(w)
+	utils.StatusResponse(w, http.StatusOK)
+}
+
+// logoutOthers godoc
+//
+//	@Summary		Logout other devices
+//	@Description	Revoke refresh tokens of every device except the current one. Access tokens of all devices are revoked, so the current device has to refresh
+//	@Tags			Authentication
+//	@Produce		json
+//	@Param			Authorization	header	string	true	"Authorization token"
+//	@Param			X-Real-IP		header	string	false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent		header	string	true	"Client User-Agent"
+//	@Param			X-Device-ID		header	string	false	"Stable device ID, the signed device cookie is used otherwise"
+//	@Success		200				"Revoked refresh tokens of other devices"
+//	@Failure		400				{object}	utils.ErrorsResponse	"no device info"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/auth/logout/others [post]
+func (h *Handler) logoutOthers(w http.ResponseWriter, r *http.Request) {
 	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
 	if !ok {
 		zap.L().Error(
@@ -154,39 +403,178 @@ func (h *Handler) logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.ctrl.Logout(r.Context(), uid)
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	device := auth.GenerateDevice(&d)
+	if err := h.ctrl.LogoutOthers(r.Context(), uid, device.ID); err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusOK)
+}
+
+// sendForgotPasswordEmail godoc
+//
+//	@Summary		Request password recovery
+//	@Description	Verify reCAPTCHA, then email a single-use recovery code if the account exists
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	dto.SendForgotPasswordEmail	true	"Account email"
+//	@Success		200		"Recovery email sent (if the account exists)"
+//	@Failure		400		{object}	utils.ErrorsResponse
+//	@Failure		401		{object}	utils.ErrorsResponse
+//	@Failure		500		{object}	utils.ErrorsResponse
+//	@Router			/auth/recovery [post]
+func (h *Handler) sendForgotPasswordEmail(w http.ResponseWriter, r *http.Request) {
+	req := &dto.SendForgotPasswordEmail{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	valid, err := h.au.VerifyRecaptcha(r.Context(), req.Token, captcha.ForgotPass)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	if !valid {
+		utils.ErrResponse(w, http.StatusUnauthorized, captcha.ErrValidationFailed)
+		return
+	}
+
+	if err = h.ctrl.SendForgotPasswordEmail(r.Context(), req.Email); err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusOK)
+}
+
+// checkForgotPasswordEmail godoc
+//
+//	@Summary		Confirm password recovery
+//	@Description	Check the recovery code, set a new password and revoke all refresh tokens
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	dto.CheckForgotPasswordEmailRequest	true	"Recovery code and new password"
+//	@Success		200		"Password updated"
+//	@Failure		400		{object}	utils.ErrorsResponse
+//	@Failure		401		{object}	utils.ErrorsResponse
+//	@Failure		404		{object}	utils.ErrorsResponse
+//	@Failure		500		{object}	utils.ErrorsResponse
+//	@Router			/auth/recovery [put]
+func (h *Handler) checkForgotPasswordEmail(w http.ResponseWriter, r *http.Request) {
+	req := &dto.CheckForgotPasswordEmailRequest{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	err := h.ctrl.CheckForgotPasswordEmail(r.Context(), req)
 	if err != nil {
+		if errors.Is(err, ctrl.ErrCodeIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
 		if errors.Is(err, ctrl.ErrNotFound) {
 			utils.ErrResponse(w, http.StatusNotFound, err)
 			return
 		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusOK)
+}
+
+// sendVerificationEmail godoc
+//
+//	@Summary		Resend email verification
+//	@Description	Verify reCAPTCHA, then email a new verification code if the account exists and is not verified
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	dto.SendVerificationEmailRequest	true	"Account email"
+//	@Success		200		"Verification email sent (if the account exists)"
+//	@Failure		400		{object}	utils.ErrorsResponse
+//	@Failure		401		{object}	utils.ErrorsResponse
+//	@Failure		429		{object}	utils.ErrorsResponse
+//	@Failure		500		{object}	utils.ErrorsResponse
+//	@Router			/auth/email/resend [post]
+func (h *Handler) sendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	req := &dto.SendVerificationEmailRequest{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	valid, err := h.au.VerifyRecaptcha(r.Context(), req.Token, captcha.VerifyEmail)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	if !valid {
+		utils.ErrResponse(w, http.StatusUnauthorized, captcha.ErrValidationFailed)
+		return
+	}
+
+	err = h.ctrl.SendVerificationEmail(r.Context(), req.Email)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrTooManyRequests) {
+			utils.ErrResponse(w, http.StatusTooManyRequests, err)
+			return
+		}
+
 		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
 		return
 	}
 
-	http.SetCookie(
-		w, &http.Cookie{
-			Name:     config.AccessCookieName,
-			Value:    "",
-			MaxAge:   -1,
-			HttpOnly: true,
-			Secure:   true,
-			Path:     "/",
-			SameSite: http.SameSiteStrictMode,
-		},
-	)
+	utils.StatusResponse(w, http.StatusOK)
+}
+
+// verifyEmail godoc
+//
+//	@Summary		Verify email
+//	@Description	Check the verification code and mark the user's email as verified
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body	dto.VerifyEmailRequest	true	"Verification code"
+//	@Success		200		"Email verified"
+//	@Failure		400		{object}	utils.ErrorsResponse
+//	@Failure		401		{object}	utils.ErrorsResponse
+//	@Failure		404		{object}	utils.ErrorsResponse
+//	@Failure		500		{object}	utils.ErrorsResponse
+//	@Router			/auth/email/verify [post]
+func (h *Handler) verifyEmail(w http.ResponseWriter, r *http.Request) {
+	req := &dto.VerifyEmailRequest{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	err := h.ctrl.VerifyEmail(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrCodeIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
 
-	http.SetCookie(
-		w, &http.Cookie{
-			Name:     config.RefreshCookieName,
-			Value:    "",
-			MaxAge:   -1,
-			HttpOnly: true,
-			Secure:   true,
-			Path:     "/",
-			SameSite: http.SameSiteStrictMode,
-		},
-	)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
 
 	utils.StatusResponse(w, http.StatusOK)
 }
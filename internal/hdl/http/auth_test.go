@@ -6,6 +6,7 @@ import (
 	"errors"
 	"github.com/JMURv/golang-clean-template/internal/auth"
 	"github.com/JMURv/golang-clean-template/internal/auth/captcha"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
 	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/ctrl"
 	"github.com/JMURv/golang-clean-template/internal/dto"
@@ -20,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestHandler_Authenticate(t *testing.T) {
@@ -30,7 +32,7 @@ func TestHandler_Authenticate(t *testing.T) {
 	testErr := errors.New("testErr")
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	tests := []struct {
 		name       string
@@ -170,7 +172,7 @@ func TestHandler_Authenticate(t *testing.T) {
 						Password: "password",
 						Token:    "token",
 					},
-				).Return(nil, ctrl.ErrNotFound)
+				).Return(nil, nil, ctrl.ErrNotFound)
 			},
 		},
 		{
@@ -199,7 +201,76 @@ func TestHandler_Authenticate(t *testing.T) {
 						Password: "password",
 						Token:    "token",
 					},
-				).Return(nil, auth.ErrInvalidCredentials)
+				).Return(nil, nil, auth.ErrInvalidCredentials)
+			},
+		},
+		{
+			name:   "ErrEmailNotVerified",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrEmailNotVerified.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				mctrl.EXPECT().Authenticate(
+					gomock.Any(), &dto.DeviceRequest{
+						IP: "0.0.0.0",
+						UA: "user-agent",
+					}, &dto.EmailAndPasswordRequest{
+						Email:    "example@mail.com",
+						Password: "password",
+						Token:    "token",
+					},
+				).Return(nil, nil, ctrl.ErrEmailNotVerified)
+			},
+		},
+		{
+			name:   "ErrAccountLocked",
+			method: http.MethodPost,
+			status: http.StatusLocked,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrAccountLocked.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				mctrl.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil, ctrl.ErrAccountLocked)
+			},
+		},
+		{
+			name:   "ErrTooManyRequests",
+			method: http.MethodPost,
+			status: http.StatusTooManyRequests,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrTooManyRequests.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				mctrl.EXPECT().Authenticate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil, ctrl.ErrTooManyRequests)
 			},
 		},
 		{
@@ -228,7 +299,7 @@ func TestHandler_Authenticate(t *testing.T) {
 						Password: "password",
 						Token:    "token",
 					},
-				).Return(nil, testErr)
+				).Return(nil, nil, testErr)
 			},
 		},
 		{
@@ -254,7 +325,38 @@ func TestHandler_Authenticate(t *testing.T) {
 						Password: "password",
 						Token:    "token",
 					},
-				).Return(&dto.TokenPair{Access: "token", Refresh: "token"}, nil)
+				).Return(&dto.TokenPair{Access: "token", Refresh: "token"}, nil, nil)
+			},
+		},
+		{
+			name:   "TwoFactorRequired",
+			method: http.MethodPost,
+			status: http.StatusAccepted,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Empty(t, r.Header().Get("Set-Cookie"))
+
+				res := &dto.TwoFactorChallenge{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, "challenge", res.Challenge)
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+				mctrl.EXPECT().Authenticate(
+					gomock.Any(), &dto.DeviceRequest{
+						IP: "0.0.0.0",
+						UA: "user-agent",
+					}, &dto.EmailAndPasswordRequest{
+						Email:    "example@mail.com",
+						Password: "password",
+						Token:    "token",
+					},
+				).Return(nil, &dto.TwoFactorChallenge{Challenge: "challenge"}, nil)
 			},
 		},
 	}
@@ -296,11 +398,13 @@ func TestHandler_Refresh(t *testing.T) {
 	testErr := errors.New("testErr")
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	tests := []struct {
 		name       string
 		passDevice bool
+		tokenMode  bool
+		body       any
 		cookie     *http.Cookie
 		status     int
 		expect     func()
@@ -377,6 +481,29 @@ func TestHandler_Refresh(t *testing.T) {
 				).Return(nil, auth.ErrTokenRevoked)
 			},
 		},
+		{
+			name:   "ErrSessionExpired",
+			status: http.StatusUnauthorized,
+			cookie: &http.Cookie{Name: config.RefreshCookieName, Value: "refresh_token"},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, auth.ErrSessionExpired.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().Refresh(
+					gomock.Any(),
+					&dto.DeviceRequest{
+						IP: "0.0.0.0",
+						UA: "user-agent",
+					},
+					&dto.RefreshRequest{
+						Refresh: "refresh_token",
+					},
+				).Return(nil, auth.ErrSessionExpired)
+			},
+		},
 		{
 			name:   "StatusInternalServerError",
 			status: http.StatusInternalServerError,
@@ -406,6 +533,83 @@ func TestHandler_Refresh(t *testing.T) {
 			cookie: &http.Cookie{Name: config.RefreshCookieName, Value: "refresh_token"},
 			assertions: func(r *httptest.ResponseRecorder) {
 				assert.Contains(t, r.Header().Get("Set-Cookie"), config.AccessCookieName)
+				for _, c := range r.Result().Cookies() {
+					if c.Name == config.RefreshCookieName {
+						assert.True(t, c.Expires.IsZero())
+					}
+				}
+			},
+			expect: func() {
+				mctrl.EXPECT().Refresh(
+					gomock.Any(),
+					&dto.DeviceRequest{
+						IP: "0.0.0.0",
+						UA: "user-agent",
+					},
+					&dto.RefreshRequest{
+						Refresh: "refresh_token",
+					},
+				).Return(&dto.TokenPair{
+					Access:  "new_access",
+					Refresh: "new_refresh",
+				}, nil)
+			},
+		},
+		{
+			name:   "Success_Remember",
+			status: http.StatusOK,
+			cookie: &http.Cookie{Name: config.RefreshCookieName, Value: "refresh_token"},
+			assertions: func(r *httptest.ResponseRecorder) {
+				for _, c := range r.Result().Cookies() {
+					if c.Name == config.RefreshCookieName {
+						assert.False(t, c.Expires.IsZero())
+					}
+				}
+			},
+			expect: func() {
+				mctrl.EXPECT().Refresh(
+					gomock.Any(),
+					&dto.DeviceRequest{
+						IP: "0.0.0.0",
+						UA: "user-agent",
+					},
+					&dto.RefreshRequest{
+						Refresh: "refresh_token",
+					},
+				).Return(&dto.TokenPair{
+					Access:    "new_access",
+					Refresh:   "new_refresh",
+					ExpiresAt: time.Now().Add(time.Hour),
+					Remember:  true,
+				}, nil)
+			},
+		},
+		{
+			name:      "TokenMode_ValidationError",
+			tokenMode: true,
+			body:      &dto.RefreshRequest{},
+			status:    http.StatusBadRequest,
+			cookie:    &http.Cookie{Name: config.RefreshCookieName, Value: "refresh_token"},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.NotEmpty(t, res.Errors)
+			},
+			expect: func() {},
+		},
+		{
+			name:      "TokenMode_Success",
+			tokenMode: true,
+			body:      &dto.RefreshRequest{Refresh: "refresh_token"},
+			status:    http.StatusOK,
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Empty(t, r.Header().Get("Set-Cookie"))
+
+				res := &dto.TokenPair{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, &dto.TokenPair{Access: "new_access", Refresh: "new_refresh"}, res)
 			},
 			expect: func() {
 				mctrl.EXPECT().Refresh(
@@ -429,6 +633,13 @@ func TestHandler_Refresh(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.expect()
 			req := httptest.NewRequest(http.MethodPost, uri, nil)
+			if tt.tokenMode {
+				payload, err := json.Marshal(tt.body)
+				require.NoError(t, err)
+
+				req = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(payload))
+				req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+			}
 
 			if !tt.passDevice {
 				ctx := context.WithValue(req.Context(), config.IpKey, "0.0.0.0")
@@ -459,21 +670,23 @@ func TestHandler_Logout(t *testing.T) {
 	defer mock.Finish()
 
 	testErr := errors.New("testErr")
-	testUUID := uuid.New()
+	testClaims := jwt.Claims{UID: uuid.New()}
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
+	testDevice := auth.GenerateDevice(&dto.DeviceRequest{IP: "127.0.0.1", UA: "test-agent"})
 
 	tests := []struct {
 		name       string
-		uid        any
+		claims     any
+		noDevice   bool
 		status     int
 		expect     func()
 		assertions func(r *httptest.ResponseRecorder)
 	}{
 		{
-			name:   "ErrFailedToGetUUID",
-			uid:    "invalid-uuid", // Wrong type
+			name:   "ErrFailedToGetClaims",
+			claims: "invalid-claims", // Wrong type
 			status: http.StatusInternalServerError,
 			assertions: func(r *httptest.ResponseRecorder) {
 				res := &utils.ErrorsResponse{}
@@ -484,22 +697,21 @@ func TestHandler_Logout(t *testing.T) {
 			expect: func() {},
 		},
 		{
-			name:   "StatusNotFound",
-			uid:    testUUID,
-			status: http.StatusNotFound,
+			name:     "ErrNoDeviceInfo",
+			claims:   testClaims,
+			noDevice: true,
+			status:   http.StatusBadRequest,
 			assertions: func(r *httptest.ResponseRecorder) {
 				res := &utils.ErrorsResponse{}
 				err := json.NewDecoder(r.Result().Body).Decode(res)
 				assert.Nil(t, err)
-				assert.Equal(t, ctrl.ErrNotFound.Error(), res.Errors[0])
-			},
-			expect: func() {
-				mctrl.EXPECT().Logout(gomock.Any(), testUUID).Return(ctrl.ErrNotFound)
+				assert.Equal(t, ErrNoDeviceInfo.Error(), res.Errors[0])
 			},
+			expect: func() {},
 		},
 		{
 			name:   "StatusInternalServerError",
-			uid:    testUUID,
+			claims: testClaims,
 			status: http.StatusInternalServerError,
 			assertions: func(r *httptest.ResponseRecorder) {
 				res := &utils.ErrorsResponse{}
@@ -508,12 +720,12 @@ func TestHandler_Logout(t *testing.T) {
 				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
 			},
 			expect: func() {
-				mctrl.EXPECT().Logout(gomock.Any(), testUUID).Return(testErr)
+				mctrl.EXPECT().Logout(gomock.Any(), testClaims, testDevice.ID).Return(testErr)
 			},
 		},
 		{
 			name:   "Success",
-			uid:    testUUID,
+			claims: testClaims,
 			status: http.StatusOK,
 			assertions: func(r *httptest.ResponseRecorder) {
 				cookies := r.Result().Cookies()
@@ -534,7 +746,7 @@ func TestHandler_Logout(t *testing.T) {
 				assert.Equal(t, -1, refreshCookie.MaxAge)
 			},
 			expect: func() {
-				mctrl.EXPECT().Logout(gomock.Any(), testUUID).Return(nil)
+				mctrl.EXPECT().Logout(gomock.Any(), testClaims, testDevice.ID).Return(nil)
 			},
 		},
 	}
@@ -545,7 +757,11 @@ func TestHandler_Logout(t *testing.T) {
 
 			req := httptest.NewRequest(http.MethodPost, uri, nil)
 
-			ctx := context.WithValue(req.Context(), config.UidKey, tt.uid)
+			ctx := context.WithValue(req.Context(), config.ClaimsKey, tt.claims)
+			if !tt.noDevice {
+				ctx = context.WithValue(ctx, config.IpKey, "127.0.0.1")
+				ctx = context.WithValue(ctx, config.UaKey, "test-agent")
+			}
 			req = req.WithContext(ctx)
 
 			w := httptest.NewRecorder()
@@ -560,3 +776,1100 @@ func TestHandler_Logout(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_LogoutAll(t *testing.T) {
+	const uri = "/auth/logout/all"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	testUUID := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name    string
+		status  int
+		cookies int
+		expect  func()
+	}{
+		{
+			name:   "StatusInternalServerError",
+			status: http.StatusInternalServerError,
+			expect: func() {
+				mctrl.EXPECT().LogoutAll(gomock.Any(), testUUID).Return(testErr)
+			},
+		},
+		{
+			name:    "Success",
+			status:  http.StatusOK,
+			cookies: 2,
+			expect: func() {
+				mctrl.EXPECT().LogoutAll(gomock.Any(), testUUID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			req := httptest.NewRequest(http.MethodPost, uri, nil)
+			req = req.WithContext(context.WithValue(req.Context(), config.UidKey, testUUID))
+
+			w := httptest.NewRecorder()
+			h.logoutAll(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+			assert.Len(t, w.Result().Cookies(), tt.cookies)
+		})
+	}
+}
+
+func TestHandler_LogoutOthers(t *testing.T) {
+	const uri = "/auth/logout/others"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	testUUID := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+	testDevice := auth.GenerateDevice(&dto.DeviceRequest{IP: "127.0.0.1", UA: "test-agent"})
+
+	tests := []struct {
+		name     string
+		noDevice bool
+		status   int
+		expect   func()
+	}{
+		{
+			name:     "ErrNoDeviceInfo",
+			noDevice: true,
+			status:   http.StatusBadRequest,
+			expect:   func() {},
+		},
+		{
+			name:   "StatusInternalServerError",
+			status: http.StatusInternalServerError,
+			expect: func() {
+				mctrl.EXPECT().LogoutOthers(gomock.Any(), testUUID, testDevice.ID).Return(testErr)
+			},
+		},
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().LogoutOthers(gomock.Any(), testUUID, testDevice.ID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			req := httptest.NewRequest(http.MethodPost, uri, nil)
+			ctx := context.WithValue(req.Context(), config.UidKey, testUUID)
+			if !tt.noDevice {
+				ctx = context.WithValue(ctx, config.IpKey, "127.0.0.1")
+				ctx = context.WithValue(ctx, config.UaKey, "test-agent")
+			}
+			req = req.WithContext(ctx)
+
+			w := httptest.NewRecorder()
+			h.logoutOthers(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+			assert.Empty(t, w.Result().Cookies())
+		})
+	}
+}
+
+func TestHandler_SendForgotPasswordEmail(t *testing.T) {
+	const uri = "/auth/recovery"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name       string
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ErrDecodeRequest",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"email": 0,
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrDecodeRequest.Error(), res.Errors[0])
+			},
+			expect: func() {},
+		},
+		{
+			name:   "ErrMissingEmail",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"email": "",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Contains(t, res.Errors[0], "required rule")
+			},
+			expect: func() {},
+		},
+		{
+			name:   "VerifyRecaptcha failure",
+			status: http.StatusInternalServerError,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.ForgotPass).Return(false, testErr)
+			},
+		},
+		{
+			name:   "ErrValidationFailed",
+			status: http.StatusUnauthorized,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, captcha.ErrValidationFailed.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.ForgotPass).Return(false, nil)
+			},
+		},
+		{
+			name:   "ErrInternal",
+			status: http.StatusInternalServerError,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.ForgotPass).Return(true, nil)
+				mctrl.EXPECT().SendForgotPasswordEmail(gomock.Any(), "example@mail.com").Return(testErr)
+			},
+		},
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.ForgotPass).Return(true, nil)
+				mctrl.EXPECT().SendForgotPasswordEmail(gomock.Any(), "example@mail.com").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+
+				w := httptest.NewRecorder()
+				h.sendForgotPasswordEmail(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_CheckForgotPasswordEmail(t *testing.T) {
+	const uri = "/auth/recovery"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	uid := uuid.New()
+	validPayload := map[string]any{
+		"password": "new-password",
+		"uidb64":   uid.String(),
+		"token":    123456,
+	}
+	validReq := &dto.CheckForgotPasswordEmailRequest{
+		Password: "new-password",
+		ID:       uid,
+		Code:     123456,
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ErrDecodeRequest",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"password": 0,
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrDecodeRequest.Error(), res.Errors[0])
+			},
+			expect: func() {},
+		},
+		{
+			name:   "ErrMissingPassword",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"password": "",
+				"uidb64":   uid.String(),
+				"token":    123456,
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Contains(t, res.Errors[0], "required rule")
+			},
+			expect: func() {},
+		},
+		{
+			name:    "ErrCodeIsNotValid",
+			status:  http.StatusUnauthorized,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrCodeIsNotValid.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().CheckForgotPasswordEmail(gomock.Any(), validReq).Return(ctrl.ErrCodeIsNotValid)
+			},
+		},
+		{
+			name:    "ErrNotFound",
+			status:  http.StatusNotFound,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrNotFound.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().CheckForgotPasswordEmail(gomock.Any(), validReq).Return(ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:    "ErrInternal",
+			status:  http.StatusInternalServerError,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().CheckForgotPasswordEmail(gomock.Any(), validReq).Return(testErr)
+			},
+		},
+		{
+			name:       "Success",
+			status:     http.StatusOK,
+			payload:    validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {},
+			expect: func() {
+				mctrl.EXPECT().CheckForgotPasswordEmail(gomock.Any(), validReq).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPut, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+
+				w := httptest.NewRecorder()
+				h.checkForgotPasswordEmail(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_SendVerificationEmail(t *testing.T) {
+	const uri = "/auth/email/resend"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name       string
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ErrDecodeRequest",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"email": 0,
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrDecodeRequest.Error(), res.Errors[0])
+			},
+			expect: func() {},
+		},
+		{
+			name:   "ErrMissingEmail",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"email": "",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Contains(t, res.Errors[0], "required rule")
+			},
+			expect: func() {},
+		},
+		{
+			name:   "VerifyRecaptcha failure",
+			status: http.StatusInternalServerError,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.VerifyEmail).Return(false, testErr)
+			},
+		},
+		{
+			name:   "ErrValidationFailed",
+			status: http.StatusUnauthorized,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, captcha.ErrValidationFailed.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.VerifyEmail).Return(false, nil)
+			},
+		},
+		{
+			name:   "ErrTooManyRequests",
+			status: http.StatusTooManyRequests,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrTooManyRequests.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.VerifyEmail).Return(true, nil)
+				mctrl.EXPECT().SendVerificationEmail(gomock.Any(), "example@mail.com").Return(ctrl.ErrTooManyRequests)
+			},
+		},
+		{
+			name:   "ErrInternal",
+			status: http.StatusInternalServerError,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.VerifyEmail).Return(true, nil)
+				mctrl.EXPECT().SendVerificationEmail(gomock.Any(), "example@mail.com").Return(testErr)
+			},
+		},
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			payload: map[string]any{
+				"email": "example@mail.com",
+				"token": "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.VerifyEmail).Return(true, nil)
+				mctrl.EXPECT().SendVerificationEmail(gomock.Any(), "example@mail.com").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+
+				w := httptest.NewRecorder()
+				h.sendVerificationEmail(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_VerifyEmail(t *testing.T) {
+	const uri = "/auth/email/verify"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	uid := uuid.New()
+	validPayload := map[string]any{
+		"uidb64": uid.String(),
+		"token":  123456,
+	}
+	validReq := &dto.VerifyEmailRequest{
+		ID:   uid,
+		Code: 123456,
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ErrDecodeRequest",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"token": "code",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrDecodeRequest.Error(), res.Errors[0])
+			},
+			expect: func() {},
+		},
+		{
+			name:   "ErrMissingCode",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"uidb64": uid.String(),
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Contains(t, res.Errors[0], "required rule")
+			},
+			expect: func() {},
+		},
+		{
+			name:    "ErrCodeIsNotValid",
+			status:  http.StatusUnauthorized,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrCodeIsNotValid.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().VerifyEmail(gomock.Any(), validReq).Return(ctrl.ErrCodeIsNotValid)
+			},
+		},
+		{
+			name:    "ErrNotFound",
+			status:  http.StatusNotFound,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrNotFound.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().VerifyEmail(gomock.Any(), validReq).Return(ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:    "ErrInternal",
+			status:  http.StatusInternalServerError,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().VerifyEmail(gomock.Any(), validReq).Return(testErr)
+			},
+		},
+		{
+			name:       "Success",
+			status:     http.StatusOK,
+			payload:    validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {},
+			expect: func() {
+				mctrl.EXPECT().VerifyEmail(gomock.Any(), validReq).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+
+				w := httptest.NewRecorder()
+				h.verifyEmail(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_SendLoginCode(t *testing.T) {
+	const uri = "/auth/code"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	validReq := &dto.LoginCodeRequest{
+		Email:    "example@mail.com",
+		Password: "password",
+		Token:    "token",
+	}
+
+	tests := []struct {
+		name       string
+		passDevice bool
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "ErrNoDeviceInfo",
+			passDevice: true,
+			status:     http.StatusBadRequest,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ErrNoDeviceInfo.Error(), res.Errors[0])
+			},
+			expect: func() {},
+		},
+		{
+			name:   "ErrDecodeRequest",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"email": 0,
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrDecodeRequest.Error(), res.Errors[0])
+			},
+			expect: func() {},
+		},
+		{
+			name:   "ErrMissingEmail",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"email":    "",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Contains(t, res.Errors[0], "required rule")
+			},
+			expect: func() {},
+		},
+		{
+			name:   "VerifyRecaptcha failure",
+			status: http.StatusInternalServerError,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(false, testErr)
+			},
+		},
+		{
+			name:   "ErrValidationFailed",
+			status: http.StatusUnauthorized,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, captcha.ErrValidationFailed.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(false, nil)
+			},
+		},
+		{
+			name:   "ErrNotFound",
+			status: http.StatusNotFound,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrNotFound.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(true, nil)
+				mctrl.EXPECT().SendLoginCode(gomock.Any(), "0.0.0.0", validReq).Return(ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:   "ErrInvalidCredentials",
+			status: http.StatusUnauthorized,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, auth.ErrInvalidCredentials.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(true, nil)
+				mctrl.EXPECT().SendLoginCode(gomock.Any(), "0.0.0.0", validReq).Return(auth.ErrInvalidCredentials)
+			},
+		},
+		{
+			name:   "ErrEmailNotVerified",
+			status: http.StatusForbidden,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrEmailNotVerified.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(true, nil)
+				mctrl.EXPECT().SendLoginCode(gomock.Any(), "0.0.0.0", validReq).Return(ctrl.ErrEmailNotVerified)
+			},
+		},
+		{
+			name:   "ErrTooManyRequests",
+			status: http.StatusTooManyRequests,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrTooManyRequests.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(true, nil)
+				mctrl.EXPECT().SendLoginCode(gomock.Any(), "0.0.0.0", validReq).Return(ctrl.ErrTooManyRequests)
+			},
+		},
+		{
+			name:   "ErrInternal",
+			status: http.StatusInternalServerError,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(true, nil)
+				mctrl.EXPECT().SendLoginCode(gomock.Any(), "0.0.0.0", validReq).Return(testErr)
+			},
+		},
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			payload: map[string]any{
+				"email":    "example@mail.com",
+				"password": "password",
+				"token":    "token",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+			expect: func() {
+				mauth.EXPECT().VerifyRecaptcha(gomock.Any(), "token", captcha.CodeAuth).Return(true, nil)
+				mctrl.EXPECT().SendLoginCode(gomock.Any(), "0.0.0.0", validReq).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+				if !tt.passDevice {
+					ctx := context.WithValue(req.Context(), config.IpKey, "0.0.0.0")
+					ctx = context.WithValue(ctx, config.UaKey, "user-agent")
+					req = req.WithContext(ctx)
+				}
+
+				w := httptest.NewRecorder()
+				h.sendLoginCode(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_CheckLoginCode(t *testing.T) {
+	const uri = "/auth/code/check"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	validPayload := map[string]any{
+		"email": "example@mail.com",
+		"code":  123456,
+	}
+	validReq := &dto.CheckLoginCodeRequest{
+		Email: "example@mail.com",
+		Code:  123456,
+	}
+	device := &dto.DeviceRequest{
+		IP: "0.0.0.0",
+		UA: "user-agent",
+	}
+
+	tests := []struct {
+		name       string
+		passDevice bool
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "ErrNoDeviceInfo",
+			passDevice: true,
+			status:     http.StatusBadRequest,
+			payload:    validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ErrNoDeviceInfo.Error(), res.Errors[0])
+			},
+			expect: func() {},
+		},
+		{
+			name:   "ErrMissingCode",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"email": "example@mail.com",
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Contains(t, res.Errors[0], "required rule")
+			},
+			expect: func() {},
+		},
+		{
+			name:    "ErrCodeIsNotValid",
+			status:  http.StatusUnauthorized,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrCodeIsNotValid.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().CheckLoginCode(gomock.Any(), device, validReq).Return(nil, nil, ctrl.ErrCodeIsNotValid)
+			},
+		},
+		{
+			name:    "ErrNotFound",
+			status:  http.StatusNotFound,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, ctrl.ErrNotFound.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().CheckLoginCode(gomock.Any(), device, validReq).Return(nil, nil, ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:    "ErrInternal",
+			status:  http.StatusInternalServerError,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				err := json.NewDecoder(r.Result().Body).Decode(res)
+				assert.Nil(t, err)
+				assert.Equal(t, hdl.ErrInternal.Error(), res.Errors[0])
+			},
+			expect: func() {
+				mctrl.EXPECT().CheckLoginCode(gomock.Any(), device, validReq).Return(nil, nil, testErr)
+			},
+		},
+		{
+			name:    "Success",
+			status:  http.StatusOK,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Contains(t, r.Header().Get("Set-Cookie"), config.AccessCookieName)
+			},
+			expect: func() {
+				mctrl.EXPECT().
+					CheckLoginCode(gomock.Any(), device, validReq).
+					Return(&dto.TokenPair{Access: "token", Refresh: "token"}, nil, nil)
+			},
+		},
+		{
+			name:    "TwoFactorRequired",
+			status:  http.StatusAccepted,
+			payload: validPayload,
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Empty(t, r.Header().Get("Set-Cookie"))
+			},
+			expect: func() {
+				mctrl.EXPECT().
+					CheckLoginCode(gomock.Any(), device, validReq).
+					Return(nil, &dto.TwoFactorChallenge{Challenge: "challenge"}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+				if !tt.passDevice {
+					ctx := context.WithValue(req.Context(), config.IpKey, "0.0.0.0")
+					ctx = context.WithValue(ctx, config.UaKey, "user-agent")
+					req = req.WithContext(ctx)
+				}
+
+				w := httptest.NewRecorder()
+				h.checkLoginCode(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_RevokedAccessToken(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	claims := jwt.Claims{UID: uuid.New(), Type: jwt.TypeAccess}
+	mauth.EXPECT().ParseClaims(gomock.Any(), "token").Return(claims, nil)
+	mauth.EXPECT().CheckRevoked(gomock.Any(), claims).Return(auth.ErrTokenRevoked)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+	req.Header.Set("Authorization", "Bearer token")
+
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+
+	res := &utils.ErrorsResponse{}
+	assert.Nil(t, json.NewDecoder(w.Result().Body).Decode(res))
+	assert.Equal(t, auth.ErrTokenRevoked.Error(), res.Errors[0])
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/JMURv/golang-clean-template/internal/auth"
 	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/ctrl"
 	"github.com/JMURv/golang-clean-template/internal/dto"
@@ -17,10 +18,14 @@ import (
 )
 
 func (h *Handler) RegisterDeviceRoutes() {
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{})).Get("/device", h.listDevices)
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{})).Get("/device/{id}", h.getDevice)
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{})).Put("/device/{id}", h.updateDevice)
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{})).Delete("/device/{id}", h.deleteDevice)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Get("/device", h.listDevices)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Get("/device/{id}", h.getDevice)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Put("/device/{id}", h.updateDevice)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Delete("/device/{id}", h.deleteDevice)
+	h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermDevicesRead)).
+		Get("/users/{id}/devices", h.listUserDevices)
+	h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermDevicesDelete)).
+		Delete("/users/{id}/devices/{device}", h.deleteUserDevice)
 }
 
 // listDevices godoc
@@ -209,3 +214,78 @@ func (h *Handler) deleteDevice(w http.ResponseWriter, r *http.Request) {
 
 	utils.StatusResponse(w, http.StatusNoContent)
 }
+
+// listUserDevices godoc
+//
+//	@Summary		List devices of a user
+//	@Description	Retrieve devices registered by any user. Requires devices:read
+//	@Tags			Device
+//	@Produce		json
+//	@Param			id	path		string	true	"User UUID"
+//	@Success		200	{array}		[]models.Device
+//	@Failure		400	{object}	utils.ErrorsResponse	"invalid UUID"
+//	@Failure		401	{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403	{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500	{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/users/{id}/devices [get]
+func (h *Handler) listUserDevices(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zap.L().Debug(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	res, err := h.ctrl.ListDevices(r.Context(), uid)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// deleteUserDevice godoc
+//
+//	@Summary		Delete a device of a user
+//	@Description	Remove a device of any user and revoke its tokens. Requires devices:delete
+//	@Tags			Device
+//	@Produce		json
+//	@Param			id		path	string	true	"User UUID"
+//	@Param			device	path	string	true	"Device ID"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	utils.ErrorsResponse	"invalid UUID"
+//	@Failure		401		{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403		{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404		{object}	utils.ErrorsResponse	"device not found"
+//	@Failure		500		{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/users/{id}/devices/{device} [delete]
+func (h *Handler) deleteUserDevice(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zap.L().Debug(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	err = h.ctrl.DeleteDevice(r.Context(), uid, chi.URLParam(r, "device"))
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
@@ -32,7 +32,7 @@ func TestHandler_ListDevices(t *testing.T) {
 	testUUID := uuid.New()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	testDevices := []models.Device{
 		{
@@ -157,7 +157,7 @@ func TestHandler_GetDevice(t *testing.T) {
 	testDeviceID := uuid.New().String()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	testDevice := models.Device{
 		ID:        testDeviceID,
@@ -298,7 +298,7 @@ func TestHandler_UpdateDevice(t *testing.T) {
 	testDeviceID := uuid.New().String()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	validRequest := map[string]interface{}{
 		"name":      "Updated Device",
@@ -478,7 +478,7 @@ func TestHandler_DeleteDevice(t *testing.T) {
 	testDeviceID := uuid.New().String()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	tests := []struct {
 		name       string
@@ -9,9 +9,11 @@ import (
 
 	_ "github.com/JMURv/golang-clean-template/api/rest/v1"
 	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/ctrl"
 	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
 	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -19,33 +21,51 @@ import (
 )
 
 type Handler struct {
-	Router *chi.Mux
-	au     auth.Core
-	srv    *http.Server
-	ctrl   ctrl.AppCtrl
+	Router  *chi.Mux
+	conf    config.Config
+	au      auth.Core
+	srv     *http.Server
+	ctrl    ctrl.AppCtrl
+	limiter *ratelimit.Limiter
 }
 
-func New(au auth.Core, ctrl ctrl.AppCtrl) *Handler {
+func New(conf config.Config, au auth.Core, ctrl ctrl.AppCtrl, limiter *ratelimit.Limiter) *Handler {
+	proxies, err := mid.ParseTrustedProxies(conf.Server.TrustedProxies)
+	if err != nil {
+		zap.L().Fatal("failed to parse trusted proxies", zap.Error(err))
+	}
+
 	r := chi.NewRouter()
 	r.Use(
 		mid.Logger(zap.L()),
 		middleware.StripSlashes,
 		middleware.RequestID,
-		middleware.RealIP,
+		mid.RealIP(proxies),
 		middleware.Recoverer,
 		mid.Prometheus,
 		mid.OT,
+		mid.Locale,
+		mid.RateLimit(limiter, ratelimit.PolicyDefault),
 	)
 
 	hdl := &Handler{
-		Router: r,
-		au:     au,
-		ctrl:   ctrl,
+		Router:  r,
+		conf:    conf,
+		au:      au,
+		ctrl:    ctrl,
+		limiter: limiter,
 	}
 
 	hdl.RegisterAuthRoutes()
 	hdl.RegisterUserRoutes()
+	hdl.RegisterTwoFactorRoutes()
+	hdl.RegisterWebAuthnRoutes()
+	hdl.RegisterOAuthRoutes()
+	hdl.RegisterOIDCRoutes()
 	hdl.RegisterDeviceRoutes()
+	hdl.RegisterRoleRoutes()
+	hdl.RegisterServiceAccountRoutes()
+	hdl.RegisterWellKnownRoutes()
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 	r.Get(
 		"/health", func(w http.ResponseWriter, r *http.Request) {
@@ -55,6 +75,27 @@ func New(au auth.Core, ctrl ctrl.AppCtrl) *Handler {
 	return hdl
 }
 
+// withAuth applies mid.Auth with the configured token lookup order. API
+// keys are accepted on every authenticated route, and every caller is held
+// to the api rate limit policy.
+func (h *Handler) withAuth(opts mid.AuthOpts) func(http.Handler) http.Handler {
+	opts.Lookup = h.conf.Auth.TokenLookup
+	opts.APIKeys = h.ctrl
+	authenticate := mid.Auth(h.au, opts)
+	limit := h.withRateLimit(ratelimit.PolicyAPI)
+	return func(next http.Handler) http.Handler {
+		return authenticate(limit(next))
+	}
+}
+
+func (h *Handler) withRateLimit(policy string) func(http.Handler) http.Handler {
+	return mid.RateLimit(h.limiter, policy)
+}
+
+func (h *Handler) withDevice() func(http.Handler) http.Handler {
+	return mid.Device([]byte(h.conf.Auth.DeviceKey))
+}
+
 func (h *Handler) Start(port int) {
 	h.srv = &http.Server{
 		Handler:      h.Router,
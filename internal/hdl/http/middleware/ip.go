@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrustedProxies parses proxy CIDRs. Plain addresses are treated as
+// single-host prefixes.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	res := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+			}
+
+			addr = normalizeAddr(addr)
+			res = append(res, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", v, err)
+		}
+
+		res = append(res, prefix.Masked())
+	}
+
+	return res, nil
+}
+
+// RealIP replaces RemoteAddr with the normalized client address. Forwarding
+// headers are only honoured when the connection comes from a trusted proxy.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if addr, ok := clientIP(r, trusted); ok {
+					r.RemoteAddr = addr.String()
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// clientIP walks X-Forwarded-For from the right and returns the first hop
+// that is not a trusted proxy. X-Real-IP is used when a trusted proxy sends
+// no X-Forwarded-For.
+func clientIP(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	peer, ok := parseAddr(r.RemoteAddr)
+	if !ok || !isTrusted(peer, trusted) {
+		return peer, ok
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, ok := parseAddr(strings.TrimSpace(hops[i]))
+			if !ok {
+				return peer, true
+			}
+
+			if !isTrusted(addr, trusted) {
+				return addr, true
+			}
+		}
+
+		return peer, true
+	}
+
+	if addr, ok := parseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ok {
+		return addr, true
+	}
+
+	return peer, true
+}
+
+// parseAddr accepts an address with or without a port.
+func parseAddr(s string) (netip.Addr, bool) {
+	if ap, err := netip.ParseAddrPort(s); err == nil {
+		return normalizeAddr(ap.Addr()), true
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return normalizeAddr(addr), true
+}
+
+// normalizeAddr maps IPv4-mapped IPv6 addresses to IPv4 and drops zones, so
+// one client is always stored the same way.
+func normalizeAddr(addr netip.Addr) netip.Addr {
+	return addr.Unmap().WithZone("")
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	res, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.0.2.1 ", "", "2001:db8::/32", "::ffff:198.51.100.7"})
+	require.NoError(t, err)
+	require.Len(t, res, 4)
+	assert.Equal(t, "10.0.0.0/8", res[0].String())
+	assert.Equal(t, "192.0.2.1/32", res[1].String())
+	assert.Equal(t, "2001:db8::/32", res[2].String())
+	assert.Equal(t, "198.51.100.7/32", res[3].String())
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"proxy.local"})
+	assert.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "fd00::/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		remote   string
+		xff      []string
+		realIP   string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "IPv4",
+			remote:   "192.0.2.1:1234",
+			expected: "192.0.2.1",
+			ok:       true,
+		},
+		{
+			name:     "IPv6",
+			remote:   "[2001:db8::1]:1234",
+			expected: "2001:db8::1",
+			ok:       true,
+		},
+		{
+			name:     "IPv6WithZone",
+			remote:   "[fe80::1%eth0]:1234",
+			expected: "fe80::1",
+			ok:       true,
+		},
+		{
+			name:     "IPv4MappedIPv6",
+			remote:   "[::ffff:192.0.2.1]:1234",
+			expected: "192.0.2.1",
+			ok:       true,
+		},
+		{
+			name:     "WithoutPort",
+			remote:   "2001:db8::1",
+			expected: "2001:db8::1",
+			ok:       true,
+		},
+		{
+			name:   "Invalid",
+			remote: "not-an-ip",
+			ok:     false,
+		},
+		{
+			name:     "UntrustedPeerIgnoresHeaders",
+			remote:   "192.0.2.1:1234",
+			xff:      []string{"203.0.113.9"},
+			realIP:   "203.0.113.10",
+			expected: "192.0.2.1",
+			ok:       true,
+		},
+		{
+			name:     "TrustedPeerUsesForwardedFor",
+			remote:   "10.0.0.1:1234",
+			xff:      []string{"203.0.113.9"},
+			expected: "203.0.113.9",
+			ok:       true,
+		},
+		{
+			name:     "SkipsTrustedHops",
+			remote:   "10.0.0.1:1234",
+			xff:      []string{"198.51.100.1, 203.0.113.9", "10.0.0.2"},
+			expected: "203.0.113.9",
+			ok:       true,
+		},
+		{
+			name:     "IPv6Hop",
+			remote:   "[fd00::1]:1234",
+			xff:      []string{"2001:db8::2, fd00::2"},
+			expected: "2001:db8::2",
+			ok:       true,
+		},
+		{
+			name:     "InvalidHopFallsBackToPeer",
+			remote:   "10.0.0.1:1234",
+			xff:      []string{"203.0.113.9, garbage"},
+			expected: "10.0.0.1",
+			ok:       true,
+		},
+		{
+			name:     "TrustedPeerUsesRealIP",
+			remote:   "10.0.0.1:1234",
+			realIP:   "203.0.113.10",
+			expected: "203.0.113.10",
+			ok:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			addr, ok := clientIP(req, trusted)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, addr.String())
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
 	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
 	metrics "github.com/JMURv/golang-clean-template/internal/observability/metrics/prometheus"
@@ -18,32 +19,89 @@ import (
 	"go.uber.org/zap"
 )
 
+// Token sources accepted by AuthOpts.Lookup.
+const (
+	TokenFromHeader = "header"
+	TokenFromCookie = "cookie"
+)
+
+var defaultLookup = []string{TokenFromHeader, TokenFromCookie}
+
+var (
+	ErrNoToken           = errors.New("no access token provided")
+	ErrClientToken       = errors.New("token was issued to an oauth client")
+	ErrInsufficientScope = errors.New("token lacks the required scope")
+)
+
+// AuthOpts configures Auth. CheckAuthor restricts the route to the user in
+// the {id} path parameter. Permission is required from everyone else: with
+// CheckAuthor it lets holders act on other users, without it it is required
+// from every caller. Lookup lists token sources in order of precedence and
+// defaults to the Authorization header, then the access cookie. Tokens
+// issued to OAuth clients are only accepted with Clients, when they act for
+// a user and carry Scope. API keys of service accounts are accepted in place
+// of access tokens when APIKeys is set.
 type AuthOpts struct {
 	CheckAuthor bool
+	Permission  string
+	Lookup      []string
+	Clients     bool
+	Scope       string
+	APIKeys     auth.APIKeys
+}
+
+// extractToken returns the access token from the first source in lookup
+// that carries one.
+func extractToken(r *http.Request, lookup []string) (string, bool) {
+	if len(lookup) == 0 {
+		lookup = defaultLookup
+	}
+
+	for _, src := range lookup {
+		switch src {
+		case TokenFromHeader:
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if ok && token != "" {
+				return token, true
+			}
+		case TokenFromCookie:
+			if c, err := r.Cookie(config.AccessCookieName); err == nil && c.Value != "" {
+				return c.Value, true
+			}
+		}
+	}
+
+	return "", false
 }
 
 func Auth(au auth.Core, opts AuthOpts) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
 			func(w http.ResponseWriter, r *http.Request) {
-				access, err := r.Cookie(config.AccessCookieName)
-				if err != nil {
-					if errors.Is(err, http.ErrNoCookie) {
-						utils.ErrResponse(w, http.StatusUnauthorized, err)
-						return
-					} else {
-						zap.L().Error("failed to get access cookie", zap.Error(err))
-						utils.ErrResponse(w, http.StatusInternalServerError, err)
-						return
-					}
+				access, ok := extractToken(r, opts.Lookup)
+				if !ok {
+					utils.ErrResponse(w, http.StatusUnauthorized, ErrNoToken)
+					return
 				}
 
-				claims, err := au.ParseClaims(r.Context(), access.Value)
-				if err != nil {
-					utils.ErrResponse(w, http.StatusForbidden, err)
+				claims, ok := authenticate(w, r, au, opts.APIKeys, access)
+				if !ok {
 					return
 				}
 
+				if claims.ClientID != "" {
+					if !opts.Clients || claims.UID == uuid.Nil {
+						utils.ErrResponse(w, http.StatusUnauthorized, ErrClientToken)
+						return
+					}
+
+					if opts.Scope != "" && !claims.HasScope(opts.Scope) {
+						utils.ErrResponse(w, http.StatusForbidden, ErrInsufficientScope)
+						return
+					}
+				}
+
+				isAuthor := false
 				if opts.CheckAuthor {
 					uid, err := uuid.Parse(chi.URLParam(r, "id"))
 					if err != nil {
@@ -52,53 +110,189 @@ func Auth(au auth.Core, opts AuthOpts) func(http.Handler) http.Handler {
 						return
 					}
 
-					if uid != claims.UID {
-						utils.ErrResponse(w, http.StatusForbidden, err)
-						return
-					}
+					isAuthor = uid == claims.UID
+				}
+
+				if (opts.CheckAuthor || opts.Permission != "") && !isAuthor && !claims.HasPermission(opts.Permission) {
+					zap.L().Debug(
+						"permission denied",
+						zap.String("uid", claims.UID.String()),
+						zap.String("permission", opts.Permission),
+					)
+					utils.ErrResponse(w, http.StatusForbidden, auth.ErrPermissionDenied)
+					return
 				}
 
 				ctx := context.WithValue(r.Context(), config.UidKey, claims.UID)
+				ctx = context.WithValue(ctx, config.ClaimsKey, claims)
+				if opts.APIKeys != nil && auth.IsAPIKey(access) {
+					ctx = context.WithValue(ctx, config.APIKeyKey, auth.APIKeyID(access))
+				}
 				next.ServeHTTP(w, r.WithContext(ctx))
 			},
 		)
 	}
 }
 
+// authenticate resolves an API key through keys, or parses an access token
+// and checks that it wasn't revoked. It writes the error response itself.
+func authenticate(
+	w http.ResponseWriter,
+	r *http.Request,
+	au auth.Core,
+	keys auth.APIKeys,
+	access string,
+) (jwt.Claims, bool) {
+	if keys != nil && auth.IsAPIKey(access) {
+		claims, err := keys.AuthenticateAPIKey(r.Context(), access)
+		if err != nil {
+			utils.ErrResponse(w, http.StatusUnauthorized, auth.ErrInvalidToken)
+			return claims, false
+		}
+
+		return claims, true
+	}
+
+	claims, err := au.ParseClaims(r.Context(), access)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusForbidden, err)
+		return claims, false
+	}
+
+	if !claims.IsAccess() {
+		utils.ErrResponse(w, http.StatusUnauthorized, auth.ErrInvalidToken)
+		return claims, false
+	}
+
+	if err = au.CheckRevoked(r.Context(), claims); err != nil {
+		utils.ErrResponse(w, http.StatusUnauthorized, err)
+		return claims, false
+	}
+
+	return claims, true
+}
+
+// RequirePermission rejects requests whose token lacks perm. It must be
+// chained after Auth.
+func RequirePermission(perm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := r.Context().Value(config.ClaimsKey).(jwt.Claims)
+				if !ok {
+					utils.ErrResponse(w, http.StatusUnauthorized, auth.ErrInvalidToken)
+					return
+				}
+
+				if !claims.HasPermission(perm) {
+					utils.ErrResponse(w, http.StatusForbidden, auth.ErrPermissionDenied)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
 var (
-	ErrIPIsIncorrect = errors.New("ip is incorrect")
-	ErrUAIsIncorrect = errors.New("user agent is incorrect")
+	ErrIPIsIncorrect       = errors.New("ip is incorrect")
+	ErrUAIsIncorrect       = errors.New("user agent is incorrect")
+	ErrDeviceIDIsIncorrect = errors.New("device id is incorrect")
 )
 
-func Device(next http.Handler) http.Handler {
-	return http.HandlerFunc(
-		func(w http.ResponseWriter, r *http.Request) {
-			ip := r.RemoteAddr
-			if ip == "" {
-				utils.ErrResponse(w, http.StatusForbidden, ErrIPIsIncorrect)
+// Device stores the normalized client IP, User-Agent and device ID in the
+// request context. The device ID is read from the X-Device-ID header, then from the
+// device cookie signed with key. Browsers without a valid cookie get one with
+// the ID derived from IP and User-Agent, so the device stays the same when
+// the network changes.
+func Device(key []byte) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				addr, ok := parseAddr(r.RemoteAddr)
+				if !ok {
+					utils.ErrResponse(w, http.StatusForbidden, ErrIPIsIncorrect)
 
-				return
-			}
+					return
+				}
 
-			ip = strings.Split(ip, ":")[0]
+				ip := addr.String()
 
-			splitIP := strings.Split(ip, ".")
-			if len(splitIP) != 4 { //nolint:mnd
-				utils.ErrResponse(w, http.StatusForbidden, ErrIPIsIncorrect)
+				ua := r.UserAgent()
+				if ua == "" {
+					utils.ErrResponse(w, http.StatusForbidden, ErrUAIsIncorrect)
 
-				return
-			}
+					return
+				}
 
-			ua := r.UserAgent()
-			if ua == "" {
-				utils.ErrResponse(w, http.StatusForbidden, ErrUAIsIncorrect)
+				id, ok := deviceID(w, r, key, ip, ua)
+				if !ok {
+					utils.ErrResponse(w, http.StatusBadRequest, ErrDeviceIDIsIncorrect)
 
+					return
+				}
+
+				zap.L().Debug("device info", zap.String("ip", ip), zap.String("ua", ua), zap.String("id", id))
+				ctx := context.WithValue(r.Context(), config.IpKey, ip)
+				ctx = context.WithValue(ctx, config.UaKey, ua)
+				ctx = context.WithValue(ctx, config.DeviceKey, id)
+				next.ServeHTTP(w, r.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// deviceID resolves the device ID of r and sets the device cookie for
+// browsers that do not have a valid one yet. Token mode clients without the
+// header get the derived ID and no cookie.
+func deviceID(w http.ResponseWriter, r *http.Request, key []byte, ip, ua string) (string, bool) {
+	if id := r.Header.Get(config.DeviceIDHeader); id != "" {
+		return id, auth.ValidDeviceID(id)
+	}
+
+	if c, err := r.Cookie(config.DeviceCookieName); err == nil {
+		if id, ok := auth.VerifyDeviceID(key, c.Value); ok {
+			return id, true
+		}
+
+		zap.L().Debug("invalid device cookie", zap.String("ip", ip))
+	}
+
+	id := auth.FallbackDeviceID(ip, ua)
+	if !utils.IsTokenMode(r) {
+		http.SetCookie(
+			w, &http.Cookie{
+				Name:     config.DeviceCookieName,
+				Value:    auth.SignDeviceID(key, id),
+				Expires:  time.Now().Add(config.DeviceCookieDuration),
+				HttpOnly: true,
+				Secure:   true,
+				Path:     "/",
+				SameSite: http.SameSiteStrictMode,
+			},
+		)
+	}
+
+	return id, true
+}
+
+// Locale stores the primary language of the Accept-Language header in the
+// request context, so outgoing emails can be rendered in that language.
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			lang := r.Header.Get("Accept-Language")
+			if lang == "" {
+				next.ServeHTTP(w, r)
 				return
 			}
 
-			zap.L().Debug("device info", zap.String("ip", ip), zap.String("ua", ua))
-			ctx := context.WithValue(r.Context(), config.IpKey, ip)
-			ctx = context.WithValue(ctx, config.UaKey, ua)
+			lang, _, _ = strings.Cut(lang, ",")
+			lang, _, _ = strings.Cut(lang, ";")
+			lang, _, _ = strings.Cut(strings.TrimSpace(lang), "-")
+
+			ctx := context.WithValue(r.Context(), config.LocaleKey, strings.ToLower(lang))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		},
 	)
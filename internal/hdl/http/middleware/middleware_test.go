@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExtractToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		cookie   string
+		lookup   []string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "DefaultPrefersHeader",
+			header:   "Bearer header-token",
+			cookie:   "cookie-token",
+			expected: "header-token",
+			ok:       true,
+		},
+		{
+			name:     "DefaultFallsBackToCookie",
+			cookie:   "cookie-token",
+			expected: "cookie-token",
+			ok:       true,
+		},
+		{
+			name:     "CookieFirst",
+			header:   "Bearer header-token",
+			cookie:   "cookie-token",
+			lookup:   []string{TokenFromCookie, TokenFromHeader},
+			expected: "cookie-token",
+			ok:       true,
+		},
+		{
+			name:   "HeaderOnlyIgnoresCookie",
+			cookie: "cookie-token",
+			lookup: []string{TokenFromHeader},
+			ok:     false,
+		},
+		{
+			name:   "NonBearerScheme",
+			header: "Basic dXNlcjpwYXNz",
+			lookup: []string{TokenFromHeader},
+			ok:     false,
+		},
+		{
+			name: "NoToken",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: config.AccessCookieName, Value: tt.cookie})
+			}
+
+			token, ok := extractToken(req, tt.lookup)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, token)
+		})
+	}
+}
+
+func TestAuth_ClientTokens(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	au := mocks.NewMockCore(mock)
+	claims := jwt.Claims{UID: uuid.New(), Type: jwt.TypeAccess, ClientID: "client", Scope: "read"}
+	service := jwt.Claims{Type: jwt.TypeAccess, ClientID: "client", Scope: "read"}
+
+	tests := []struct {
+		name   string
+		claims jwt.Claims
+		opts   AuthOpts
+		status int
+	}{
+		{name: "Rejected", claims: claims, opts: AuthOpts{}, status: http.StatusUnauthorized},
+		{name: "Allowed", claims: claims, opts: AuthOpts{Clients: true}, status: http.StatusOK},
+		{name: "Scope", claims: claims, opts: AuthOpts{Clients: true, Scope: "read"}, status: http.StatusOK},
+		{name: "MissingScope", claims: claims, opts: AuthOpts{Clients: true, Scope: "write"}, status: http.StatusForbidden},
+		{name: "NoUser", claims: service, opts: AuthOpts{Clients: true}, status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			au.EXPECT().ParseClaims(gomock.Any(), "token").Return(tt.claims, nil)
+			au.EXPECT().CheckRevoked(gomock.Any(), tt.claims).Return(nil)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.claims, r.Context().Value(config.ClaimsKey))
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			w := httptest.NewRecorder()
+			Auth(au, tt.opts)(next).ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestDevice(t *testing.T) {
+	key := []byte("key")
+	fallback := auth.FallbackDeviceID("192.0.2.1", "test-agent")
+
+	tests := []struct {
+		name      string
+		header    string
+		cookie    string
+		tokenMode bool
+		status    int
+		expected  string
+		setCookie bool
+	}{
+		{
+			name:     "Header",
+			header:   "device-1",
+			cookie:   auth.SignDeviceID(key, "device-2"),
+			status:   http.StatusOK,
+			expected: "device-1",
+		},
+		{
+			name:   "InvalidHeader",
+			header: "device.1",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:     "SignedCookie",
+			cookie:   auth.SignDeviceID(key, "device-2"),
+			status:   http.StatusOK,
+			expected: "device-2",
+		},
+		{
+			name:      "TamperedCookie",
+			cookie:    "device-2.bad",
+			status:    http.StatusOK,
+			expected:  fallback,
+			setCookie: true,
+		},
+		{
+			name:      "Fallback",
+			status:    http.StatusOK,
+			expected:  fallback,
+			setCookie: true,
+		},
+		{
+			name:      "TokenModeFallback",
+			tokenMode: true,
+			status:    http.StatusOK,
+			expected:  fallback,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := Device(key)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = r.Context().Value(config.DeviceKey).(string)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("User-Agent", "test-agent")
+			if tt.header != "" {
+				req.Header.Set(config.DeviceIDHeader, tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: config.DeviceCookieName, Value: tt.cookie})
+			}
+			if tt.tokenMode {
+				req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+			assert.Equal(t, tt.expected, got)
+
+			cookies := w.Result().Cookies()
+			if !tt.setCookie {
+				assert.Empty(t, cookies)
+				return
+			}
+
+			assert.Len(t, cookies, 1)
+			id, ok := auth.VerifyDeviceID(key, cookies[0].Value)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, id)
+		})
+	}
+}
+
+func TestDevice_IP(t *testing.T) {
+	tests := []struct {
+		name     string
+		remote   string
+		status   int
+		expected string
+	}{
+		{name: "IPv4", remote: "192.0.2.1:1234", status: http.StatusOK, expected: "192.0.2.1"},
+		{name: "IPv6", remote: "[2001:db8::1]:1234", status: http.StatusOK, expected: "2001:db8::1"},
+		{name: "Mapped", remote: "[::ffff:192.0.2.1]:1234", status: http.StatusOK, expected: "192.0.2.1"},
+		{name: "Invalid", remote: "unknown", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := Device([]byte("key"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = r.Context().Value(config.IpKey).(string)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("User-Agent", "test-agent")
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestAuth_RefreshToken(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	au := mocks.NewMockCore(mock)
+	au.EXPECT().ParseClaims(gomock.Any(), "token").Return(jwt.Claims{UID: uuid.New(), Type: jwt.TypeRefresh}, nil)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("refresh token reached the handler")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+
+	w := httptest.NewRecorder()
+	Auth(au, AuthOpts{})(next).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestAuth_APIKey(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	au := mocks.NewMockCore(mock)
+	keys := mocks.NewMockAppCtrl(mock)
+	const key = "sk_0123abcd_secret"
+	claims := jwt.Claims{UID: uuid.New(), Type: jwt.TypeAccess, Permissions: []string{auth.PermUsersList}}
+
+	tests := []struct {
+		name   string
+		opts   AuthOpts
+		expect func()
+		status int
+	}{
+		{
+			name:   "Allowed",
+			opts:   AuthOpts{Permission: auth.PermUsersList},
+			expect: func() { keys.EXPECT().AuthenticateAPIKey(gomock.Any(), key).Return(claims, nil) },
+			status: http.StatusOK,
+		},
+		{
+			name:   "MissingPermission",
+			opts:   AuthOpts{Permission: auth.PermUsersDelete},
+			expect: func() { keys.EXPECT().AuthenticateAPIKey(gomock.Any(), key).Return(claims, nil) },
+			status: http.StatusForbidden,
+		},
+		{
+			name: "UnknownKey",
+			expect: func() {
+				keys.EXPECT().AuthenticateAPIKey(gomock.Any(), key).Return(jwt.Claims{}, auth.ErrInvalidToken)
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, claims.UID, r.Context().Value(config.UidKey))
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+key)
+
+			opts := tt.opts
+			opts.APIKeys = keys
+			w := httptest.NewRecorder()
+			Auth(au, opts)(next).ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
+	"github.com/google/uuid"
+)
+
+// RateLimit counts requests against the policy name of l and rejects the
+// ones over it with 429. Responses carry the RateLimit headers of the
+// policy. Chained after Auth, requests are counted per API key or user when
+// the policy asks for it, otherwise per client IP. Unknown policies and a
+// nil l let every request through.
+func RateLimit(l *ratelimit.Limiter, name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		p, ok := l.Policy(name)
+		if !ok {
+			return next
+		}
+
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				uid, _ := r.Context().Value(config.UidKey).(uuid.UUID)
+				keyID, _ := r.Context().Value(config.APIKeyKey).(string)
+
+				ip := r.RemoteAddr
+				if addr, ok := parseAddr(r.RemoteAddr); ok {
+					ip = addr.String()
+				}
+
+				res := l.Allow(r.Context(), p, ratelimit.Caller(p, ip, uid, keyID))
+				for k, v := range ratelimit.Headers(p, res) {
+					w.Header().Set(k, v)
+				}
+
+				if !res.Allowed {
+					utils.ErrResponse(w, http.StatusTooManyRequests, ratelimit.ErrRateLimited)
+					return
+				}
+
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
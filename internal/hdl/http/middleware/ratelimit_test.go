@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// countingStore allows the first limit requests of every key.
+type countingStore map[string]int
+
+func (s countingStore) Allow(_ context.Context, key string, limit int, period time.Duration) (ratelimit.Result, error) {
+	s[key]++
+	if s[key] > limit {
+		return ratelimit.Result{Limit: limit, ResetAfter: period, RetryAfter: period}, nil
+	}
+
+	return ratelimit.Result{Allowed: true, Limit: limit, Remaining: limit - s[key], ResetAfter: period}, nil
+}
+
+func TestRateLimit(t *testing.T) {
+	conf := config.Config{}
+	conf.RateLimit.Enabled = true
+	conf.RateLimit.Policies = []string{"test=ip:1/1m"}
+	l := ratelimit.New(conf, countingStore{})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		limiter    *ratelimit.Limiter
+		policy     string
+		remoteAddr string
+		status     int
+		retryAfter string
+	}{
+		{name: "Allowed", limiter: l, policy: "test", remoteAddr: "10.0.0.1:1234", status: http.StatusOK},
+		{name: "Limited", limiter: l, policy: "test", remoteAddr: "10.0.0.1:4321", status: http.StatusTooManyRequests, retryAfter: "60"},
+		{name: "OtherIP", limiter: l, policy: "test", remoteAddr: "10.0.0.2:1234", status: http.StatusOK},
+		{name: "UnknownPolicy", limiter: l, policy: "unknown", remoteAddr: "10.0.0.1:1234", status: http.StatusOK},
+		{name: "Disabled", policy: "test", remoteAddr: "10.0.0.1:1234", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+
+			w := httptest.NewRecorder()
+			RateLimit(tt.limiter, tt.policy)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
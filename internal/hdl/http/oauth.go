@@ -0,0 +1,541 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	_ "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) RegisterOAuthRoutes() {
+	clients := h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermOAuthClients))
+	clients.Post("/oauth/clients", h.createOAuthClient)
+	clients.Get("/oauth/clients", h.listOAuthClients)
+	clients.Delete("/oauth/clients/{id}", h.deleteOAuthClient)
+
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Get("/oauth/authorize", h.authorize)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Post("/oauth/authorize", h.consent)
+	h.Router.Post("/oauth/token", h.token)
+	h.Router.Post("/oauth/introspect", h.introspect)
+	h.Router.Post("/oauth/revoke", h.revoke)
+
+	h.Router.Post("/oauth/device/code", h.deviceCode)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Get("/oauth/device", h.deviceConsent)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Post("/oauth/device", h.approveDevice)
+}
+
+// createOAuthClient godoc
+//
+//	@Summary		Register an OAuth client
+//	@Description	Public clients get no secret and must use PKCE. The secret of a confidential client is only returned here. Requires oauth:clients
+//	@Tags			OAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Authorization token"
+//	@Param			body			body		dto.CreateOAuthClientRequest	true	"Client settings"
+//	@Success		201				{object}	dto.CreateOAuthClientResponse
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid payload"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/oauth/clients [post]
+func (h *Handler) createOAuthClient(w http.ResponseWriter, r *http.Request) {
+	req := &dto.CreateOAuthClientRequest{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.CreateOAuthClient(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrInvalidOAuthClient) {
+			utils.ErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, res)
+}
+
+// listOAuthClients godoc
+//
+//	@Summary		List OAuth clients
+//	@Description	Returns registered clients without their secrets. Requires oauth:clients
+//	@Tags			OAuth
+//	@Produce		json
+//	@Param			Authorization	header		string	true	"Authorization token"
+//	@Success		200				{array}		models.OAuthClient
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/oauth/clients [get]
+func (h *Handler) listOAuthClients(w http.ResponseWriter, r *http.Request) {
+	res, err := h.ctrl.ListOAuthClients(r.Context())
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// deleteOAuthClient godoc
+//
+//	@Summary		Delete an OAuth client
+//	@Description	Removes the client with its consents and refresh tokens. Requires oauth:clients
+//	@Tags			OAuth
+//	@Param			id	path	string	true	"Client ID"
+//	@Produce		json
+//	@Param			Authorization	header	string	true	"Authorization token"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid client ID"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404				{object}	utils.ErrorsResponse	"client not found"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/oauth/clients/{id} [delete]
+func (h *Handler) deleteOAuthClient(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zap.L().Debug(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	err = h.ctrl.DeleteOAuthClient(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
+
+// authorize godoc
+//
+//	@Summary		OAuth authorization endpoint
+//	@Description	Validates an authorization code request of the logged in user. If the user already granted the scopes, redirects back to the client with a code. Otherwise returns the consent to ask for, which is answered with POST /oauth/authorize. An unknown client or redirect URI is never redirected to
+//	@Tags			OAuth
+//	@Produce		json
+//	@Param			response_type			query		string	true	"Must be 'code'"
+//	@Param			client_id				query		string	true	"Client ID"
+//	@Param			redirect_uri			query		string	false	"Registered redirect URI, optional if the client has only one"
+//	@Param			scope					query		string	false	"Space delimited scopes, all scopes of the client by default"
+//	@Param			state					query		string	false	"Opaque value returned to the client"
+//	@Param			code_challenge			query		string	true	"PKCE code challenge"
+//	@Param			code_challenge_method	query		string	true	"Must be 'S256'"
+//	@Param			prompt					query		string	false	"Set to 'consent' to ask again"
+//	@Param			nonce					query		string	false	"Value copied into the ID token of openid requests"
+//	@Success		200						{object}	dto.AuthorizeResponse	"Consent required"
+//	@Success		302						"Redirect to the client"
+//	@Failure		400						{object}	oauth.Error				"unknown client or redirect URI"
+//	@Failure		401						{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		500						{object}	oauth.Error				"internal error"
+//	@Router			/oauth/authorize [get]
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	res, err := h.ctrl.Authorize(r.Context(), uid, parseAuthorizeRequest(r.URL.Query()))
+	if err != nil {
+		oauthErrResponse(w, err)
+		return
+	}
+
+	if res.RedirectTo != "" {
+		http.Redirect(w, r, res.RedirectTo, http.StatusFound)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// consent godoc
+//
+//	@Summary		Answer an OAuth consent
+//	@Description	Repeats the authorization request with the user's decision. Returns the URL to send the user agent to, with a code or access_denied
+//	@Tags			OAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Authorization token"
+//	@Param			body			body		dto.ConsentRequest		true	"Authorization request and decision"
+//	@Success		200				{object}	dto.AuthorizeResponse	"Redirect URL"
+//	@Failure		400				{object}	oauth.Error				"unknown client or redirect URI"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		500				{object}	oauth.Error				"internal error"
+//	@Router			/oauth/authorize [post]
+func (h *Handler) consent(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	req := &dto.ConsentRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.Consent(r.Context(), uid, req)
+	if err != nil {
+		oauthErrResponse(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// token godoc
+//
+//	@Summary		OAuth token endpoint
+//	@Description	Issues tokens for the authorization_code (with PKCE), refresh_token, client_credentials and device_code grants. Confidential clients authenticate with HTTP Basic or client_secret in the form. Refresh tokens are rotated on use. An ID token is included when the openid scope is granted
+//	@Tags			OAuth
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			grant_type		formData	string	true	"authorization_code, refresh_token, client_credentials or urn:ietf:params:oauth:grant-type:device_code"
+//	@Param			code			formData	string	false	"Authorization code"
+//	@Param			redirect_uri	formData	string	false	"Redirect URI of the authorization request, if it had one"
+//	@Param			code_verifier	formData	string	false	"PKCE code verifier"
+//	@Param			refresh_token	formData	string	false	"Refresh token"
+//	@Param			device_code		formData	string	false	"Device code"
+//	@Param			scope			formData	string	false	"Space delimited scopes"
+//	@Param			client_id		formData	string	false	"Client ID, unless sent with HTTP Basic"
+//	@Param			client_secret	formData	string	false	"Client secret, unless sent with HTTP Basic"
+//	@Success		200				{object}	dto.OAuthTokenResponse
+//	@Failure		400				{object}	oauth.Error
+//	@Failure		401				{object}	oauth.Error	"invalid client"
+//	@Failure		500				{object}	oauth.Error
+//	@Router			/oauth/token [post]
+func (h *Handler) token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if err := r.ParseForm(); err != nil {
+		oauthErrResponse(w, oauth.ErrInvalidRequest)
+		return
+	}
+
+	req := &dto.OAuthTokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		DeviceCode:   r.PostForm.Get("device_code"),
+		Scope:        r.PostForm.Get("scope"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+	}
+
+	if !parseClientAuth(r, &req.ClientID, &req.ClientSecret) {
+		oauthErrResponse(w, oauth.ErrInvalidClient)
+		return
+	}
+
+	res, err := h.ctrl.Token(r.Context(), req)
+	if err != nil {
+		oauthErrResponse(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// introspect godoc
+//
+//	@Summary		OAuth token introspection
+//	@Description	Describes an access or refresh token (RFC 7662). Only confidential clients may introspect, authenticating like on the token endpoint. Revoked, rotated, expired and unknown tokens are reported as {"active": false}
+//	@Tags			OAuth
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			token			formData	string	true	"Token to describe"
+//	@Param			token_type_hint	formData	string	false	"access_token or refresh_token, ignored"
+//	@Param			client_id		formData	string	false	"Client ID, unless sent with HTTP Basic"
+//	@Param			client_secret	formData	string	false	"Client secret, unless sent with HTTP Basic"
+//	@Success		200				{object}	dto.IntrospectionResponse
+//	@Failure		400				{object}	oauth.Error
+//	@Failure		401				{object}	oauth.Error	"invalid or public client"
+//	@Failure		500				{object}	oauth.Error
+//	@Router			/oauth/introspect [post]
+func (h *Handler) introspect(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		oauthErrResponse(w, oauth.ErrInvalidRequest)
+		return
+	}
+
+	req := &dto.IntrospectRequest{
+		Token:         r.PostForm.Get("token"),
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+		ClientID:      r.PostForm.Get("client_id"),
+		ClientSecret:  r.PostForm.Get("client_secret"),
+	}
+
+	if !parseClientAuth(r, &req.ClientID, &req.ClientSecret) {
+		oauthErrResponse(w, oauth.ErrInvalidClient)
+		return
+	}
+
+	res, err := h.ctrl.Introspect(r.Context(), req)
+	if err != nil {
+		oauthErrResponse(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// revoke godoc
+//
+//	@Summary		OAuth token revocation
+//	@Description	Revokes an access or refresh token issued to the client (RFC 7009). Clients authenticate like on the token endpoint. Unknown and already inactive tokens are accepted
+//	@Tags			OAuth
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			token			formData	string	true	"Token to revoke"
+//	@Param			token_type_hint	formData	string	false	"access_token or refresh_token, ignored"
+//	@Param			client_id		formData	string	false	"Client ID, unless sent with HTTP Basic"
+//	@Param			client_secret	formData	string	false	"Client secret, unless sent with HTTP Basic"
+//	@Success		200				"Revoked"
+//	@Failure		400				{object}	oauth.Error	"missing token or token of another client"
+//	@Failure		401				{object}	oauth.Error	"invalid client"
+//	@Failure		500				{object}	oauth.Error
+//	@Router			/oauth/revoke [post]
+func (h *Handler) revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		oauthErrResponse(w, oauth.ErrInvalidRequest)
+		return
+	}
+
+	req := &dto.RevokeTokenRequest{
+		Token:         r.PostForm.Get("token"),
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+		ClientID:      r.PostForm.Get("client_id"),
+		ClientSecret:  r.PostForm.Get("client_secret"),
+	}
+
+	if !parseClientAuth(r, &req.ClientID, &req.ClientSecret) {
+		oauthErrResponse(w, oauth.ErrInvalidClient)
+		return
+	}
+
+	if err := h.ctrl.RevokeClientToken(r.Context(), req); err != nil {
+		oauthErrResponse(w, err)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusOK)
+}
+
+// deviceCode godoc
+//
+//	@Summary		OAuth device authorization endpoint
+//	@Description	Starts the device authorization grant for clients without a browser. The device shows user_code and verification_uri, then polls POST /oauth/token with the device_code every interval seconds. Clients authenticate like on the token endpoint
+//	@Tags			OAuth
+//	@Accept			x-www-form-urlencoded
+//	@Produce		json
+//	@Param			scope			formData	string	false	"Space delimited scopes"
+//	@Param			client_id		formData	string	false	"Client ID, unless sent with HTTP Basic"
+//	@Param			client_secret	formData	string	false	"Client secret, unless sent with HTTP Basic"
+//	@Success		200				{object}	dto.DeviceCodeResponse
+//	@Failure		400				{object}	oauth.Error
+//	@Failure		401				{object}	oauth.Error	"invalid client"
+//	@Failure		500				{object}	oauth.Error
+//	@Router			/oauth/device/code [post]
+func (h *Handler) deviceCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		oauthErrResponse(w, oauth.ErrInvalidRequest)
+		return
+	}
+
+	req := &dto.DeviceCodeRequest{
+		Scope:        r.PostForm.Get("scope"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+	}
+
+	if !parseClientAuth(r, &req.ClientID, &req.ClientSecret) {
+		oauthErrResponse(w, oauth.ErrInvalidClient)
+		return
+	}
+
+	res, err := h.ctrl.DeviceAuthorization(r.Context(), req)
+	if err != nil {
+		oauthErrResponse(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// deviceConsent godoc
+//
+//	@Summary		Look up a device authorization
+//	@Description	Returns the client and scopes behind the user code shown on a device, to ask the logged in user for approval. Each lookup counts towards a per-user limit
+//	@Tags			OAuth
+//	@Produce		json
+//	@Param			Authorization	header		string	true	"Authorization token"
+//	@Param			user_code		query		string	true	"User code shown on the device"
+//	@Success		200				{object}	dto.DeviceConsentResponse
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		404				{object}	utils.ErrorsResponse	"unknown or expired user code"
+//	@Failure		429				{object}	utils.ErrorsResponse	"too many user code checks"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/oauth/device [get]
+func (h *Handler) deviceConsent(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	res, err := h.ctrl.GetDeviceConsent(r.Context(), uid, r.URL.Query().Get("user_code"))
+	if err != nil {
+		deviceErrResponse(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// approveDevice godoc
+//
+//	@Summary		Answer a device authorization
+//	@Description	Approves or denies the device authorization of the user code on behalf of the logged in user. The device receives its tokens on its next poll
+//	@Tags			OAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header	string						true	"Authorization token"
+//	@Param			body			body	dto.DeviceApprovalRequest	true	"User code and decision"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid payload"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		404				{object}	utils.ErrorsResponse	"unknown or expired user code"
+//	@Failure		429				{object}	utils.ErrorsResponse	"too many user code checks"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/oauth/device [post]
+func (h *Handler) approveDevice(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	req := &dto.DeviceApprovalRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	if err := h.ctrl.ApproveDevice(r.Context(), uid, req); err != nil {
+		deviceErrResponse(w, err)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
+
+func deviceErrResponse(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ctrl.ErrNotFound):
+		utils.ErrResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, ctrl.ErrTooManyRequests):
+		utils.ErrResponse(w, http.StatusTooManyRequests, err)
+	default:
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+	}
+}
+
+// parseClientAuth reads client credentials sent with HTTP Basic into id and
+// secret. RFC 6749 section 2.3.1: Basic credentials are form-urlencoded
+// first.
+func parseClientAuth(r *http.Request, id, secret *string) bool {
+	basicID, basicSecret, ok := r.BasicAuth()
+	if !ok {
+		return true
+	}
+
+	var err error
+	if *id, err = url.QueryUnescape(basicID); err != nil {
+		return false
+	}
+
+	*secret, err = url.QueryUnescape(basicSecret)
+	return err == nil
+}
+
+func parseAuthorizeRequest(q url.Values) *dto.AuthorizeRequest {
+	return &dto.AuthorizeRequest{
+		ResponseType:        q.Get("response_type"),
+		ClientID:            q.Get("client_id"),
+		RedirectURI:         q.Get("redirect_uri"),
+		Scope:               q.Get("scope"),
+		State:               q.Get("state"),
+		CodeChallenge:       q.Get("code_challenge"),
+		CodeChallengeMethod: q.Get("code_challenge_method"),
+		Prompt:              q.Get("prompt"),
+		Nonce:               q.Get("nonce"),
+	}
+}
+
+// oauthErrResponse writes err in the OAuth error format. Unexpected errors
+// become server_error.
+func oauthErrResponse(w http.ResponseWriter, err error) {
+	var oe *oauth.Error
+	if !errors.As(err, &oe) {
+		utils.SuccessResponse(w, http.StatusInternalServerError, oauth.ErrServerError)
+		return
+	}
+
+	status := http.StatusBadRequest
+	if errors.Is(oe, oauth.ErrInvalidClient) {
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	utils.SuccessResponse(w, status, oe)
+}
@@ -0,0 +1,540 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateOAuthClient(t *testing.T) {
+	const uri = "/oauth/clients"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name    string
+		status  int
+		payload map[string]any
+		expect  func()
+	}{
+		{
+			name:   "Success",
+			status: http.StatusCreated,
+			payload: map[string]any{
+				"name":       "backend",
+				"grantTypes": []string{oauth.GrantClientCredentials},
+			},
+			expect: func() {
+				mctrl.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Any()).
+					Return(&dto.CreateOAuthClientResponse{Client: &models.OAuthClient{}, Secret: "secret"}, nil)
+			},
+		},
+		{
+			name:   "UnknownGrant",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"name":       "backend",
+				"grantTypes": []string{"password"},
+			},
+			expect: func() {},
+		},
+		{
+			name:   "InvalidClient",
+			status: http.StatusBadRequest,
+			payload: map[string]any{
+				"name":         "spa",
+				"redirectUris": []string{"javascript:alert(1)"},
+				"grantTypes":   []string{oauth.GrantAuthorizationCode},
+			},
+			expect: func() {
+				mctrl.EXPECT().
+					CreateOAuthClient(gomock.Any(), gomock.Any()).
+					Return(nil, ctrl.ErrInvalidOAuthClient)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				payload, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(payload))
+				req.Header.Set("Content-Type", "application/json")
+
+				w := httptest.NewRecorder()
+				h.createOAuthClient(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+			},
+		)
+	}
+}
+
+func TestHandler_Authorize(t *testing.T) {
+	const uri = "/oauth/authorize?response_type=code&client_id=app&state=xyz"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name       string
+		status     int
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Redirect",
+			status: http.StatusFound,
+			expect: func() {
+				mctrl.EXPECT().
+					Authorize(
+						gomock.Any(), uid,
+						&dto.AuthorizeRequest{ResponseType: "code", ClientID: "app", State: "xyz"},
+					).
+					Return(&dto.AuthorizeResponse{RedirectTo: "https://app.example.com/cb?code=abc"}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Equal(t, "https://app.example.com/cb?code=abc", r.Header().Get("Location"))
+			},
+		},
+		{
+			name:   "Consent",
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().
+					Authorize(gomock.Any(), uid, gomock.Any()).
+					Return(&dto.AuthorizeResponse{ClientName: "app", Scope: "profile"}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &dto.AuthorizeResponse{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, "profile", res.Scope)
+			},
+		},
+		{
+			name:   "UnknownClient",
+			status: http.StatusBadRequest,
+			expect: func() {
+				mctrl.EXPECT().
+					Authorize(gomock.Any(), uid, gomock.Any()).
+					Return(nil, oauth.ErrInvalidRequest.WithDescription("unknown client"))
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Empty(t, r.Header().Get("Location"))
+
+				res := &oauth.Error{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, "invalid_request", res.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				req := httptest.NewRequest(http.MethodGet, uri, nil)
+				req = req.WithContext(context.WithValue(req.Context(), config.UidKey, uid))
+
+				w := httptest.NewRecorder()
+				h.authorize(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_Token(t *testing.T) {
+	const uri = "/oauth/token"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name       string
+		status     int
+		form       url.Values
+		basic      []string
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			form:   url.Values{"grant_type": {oauth.GrantClientCredentials}, "scope": {"profile"}},
+			basic:  []string{"app", "se%2Bcret"},
+			expect: func() {
+				mctrl.EXPECT().
+					Token(
+						gomock.Any(), &dto.OAuthTokenRequest{
+							GrantType:    oauth.GrantClientCredentials,
+							Scope:        "profile",
+							ClientID:     "app",
+							ClientSecret: "se+cret",
+						},
+					).
+					Return(&dto.OAuthTokenResponse{AccessToken: "access", TokenType: oauth.TokenTypeBearer}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Equal(t, "no-store", r.Header().Get("Cache-Control"))
+
+				res := &dto.OAuthTokenResponse{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, "access", res.AccessToken)
+			},
+		},
+		{
+			name:   "InvalidClient",
+			status: http.StatusUnauthorized,
+			form:   url.Values{"grant_type": {oauth.GrantClientCredentials}, "client_id": {"app"}},
+			expect: func() {
+				mctrl.EXPECT().Token(gomock.Any(), gomock.Any()).Return(nil, oauth.ErrInvalidClient)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.NotEmpty(t, r.Header().Get("WWW-Authenticate"))
+			},
+		},
+		{
+			name:   "InvalidGrant",
+			status: http.StatusBadRequest,
+			form:   url.Values{"grant_type": {oauth.GrantAuthorizationCode}, "code": {"abc"}},
+			expect: func() {
+				mctrl.EXPECT().Token(gomock.Any(), gomock.Any()).Return(nil, oauth.ErrInvalidGrant)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &oauth.Error{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, "invalid_grant", res.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			status: http.StatusInternalServerError,
+			form:   url.Values{"grant_type": {oauth.GrantRefreshToken}},
+			expect: func() {
+				mctrl.EXPECT().Token(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &oauth.Error{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, "server_error", res.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				req := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(tt.form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if tt.basic != nil {
+					req.SetBasicAuth(tt.basic[0], tt.basic[1])
+				}
+
+				w := httptest.NewRecorder()
+				h.token(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_Introspect(t *testing.T) {
+	const uri = "/oauth/introspect"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		status int
+		expect func()
+	}{
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().
+					Introspect(
+						gomock.Any(), &dto.IntrospectRequest{
+							Token:         "token",
+							TokenTypeHint: "access_token",
+							ClientID:      "cli",
+							ClientSecret:  "secret",
+						},
+					).
+					Return(&dto.IntrospectionResponse{Active: true}, nil)
+			},
+		},
+		{
+			name:   "InvalidClient",
+			status: http.StatusUnauthorized,
+			expect: func() {
+				mctrl.EXPECT().Introspect(gomock.Any(), gomock.Any()).Return(nil, oauth.ErrInvalidClient)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				form := url.Values{"token": {"token"}, "token_type_hint": {"access_token"}}
+				req := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("cli", "secret")
+
+				w := httptest.NewRecorder()
+				h.introspect(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+			},
+		)
+	}
+}
+
+func TestHandler_Revoke(t *testing.T) {
+	const uri = "/oauth/revoke"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		status int
+		expect func()
+	}{
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().
+					RevokeClientToken(gomock.Any(), &dto.RevokeTokenRequest{Token: "token", ClientID: "cli"}).
+					Return(nil)
+			},
+		},
+		{
+			name:   "AnotherClient",
+			status: http.StatusBadRequest,
+			expect: func() {
+				mctrl.EXPECT().RevokeClientToken(gomock.Any(), gomock.Any()).Return(oauth.ErrInvalidRequest)
+			},
+		},
+		{
+			name:   "InternalError",
+			status: http.StatusInternalServerError,
+			expect: func() {
+				mctrl.EXPECT().RevokeClientToken(gomock.Any(), gomock.Any()).Return(errors.New("db"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				form := url.Values{"token": {"token"}, "client_id": {"cli"}}
+				req := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+				w := httptest.NewRecorder()
+				h.revoke(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+			},
+		)
+	}
+}
+
+func TestHandler_DeviceCode(t *testing.T) {
+	const uri = "/oauth/device/code"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		status int
+		expect func()
+	}{
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().
+					DeviceAuthorization(gomock.Any(), &dto.DeviceCodeRequest{ClientID: "cli", Scope: "profile"}).
+					Return(&dto.DeviceCodeResponse{DeviceCode: "device", UserCode: "WDJB-MJHT"}, nil)
+			},
+		},
+		{
+			name:   "UnauthorizedClient",
+			status: http.StatusBadRequest,
+			expect: func() {
+				mctrl.EXPECT().DeviceAuthorization(gomock.Any(), gomock.Any()).Return(nil, oauth.ErrUnauthorizedClient)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				form := url.Values{"client_id": {"cli"}, "scope": {"profile"}}
+				req := httptest.NewRequest(http.MethodPost, uri, strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+				w := httptest.NewRecorder()
+				h.deviceCode(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+			},
+		)
+	}
+}
+
+func TestHandler_ApproveDevice(t *testing.T) {
+	const uri = "/oauth/device"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name    string
+		status  int
+		payload map[string]any
+		expect  func()
+	}{
+		{
+			name:    "Success",
+			status:  http.StatusNoContent,
+			payload: map[string]any{"userCode": "WDJB-MJHT", "approve": true},
+			expect: func() {
+				mctrl.EXPECT().
+					ApproveDevice(gomock.Any(), uid, &dto.DeviceApprovalRequest{UserCode: "WDJB-MJHT", Approve: true}).
+					Return(nil)
+			},
+		},
+		{
+			name:    "MissingCode",
+			status:  http.StatusBadRequest,
+			payload: map[string]any{"approve": true},
+			expect:  func() {},
+		},
+		{
+			name:    "UnknownCode",
+			status:  http.StatusNotFound,
+			payload: map[string]any{"userCode": "BCDF-GHJK"},
+			expect: func() {
+				mctrl.EXPECT().ApproveDevice(gomock.Any(), uid, gomock.Any()).Return(ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:    "TooManyRequests",
+			status:  http.StatusTooManyRequests,
+			payload: map[string]any{"userCode": "BCDF-GHJK"},
+			expect: func() {
+				mctrl.EXPECT().ApproveDevice(gomock.Any(), uid, gomock.Any()).Return(ctrl.ErrTooManyRequests)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				payload, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(payload))
+				req.Header.Set("Content-Type", "application/json")
+				req = req.WithContext(context.WithValue(req.Context(), config.UidKey, uid))
+
+				w := httptest.NewRecorder()
+				h.approveDevice(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+			},
+		)
+	}
+}
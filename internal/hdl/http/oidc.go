@@ -0,0 +1,161 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) RegisterOIDCRoutes() {
+	h.Router.Get("/.well-known/openid-configuration", h.openIDConfiguration)
+
+	userinfo := h.Router.With(h.withAuth(mid.AuthOpts{Clients: true, Scope: oauth.ScopeOpenID}))
+	userinfo.Get("/userinfo", h.userInfo)
+	userinfo.Post("/userinfo", h.userInfo)
+
+	h.Router.With(h.withDevice()).Get("/oauth/logout", h.endSession)
+	h.Router.With(h.withDevice()).Post("/oauth/logout", h.endSession)
+}
+
+// openIDConfiguration godoc
+//
+//	@Summary		OpenID Provider metadata
+//	@Description	Discovery document for OpenID Connect clients. Endpoints are relative to OIDC_ISSUER. ID tokens are signed with the JWT keys published at jwks_uri, so clients need an asymmetric JWT_ALG to verify them
+//	@Tags			OpenID Connect
+//	@Produce		json
+//	@Success		200	{object}	dto.OpenIDConfiguration
+//	@Router			/.well-known/openid-configuration [get]
+func (h *Handler) openIDConfiguration(w http.ResponseWriter, r *http.Request) {
+	issuer := h.conf.OIDCIssuer()
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	utils.SuccessResponse(
+		w, http.StatusOK, &dto.OpenIDConfiguration{
+			Issuer:                      issuer,
+			AuthorizationEndpoint:       issuer + "/oauth/authorize",
+			TokenEndpoint:               issuer + "/oauth/token",
+			UserinfoEndpoint:            issuer + "/userinfo",
+			JWKSURI:                     issuer + "/.well-known/jwks.json",
+			EndSessionEndpoint:          issuer + "/oauth/logout",
+			DeviceAuthorizationEndpoint: issuer + "/oauth/device/code",
+			IntrospectionEndpoint:       issuer + "/oauth/introspect",
+			RevocationEndpoint:          issuer + "/oauth/revoke",
+			ScopesSupported:             []string{oauth.ScopeOpenID, oauth.ScopeProfile, oauth.ScopeEmail},
+			ResponseTypesSupported:      []string{oauth.ResponseTypeCode},
+			GrantTypesSupported: []string{
+				oauth.GrantAuthorizationCode,
+				oauth.GrantRefreshToken,
+				oauth.GrantClientCredentials,
+				oauth.GrantDeviceCode,
+			},
+			SubjectTypesSupported:             []string{"public"},
+			IDTokenSigningAlgValuesSupported:  []string{h.conf.Auth.JWT.Alg},
+			TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+			CodeChallengeMethodsSupported:     []string{oauth.MethodS256},
+			ClaimsSupported: []string{
+				"sub", "iss", "aud", "exp", "iat", "nonce",
+				"email", "email_verified", "name", "picture",
+			},
+		},
+	)
+}
+
+// userInfo godoc
+//
+//	@Summary		OpenID Connect userinfo
+//	@Description	Returns claims about the user the access token was issued for. Client tokens need the openid scope, email and profile add their claims
+//	@Tags			OpenID Connect
+//	@Produce		json
+//	@Param			Authorization	header		string	true	"Authorization token"
+//	@Success		200				{object}	dto.UserInfoResponse
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"insufficient scope"
+//	@Failure		404				{object}	utils.ErrorsResponse	"user not found"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/userinfo [get]
+func (h *Handler) userInfo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(config.ClaimsKey).(jwt.Claims)
+	if !ok {
+		zap.L().Error(
+			"failed to get claims from context",
+			zap.Any("claims", r.Context().Value(config.ClaimsKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	res, err := h.ctrl.UserInfo(r.Context(), claims.UID, claims.Scope)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// endSession godoc
+//
+//	@Summary		RP-initiated logout
+//	@Description	Ends the session of the user named by id_token_hint on this device and clears JWT cookies. Redirects to post_logout_redirect_uri, which must be a redirect URI of the client, with state. Parameters can also be sent as a form
+//	@Tags			OpenID Connect
+//	@Produce		json
+//	@Param			id_token_hint				query	string	true	"ID token issued to the client"
+//	@Param			client_id					query	string	false	"Client ID, must match the ID token audience"
+//	@Param			post_logout_redirect_uri	query	string	false	"Where to send the user agent afterwards"
+//	@Param			state						query	string	false	"Opaque value returned to the client"
+//	@Param			User-Agent					header	string	true	"Client User-Agent"
+//	@Success		200							"Logged out"
+//	@Success		302							"Redirect to the client"
+//	@Failure		400							{object}	oauth.Error	"invalid hint, client or redirect URI"
+//	@Failure		500							{object}	oauth.Error	"internal error"
+//	@Router			/oauth/logout [get]
+func (h *Handler) endSession(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		oauthErrResponse(w, oauth.ErrInvalidRequest)
+		return
+	}
+
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	device := auth.GenerateDevice(&d)
+	res, err := h.ctrl.EndSession(
+		r.Context(), &dto.EndSessionRequest{
+			IDTokenHint:           r.Form.Get("id_token_hint"),
+			ClientID:              r.Form.Get("client_id"),
+			PostLogoutRedirectURI: r.Form.Get("post_logout_redirect_uri"),
+			State:                 r.Form.Get("state"),
+		}, device.ID,
+	)
+	if err != nil {
+		oauthErrResponse(w, err)
+		return
+	}
+
+	utils.ClearAuthCookies(w)
+	if res.RedirectTo != "" {
+		http.Redirect(w, r, res.RedirectTo, http.StatusFound)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusOK)
+}
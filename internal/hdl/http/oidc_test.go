@@ -0,0 +1,185 @@
+package http
+
+import (
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_OpenIDConfiguration(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	conf := config.Config{}
+	conf.Server.Scheme = "https"
+	conf.Server.Domain = "sso.example.com"
+	conf.Auth.JWT.Alg = jwt.ES256
+	h := New(conf, mocks.NewMockCore(mock), mocks.NewMockAppCtrl(mock), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil)
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	res := &dto.OpenIDConfiguration{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(res))
+	assert.Equal(t, "https://sso.example.com", res.Issuer)
+	assert.Equal(t, "https://sso.example.com/.well-known/jwks.json", res.JWKSURI)
+	assert.Equal(t, "https://sso.example.com/userinfo", res.UserinfoEndpoint)
+	assert.Equal(t, []string{jwt.ES256}, res.IDTokenSigningAlgValuesSupported)
+	assert.Contains(t, res.ScopesSupported, oauth.ScopeOpenID)
+}
+
+func TestHandler_UserInfo(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	uid := uuid.New()
+	client := func(scope string) jwt.Claims {
+		return jwt.Claims{UID: uid, Type: jwt.TypeAccess, ClientID: "app", Scope: scope}
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.Claims
+		status int
+		expect func()
+	}{
+		{
+			name:   "Success",
+			claims: client("openid email"),
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().
+					UserInfo(gomock.Any(), uid, "openid email").
+					Return(&dto.UserInfoResponse{Sub: uid.String(), Email: "user@example.com"}, nil)
+			},
+		},
+		{
+			name:   "NoOpenIDScope",
+			claims: client("profile"),
+			status: http.StatusForbidden,
+			expect: func() {},
+		},
+		{
+			name:   "NotFound",
+			claims: client("openid"),
+			status: http.StatusNotFound,
+			expect: func() {
+				mctrl.EXPECT().UserInfo(gomock.Any(), uid, "openid").Return(nil, ctrl.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				mauth.EXPECT().ParseClaims(gomock.Any(), "token").Return(tt.claims, nil)
+				mauth.EXPECT().CheckRevoked(gomock.Any(), tt.claims).Return(nil)
+
+				req := httptest.NewRequest(http.MethodGet, "/userinfo", nil)
+				req.Header.Set("Authorization", "Bearer token")
+
+				w := httptest.NewRecorder()
+				h.Router.ServeHTTP(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				if tt.status == http.StatusOK {
+					res := &dto.UserInfoResponse{}
+					assert.Nil(t, json.NewDecoder(w.Result().Body).Decode(res))
+					assert.Equal(t, uid.String(), res.Sub)
+				}
+			},
+		)
+	}
+}
+
+func TestHandler_EndSession(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name       string
+		status     int
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Redirect",
+			status: http.StatusFound,
+			expect: func() {
+				mctrl.EXPECT().
+					EndSession(
+						gomock.Any(), &dto.EndSessionRequest{
+							IDTokenHint:           "hint",
+							PostLogoutRedirectURI: "https://app.example.com/cb",
+							State:                 "xyz",
+						}, gomock.Any(),
+					).
+					Return(&dto.EndSessionResponse{RedirectTo: "https://app.example.com/cb?state=xyz"}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Equal(t, "https://app.example.com/cb?state=xyz", r.Header().Get("Location"))
+				assert.NotEmpty(t, r.Result().Cookies())
+			},
+		},
+		{
+			name:   "InvalidHint",
+			status: http.StatusBadRequest,
+			expect: func() {
+				mctrl.EXPECT().
+					EndSession(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, oauth.ErrInvalidRequest.WithDescription("invalid id_token_hint"))
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Empty(t, r.Header().Get("Location"))
+
+				res := &oauth.Error{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, "invalid_request", res.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				uri := "/oauth/logout?id_token_hint=hint&post_logout_redirect_uri=https%3A%2F%2Fapp.example.com%2Fcb&state=xyz"
+				req := httptest.NewRequest(http.MethodGet, uri, nil)
+				req.Header.Set("User-Agent", "test-user-agent")
+
+				w := httptest.NewRecorder()
+				h.Router.ServeHTTP(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
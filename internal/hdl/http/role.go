@@ -0,0 +1,169 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	_ "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) RegisterRoleRoutes() {
+	read := h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermRolesRead))
+	read.Get("/roles", h.listRoles)
+	read.Get("/users/{id}/roles", h.listUserRoles)
+
+	assign := h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermRolesAssign))
+	assign.Post("/users/{id}/roles", h.assignRole)
+	assign.Delete("/users/{id}/roles/{role}", h.revokeRole)
+}
+
+// listRoles godoc
+//
+//	@Summary		List roles
+//	@Description	Returns all roles with their permissions. Requires roles:read
+//	@Tags			Role
+//	@Produce		json
+//	@Success		200	{array}		models.Role
+//	@Failure		401	{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403	{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500	{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/roles [get]
+func (h *Handler) listRoles(w http.ResponseWriter, r *http.Request) {
+	res, err := h.ctrl.ListRoles(r.Context())
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// listUserRoles godoc
+//
+//	@Summary		List user roles
+//	@Description	Returns roles assigned to the user. Requires roles:read
+//	@Tags			Role
+//	@Produce		json
+//	@Param			id	path		string	true	"User UUID"
+//	@Success		200	{array}		models.Role
+//	@Failure		401	{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403	{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500	{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/users/{id}/roles [get]
+func (h *Handler) listUserRoles(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zap.L().Debug(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	res, err := h.ctrl.ListUserRoles(r.Context(), uid)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// assignRole godoc
+//
+//	@Summary		Assign role
+//	@Description	Grants a role to the user. Requires roles:assign
+//	@Tags			Role
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string					true	"User UUID"
+//	@Param			body	body		dto.AssignRoleRequest	true	"Role name"
+//	@Success		204		{object}	nil						"No Content"
+//	@Failure		400		{object}	utils.ErrorsResponse	"invalid request"
+//	@Failure		401		{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403		{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404		{object}	utils.ErrorsResponse	"user or role not found"
+//	@Failure		500		{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/users/{id}/roles [post]
+func (h *Handler) assignRole(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zap.L().Debug(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	req := &dto.AssignRoleRequest{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	err = h.ctrl.AssignRole(r.Context(), uid, req.Role)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
+
+// revokeRole godoc
+//
+//	@Summary		Revoke role
+//	@Description	Removes a role from the user. Requires roles:assign
+//	@Tags			Role
+//	@Produce		json
+//	@Param			id		path		string					true	"User UUID"
+//	@Param			role	path		string					true	"Role name"
+//	@Success		204		{object}	nil						"No Content"
+//	@Failure		400		{object}	utils.ErrorsResponse	"invalid UUID"
+//	@Failure		401		{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403		{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404		{object}	utils.ErrorsResponse	"role is not assigned"
+//	@Failure		500		{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/users/{id}/roles/{role} [delete]
+func (h *Handler) revokeRole(w http.ResponseWriter, r *http.Request) {
+	uid, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		zap.L().Debug(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	err = h.ctrl.RevokeRole(r.Context(), uid, chi.URLParam(r, "role"))
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
@@ -0,0 +1,256 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_AssignRole(t *testing.T) {
+	const uriTemplate = "/users/%s/roles"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	testUUID := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		uid    string
+		status int
+		body   any
+		expect func()
+	}{
+		{
+			name:   "ErrFailedToParseUUID",
+			uid:    "invalid-uuid",
+			status: http.StatusBadRequest,
+			body:   &dto.AssignRoleRequest{Role: "admin"},
+			expect: func() {},
+		},
+		{
+			name:   "ValidationError",
+			uid:    testUUID.String(),
+			status: http.StatusBadRequest,
+			body:   &dto.AssignRoleRequest{},
+			expect: func() {},
+		},
+		{
+			name:   "StatusNotFound",
+			uid:    testUUID.String(),
+			status: http.StatusNotFound,
+			body:   &dto.AssignRoleRequest{Role: "unknown"},
+			expect: func() {
+				mctrl.EXPECT().AssignRole(gomock.Any(), testUUID, "unknown").Return(ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:   "StatusInternalServerError",
+			uid:    testUUID.String(),
+			status: http.StatusInternalServerError,
+			body:   &dto.AssignRoleRequest{Role: "admin"},
+			expect: func() {
+				mctrl.EXPECT().AssignRole(gomock.Any(), testUUID, "admin").Return(testErr)
+			},
+		},
+		{
+			name:   "Success",
+			uid:    testUUID.String(),
+			status: http.StatusNoContent,
+			body:   &dto.AssignRoleRequest{Role: "admin"},
+			expect: func() {
+				mctrl.EXPECT().AssignRole(gomock.Any(), testUUID, "admin").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			payload, err := json.Marshal(tt.body)
+			assert.Nil(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(uriTemplate, tt.uid), bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.uid)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.assignRole(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+
+			defer func() {
+				assert.Nil(t, w.Result().Body.Close())
+			}()
+		})
+	}
+}
+
+func TestHandler_RevokeRole(t *testing.T) {
+	const uriTemplate = "/users/%s/roles/%s"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testUUID := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		uid    string
+		status int
+		expect func()
+	}{
+		{
+			name:   "ErrFailedToParseUUID",
+			uid:    "invalid-uuid",
+			status: http.StatusBadRequest,
+			expect: func() {},
+		},
+		{
+			name:   "StatusNotFound",
+			uid:    testUUID.String(),
+			status: http.StatusNotFound,
+			expect: func() {
+				mctrl.EXPECT().RevokeRole(gomock.Any(), testUUID, "admin").Return(ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:   "Success",
+			uid:    testUUID.String(),
+			status: http.StatusNoContent,
+			expect: func() {
+				mctrl.EXPECT().RevokeRole(gomock.Any(), testUUID, "admin").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf(uriTemplate, tt.uid, "admin"), nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.uid)
+			rctx.URLParams.Add("role", "admin")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.revokeRole(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+
+			defer func() {
+				assert.Nil(t, w.Result().Body.Close())
+			}()
+		})
+	}
+}
+
+func TestHandler_Permissions(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	callerID := uuid.New()
+	otherID := uuid.New()
+	user := jwt.Claims{UID: callerID, Type: jwt.TypeAccess}
+	admin := jwt.Claims{UID: callerID, Type: jwt.TypeAccess, Roles: []string{"admin"}, Permissions: []string{auth.PermUsersDelete}}
+
+	tests := []struct {
+		name   string
+		method string
+		uri    string
+		claims jwt.Claims
+		status int
+		expect func()
+	}{
+		{
+			name:   "ListUsers_Forbidden",
+			method: http.MethodGet,
+			uri:    "/users",
+			claims: user,
+			status: http.StatusForbidden,
+			expect: func() {},
+		},
+		{
+			name:   "DeleteOtherUser_Forbidden",
+			method: http.MethodDelete,
+			uri:    "/users/" + otherID.String(),
+			claims: user,
+			status: http.StatusForbidden,
+			expect: func() {},
+		},
+		{
+			name:   "DeleteSelf",
+			method: http.MethodDelete,
+			uri:    "/users/" + callerID.String(),
+			claims: user,
+			status: http.StatusNoContent,
+			expect: func() {
+				mctrl.EXPECT().DeleteUser(gomock.Any(), callerID).Return(nil)
+			},
+		},
+		{
+			name:   "DeleteOtherUser_WithPermission",
+			method: http.MethodDelete,
+			uri:    "/users/" + otherID.String(),
+			claims: admin,
+			status: http.StatusNoContent,
+			expect: func() {
+				mctrl.EXPECT().DeleteUser(gomock.Any(), otherID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+			mauth.EXPECT().ParseClaims(gomock.Any(), "token").Return(tt.claims, nil)
+			mauth.EXPECT().CheckRevoked(gomock.Any(), tt.claims).Return(nil)
+
+			req := httptest.NewRequest(tt.method, tt.uri, nil)
+			req.AddCookie(&http.Cookie{Name: config.AccessCookieName, Value: "token"})
+
+			w := httptest.NewRecorder()
+			h.Router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+
+			if tt.status == http.StatusForbidden {
+				res := &utils.ErrorsResponse{}
+				assert.Nil(t, json.NewDecoder(w.Result().Body).Decode(res))
+				assert.Equal(t, auth.ErrPermissionDenied.Error(), res.Errors[0])
+			}
+
+			defer func() {
+				assert.Nil(t, w.Result().Body.Close())
+			}()
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	_ "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) RegisterServiceAccountRoutes() {
+	sa := h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermServiceAccounts))
+	sa.Post("/service-accounts", h.createServiceAccount)
+	sa.Get("/service-accounts", h.listServiceAccounts)
+	sa.Delete("/service-accounts/{id}", h.deleteServiceAccount)
+	sa.Post("/service-accounts/{id}/keys", h.createAPIKey)
+	sa.Get("/service-accounts/{id}/keys", h.listAPIKeys)
+	sa.Delete("/service-accounts/{id}/keys/{keyID}", h.deleteAPIKey)
+}
+
+// createServiceAccount godoc
+//
+//	@Summary		Create a service account
+//	@Description	Service accounts are principals for machine clients. They authenticate with API keys instead of a password. Requires service-accounts:manage
+//	@Tags			Service accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Authorization token"
+//	@Param			body			body		dto.CreateServiceAccountRequest	true	"Service account"
+//	@Success		201				{object}	models.ServiceAccount
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid payload"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		409				{object}	utils.ErrorsResponse	"name is taken"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/service-accounts [post]
+func (h *Handler) createServiceAccount(w http.ResponseWriter, r *http.Request) {
+	req := &dto.CreateServiceAccountRequest{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.CreateServiceAccount(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrAlreadyExists) {
+			utils.ErrResponse(w, http.StatusConflict, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, res)
+}
+
+// listServiceAccounts godoc
+//
+//	@Summary		List service accounts
+//	@Description	Requires service-accounts:manage
+//	@Tags			Service accounts
+//	@Produce		json
+//	@Param			Authorization	header		string	true	"Authorization token"
+//	@Success		200				{array}		models.ServiceAccount
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/service-accounts [get]
+func (h *Handler) listServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	res, err := h.ctrl.ListServiceAccounts(r.Context())
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// deleteServiceAccount godoc
+//
+//	@Summary		Delete a service account
+//	@Description	Removes the service account with its API keys. Requires service-accounts:manage
+//	@Tags			Service accounts
+//	@Param			id	path	string	true	"Service account ID"
+//	@Produce		json
+//	@Param			Authorization	header	string	true	"Authorization token"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid service account ID"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404				{object}	utils.ErrorsResponse	"service account not found"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/service-accounts/{id} [delete]
+func (h *Handler) deleteServiceAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := h.ctrl.DeleteServiceAccount(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
+
+// createAPIKey godoc
+//
+//	@Summary		Create an API key
+//	@Description	Issues a named key of the service account. The key is only returned here. Scopes are permissions the key grants and must be held by the caller. Send the key as a bearer token. Requires service-accounts:manage
+//	@Tags			Service accounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		string					true	"Service account ID"
+//	@Param			Authorization	header		string					true	"Authorization token"
+//	@Param			body			body		dto.CreateAPIKeyRequest	true	"API key settings"
+//	@Success		201				{object}	dto.CreateAPIKeyResponse
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid payload or expiry"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404				{object}	utils.ErrorsResponse	"service account not found"
+//	@Failure		409				{object}	utils.ErrorsResponse	"name is taken"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/service-accounts/{id}/keys [post]
+func (h *Handler) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(config.ClaimsKey).(jwt.Claims)
+	if !ok {
+		zap.L().Error(
+			"failed to get claims from context",
+			zap.Any("claims", r.Context().Value(config.ClaimsKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	id, ok := parseUUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	req := &dto.CreateAPIKeyRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.CreateAPIKey(r.Context(), claims, id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ctrl.ErrInvalidAPIKey):
+			utils.ErrResponse(w, http.StatusBadRequest, err)
+		case errors.Is(err, auth.ErrPermissionDenied):
+			utils.ErrResponse(w, http.StatusForbidden, err)
+		case errors.Is(err, ctrl.ErrNotFound):
+			utils.ErrResponse(w, http.StatusNotFound, err)
+		case errors.Is(err, ctrl.ErrAlreadyExists):
+			utils.ErrResponse(w, http.StatusConflict, err)
+		default:
+			utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		}
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, res)
+}
+
+// listAPIKeys godoc
+//
+//	@Summary		List API keys
+//	@Description	Returns the keys of the service account with their prefix and last use, never the keys themselves. Requires service-accounts:manage
+//	@Tags			Service accounts
+//	@Produce		json
+//	@Param			id				path		string	true	"Service account ID"
+//	@Param			Authorization	header		string	true	"Authorization token"
+//	@Success		200				{array}		models.APIKey
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid service account ID"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/service-accounts/{id}/keys [get]
+func (h *Handler) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseUUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	res, err := h.ctrl.ListAPIKeys(r.Context(), id)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// deleteAPIKey godoc
+//
+//	@Summary		Revoke an API key
+//	@Description	Deletes the key, requests using it are rejected right away. Requires service-accounts:manage
+//	@Tags			Service accounts
+//	@Produce		json
+//	@Param			id				path	string	true	"Service account ID"
+//	@Param			keyID			path	string	true	"API key ID"
+//	@Param			Authorization	header	string	true	"Authorization token"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid ID"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404				{object}	utils.ErrorsResponse	"api key not found"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/service-accounts/{id}/keys/{keyID} [delete]
+func (h *Handler) deleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	saID, ok := parseUUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	id, ok := parseUUIDParam(w, r, "keyID")
+	if !ok {
+		return
+	}
+
+	err := h.ctrl.DeleteAPIKey(r.Context(), saID, id)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
+
+// parseUUIDParam parses the path parameter name. It writes the error
+// response itself.
+func parseUUIDParam(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		zap.L().Debug(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrFailedToParseUUID)
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,184 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_CreateServiceAccount(t *testing.T) {
+	const uri = "/service-accounts"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		status int
+		body   any
+		expect func()
+	}{
+		{
+			name:   "ValidationError",
+			status: http.StatusBadRequest,
+			body:   &dto.CreateServiceAccountRequest{},
+			expect: func() {},
+		},
+		{
+			name:   "StatusConflict",
+			status: http.StatusConflict,
+			body:   &dto.CreateServiceAccountRequest{Name: "ci"},
+			expect: func() {
+				mctrl.EXPECT().CreateServiceAccount(gomock.Any(), gomock.Any()).Return(nil, ctrl.ErrAlreadyExists)
+			},
+		},
+		{
+			name:   "StatusInternalServerError",
+			status: http.StatusInternalServerError,
+			body:   &dto.CreateServiceAccountRequest{Name: "ci"},
+			expect: func() {
+				mctrl.EXPECT().CreateServiceAccount(gomock.Any(), gomock.Any()).Return(nil, errors.New("testErr"))
+			},
+		},
+		{
+			name:   "Success",
+			status: http.StatusCreated,
+			body:   &dto.CreateServiceAccountRequest{Name: "ci"},
+			expect: func() {
+				mctrl.EXPECT().
+					CreateServiceAccount(gomock.Any(), &dto.CreateServiceAccountRequest{Name: "ci"}).
+					Return(&models.ServiceAccount{ID: uuid.New(), Name: "ci"}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			payload, err := json.Marshal(tt.body)
+			assert.Nil(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			h.createServiceAccount(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+
+			defer func() {
+				assert.Nil(t, w.Result().Body.Close())
+			}()
+		})
+	}
+}
+
+func TestHandler_CreateAPIKey(t *testing.T) {
+	const uriTemplate = "/service-accounts/%s/keys"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testUUID := uuid.New()
+	claims := jwt.Claims{UID: uuid.New(), Permissions: []string{auth.PermServiceAccounts}}
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+		body   any
+		expect func()
+	}{
+		{
+			name:   "ErrFailedToParseUUID",
+			id:     "invalid-uuid",
+			status: http.StatusBadRequest,
+			body:   &dto.CreateAPIKeyRequest{Name: "deploy"},
+			expect: func() {},
+		},
+		{
+			name:   "ValidationError",
+			id:     testUUID.String(),
+			status: http.StatusBadRequest,
+			body:   &dto.CreateAPIKeyRequest{},
+			expect: func() {},
+		},
+		{
+			name:   "ScopeNotHeld",
+			id:     testUUID.String(),
+			status: http.StatusForbidden,
+			body:   &dto.CreateAPIKeyRequest{Name: "deploy", Scopes: []string{auth.PermUsersDelete}},
+			expect: func() {
+				mctrl.EXPECT().
+					CreateAPIKey(gomock.Any(), claims, testUUID, gomock.Any()).
+					Return(nil, auth.ErrPermissionDenied)
+			},
+		},
+		{
+			name:   "StatusNotFound",
+			id:     testUUID.String(),
+			status: http.StatusNotFound,
+			body:   &dto.CreateAPIKeyRequest{Name: "deploy"},
+			expect: func() {
+				mctrl.EXPECT().CreateAPIKey(gomock.Any(), claims, testUUID, gomock.Any()).Return(nil, ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:   "Success",
+			id:     testUUID.String(),
+			status: http.StatusCreated,
+			body:   &dto.CreateAPIKeyRequest{Name: "deploy"},
+			expect: func() {
+				mctrl.EXPECT().
+					CreateAPIKey(gomock.Any(), claims, testUUID, &dto.CreateAPIKeyRequest{Name: "deploy"}).
+					Return(&dto.CreateAPIKeyResponse{APIKey: &models.APIKey{Name: "deploy"}, Key: "sk_0123abcd_key"}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			payload, err := json.Marshal(tt.body)
+			assert.Nil(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(uriTemplate, tt.id), bytes.NewBuffer(payload))
+			req.Header.Set("Content-Type", "application/json")
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			req = req.WithContext(context.WithValue(ctx, config.ClaimsKey, claims))
+
+			w := httptest.NewRecorder()
+			h.createAPIKey(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+
+			defer func() {
+				assert.Nil(t, w.Result().Body.Close())
+			}()
+		})
+	}
+}
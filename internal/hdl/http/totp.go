@@ -0,0 +1,232 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) RegisterTwoFactorRoutes() {
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Post("/auth/2fa/totp/enroll", h.enrollTOTP)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Post("/auth/2fa/totp/confirm", h.confirmTOTP)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Delete("/auth/2fa/totp", h.disableTOTP)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth), h.withDevice()).Post("/auth/2fa/verify", h.verifyTwoFactor)
+}
+
+// enrollTOTP godoc
+//
+//	@Summary		Start TOTP enrollment
+//	@Description	Generate a new authenticator secret and its otpauth URI. 2FA is enabled once the first code is confirmed
+//	@Tags			Two-factor
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Authorization token"
+//	@Success		200				{object}	dto.TOTPEnrollResponse	"Secret and otpauth URI"
+//	@Failure		404				{object}	utils.ErrorsResponse	"user not found"
+//	@Failure		409				{object}	utils.ErrorsResponse	"2FA is already enabled"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/auth/2fa/totp/enroll [post]
+func (h *Handler) enrollTOTP(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	res, err := h.ctrl.EnrollTOTP(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrAlreadyExists) {
+			utils.ErrResponse(w, http.StatusConflict, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// confirmTOTP godoc
+//
+//	@Summary		Confirm TOTP enrollment
+//	@Description	Enable 2FA with the first authenticator code and return the one-time recovery codes. They are only shown once
+//	@Tags			Two-factor
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string						true	"Authorization token"
+//	@Param			body			body		dto.TOTPCodeRequest			true	"Authenticator code"
+//	@Success		200				{object}	dto.RecoveryCodesResponse	"Recovery codes"
+//	@Failure		400				{object}	utils.ErrorsResponse		"invalid payload"
+//	@Failure		401				{object}	utils.ErrorsResponse		"invalid code"
+//	@Failure		404				{object}	utils.ErrorsResponse		"no pending enrollment"
+//	@Failure		409				{object}	utils.ErrorsResponse		"2FA is already enabled"
+//	@Failure		429				{object}	utils.ErrorsResponse		"too many invalid codes"
+//	@Failure		500				{object}	utils.ErrorsResponse		"internal error"
+//	@Router			/auth/2fa/totp/confirm [post]
+func (h *Handler) confirmTOTP(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	req := &dto.TOTPCodeRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.ConfirmTOTP(r.Context(), uid, req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrCodeIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrTooManyRequests) {
+			utils.ErrResponse(w, http.StatusTooManyRequests, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrAlreadyExists) {
+			utils.ErrResponse(w, http.StatusConflict, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// disableTOTP godoc
+//
+//	@Summary		Disable TOTP
+//	@Description	Turn 2FA off with an authenticator code or a recovery code
+//	@Tags			Two-factor
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header	string					true	"Authorization token"
+//	@Param			body			body	dto.SecondFactorRequest	true	"Authenticator or recovery code"
+//	@Success		204				"2FA disabled"
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid payload"
+//	@Failure		401				{object}	utils.ErrorsResponse	"invalid code"
+//	@Failure		404				{object}	utils.ErrorsResponse	"2FA is not enabled"
+//	@Failure		429				{object}	utils.ErrorsResponse	"too many invalid codes"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/auth/2fa/totp [delete]
+func (h *Handler) disableTOTP(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	req := &dto.SecondFactorRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	err := h.ctrl.DisableTOTP(r.Context(), uid, req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrCodeIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrTooManyRequests) {
+			utils.ErrResponse(w, http.StatusTooManyRequests, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
+
+// verifyTwoFactor godoc
+//
+//	@Summary		Complete a 2FA login
+//	@Description	Exchange the login challenge and an authenticator or recovery code for JWT cookies, or return them with X-Auth-Mode: token. The challenge is bound to the device that started the login
+//	@Tags			Two-factor
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Real-IP	header		string						false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent	header		string						true	"Client User-Agent"
+//	@Param			X-Device-ID	header		string						false	"Stable device ID, the signed device cookie is used otherwise"
+//	@Param			X-Auth-Mode	header		string						false	"Set to 'token' to receive tokens in the body"
+//	@Param			body		body		dto.VerifyTwoFactorRequest	true	"Challenge and code"
+//	@Success		200			{object}	dto.TokenPair				"Successfully authenticated (sets cookies unless X-Auth-Mode: token)"
+//	@Failure		400			{object}	utils.ErrorsResponse
+//	@Failure		401			{object}	utils.ErrorsResponse
+//	@Failure		429			{object}	utils.ErrorsResponse
+//	@Failure		500			{object}	utils.ErrorsResponse
+//	@Router			/auth/2fa/verify [post]
+func (h *Handler) verifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	req := &dto.VerifyTwoFactorRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.VerifyTwoFactor(r.Context(), &d, req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrChallengeIsNotValid) || errors.Is(err, ctrl.ErrCodeIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrTooManyRequests) {
+			utils.ErrResponse(w, http.StatusTooManyRequests, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.TokensResponse(w, r, res)
+}
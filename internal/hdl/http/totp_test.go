@@ -0,0 +1,338 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_EnrollTOTP(t *testing.T) {
+	const uri = "/auth/2fa/totp/enroll"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name       string
+		status     int
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().
+					EnrollTOTP(gomock.Any(), uid).
+					Return(&dto.TOTPEnrollResponse{Secret: "SECRET", URI: "otpauth://totp/sso"}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &dto.TOTPEnrollResponse{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, "SECRET", res.Secret)
+			},
+		},
+		{
+			name:   "AlreadyEnabled",
+			status: http.StatusConflict,
+			expect: func() {
+				mctrl.EXPECT().EnrollTOTP(gomock.Any(), uid).Return(nil, ctrl.ErrAlreadyExists)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				req := httptest.NewRequest(http.MethodPost, uri, nil)
+				req = req.WithContext(context.WithValue(req.Context(), config.UidKey, uid))
+
+				w := httptest.NewRecorder()
+				h.enrollTOTP(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_ConfirmTOTP(t *testing.T) {
+	const uri = "/auth/2fa/totp/confirm"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name    string
+		status  int
+		payload map[string]any
+		expect  func()
+	}{
+		{
+			name:    "Success",
+			status:  http.StatusOK,
+			payload: map[string]any{"code": "123456"},
+			expect: func() {
+				mctrl.EXPECT().
+					ConfirmTOTP(gomock.Any(), uid, &dto.TOTPCodeRequest{Code: "123456"}).
+					Return(&dto.RecoveryCodesResponse{Codes: []string{"abcd-efgh"}}, nil)
+			},
+		},
+		{
+			name:    "InvalidPayload",
+			status:  http.StatusBadRequest,
+			payload: map[string]any{"code": "12ab"},
+			expect:  func() {},
+		},
+		{
+			name:    "InvalidCode",
+			status:  http.StatusUnauthorized,
+			payload: map[string]any{"code": "123456"},
+			expect: func() {
+				mctrl.EXPECT().
+					ConfirmTOTP(gomock.Any(), uid, &dto.TOTPCodeRequest{Code: "123456"}).
+					Return(nil, ctrl.ErrCodeIsNotValid)
+			},
+		},
+		{
+			name:    "TooManyFailures",
+			status:  http.StatusTooManyRequests,
+			payload: map[string]any{"code": "123456"},
+			expect: func() {
+				mctrl.EXPECT().
+					ConfirmTOTP(gomock.Any(), uid, &dto.TOTPCodeRequest{Code: "123456"}).
+					Return(nil, ctrl.ErrTooManyRequests)
+			},
+		},
+		{
+			name:    "NotEnrolled",
+			status:  http.StatusNotFound,
+			payload: map[string]any{"code": "123456"},
+			expect: func() {
+				mctrl.EXPECT().
+					ConfirmTOTP(gomock.Any(), uid, &dto.TOTPCodeRequest{Code: "123456"}).
+					Return(nil, ctrl.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+				req = req.WithContext(context.WithValue(req.Context(), config.UidKey, uid))
+
+				w := httptest.NewRecorder()
+				h.confirmTOTP(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+				assert.Nil(t, w.Result().Body.Close())
+			},
+		)
+	}
+}
+
+func TestHandler_DisableTOTP(t *testing.T) {
+	const uri = "/auth/2fa/totp"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name    string
+		status  int
+		payload map[string]any
+		expect  func()
+	}{
+		{
+			name:    "RecoveryCode",
+			status:  http.StatusNoContent,
+			payload: map[string]any{"recoveryCode": "abcd-efgh"},
+			expect: func() {
+				mctrl.EXPECT().
+					DisableTOTP(gomock.Any(), uid, &dto.SecondFactorRequest{RecoveryCode: "abcd-efgh"}).
+					Return(nil)
+			},
+		},
+		{
+			name:    "NoCode",
+			status:  http.StatusBadRequest,
+			payload: map[string]any{},
+			expect:  func() {},
+		},
+		{
+			name:    "NotEnabled",
+			status:  http.StatusNotFound,
+			payload: map[string]any{"code": "123456"},
+			expect: func() {
+				mctrl.EXPECT().
+					DisableTOTP(gomock.Any(), uid, &dto.SecondFactorRequest{Code: "123456"}).
+					Return(ctrl.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodDelete, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+				req = req.WithContext(context.WithValue(req.Context(), config.UidKey, uid))
+
+				w := httptest.NewRecorder()
+				h.disableTOTP(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+				assert.Nil(t, w.Result().Body.Close())
+			},
+		)
+	}
+}
+
+func TestHandler_VerifyTwoFactor(t *testing.T) {
+	const uri = "/auth/2fa/verify"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	device := &dto.DeviceRequest{IP: "0.0.0.0", UA: "user-agent"}
+	validReq := &dto.VerifyTwoFactorRequest{
+		Challenge:           "challenge",
+		SecondFactorRequest: dto.SecondFactorRequest{Code: "123456"},
+	}
+	validPayload := map[string]any{"challenge": "challenge", "code": "123456"}
+
+	tests := []struct {
+		name       string
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "Success",
+			status:  http.StatusOK,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					VerifyTwoFactor(gomock.Any(), device, validReq).
+					Return(&dto.TokenPair{Access: "token", Refresh: "token"}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Contains(t, r.Header().Get("Set-Cookie"), config.AccessCookieName)
+			},
+		},
+		{
+			name:       "MissingChallenge",
+			status:     http.StatusBadRequest,
+			payload:    map[string]any{"code": "123456"},
+			expect:     func() {},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+		{
+			name:    "InvalidChallenge",
+			status:  http.StatusUnauthorized,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					VerifyTwoFactor(gomock.Any(), device, validReq).
+					Return(nil, ctrl.ErrChallengeIsNotValid)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &utils.ErrorsResponse{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, ctrl.ErrChallengeIsNotValid.Error(), res.Errors[0])
+			},
+		},
+		{
+			name:    "InvalidCode",
+			status:  http.StatusUnauthorized,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					VerifyTwoFactor(gomock.Any(), device, validReq).
+					Return(nil, ctrl.ErrCodeIsNotValid)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+		{
+			name:    "TooManyFailures",
+			status:  http.StatusTooManyRequests,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					VerifyTwoFactor(gomock.Any(), device, validReq).
+					Return(nil, ctrl.ErrTooManyRequests)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+				ctx := context.WithValue(req.Context(), config.IpKey, "0.0.0.0")
+				ctx = context.WithValue(ctx, config.UaKey, "user-agent")
+				req = req.WithContext(ctx)
+
+				w := httptest.NewRecorder()
+				h.verifyTwoFactor(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
 	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/ctrl"
 	"github.com/JMURv/golang-clean-template/internal/dto"
@@ -11,6 +13,7 @@ import (
 	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
 	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
 	_ "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
 	"github.com/JMURv/golang-clean-template/internal/repo/s3"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -20,25 +23,30 @@ import (
 )
 
 func (h *Handler) RegisterUserRoutes() {
-	h.Router.Post("/users/exists", h.existsUser)
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{})).Get("/users/me", h.getMe)
-	h.Router.Get("/users", h.listUsers)
-	h.Router.Post("/users", h.createUser)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyUsersExists)).Post("/users/exists", h.existsUser)
+	h.Router.With(h.withAuth(mid.AuthOpts{Clients: true, Scope: oauth.ScopeProfile})).Get("/users/me", h.getMe)
+	h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermUsersList)).Get("/users", h.listUsers)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth)).Post("/users", h.createUser)
 	h.Router.Get("/users/{id}", h.getUser)
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{CheckAuthor: true})).Put("/users/{id}", h.updateUser)
-	h.Router.With(mid.Auth(h.au, mid.AuthOpts{})).Delete("/users/{id}", h.deleteUser)
+	h.Router.With(h.withAuth(mid.AuthOpts{CheckAuthor: true, Permission: auth.PermUsersUpdate})).
+		Put("/users/{id}", h.updateUser)
+	h.Router.With(h.withAuth(mid.AuthOpts{CheckAuthor: true, Permission: auth.PermUsersDelete})).
+		Delete("/users/{id}", h.deleteUser)
+	h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermUsersUpdate)).
+		Post("/users/{id}/unlock", h.unlockUser)
 }
 
 // existsUser godoc
 //
 //	@Summary		Check if a user exists by email
-//	@Description	Returns 200 if user exists, 404 otherwise
+//	@Description	Returns 200 if user exists, 404 otherwise. Limited per client IP, so emails can't be enumerated
 //	@Tags			User
 //	@Accept			json
 //	@Produce		json
 //	@Param			body	body		dto.CheckEmailRequest	true	"Email payload"
 //	@Success		200		{object}	dto.ExistsUserResponse
 //	@Failure		404		{object}	utils.ErrorsResponse	"user not found"
+//	@Failure		429		{object}	utils.ErrorsResponse	"rate limited"
 //	@Failure		500		{object}	utils.ErrorsResponse	"internal error"
 //	@Router			/users/exists [post]
 func (h *Handler) existsUser(w http.ResponseWriter, r *http.Request) {
@@ -63,13 +71,15 @@ func (h *Handler) existsUser(w http.ResponseWriter, r *http.Request) {
 // listUsers godoc
 //
 //	@Summary		List all users
-//	@Description	Retrieve a paginated list of users with optional filters
+//	@Description	Retrieve a paginated list of users with optional filters. Requires users:list
 //	@Tags			User
 //	@Accept			json
 //	@Produce		json
 //	@Param			page	query		int	false	"Page number"	default(1)
 //	@Param			size	query		int	false	"Page size"		default(20)
 //	@Success		200		{array}		dto.PaginatedUserResponse
+//	@Failure		401		{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403		{object}	utils.ErrorsResponse	"permission denied"
 //	@Failure		500		{object}	utils.ErrorsResponse	"internal error"
 //	@Router			/users [get]
 func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
@@ -88,7 +98,7 @@ func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
 // getMe godoc
 //
 //	@Summary		Retrieve current user profile
-//	@Description	Returns the authenticated user's profile
+//	@Description	Returns the authenticated user's profile. OAuth clients need the profile scope
 //	@Tags			User
 //	@Produce		json
 //	@Success		200	{object}	models.User
@@ -219,7 +229,7 @@ func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
 // updateUser godoc
 //
 //	@Summary		Update an existing user
-//	@Description	Updates user profile and avatar
+//	@Description	Updates user profile and avatar. A changed email becomes unverified and gets a verification code. Other users require users:update
 //	@Tags			User
 //	@Accept			multipart/form-data
 //	@Produce		json
@@ -229,6 +239,7 @@ func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
 //	@Success		200		{object}	nil						"OK"
 //	@Failure		400		{object}	utils.ErrorsResponse	"bad request"
 //	@Failure		401		{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403		{object}	utils.ErrorsResponse	"permission denied"
 //	@Failure		404		{object}	utils.ErrorsResponse	"user not found"
 //	@Failure		500		{object}	utils.ErrorsResponse	"internal error"
 //	@Router			/users/{id} [put]
@@ -288,13 +299,14 @@ func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request) {
 // deleteUser godoc
 //
 //	@Summary		Delete a user
-//	@Description	Removes a user by UUID
+//	@Description	Removes a user by UUID. Other users require users:delete
 //	@Tags			User
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		string					true	"User UUID"
 //	@Success		204	{object}	nil						"No Content"
 //	@Failure		401	{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403	{object}	utils.ErrorsResponse	"permission denied"
 //	@Failure		404	{object}	utils.ErrorsResponse	"user not found"
 //	@Failure		500	{object}	utils.ErrorsResponse	"internal error"
 //	@Router			/users/{id} [delete]
@@ -322,3 +334,38 @@ func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
 
 	utils.StatusResponse(w, http.StatusNoContent)
 }
+
+// unlockUser godoc
+//
+//	@Summary		Unlock a user
+//	@Description	Lifts the lockout applied after too many failed logins and forgets the failures. Requires users:update
+//	@Tags			User
+//	@Produce		json
+//	@Param			id				path	string	true	"User UUID"
+//	@Param			Authorization	header	string	true	"Authorization token"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid user ID"
+//	@Failure		401				{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403				{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		404				{object}	utils.ErrorsResponse	"user not found"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/users/{id}/unlock [post]
+func (h *Handler) unlockUser(w http.ResponseWriter, r *http.Request) {
+	uid, ok := parseUUIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	err := h.ctrl.UnlockUser(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
@@ -34,7 +34,7 @@ func TestHandler_ExistsUser(t *testing.T) {
 	testEmail := "test@example.com"
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	tests := []struct {
 		name       string
@@ -169,7 +169,7 @@ func TestHandler_ListUsers(t *testing.T) {
 	testErr := errors.New("testErr")
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	testUsers := dto.PaginatedUserResponse{
 		Data: []*md.User{
@@ -326,7 +326,7 @@ func TestHandler_GetMe(t *testing.T) {
 	testUUID := uuid.New()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	testUser := md.User{
 		ID:              testUUID,
@@ -453,7 +453,7 @@ func TestHandler_GetUser(t *testing.T) {
 	testUUID := uuid.New()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	testUser := md.User{
 		ID:              testUUID,
@@ -581,7 +581,7 @@ func TestHandler_CreateUser(t *testing.T) {
 	testUUID := uuid.New()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	validRequest := map[string]interface{}{
 		"name":     "Test User",
@@ -773,7 +773,7 @@ func TestHandler_UpdateUser(t *testing.T) {
 	testErr := errors.New("testErr")
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	validRequest := map[string]any{
 		"name":  "Test User",
@@ -963,7 +963,7 @@ func TestHandler_DeleteUser(t *testing.T) {
 	testUUID := uuid.New()
 	mctrl := mocks.NewMockAppCtrl(mock)
 	mauth := mocks.NewMockCore(mock)
-	h := New(mauth, mctrl)
+	h := New(config.Config{}, mauth, mctrl, nil)
 
 	tests := []struct {
 		name       string
@@ -1071,3 +1071,73 @@ func TestHandler_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_UnlockUser(t *testing.T) {
+	const uriTemplate = "/users/%s/unlock"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	testErr := errors.New("testErr")
+	testUUID := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		userID string
+		status int
+		expect func()
+	}{
+		{
+			name:   "ErrFailedToParseUUID",
+			userID: "invalid-uuid",
+			status: http.StatusBadRequest,
+			expect: func() {},
+		},
+		{
+			name:   "StatusNotFound",
+			userID: testUUID.String(),
+			status: http.StatusNotFound,
+			expect: func() {
+				mctrl.EXPECT().UnlockUser(gomock.Any(), testUUID).Return(ctrl.ErrNotFound)
+			},
+		},
+		{
+			name:   "StatusInternalServerError",
+			userID: testUUID.String(),
+			status: http.StatusInternalServerError,
+			expect: func() {
+				mctrl.EXPECT().UnlockUser(gomock.Any(), testUUID).Return(testErr)
+			},
+		},
+		{
+			name:   "Success",
+			userID: testUUID.String(),
+			status: http.StatusNoContent,
+			expect: func() {
+				mctrl.EXPECT().UnlockUser(gomock.Any(), testUUID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf(uriTemplate, tt.userID), nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+			w := httptest.NewRecorder()
+			h.unlockUser(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+
+			defer func() {
+				assert.Nil(t, w.Result().Body.Close())
+			}()
+		})
+	}
+}
@@ -128,13 +128,17 @@ func ParseDeviceByRequest(ctx context.Context) (dto.DeviceRequest, bool) {
 		return dto.DeviceRequest{}, false
 	}
 
+	id, _ := ctx.Value(config.DeviceKey).(string)
 	return dto.DeviceRequest{
+		ID: id,
 		IP: ip,
 		UA: ua,
 	}, true
 }
 
-func GetAuthCookies(accessStr, refreshStr string) (*http.Cookie, *http.Cookie) {
+// GetAuthCookies builds the auth cookies. A zero refreshExpires makes the
+// refresh cookie a session cookie that the browser drops on close.
+func GetAuthCookies(accessStr, refreshStr string, refreshExpires time.Time) (*http.Cookie, *http.Cookie) {
 	access := &http.Cookie{
 		Name:     config.AccessCookieName,
 		Value:    accessStr,
@@ -148,7 +152,7 @@ func GetAuthCookies(accessStr, refreshStr string) (*http.Cookie, *http.Cookie) {
 	refresh := &http.Cookie{
 		Name:     config.RefreshCookieName,
 		Value:    refreshStr,
-		Expires:  time.Now().Add(config.RefreshTokenDuration),
+		Expires:  refreshExpires,
 		HttpOnly: true,
 		Secure:   true,
 		Path:     "/",
@@ -158,12 +162,53 @@ func GetAuthCookies(accessStr, refreshStr string) (*http.Cookie, *http.Cookie) {
 	return access, refresh
 }
 
-func SetAuthCookies(w http.ResponseWriter, access, refresh string) {
-	accessCookie, refreshCookie := GetAuthCookies(access, refresh)
+// IsTokenMode reports whether the client asked for tokens in the response body
+// instead of cookies, which is what mobile and service clients use.
+func IsTokenMode(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(config.AuthModeHeader), config.AuthModeToken)
+}
+
+// TokensResponse returns the pair in the body in token mode and sets auth
+// cookies otherwise. The refresh cookie only outlives the browser session
+// when the user asked to be remembered.
+func TokensResponse(w http.ResponseWriter, r *http.Request, pair *dto.TokenPair) {
+	if IsTokenMode(r) {
+		SuccessResponse(w, http.StatusOK, pair)
+		return
+	}
+
+	var exp time.Time
+	if pair.Remember {
+		exp = pair.ExpiresAt
+	}
+
+	SetAuthCookies(w, pair.Access, pair.Refresh, exp)
+	StatusResponse(w, http.StatusOK)
+}
+
+func SetAuthCookies(w http.ResponseWriter, access, refresh string, refreshExpires time.Time) {
+	accessCookie, refreshCookie := GetAuthCookies(access, refresh, refreshExpires)
 	http.SetCookie(w, accessCookie)
 	http.SetCookie(w, refreshCookie)
 }
 
+// ClearAuthCookies expires both auth cookies.
+func ClearAuthCookies(w http.ResponseWriter) {
+	for _, name := range []string{config.AccessCookieName, config.RefreshCookieName} {
+		http.SetCookie(
+			w, &http.Cookie{
+				Name:     name,
+				Value:    "",
+				MaxAge:   -1,
+				HttpOnly: true,
+				Secure:   true,
+				Path:     "/",
+				SameSite: http.SameSiteStrictMode,
+			},
+		)
+	}
+}
+
 var (
 	ErrInvalidFileUpload = errors.New("invalid file upload")
 	ErrFileTooLarge      = errors.New("file too large")
@@ -0,0 +1,286 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+	_ "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) RegisterWebAuthnRoutes() {
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Post("/auth/webauthn/register/begin", h.beginWebAuthnRegistration)
+	h.Router.With(h.withDevice(), h.withAuth(mid.AuthOpts{})).Post(
+		"/auth/webauthn/register/finish",
+		h.finishWebAuthnRegistration,
+	)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth)).Post("/auth/webauthn/login/begin", h.beginWebAuthnLogin)
+	h.Router.With(h.withRateLimit(ratelimit.PolicyAuth), h.withDevice()).
+		Post("/auth/webauthn/login/finish", h.finishWebAuthnLogin)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Get("/auth/webauthn/credentials", h.listWebAuthnCredentials)
+	h.Router.With(h.withAuth(mid.AuthOpts{})).Delete("/auth/webauthn/credentials/{id}", h.deleteWebAuthnCredential)
+}
+
+// beginWebAuthnRegistration godoc
+//
+//	@Summary		Start passkey registration
+//	@Description	Return options for navigator.credentials.create. Passkeys the user already has are excluded
+//	@Tags			WebAuthn
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Authorization token"
+//	@Success		200				{object}	dto.WebAuthnCreationResponse	"Credential creation options"
+//	@Failure		404				{object}	utils.ErrorsResponse			"user not found"
+//	@Failure		500				{object}	utils.ErrorsResponse			"internal error"
+//	@Router			/auth/webauthn/register/begin [post]
+func (h *Handler) beginWebAuthnRegistration(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	res, err := h.ctrl.BeginWebAuthnRegistration(r.Context(), uid)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, &dto.WebAuthnCreationResponse{PublicKey: res})
+}
+
+// finishWebAuthnRegistration godoc
+//
+//	@Summary		Finish passkey registration
+//	@Description	Verify the attestation and store the passkey. It is linked to the current device if the device is known
+//	@Tags			WebAuthn
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string							true	"Authorization token"
+//	@Param			X-Real-IP		header		string							false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent		header		string							true	"Client User-Agent"
+//	@Param			X-Device-ID		header		string							false	"Stable device ID, the signed device cookie is used otherwise"
+//	@Param			body			body		dto.WebAuthnRegistrationRequest	true	"Passkey name and attestation"
+//	@Success		201				{object}	models.WebAuthnCredential		"Registered passkey"
+//	@Failure		400				{object}	utils.ErrorsResponse			"invalid payload"
+//	@Failure		401				{object}	utils.ErrorsResponse			"invalid challenge or credential"
+//	@Failure		409				{object}	utils.ErrorsResponse			"passkey already registered"
+//	@Failure		500				{object}	utils.ErrorsResponse			"internal error"
+//	@Router			/auth/webauthn/register/finish [post]
+func (h *Handler) finishWebAuthnRegistration(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	req := &dto.WebAuthnRegistrationRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.FinishWebAuthnRegistration(r.Context(), uid, &d, req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrChallengeIsNotValid) || errors.Is(err, ctrl.ErrCredentialIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrAlreadyExists) {
+			utils.ErrResponse(w, http.StatusConflict, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, res)
+}
+
+// beginWebAuthnLogin godoc
+//
+//	@Summary		Start passkey login
+//	@Description	Return options for navigator.credentials.get. The options allow any discoverable passkey for the site and don't depend on the email, which only restricts the account that can finish the login
+//	@Tags			WebAuthn
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		dto.WebAuthnLoginRequest	false	"Account email"
+//	@Success		200		{object}	dto.WebAuthnRequestResponse	"Credential request options"
+//	@Failure		400		{object}	utils.ErrorsResponse		"invalid payload"
+//	@Failure		500		{object}	utils.ErrorsResponse		"internal error"
+//	@Router			/auth/webauthn/login/begin [post]
+func (h *Handler) beginWebAuthnLogin(w http.ResponseWriter, r *http.Request) {
+	req := &dto.WebAuthnLoginRequest{}
+	if ok := utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.BeginWebAuthnLogin(r.Context(), req)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, &dto.WebAuthnRequestResponse{PublicKey: res})
+}
+
+// finishWebAuthnLogin godoc
+//
+//	@Summary		Finish passkey login
+//	@Description	Verify the assertion and set JWT cookies, or return them with X-Auth-Mode: token. A passkey verifies the user, so no second factor is asked
+//	@Tags			WebAuthn
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Real-IP	header		string							false	"Client IP, only honoured from trusted proxies"
+//	@Param			User-Agent	header		string							true	"Client User-Agent"
+//	@Param			X-Device-ID	header		string							false	"Stable device ID, the signed device cookie is used otherwise"
+//	@Param			X-Auth-Mode	header		string							false	"Set to 'token' to receive tokens in the body"
+//	@Param			body		body		dto.FinishWebAuthnLoginRequest	true	"Assertion"
+//	@Success		200			{object}	dto.TokenPair					"Successfully authenticated (sets cookies unless X-Auth-Mode: token)"
+//	@Failure		400			{object}	utils.ErrorsResponse
+//	@Failure		401			{object}	utils.ErrorsResponse
+//	@Failure		403			{object}	utils.ErrorsResponse
+//	@Failure		404			{object}	utils.ErrorsResponse
+//	@Failure		500			{object}	utils.ErrorsResponse
+//	@Router			/auth/webauthn/login/finish [post]
+func (h *Handler) finishWebAuthnLogin(w http.ResponseWriter, r *http.Request) {
+	d, ok := utils.ParseDeviceByRequest(r.Context())
+	if !ok {
+		utils.ErrResponse(w, http.StatusBadRequest, ErrNoDeviceInfo)
+		return
+	}
+
+	req := &dto.FinishWebAuthnLoginRequest{}
+	if ok = utils.ParseAndValidate(w, r, req); !ok {
+		return
+	}
+
+	res, err := h.ctrl.FinishWebAuthnLogin(r.Context(), &d, req)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrChallengeIsNotValid) || errors.Is(err, ctrl.ErrCredentialIsNotValid) {
+			utils.ErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrEmailNotVerified) {
+			utils.ErrResponse(w, http.StatusForbidden, err)
+			return
+		}
+
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.TokensResponse(w, r, res)
+}
+
+// listWebAuthnCredentials godoc
+//
+//	@Summary		List passkeys
+//	@Description	Retrieve passkeys registered by the current user
+//	@Tags			WebAuthn
+//	@Produce		json
+//	@Param			Authorization	header		string	true	"Authorization token"
+//	@Success		200				{array}		[]models.WebAuthnCredential
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/auth/webauthn/credentials [get]
+func (h *Handler) listWebAuthnCredentials(w http.ResponseWriter, r *http.Request) {
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	res, err := h.ctrl.ListWebAuthnCredentials(r.Context(), uid)
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, res)
+}
+
+// deleteWebAuthnCredential godoc
+//
+//	@Summary		Delete a passkey
+//	@Description	Remove a passkey of the current user
+//	@Tags			WebAuthn
+//	@Param			id	path	string	true	"Credential ID"
+//	@Produce		json
+//	@Param			Authorization	header	string	true	"Authorization token"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	utils.ErrorsResponse	"invalid credential ID"
+//	@Failure		404				{object}	utils.ErrorsResponse	"passkey not found"
+//	@Failure		500				{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/auth/webauthn/credentials/{id} [delete]
+func (h *Handler) deleteWebAuthnCredential(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		zap.L().Error(
+			hdl.ErrToRetrievePathArg.Error(),
+			zap.String("path", r.URL.Path),
+		)
+		utils.ErrResponse(w, http.StatusBadRequest, hdl.ErrToRetrievePathArg)
+		return
+	}
+
+	uid, ok := r.Context().Value(config.UidKey).(uuid.UUID)
+	if uid == uuid.Nil || !ok {
+		zap.L().Error(
+			hdl.ErrFailedToParseUUID.Error(),
+			zap.Any("uid", r.Context().Value(config.UidKey)),
+		)
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrFailedToParseUUID)
+		return
+	}
+
+	err := h.ctrl.DeleteWebAuthnCredential(r.Context(), uid, id)
+	if err != nil {
+		if errors.Is(err, ctrl.ErrNotFound) {
+			utils.ErrResponse(w, http.StatusNotFound, err)
+			return
+		}
+
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.StatusResponse(w, http.StatusNoContent)
+}
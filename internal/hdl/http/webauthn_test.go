@@ -0,0 +1,334 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/ctrl"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_BeginWebAuthnRegistration(t *testing.T) {
+	const uri = "/auth/webauthn/register/begin"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name       string
+		status     int
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Success",
+			status: http.StatusOK,
+			expect: func() {
+				mctrl.EXPECT().
+					BeginWebAuthnRegistration(gomock.Any(), uid).
+					Return(&webauthn.CreationOptions{Challenge: webauthn.Bytes{1, 2, 3}}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				res := &dto.WebAuthnCreationResponse{}
+				assert.Nil(t, json.NewDecoder(r.Result().Body).Decode(res))
+				assert.Equal(t, webauthn.Bytes{1, 2, 3}, res.PublicKey.Challenge)
+			},
+		},
+		{
+			name:   "UserNotFound",
+			status: http.StatusNotFound,
+			expect: func() {
+				mctrl.EXPECT().BeginWebAuthnRegistration(gomock.Any(), uid).Return(nil, ctrl.ErrNotFound)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				req := httptest.NewRequest(http.MethodPost, uri, nil)
+				req = req.WithContext(context.WithValue(req.Context(), config.UidKey, uid))
+
+				w := httptest.NewRecorder()
+				h.beginWebAuthnRegistration(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_FinishWebAuthnRegistration(t *testing.T) {
+	const uri = "/auth/webauthn/register/finish"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	device := &dto.DeviceRequest{IP: "0.0.0.0", UA: "user-agent"}
+	validPayload := map[string]any{
+		"name": "laptop",
+		"credential": map[string]any{
+			"id":   "AQID",
+			"type": webauthn.PublicKeyType,
+			"response": map[string]any{
+				"clientDataJSON":    "e30",
+				"attestationObject": "oA",
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		payload map[string]any
+		expect  func()
+	}{
+		{
+			name:    "Success",
+			status:  http.StatusCreated,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					FinishWebAuthnRegistration(gomock.Any(), uid, device, gomock.Any()).
+					Return(&models.WebAuthnCredential{ID: "AQID", UserID: uid}, nil)
+			},
+		},
+		{
+			name:    "MissingCredential",
+			status:  http.StatusBadRequest,
+			payload: map[string]any{"name": "laptop"},
+			expect:  func() {},
+		},
+		{
+			name:    "InvalidCredential",
+			status:  http.StatusUnauthorized,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					FinishWebAuthnRegistration(gomock.Any(), uid, device, gomock.Any()).
+					Return(nil, ctrl.ErrCredentialIsNotValid)
+			},
+		},
+		{
+			name:    "AlreadyExists",
+			status:  http.StatusConflict,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					FinishWebAuthnRegistration(gomock.Any(), uid, device, gomock.Any()).
+					Return(nil, ctrl.ErrAlreadyExists)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+				ctx := context.WithValue(req.Context(), config.UidKey, uid)
+				ctx = context.WithValue(ctx, config.IpKey, "0.0.0.0")
+				ctx = context.WithValue(ctx, config.UaKey, "user-agent")
+				req = req.WithContext(ctx)
+
+				w := httptest.NewRecorder()
+				h.finishWebAuthnRegistration(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+				assert.Nil(t, w.Result().Body.Close())
+			},
+		)
+	}
+}
+
+func TestHandler_FinishWebAuthnLogin(t *testing.T) {
+	const uri = "/auth/webauthn/login/finish"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	device := &dto.DeviceRequest{IP: "0.0.0.0", UA: "user-agent"}
+	validPayload := map[string]any{
+		"credential": map[string]any{
+			"id":   "AQID",
+			"type": webauthn.PublicKeyType,
+			"response": map[string]any{
+				"clientDataJSON":    "e30",
+				"authenticatorData": "AA",
+				"signature":         "AA",
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		payload    map[string]any
+		expect     func()
+		assertions func(r *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "Success",
+			status:  http.StatusOK,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					FinishWebAuthnLogin(gomock.Any(), device, gomock.Any()).
+					Return(&dto.TokenPair{Access: "token", Refresh: "token"}, nil)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {
+				assert.Contains(t, r.Header().Get("Set-Cookie"), config.AccessCookieName)
+			},
+		},
+		{
+			name:       "MissingSignature",
+			status:     http.StatusBadRequest,
+			payload:    map[string]any{"credential": map[string]any{"id": "AQID", "type": "public-key"}},
+			expect:     func() {},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+		{
+			name:    "InvalidChallenge",
+			status:  http.StatusUnauthorized,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					FinishWebAuthnLogin(gomock.Any(), device, gomock.Any()).
+					Return(nil, ctrl.ErrChallengeIsNotValid)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+		{
+			name:    "EmailNotVerified",
+			status:  http.StatusForbidden,
+			payload: validPayload,
+			expect: func() {
+				mctrl.EXPECT().
+					FinishWebAuthnLogin(gomock.Any(), device, gomock.Any()).
+					Return(nil, ctrl.ErrEmailNotVerified)
+			},
+			assertions: func(r *httptest.ResponseRecorder) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+				b, err := json.Marshal(tt.payload)
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer(b))
+				req.Header.Set("Content-Type", "application/json")
+				ctx := context.WithValue(req.Context(), config.IpKey, "0.0.0.0")
+				ctx = context.WithValue(ctx, config.UaKey, "user-agent")
+				req = req.WithContext(ctx)
+
+				w := httptest.NewRecorder()
+				h.finishWebAuthnLogin(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+
+				defer func() {
+					assert.Nil(t, w.Result().Body.Close())
+				}()
+
+				tt.assertions(w)
+			},
+		)
+	}
+}
+
+func TestHandler_DeleteWebAuthnCredential(t *testing.T) {
+	const uri = "/auth/webauthn/credentials/"
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	uid := uuid.New()
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	tests := []struct {
+		name   string
+		status int
+		id     string
+		expect func()
+	}{
+		{
+			name:   "Success",
+			status: http.StatusNoContent,
+			id:     "AQID",
+			expect: func() {
+				mctrl.EXPECT().DeleteWebAuthnCredential(gomock.Any(), uid, "AQID").Return(nil)
+			},
+		},
+		{
+			name:   "MissingID",
+			status: http.StatusBadRequest,
+			id:     "",
+			expect: func() {},
+		},
+		{
+			name:   "NotFound",
+			status: http.StatusNotFound,
+			id:     "AQID",
+			expect: func() {
+				mctrl.EXPECT().DeleteWebAuthnCredential(gomock.Any(), uid, "AQID").Return(ctrl.ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.expect()
+
+				req := httptest.NewRequest(http.MethodDelete, uri+tt.id, nil)
+				req = req.WithContext(context.WithValue(req.Context(), config.UidKey, uid))
+
+				rctx := chi.NewRouteContext()
+				rctx.URLParams.Add("id", tt.id)
+				req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+				w := httptest.NewRecorder()
+				h.deleteWebAuthnCredential(w, req)
+				assert.Equal(t, tt.status, w.Result().StatusCode)
+				assert.Nil(t, w.Result().Body.Close())
+			},
+		)
+	}
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	_ "github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/hdl"
+	mid "github.com/JMURv/golang-clean-template/internal/hdl/http/middleware"
+	"github.com/JMURv/golang-clean-template/internal/hdl/http/utils"
+)
+
+func (h *Handler) RegisterWellKnownRoutes() {
+	h.Router.Get("/.well-known/jwks.json", h.jwks)
+	h.Router.With(h.withAuth(mid.AuthOpts{}), mid.RequirePermission(auth.PermKeysRotate)).
+		Post("/keys/rotate", h.rotateKey)
+}
+
+// jwks godoc
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Returns public keys used to verify access tokens, including keys pending retirement. Empty when tokens are signed with HS256
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	jwt.JWKSet
+//	@Router			/.well-known/jwks.json [get]
+func (h *Handler) jwks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.SuccessResponse(w, http.StatusOK, h.au.JWKS())
+}
+
+// rotateKey godoc
+//
+//	@Summary		Rotate signing key
+//	@Description	Generates a new signing key. Tokens signed with the previous key stay valid until it retires. Requires keys:rotate
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	dto.RotateKeyResponse
+//	@Failure		401	{object}	utils.ErrorsResponse	"unauthorized"
+//	@Failure		403	{object}	utils.ErrorsResponse	"permission denied"
+//	@Failure		500	{object}	utils.ErrorsResponse	"internal error"
+//	@Router			/keys/rotate [post]
+func (h *Handler) rotateKey(w http.ResponseWriter, r *http.Request) {
+	kid, err := h.au.Rotate(r.Context())
+	if err != nil {
+		utils.ErrResponse(w, http.StatusInternalServerError, hdl.ErrInternal)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, &dto.RotateKeyResponse{KID: kid})
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/auth"
+	"github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/tests/mocks"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_JWKS(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	set := jwt.JWKSet{Keys: []jwt.JWK{{Kty: "OKP", Crv: "Ed25519", Kid: "kid", X: "x"}}}
+	mauth.EXPECT().JWKS().Return(set)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+
+	var res jwt.JWKSet
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+	assert.Equal(t, set, res)
+}
+
+func TestHandler_RotateKey(t *testing.T) {
+	mock := gomock.NewController(t)
+	defer mock.Finish()
+
+	mctrl := mocks.NewMockAppCtrl(mock)
+	mauth := mocks.NewMockCore(mock)
+	h := New(config.Config{}, mauth, mctrl, nil)
+
+	user := jwt.Claims{UID: uuid.New(), Type: jwt.TypeAccess}
+	admin := jwt.Claims{UID: uuid.New(), Type: jwt.TypeAccess, Permissions: []string{auth.PermKeysRotate}}
+
+	tests := []struct {
+		name   string
+		claims jwt.Claims
+		status int
+		expect func()
+	}{
+		{
+			name:   "Forbidden",
+			claims: user,
+			status: http.StatusForbidden,
+			expect: func() {},
+		},
+		{
+			name:   "ErrInternal",
+			claims: admin,
+			status: http.StatusInternalServerError,
+			expect: func() {
+				mauth.EXPECT().Rotate(gomock.Any()).Return("", errors.New("testErr"))
+			},
+		},
+		{
+			name:   "Success",
+			claims: admin,
+			status: http.StatusOK,
+			expect: func() {
+				mauth.EXPECT().Rotate(gomock.Any()).Return("next", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expect()
+			mauth.EXPECT().ParseClaims(gomock.Any(), "token").Return(tt.claims, nil)
+			mauth.EXPECT().CheckRevoked(gomock.Any(), tt.claims).Return(nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/keys/rotate", nil)
+			req.Header.Set("Authorization", "Bearer token")
+
+			w := httptest.NewRecorder()
+			h.Router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+
+			if tt.status == http.StatusOK {
+				res := &dto.RotateKeyResponse{}
+				assert.Nil(t, json.NewDecoder(w.Result().Body).Decode(res))
+				assert.Equal(t, "next", res.KID)
+			}
+		})
+	}
+}
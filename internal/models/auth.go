@@ -7,14 +7,19 @@ import (
 )
 
 type RefreshToken struct {
-	ID         uint64    `db:"id"           json:"id"`
-	UserID     uuid.UUID `db:"user_id"      json:"userId"`
-	TokenHash  string    `db:"token_hash"   json:"tokenHash"`
-	ExpiresAt  time.Time `db:"expires_at"   json:"expiresAt"`
-	Revoked    bool      `db:"revoked"      json:"revoked"`
-	DeviceID   string    `db:"device_id"    json:"deviceId"`
-	LastUsedAt time.Time `db:"last_used_at" json:"lastUsedAt"`
-	CreatedAt  time.Time `db:"created_at"   json:"createdAt"`
+	ID               uint64    `db:"id"                 json:"id"`
+	UserID           uuid.UUID `db:"user_id"            json:"userId"`
+	TokenHash        string    `db:"token_hash"         json:"tokenHash"`
+	FamilyID         uuid.UUID `db:"family_id"          json:"familyId"`
+	ParentID         uint64    `db:"parent_id"          json:"parentId"`
+	ExpiresAt        time.Time `db:"expires_at"         json:"expiresAt"`
+	Revoked          bool      `db:"revoked"            json:"revoked"`
+	Rotated          bool      `db:"rotated"            json:"rotated"`
+	DeviceID         string    `db:"device_id"          json:"deviceId"`
+	Remember         bool      `db:"remember"           json:"remember"`
+	SessionStartedAt time.Time `db:"session_started_at" json:"sessionStartedAt"`
+	LastUsedAt       time.Time `db:"last_used_at"       json:"lastUsedAt"`
+	CreatedAt        time.Time `db:"created_at"         json:"createdAt"`
 }
 
 type Device struct {
@@ -29,3 +34,54 @@ type Device struct {
 	LastActive time.Time `db:"last_active" json:"lastActive"`
 	CreatedAt  time.Time `db:"created_at"  json:"createdAt"`
 }
+
+// Security event kinds.
+const (
+	SecurityEventTokenReuse = "refresh_token_reuse"
+)
+
+type SecurityEvent struct {
+	ID        int64     `db:"id"         json:"id"`
+	UserID    uuid.UUID `db:"user_id"    json:"userId"`
+	Kind      string    `db:"kind"       json:"kind"`
+	DeviceID  string    `db:"device_id"  json:"deviceId"`
+	IP        string    `db:"ip"         json:"ip"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// TOTP is the authenticator app secret of a user. Secret is encrypted with
+// AUTH_ENCRYPTION_KEY, LastStep is the last accepted time step.
+type TOTP struct {
+	UserID    uuid.UUID `db:"user_id"    json:"userId"`
+	Secret    string    `db:"secret"     json:"-"`
+	Confirmed bool      `db:"confirmed"  json:"confirmed"`
+	LastStep  int64     `db:"last_step"  json:"lastStep"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// WebAuthnCredential is a registered passkey. ID is the base64url encoded
+// credential ID, DeviceID the device it was registered from, if known.
+type WebAuthnCredential struct {
+	ID         string    `db:"id"           json:"id"`
+	UserID     uuid.UUID `db:"user_id"      json:"userId"`
+	DeviceID   string    `db:"device_id"    json:"deviceId"`
+	Name       string    `db:"name"         json:"name"`
+	PublicKey  []byte    `db:"public_key"   json:"-"`
+	Alg        int64     `db:"alg"          json:"alg"`
+	SignCount  int64     `db:"sign_count"   json:"-"`
+	AAGUID     uuid.UUID `db:"aaguid"       json:"aaguid"`
+	Transports string    `db:"transports"   json:"transports"`
+	LastUsedAt time.Time `db:"last_used_at" json:"lastUsedAt"`
+	CreatedAt  time.Time `db:"created_at"   json:"createdAt"`
+}
+
+// SigningKey is a JWT signing key shared by all replicas. PrivateKey is
+// encrypted with AUTH_ENCRYPTION_KEY and is empty for configured keys, whose
+// rows only pin the retire time. The active key has no RetireAt.
+type SigningKey struct {
+	ID         string     `db:"kid"         json:"kid"`
+	Alg        string     `db:"alg"         json:"alg"`
+	PrivateKey string     `db:"private_key" json:"-"`
+	RetireAt   *time.Time `db:"retire_at"   json:"retireAt"`
+	CreatedAt  time.Time  `db:"created_at"  json:"createdAt"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	EmailPending = "pending"
+	EmailSent    = "sent"
+	EmailDead    = "dead"
+)
+
+// Email is a rendered message waiting in the outbox.
+type Email struct {
+	ID            int64     `db:"id"              json:"id"`
+	To            string    `db:"recipient"       json:"to"`
+	Subject       string    `db:"subject"         json:"subject"`
+	Template      string    `db:"template"        json:"template"`
+	Locale        string    `db:"locale"          json:"locale"`
+	Text          string    `db:"text_body"       json:"text"`
+	HTML          string    `db:"html_body"       json:"html"`
+	Status        string    `db:"status"          json:"status"`
+	Attempts      int       `db:"attempts"        json:"attempts"`
+	LastError     string    `db:"last_error"      json:"lastError"`
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"nextAttemptAt"`
+	CreatedAt     time.Time `db:"created_at"      json:"createdAt"`
+}
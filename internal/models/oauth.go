@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient is an application registered to request tokens. RedirectURIs,
+// Scopes and GrantTypes are space delimited. Public clients have no secret
+// and must use PKCE.
+type OAuthClient struct {
+	ID           uuid.UUID `db:"id"            json:"id"`
+	Name         string    `db:"name"          json:"name"`
+	SecretHash   string    `db:"secret_hash"   json:"-"`
+	Public       bool      `db:"public"        json:"public"`
+	RedirectURIs string    `db:"redirect_uris" json:"redirectUris"`
+	Scopes       string    `db:"scopes"        json:"scopes"`
+	GrantTypes   string    `db:"grant_types"   json:"grantTypes"`
+	CreatedAt    time.Time `db:"created_at"    json:"createdAt"`
+}
+
+// OAuthConsent holds the scopes a user granted to a client.
+type OAuthConsent struct {
+	UserID    uuid.UUID `db:"user_id"    json:"userId"`
+	ClientID  uuid.UUID `db:"client_id"  json:"clientId"`
+	Scope     string    `db:"scope"      json:"scope"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// OAuthToken is a refresh token issued to a client on behalf of a user.
+// Revoked tokens are kept to detect reuse.
+type OAuthToken struct {
+	ID        uint64    `db:"id"         json:"id"`
+	TokenHash string    `db:"token_hash" json:"tokenHash"`
+	ClientID  uuid.UUID `db:"client_id"  json:"clientId"`
+	UserID    uuid.UUID `db:"user_id"    json:"userId"`
+	Scope     string    `db:"scope"      json:"scope"`
+	ExpiresAt time.Time `db:"expires_at" json:"expiresAt"`
+	Revoked   bool      `db:"revoked"    json:"revoked"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Role is a named set of permissions that can be assigned to users.
+type Role struct {
+	ID          int64    `db:"id"          json:"id"`
+	Name        string   `db:"name"        json:"name"`
+	Description string   `db:"description" json:"description"`
+	Permissions []string `db:"-"           json:"permissions"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceAccount is a non-human principal that authenticates with API keys.
+type ServiceAccount struct {
+	ID          uuid.UUID `db:"id"          json:"id"`
+	Name        string    `db:"name"        json:"name"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at"  json:"createdAt"`
+}
+
+// APIKey is a long-lived credential of a service account. Scopes are the
+// space delimited permissions the key grants. Only the hash of the key is
+// stored, Prefix identifies it in listings. ExpiresAt is nil for keys that
+// don't expire.
+type APIKey struct {
+	ID               uuid.UUID  `db:"id"                 json:"id"`
+	ServiceAccountID uuid.UUID  `db:"service_account_id" json:"serviceAccountId"`
+	Name             string     `db:"name"               json:"name"`
+	Prefix           string     `db:"prefix"             json:"prefix"`
+	KeyHash          string     `db:"key_hash"           json:"-"`
+	Scopes           string     `db:"scopes"             json:"scopes"`
+	ExpiresAt        *time.Time `db:"expires_at"         json:"expiresAt"`
+	LastUsedAt       *time.Time `db:"last_used_at"       json:"lastUsedAt"`
+	CreatedAt        time.Time  `db:"created_at"         json:"createdAt"`
+}
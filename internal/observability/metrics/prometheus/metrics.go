@@ -46,6 +46,11 @@ func (m *Metric) Start() {
 	m.reg.MustRegister(
 		SrvMetrics,
 		RequestMetrics,
+		EmailQueueDepth,
+		EmailsSent,
+		EmailFailures,
+		SecurityEvents,
+		RateLimitDecisions,
 		collectors.NewGoCollector(),
 		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
 	)
@@ -92,3 +97,43 @@ var RequestMetrics = promauto.NewSummaryVec(
 func ObserveRequest(d time.Duration, status int, endpoint string) {
 	RequestMetrics.WithLabelValues(strconv.Itoa(status), endpoint).Observe(d.Seconds())
 }
+
+var EmailQueueDepth = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "svc",
+		Name:      "email_queue_depth",
+		Help:      "Number of unsent emails in the outbox by status",
+	}, []string{"status"},
+)
+
+var EmailsSent = promauto.NewCounter(
+	prometheus.CounterOpts{
+		Namespace: "svc",
+		Name:      "emails_sent_total",
+		Help:      "Number of emails delivered by the queue worker",
+	},
+)
+
+var EmailFailures = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "svc",
+		Name:      "email_failures_total",
+		Help:      "Number of failed delivery attempts by outcome (retry or dead)",
+	}, []string{"outcome"},
+)
+
+var SecurityEvents = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "svc",
+		Name:      "security_events_total",
+		Help:      "Number of security events by kind",
+	}, []string{"kind"},
+)
+
+var RateLimitDecisions = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "svc",
+		Name:      "rate_limit_decisions_total",
+		Help:      "Number of rate limit decisions by policy and decision (allowed, limited or error)",
+	}, []string{"policy", "decision"},
+)
@@ -0,0 +1,7 @@
+package ratelimit
+
+import "errors"
+
+var ErrInvalidPolicy = errors.New("policy must look like name=ip|user|apikey:limit/period")
+
+var ErrRateLimited = errors.New("rate limit exceeded")
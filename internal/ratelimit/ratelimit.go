@@ -0,0 +1,204 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	metrics "github.com/JMURv/golang-clean-template/internal/observability/metrics/prometheus"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// What a policy counts requests by. Callers that lack the identity a policy
+// asks for fall back to the next one: an API key to its user, a user to the
+// IP.
+const (
+	ByIP     = "ip"
+	ByUser   = "user"
+	ByAPIKey = "apikey"
+)
+
+// Names of the policies applied by the handlers.
+const (
+	PolicyDefault     = "default"
+	PolicyAuth        = "auth"
+	PolicyUsersExists = "users-exists"
+	PolicyAPI         = "api"
+	PolicyGRPC        = "grpc"
+)
+
+// DefaultPolicies are used unless RATE_LIMIT_POLICIES overrides them.
+var DefaultPolicies = []string{
+	PolicyDefault + "=ip:300/1m",
+	PolicyAuth + "=ip:20/1m",
+	PolicyUsersExists + "=ip:10/1h",
+	PolicyAPI + "=apikey:600/1m",
+	PolicyGRPC + "=apikey:600/1m",
+}
+
+// Policy allows Limit requests per Period to every caller, with bursts of up
+// to Limit requests.
+type Policy struct {
+	Name   string
+	By     string
+	Limit  int
+	Period time.Duration
+}
+
+// Result is the decision on one request.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// Store counts requests under key with the generic cell rate algorithm.
+type Store interface {
+	Allow(ctx context.Context, key string, limit int, period time.Duration) (Result, error)
+}
+
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+}
+
+// New returns a limiter with the default policies overridden by
+// RATE_LIMIT_POLICIES, or nil when RATE_LIMIT_ENABLED is off. A nil limiter
+// allows every request.
+func New(conf config.Config, store Store) *Limiter {
+	if !conf.RateLimit.Enabled {
+		return nil
+	}
+
+	policies, err := ParsePolicies(slices.Concat(DefaultPolicies, conf.RateLimit.Policies))
+	if err != nil {
+		zap.L().Fatal("failed to parse rate limit policies", zap.Error(err))
+	}
+
+	return &Limiter{store: store, policies: policies}
+}
+
+// ParsePolicies parses policies in the name=by:limit/period format, such as
+// auth=ip:20/1m. Later entries override earlier ones of the same name.
+func ParsePolicies(values []string) (map[string]Policy, error) {
+	res := make(map[string]Policy, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		p, err := parsePolicy(v)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit policy %q: %w", v, err)
+		}
+
+		res[p.Name] = p
+	}
+
+	return res, nil
+}
+
+func parsePolicy(v string) (Policy, error) {
+	name, rule, ok := strings.Cut(v, "=")
+	if !ok || name == "" {
+		return Policy{}, ErrInvalidPolicy
+	}
+
+	by, rate, ok := strings.Cut(rule, ":")
+	if !ok || (by != ByIP && by != ByUser && by != ByAPIKey) {
+		return Policy{}, ErrInvalidPolicy
+	}
+
+	limit, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Policy{}, ErrInvalidPolicy
+	}
+
+	n, err := strconv.Atoi(limit)
+	if err != nil || n <= 0 {
+		return Policy{}, ErrInvalidPolicy
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Policy{}, ErrInvalidPolicy
+	}
+
+	return Policy{Name: name, By: by, Limit: n, Period: d}, nil
+}
+
+// Policy returns the policy called name.
+func (l *Limiter) Policy(name string) (Policy, bool) {
+	if l == nil {
+		return Policy{}, false
+	}
+
+	p, ok := l.policies[name]
+	return p, ok
+}
+
+// Allow counts a request of the caller against p. Failures of the store let
+// the request through, so an outage of Redis doesn't take the API down with
+// it.
+func (l *Limiter) Allow(ctx context.Context, p Policy, caller string) Result {
+	const op = "ratelimit.Allow"
+
+	res, err := l.store.Allow(ctx, fmt.Sprintf("ratelimit:%s:%s", p.Name, caller), p.Limit, p.Period)
+	if err != nil {
+		zap.L().Error("failed to check rate limit", zap.String("op", op), zap.String("policy", p.Name), zap.Error(err))
+		metrics.RateLimitDecisions.WithLabelValues(p.Name, "error").Inc()
+		return Result{Allowed: true, Limit: p.Limit, Remaining: p.Limit}
+	}
+
+	decision := "allowed"
+	if !res.Allowed {
+		decision = "limited"
+		zap.L().Debug("rate limited", zap.String("op", op), zap.String("policy", p.Name), zap.String("caller", caller))
+	}
+
+	metrics.RateLimitDecisions.WithLabelValues(p.Name, decision).Inc()
+	return res
+}
+
+// Headers returns the RateLimit header fields describing res under p, and
+// Retry-After for a rejected request. Durations are in whole seconds,
+// rounded up.
+func Headers(p Policy, res Result) map[string]string {
+	h := map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(res.Limit),
+		"RateLimit-Remaining": strconv.Itoa(res.Remaining),
+		"RateLimit-Reset":     seconds(res.ResetAfter),
+		"RateLimit-Policy":    fmt.Sprintf("%d;w=%s", p.Limit, seconds(p.Period)),
+	}
+	if !res.Allowed {
+		h["Retry-After"] = seconds(res.RetryAfter)
+	}
+
+	return h
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)
+}
+
+// Caller names who p counts a request against. keyID is the prefix of the
+// API key the request was authenticated with, uid its user, both are empty
+// for anonymous requests.
+func Caller(p Policy, ip string, uid uuid.UUID, keyID string) string {
+	switch {
+	case p.By == ByAPIKey && keyID != "":
+		return "apikey:" + keyID
+	case (p.By == ByAPIKey || p.By == ByUser) && uid != uuid.Nil:
+		return "user:" + uid.String()
+	default:
+		return "ip:" + ip
+	}
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// fakeStore allows the first limit requests of every key.
+type fakeStore struct {
+	counts map[string]int
+	err    error
+}
+
+func (f *fakeStore) Allow(_ context.Context, key string, limit int, period time.Duration) (Result, error) {
+	if f.err != nil {
+		return Result{}, f.err
+	}
+
+	f.counts[key]++
+	n := f.counts[key]
+	if n > limit {
+		return Result{Limit: limit, ResetAfter: period, RetryAfter: period / time.Duration(limit)}, nil
+	}
+
+	return Result{Allowed: true, Limit: limit, Remaining: limit - n, ResetAfter: period}, nil
+}
+
+func TestParsePolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      []string
+		expected    map[string]Policy
+		expectedErr error
+	}{
+		{
+			name:   "Valid",
+			values: []string{"auth=ip:20/1m", " api=apikey:600/1h ", ""},
+			expected: map[string]Policy{
+				"auth": {Name: "auth", By: ByIP, Limit: 20, Period: time.Minute},
+				"api":  {Name: "api", By: ByAPIKey, Limit: 600, Period: time.Hour},
+			},
+		},
+		{
+			name:   "LaterOverrides",
+			values: []string{"auth=ip:20/1m", "auth=user:5/1s"},
+			expected: map[string]Policy{
+				"auth": {Name: "auth", By: ByUser, Limit: 5, Period: time.Second},
+			},
+		},
+		{name: "NoName", values: []string{"=ip:20/1m"}, expectedErr: ErrInvalidPolicy},
+		{name: "UnknownKey", values: []string{"auth=email:20/1m"}, expectedErr: ErrInvalidPolicy},
+		{name: "NoPeriod", values: []string{"auth=ip:20"}, expectedErr: ErrInvalidPolicy},
+		{name: "ZeroLimit", values: []string{"auth=ip:0/1m"}, expectedErr: ErrInvalidPolicy},
+		{name: "InvalidPeriod", values: []string{"auth=ip:20/minute"}, expectedErr: ErrInvalidPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParsePolicies(tt.values)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New(config.Config{}, &fakeStore{}))
+
+	conf := config.Config{}
+	conf.RateLimit.Enabled = true
+	conf.RateLimit.Policies = []string{PolicyAuth + "=ip:5/1m"}
+	l := New(conf, &fakeStore{})
+
+	p, ok := l.Policy(PolicyAuth)
+	require.True(t, ok)
+	assert.Equal(t, 5, p.Limit)
+
+	_, ok = l.Policy(PolicyDefault)
+	assert.True(t, ok)
+	assert.Len(t, DefaultPolicies, 5)
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	p := Policy{Name: "test", By: ByIP, Limit: 2, Period: time.Minute}
+
+	t.Run("CountsPerCaller", func(t *testing.T) {
+		l := &Limiter{store: &fakeStore{counts: map[string]int{}}}
+
+		assert.True(t, l.Allow(context.Background(), p, "ip:1").Allowed)
+		assert.True(t, l.Allow(context.Background(), p, "ip:1").Allowed)
+		assert.False(t, l.Allow(context.Background(), p, "ip:1").Allowed)
+		assert.True(t, l.Allow(context.Background(), p, "ip:2").Allowed)
+	})
+
+	t.Run("FailsOpen", func(t *testing.T) {
+		l := &Limiter{store: &fakeStore{err: errors.New("redis is down")}}
+
+		res := l.Allow(context.Background(), p, "ip:1")
+		assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 2}, res)
+	})
+
+	t.Run("NilLimiter", func(t *testing.T) {
+		var l *Limiter
+		_, ok := l.Policy(PolicyDefault)
+		assert.False(t, ok)
+	})
+}
+
+func TestHeaders(t *testing.T) {
+	p := Policy{Name: "test", Limit: 10, Period: time.Minute}
+
+	assert.Equal(
+		t, map[string]string{
+			"RateLimit-Limit":     "10",
+			"RateLimit-Remaining": "3",
+			"RateLimit-Reset":     "2",
+			"RateLimit-Policy":    "10;w=60",
+		}, Headers(p, Result{Allowed: true, Limit: 10, Remaining: 3, ResetAfter: 1500 * time.Millisecond}),
+	)
+
+	h := Headers(p, Result{Limit: 10, ResetAfter: time.Minute, RetryAfter: 5*time.Second + time.Millisecond})
+	assert.Equal(t, "0", h["RateLimit-Remaining"])
+	assert.Equal(t, "6", h["Retry-After"])
+}
+
+func TestCaller(t *testing.T) {
+	uid := uuid.New()
+
+	tests := []struct {
+		name     string
+		by       string
+		uid      uuid.UUID
+		keyID    string
+		expected string
+	}{
+		{name: "APIKey", by: ByAPIKey, uid: uid, keyID: "sk_1", expected: "apikey:sk_1"},
+		{name: "APIKeyFallsBackToUser", by: ByAPIKey, uid: uid, expected: "user:" + uid.String()},
+		{name: "APIKeyFallsBackToIP", by: ByAPIKey, expected: "ip:10.0.0.1"},
+		{name: "User", by: ByUser, uid: uid, keyID: "sk_1", expected: "user:" + uid.String()},
+		{name: "IP", by: ByIP, uid: uid, keyID: "sk_1", expected: "ip:10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Caller(Policy{By: tt.by}, "10.0.0.1", tt.uid, tt.keyID))
+		})
+	}
+}
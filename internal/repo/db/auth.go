@@ -2,12 +2,14 @@ package db
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"errors"
 	"time"
 
 	"github.com/JMURv/golang-clean-template/internal/config"
 	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
@@ -18,6 +20,7 @@ func (r *Repository) CreateToken(
 	userID uuid.UUID,
 	hashedT string,
 	expiresAt time.Time,
+	remember bool,
 	device *md.Device,
 ) error {
 	const op = "auth.CreateToken.repo"
@@ -71,9 +74,21 @@ func (r *Repository) CreateToken(
 		return err
 	}
 
+	_, err = tx.ExecContext(ctx, createDeviceHistory, userID, device.ID, device.IP, device.UA)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to record device history",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
 	_, err = tx.ExecContext(
 		ctx, createRefreshToken,
-		userID, hashedT, expiresAt, device.ID,
+		userID, hashedT, expiresAt, device.ID, remember,
 	)
 	if err != nil {
 		span.SetTag(config.ErrorSpanTag, true)
@@ -100,45 +115,215 @@ func (r *Repository) CreateToken(
 	return nil
 }
 
-func (r *Repository) IsTokenValid(
-	ctx context.Context,
-	userID uuid.UUID,
-	d *md.Device,
-	token string,
-) (bool, error) {
-	const op = "auth.IsTokenValid.repo"
+// GetToken returns the refresh token stored under hashedT, including rotated
+// and revoked ones so that reuse can be detected.
+func (r *Repository) GetToken(ctx context.Context, userID uuid.UUID, hashedT string) (*md.RefreshToken, error) {
+	const op = "auth.GetToken.repo"
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
-	var stored string
+	var token md.RefreshToken
 
-	err := r.conn.QueryRowContext(ctx, isValidToken, userID, d.ID).Scan(&stored)
+	err := r.conn.GetContext(ctx, &token, getTokenByHash, userID, hashedT)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			zap.L().Debug(
 				"no token found",
 				zap.String("op", op),
 				zap.String("userID", userID.String()),
-				zap.Any("device", d),
 			)
 
-			return false, nil
+			return nil, repo.ErrNotFound
 		}
 
 		span.SetTag(config.ErrorSpanTag, true)
 		zap.L().Error(
-			"failed to validate token",
+			"failed to get token",
 			zap.String("op", op),
 			zap.String("userID", userID.String()),
-			zap.Any("device", d),
 			zap.Error(err),
 		)
 
-		return false, err
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashedT), []byte(token.TokenHash)) != 1 {
+		return nil, repo.ErrNotFound
 	}
 
-	return token == stored, nil
+	return &token, nil
+}
+
+// RotateToken marks parent as used and stores its successor in the same
+// family and session. The IP and User-Agent of device are recorded as the
+// latest ones of the session device. It returns repo.ErrNotFound if parent
+// was rotated or revoked concurrently.
+func (r *Repository) RotateToken(
+	ctx context.Context,
+	parent *md.RefreshToken,
+	hashedT string,
+	expiresAt time.Time,
+	device *md.Device,
+) error {
+	const op = "auth.RotateToken.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to begin transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"error while transaction rollback",
+				zap.String("op", op),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, markTokenRotated, parent.ID)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to mark token rotated",
+			zap.String("op", op),
+			zap.Uint64("tokenID", parent.ID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		return repo.ErrNotFound
+	}
+
+	_, err = tx.ExecContext(
+		ctx, createChildToken,
+		parent.UserID, hashedT, expiresAt, parent.DeviceID, parent.FamilyID, parent.ID,
+		parent.Remember, parent.SessionStartedAt,
+	)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create refresh token",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, touchDevice, parent.DeviceID, parent.UserID, device.UA, device.IP)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to update device activity",
+			zap.String("op", op),
+			zap.String("deviceID", parent.DeviceID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, createDeviceHistory, parent.UserID, parent.DeviceID, device.IP, device.UA)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to record device history",
+			zap.String("op", op),
+			zap.String("deviceID", parent.DeviceID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to commit transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every token of the family and of the device session
+// the family belongs to.
+func (r *Repository) RevokeFamily(ctx context.Context, token *md.RefreshToken) error {
+	const op = "auth.RevokeFamily.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, revokeTokenFamily, token.FamilyID, token.UserID, token.DeviceID)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to revoke token family",
+			zap.String("op", op),
+			zap.String("familyID", token.FamilyID.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) CreateSecurityEvent(ctx context.Context, e *md.SecurityEvent) error {
+	const op = "auth.CreateSecurityEvent.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, createSecurityEvent, e.UserID, e.Kind, e.DeviceID, e.IP)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create security event",
+			zap.String("op", op),
+			zap.String("userID", e.UserID.String()),
+			zap.String("kind", e.Kind),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
 }
 
 func (r *Repository) RevokeAllTokens(ctx context.Context, userID uuid.UUID) error {
@@ -193,7 +378,7 @@ func (r *Repository) GetByDevice(
 }
 
 func (r *Repository) RevokeByDevice(ctx context.Context, userID uuid.UUID, deviceID string) error {
-	const op = "auth.GetByDevice.repo"
+	const op = "auth.RevokeByDevice.repo"
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
@@ -212,3 +397,26 @@ func (r *Repository) RevokeByDevice(ctx context.Context, userID uuid.UUID, devic
 
 	return err
 }
+
+// RevokeOtherDevices revokes the tokens of every device of the user except
+// deviceID.
+func (r *Repository) RevokeOtherDevices(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	const op = "auth.RevokeOtherDevices.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, revokeTokenOtherDevices, userID, deviceID)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to revoke tokens of other devices",
+			zap.String("op", op),
+			zap.String("userID", userID.String()),
+			zap.String("deviceID", deviceID),
+			zap.Error(err),
+		)
+	}
+
+	return err
+}
@@ -3,8 +3,19 @@ package db
 const createUserDevice = `
 INSERT INTO devices (id, user_id, name, device_type, os, browser, user_agent, ip)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (id) DO UPDATE 
-SET last_active = NOW()
+ON CONFLICT (user_id, id) DO UPDATE
+SET os = EXCLUDED.os,
+    browser = EXCLUDED.browser,
+    user_agent = EXCLUDED.user_agent,
+    ip = EXCLUDED.ip,
+    last_active = NOW()
+`
+
+const createDeviceHistory = `
+INSERT INTO device_history (user_id, device_id, ip, user_agent)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, device_id, ip, user_agent) DO UPDATE
+SET last_seen = NOW()
 `
 
 const getTokenByDevice = `
@@ -16,16 +27,44 @@ LIMIT 1
 `
 
 const createRefreshToken = `
-INSERT INTO refresh_tokens (user_id, token_hash, expires_at, device_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO refresh_tokens (user_id, token_hash, expires_at, device_id, remember)
+VALUES ($1, $2, $3, $4, $5)
 `
 
-const isValidToken = `
-SELECT token_hash
-FROM refresh_tokens 
-WHERE user_id = $1 AND device_id = $2 AND expires_at > NOW() AND revoked IS FALSE
-ORDER BY expires_at DESC
-LIMIT 1
+const getTokenByHash = `
+SELECT id, user_id, token_hash, family_id, COALESCE(parent_id, 0) AS parent_id,
+       expires_at, revoked, rotated_at IS NOT NULL AS rotated, device_id,
+       remember, session_started_at, COALESCE(last_used_at, created_at) AS last_used_at
+FROM refresh_tokens
+WHERE user_id = $1 AND token_hash = $2
+`
+
+const markTokenRotated = `
+UPDATE refresh_tokens
+SET rotated_at = NOW(), last_used_at = NOW()
+WHERE id = $1 AND rotated_at IS NULL AND revoked IS FALSE
+`
+
+const createChildToken = `
+INSERT INTO refresh_tokens (user_id, token_hash, expires_at, device_id, family_id, parent_id, remember, session_started_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+const touchDevice = `
+UPDATE devices
+SET user_agent = $3, ip = $4, last_active = NOW()
+WHERE id = $1 AND user_id = $2
+`
+
+const revokeTokenFamily = `
+UPDATE refresh_tokens
+SET revoked = TRUE
+WHERE family_id = $1 OR (user_id = $2 AND device_id = $3)
+`
+
+const createSecurityEvent = `
+INSERT INTO security_events (user_id, kind, device_id, ip)
+VALUES ($1, $2, $3, $4)
 `
 
 const revokeToken = `
@@ -34,6 +73,12 @@ SET revoked = TRUE
 WHERE user_id = $1
 `
 
+const revokeTokenOtherDevices = `
+UPDATE refresh_tokens
+SET revoked = TRUE
+WHERE user_id = $1 AND device_id <> $2
+`
+
 const revokeTokenByDevice = `
 UPDATE refresh_tokens
 SET revoked = TRUE
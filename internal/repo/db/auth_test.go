@@ -6,6 +6,7 @@ import (
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -14,7 +15,7 @@ import (
 	"time"
 )
 
-func TestRepository_IsTokenValid(t *testing.T) {
+func TestRepository_GetToken(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -22,74 +23,62 @@ func TestRepository_IsTokenValid(t *testing.T) {
 	defer db.Close()
 
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
-	repo := &Repository{conn: sqlxDB}
+	r := &Repository{conn: sqlxDB}
 
 	userID := uuid.New()
-	device := &md.Device{ID: "device123"}
-	validToken := "valid-token"
-	invalidToken := "invalid-token"
+	familyID := uuid.New()
+	hashedToken := "hashed-token"
+	startedAt := time.Now().Add(-time.Hour)
+	lastUsedAt := time.Now()
+	columns := []string{
+		"id", "user_id", "token_hash", "family_id", "parent_id",
+		"expires_at", "revoked", "rotated", "device_id",
+		"remember", "session_started_at", "last_used_at",
+	}
 
 	tests := []struct {
 		name        string
-		userID      uuid.UUID
-		device      *md.Device
-		token       string
 		mock        func()
-		expected    bool
+		expected    *md.RefreshToken
 		expectedErr error
 	}{
 		{
-			name:   "ValidToken",
-			userID: userID,
-			device: device,
-			token:  validToken,
+			name: "Success",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"token"}).AddRow(validToken)
-				mock.ExpectQuery(regexp.QuoteMeta(isValidToken)).
-					WithArgs(userID, device.ID).
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, userID, hashedToken, familyID, 0, time.Time{}, false, true, "device123", true, startedAt, lastUsedAt)
+				mock.ExpectQuery(regexp.QuoteMeta(getTokenByHash)).
+					WithArgs(userID, hashedToken).
 					WillReturnRows(rows)
 			},
-			expected:    true,
-			expectedErr: nil,
-		},
-		{
-			name:   "InvalidToken",
-			userID: userID,
-			device: device,
-			token:  invalidToken,
-			mock: func() {
-				rows := sqlmock.NewRows([]string{"token"}).AddRow(validToken)
-				mock.ExpectQuery(regexp.QuoteMeta(isValidToken)).
-					WithArgs(userID, device.ID).
-					WillReturnRows(rows)
+			expected: &md.RefreshToken{
+				ID:               1,
+				UserID:           userID,
+				TokenHash:        hashedToken,
+				FamilyID:         familyID,
+				Rotated:          true,
+				DeviceID:         "device123",
+				Remember:         true,
+				SessionStartedAt: startedAt,
+				LastUsedAt:       lastUsedAt,
 			},
-			expected:    false,
-			expectedErr: nil,
 		},
 		{
-			name:   "NoTokenFound",
-			userID: userID,
-			device: device,
-			token:  validToken,
+			name: "NotFound",
 			mock: func() {
-				mock.ExpectQuery(regexp.QuoteMeta(isValidToken)).
-					WithArgs(userID, device.ID).
+				mock.ExpectQuery(regexp.QuoteMeta(getTokenByHash)).
+					WithArgs(userID, hashedToken).
 					WillReturnError(sql.ErrNoRows)
 			},
-			expected:    false,
-			expectedErr: nil,
+			expectedErr: repo.ErrNotFound,
 		},
 		{
-			name:   "DatabaseError",
-			userID: userID,
-			device: device,
-			token:  validToken,
+			name: "DatabaseError",
 			mock: func() {
-				mock.ExpectQuery(regexp.QuoteMeta(isValidToken)).
-					WithArgs(userID, device.ID).
+				mock.ExpectQuery(regexp.QuoteMeta(getTokenByHash)).
+					WithArgs(userID, hashedToken).
 					WillReturnError(errors.New("database error"))
 			},
-			expected:    false,
 			expectedErr: errors.New("database error"),
 		},
 	}
@@ -98,22 +87,134 @@ func TestRepository_IsTokenValid(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			valid, err := repo.IsTokenValid(context.Background(), tt.userID, tt.device, tt.token)
-
+			res, err := r.GetToken(context.Background(), userID, hashedToken)
 			if tt.expectedErr != nil {
-				assert.Error(t, err)
 				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Nil(t, res)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
 			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_RotateToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	r := &Repository{conn: sqlxDB}
+
+	parent := &md.RefreshToken{
+		ID:               1,
+		UserID:           uuid.New(),
+		FamilyID:         uuid.New(),
+		DeviceID:         "device123",
+		Remember:         true,
+		SessionStartedAt: time.Now().Add(-time.Hour),
+	}
+	hashedToken := "hashed-token"
+	expiresAt := time.Now().Add(time.Hour)
+	device := &md.Device{ID: parent.DeviceID, IP: "10.0.0.2", UA: "Mozilla/5.0"}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(markTokenRotated)).
+					WithArgs(parent.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createChildToken)).
+					WithArgs(
+						parent.UserID, hashedToken, expiresAt, parent.DeviceID, parent.FamilyID, parent.ID,
+						parent.Remember, parent.SessionStartedAt,
+					).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectExec(regexp.QuoteMeta(touchDevice)).
+					WithArgs(parent.DeviceID, parent.UserID, device.UA, device.IP).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createDeviceHistory)).
+					WithArgs(parent.UserID, parent.DeviceID, device.IP, device.UA).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "AlreadyRotated",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(markTokenRotated)).
+					WithArgs(parent.ID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "CreateTokenError",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(markTokenRotated)).
+					WithArgs(parent.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createChildToken)).
+					WithArgs(
+						parent.UserID, hashedToken, expiresAt, parent.DeviceID, parent.FamilyID, parent.ID,
+						parent.Remember, parent.SessionStartedAt,
+					).
+					WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			expectedErr: errors.New("insert error"),
+		},
+	}
 
-			assert.Equal(t, tt.expected, valid)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.RotateToken(context.Background(), parent, hashedToken, expiresAt, device)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
 		})
 	}
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestRepository_RevokeFamily(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	r := &Repository{conn: sqlxDB}
+
+	token := &md.RefreshToken{UserID: uuid.New(), FamilyID: uuid.New(), DeviceID: "device123"}
+	mock.ExpectExec(regexp.QuoteMeta(revokeTokenFamily)).
+		WithArgs(token.FamilyID, token.UserID, token.DeviceID).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, r.RevokeFamily(context.Background(), token))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestRepository_CreateToken(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -166,12 +267,16 @@ func TestRepository_CreateToken(t *testing.T) {
 						device.IP,
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createDeviceHistory)).
+					WithArgs(userID, device.ID, device.IP, device.UA).
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec(regexp.QuoteMeta(createRefreshToken)).
 					WithArgs(
 						userID,
 						hashedToken,
 						expiresAt,
 						device.ID,
+						true,
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
@@ -233,12 +338,16 @@ func TestRepository_CreateToken(t *testing.T) {
 						device.IP,
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createDeviceHistory)).
+					WithArgs(userID, device.ID, device.IP, device.UA).
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec(regexp.QuoteMeta(createRefreshToken)).
 					WithArgs(
 						userID,
 						hashedToken,
 						expiresAt,
 						device.ID,
+						true,
 					).
 					WillReturnError(errors.New("token create error"))
 				mock.ExpectRollback()
@@ -265,12 +374,16 @@ func TestRepository_CreateToken(t *testing.T) {
 						device.IP,
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createDeviceHistory)).
+					WithArgs(userID, device.ID, device.IP, device.UA).
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec(regexp.QuoteMeta(createRefreshToken)).
 					WithArgs(
 						userID,
 						hashedToken,
 						expiresAt,
 						device.ID,
+						true,
 					).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit().WillReturnError(errors.New("commit error"))
@@ -283,7 +396,7 @@ func TestRepository_CreateToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			err := repo.CreateToken(context.Background(), tt.userID, tt.hashedT, tt.expiresAt, tt.device)
+			err := repo.CreateToken(context.Background(), tt.userID, tt.hashedT, tt.expiresAt, true, tt.device)
 
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
@@ -534,3 +647,27 @@ func TestRepository_RevokeByDevice(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRepository_RevokeOtherDevices(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	r := &Repository{conn: sqlxDB}
+
+	userID := uuid.New()
+	mock.ExpectExec(regexp.QuoteMeta(revokeTokenOtherDevices)).
+		WithArgs(userID, "device123").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, r.RevokeOtherDevices(context.Background(), userID, "device123"))
+
+	mock.ExpectExec(regexp.QuoteMeta(revokeTokenOtherDevices)).
+		WithArgs(userID, "device123").
+		WillReturnError(errors.New("database error"))
+	assert.EqualError(t, r.RevokeOtherDevices(context.Background(), userID, "device123"), "database error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
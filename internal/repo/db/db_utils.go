@@ -1,16 +1,21 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"os"
 	"path/filepath"
 
 	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/repo"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func applyMigrations(db *sql.DB, conf config.Config) error {
@@ -45,4 +50,83 @@ func applyMigrations(db *sql.DB, conf config.Config) error {
 	return nil
 }
 
-func mustPrecreate(conf config.Config, db *sql.DB) {}
+// adminCreateQ creates the admin and grants the role in one statement, so
+// the role only ever goes to the row inserted here.
+const adminCreateQ = `
+WITH created AS (
+	INSERT INTO users (name, password, email, is_active, is_email_verified)
+	VALUES ('admin', $1, $2, TRUE, TRUE)
+	ON CONFLICT (email) DO NOTHING
+	RETURNING id
+)
+INSERT INTO user_roles (user_id, role_id)
+SELECT created.id, r.id
+FROM created, roles r
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING
+`
+
+// mustPrecreate bootstraps the admin account from AUTH_ADMIN_EMAIL and
+// AUTH_ADMIN_PASSWORD. An account that already uses the email is left
+// alone, so whoever registered it first is never promoted.
+func mustPrecreate(conf config.Config, db *sql.DB) {
+	if conf.Auth.Admin.Email == "" || conf.Auth.Admin.Password == "" {
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(conf.Auth.Admin.Password), bcrypt.DefaultCost)
+	if err != nil {
+		zap.L().Fatal("failed to hash admin password", zap.Error(err))
+	}
+
+	res, err := db.Exec(adminCreateQ, string(hash), conf.Auth.Admin.Email)
+	if err != nil {
+		zap.L().Fatal("failed to create admin", zap.Error(err))
+	}
+
+	if aff, _ := res.RowsAffected(); aff > 0 {
+		zap.L().Info("Created admin", zap.String("email", conf.Auth.Admin.Email))
+	}
+}
+
+// execOne runs query and returns repo.ErrNotFound if it affected no rows.
+func (r *Repository) execOne(
+	ctx context.Context,
+	span opentracing.Span,
+	op string,
+	uid uuid.UUID,
+	query string,
+	args ...any,
+) error {
+	res, err := r.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to execute query",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
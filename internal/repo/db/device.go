@@ -163,11 +163,6 @@ func (r *Repository) DeleteDevice(ctx context.Context, userID uuid.UUID, deviceI
 	span, ctx := opentracing.StartSpanFromContext(ctx, op)
 	defer span.Finish()
 
-	err := r.RevokeByDevice(ctx, userID, deviceID)
-	if err != nil {
-		return err
-	}
-
 	res, err := r.conn.ExecContext(ctx, deleteDevice, deviceID, userID)
 	if err != nil {
 		span.SetTag(config.ErrorSpanTag, true)
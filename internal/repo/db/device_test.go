@@ -469,10 +469,6 @@ func TestRepository_DeleteDevice(t *testing.T) {
 			userID:   userID,
 			deviceID: deviceID,
 			mock: func() {
-				mock.ExpectExec(regexp.QuoteMeta(revokeTokenByDevice)).
-					WithArgs(userID, deviceID).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
 				mock.ExpectExec(regexp.QuoteMeta(deleteDevice)).
 					WithArgs(deviceID, userID).
 					WillReturnResult(sqlmock.NewResult(0, 1))
@@ -484,10 +480,6 @@ func TestRepository_DeleteDevice(t *testing.T) {
 			userID:   userID,
 			deviceID: deviceID,
 			mock: func() {
-				mock.ExpectExec(regexp.QuoteMeta(revokeTokenByDevice)).
-					WithArgs(userID, deviceID).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
 				mock.ExpectExec(regexp.QuoteMeta(deleteDevice)).
 					WithArgs(deviceID, userID).
 					WillReturnResult(sqlmock.NewResult(0, 0))
@@ -499,10 +491,6 @@ func TestRepository_DeleteDevice(t *testing.T) {
 			userID:   userID,
 			deviceID: deviceID,
 			mock: func() {
-				mock.ExpectExec(regexp.QuoteMeta(revokeTokenByDevice)).
-					WithArgs(userID, deviceID).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
 				mock.ExpectExec(regexp.QuoteMeta(deleteDevice)).
 					WithArgs(deviceID, userID).
 					WillReturnError(errors.New("database error"))
@@ -514,10 +502,6 @@ func TestRepository_DeleteDevice(t *testing.T) {
 			userID:   userID,
 			deviceID: deviceID,
 			mock: func() {
-				mock.ExpectExec(regexp.QuoteMeta(revokeTokenByDevice)).
-					WithArgs(userID, deviceID).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-
 				result := sqlmock.NewErrorResult(errors.New("rows affected error"))
 				mock.ExpectExec(regexp.QuoteMeta(deleteDevice)).
 					WithArgs(deviceID, userID).
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+func (r *Repository) EnqueueEmail(ctx context.Context, e *md.Email) error {
+	const op = "emails.EnqueueEmail.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(
+		ctx,
+		emailEnqueueQ,
+		e.To,
+		e.Subject,
+		e.Template,
+		e.Locale,
+		e.Text,
+		e.HTML,
+	)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to enqueue email",
+			zap.String("op", op),
+			zap.String("template", e.Template),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// ClaimEmails picks up to limit due messages and hides them from other
+// workers for lease.
+func (r *Repository) ClaimEmails(ctx context.Context, limit int, lease time.Duration) ([]md.Email, error) {
+	const op = "emails.ClaimEmails.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := make([]md.Email, 0, limit)
+	err := r.conn.SelectContext(ctx, &res, emailClaimQ, limit, lease.Seconds())
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to claim emails",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) MarkEmailSent(ctx context.Context, id int64) error {
+	const op = "emails.MarkEmailSent.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.updateEmail(ctx, span, op, emailMarkSentQ, id)
+}
+
+func (r *Repository) RetryEmail(ctx context.Context, id int64, next time.Time, lastErr string) error {
+	const op = "emails.RetryEmail.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.updateEmail(ctx, span, op, emailRetryQ, id, lastErr, next)
+}
+
+func (r *Repository) MarkEmailDead(ctx context.Context, id int64, lastErr string) error {
+	const op = "emails.MarkEmailDead.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.updateEmail(ctx, span, op, emailMarkDeadQ, id, lastErr)
+}
+
+// PurgeSentEmails deletes the messages delivered before before and returns
+// how many there were.
+func (r *Repository) PurgeSentEmails(ctx context.Context, before time.Time) (int64, error) {
+	const op = "emails.PurgeSentEmails.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := r.conn.ExecContext(ctx, emailPurgeSentQ, before)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to purge sent emails",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// CountEmails returns the number of unsent messages per status.
+func (r *Repository) CountEmails(ctx context.Context) (map[string]int64, error) {
+	const op = "emails.CountEmails.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	rows, err := r.conn.QueryContext(ctx, emailCountQ)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to count emails",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			zap.L().Error("failed to close rows", zap.String("op", op), zap.Error(err))
+		}
+	}()
+
+	res := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err = rows.Scan(&status, &count); err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error("failed to scan row", zap.String("op", op), zap.Error(err))
+			return nil, err
+		}
+
+		res[status] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to scan rows", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) updateEmail(
+	ctx context.Context,
+	span opentracing.Span,
+	op, query string,
+	id int64,
+	args ...any,
+) error {
+	res, err := r.conn.ExecContext(ctx, query, append([]any{id}, args...)...)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to update email",
+			zap.String("op", op),
+			zap.Int64("id", id),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		zap.L().Debug(
+			"failed to find email",
+			zap.String("op", op),
+			zap.Int64("id", id),
+		)
+
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
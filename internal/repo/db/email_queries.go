@@ -0,0 +1,76 @@
+package db
+
+const emailEnqueueQ = `
+INSERT INTO email_outbox (recipient, subject, template, locale, text_body, html_body)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+// emailClaimQ hides claimed rows for the lease interval, so a crashed worker's
+// messages become visible again instead of being lost.
+const emailClaimQ = `
+UPDATE email_outbox
+SET attempts = attempts + 1,
+    next_attempt_at = NOW() + make_interval(secs => $2),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id
+    FROM email_outbox
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING
+	id,
+	recipient,
+	subject,
+	template,
+	locale,
+	text_body,
+	html_body,
+	status,
+	attempts,
+	COALESCE(last_error, '') AS last_error,
+	next_attempt_at,
+	created_at
+`
+
+// emailMarkSentQ drops the bodies, they may hold codes that must not outlive
+// the delivery.
+const emailMarkSentQ = `
+UPDATE email_outbox
+SET status = 'sent',
+    text_body = '',
+    html_body = '',
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+const emailRetryQ = `
+UPDATE email_outbox
+SET last_error = $2,
+    next_attempt_at = $3,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+const emailMarkDeadQ = `
+UPDATE email_outbox
+SET status = 'dead',
+    last_error = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+const emailPurgeSentQ = `
+DELETE FROM email_outbox
+WHERE status = 'sent' AND updated_at < $1
+`
+
+const emailCountQ = `
+SELECT status, COUNT(*)
+FROM email_outbox
+WHERE status <> 'sent'
+GROUP BY status
+`
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRepository_EnqueueEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	e := &md.Email{
+		To:       "test@example.com",
+		Subject:  "subject",
+		Template: "verification",
+		Locale:   "en",
+		Text:     "text",
+		HTML:     "<p>html</p>",
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(emailEnqueueQ)).
+					WithArgs(e.To, e.Subject, e.Template, e.Locale, e.Text, e.HTML).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(emailEnqueueQ)).
+					WithArgs(e.To, e.Subject, e.Template, e.Locale, e.Text, e.HTML).
+					WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := r.EnqueueEmail(context.Background(), e)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ClaimEmails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+	cols := []string{
+		"id", "recipient", "subject", "template", "locale", "text_body", "html_body",
+		"status", "attempts", "last_error", "next_attempt_at", "created_at",
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    []md.Email
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows(cols).
+					AddRow(1, "test@example.com", "subject", "verification", "en", "text", "html", md.EmailPending, 1, "", now, now)
+				mock.ExpectQuery(regexp.QuoteMeta(emailClaimQ)).
+					WithArgs(10, time.Minute.Seconds()).
+					WillReturnRows(rows)
+			},
+			expected: []md.Email{
+				{
+					ID:            1,
+					To:            "test@example.com",
+					Subject:       "subject",
+					Template:      "verification",
+					Locale:        "en",
+					Text:          "text",
+					HTML:          "html",
+					Status:        md.EmailPending,
+					Attempts:      1,
+					NextAttemptAt: now,
+					CreatedAt:     now,
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(emailClaimQ)).
+					WithArgs(10, time.Minute.Seconds()).
+					WillReturnError(errors.New("database error"))
+			},
+			expected:    nil,
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			res, err := r.ClaimEmails(context.Background(), 10, time.Minute)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Nil(t, res)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_UpdateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	const id = int64(1)
+	next := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name        string
+		mock        func()
+		call        func() error
+		expectedErr error
+	}{
+		{
+			name: "MarkSent",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(emailMarkSentQ)).
+					WithArgs(id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func() error {
+				return r.MarkEmailSent(context.Background(), id)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Retry",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(emailRetryQ)).
+					WithArgs(id, "timeout", next).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func() error {
+				return r.RetryEmail(context.Background(), id, next, "timeout")
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "MarkDead",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(emailMarkDeadQ)).
+					WithArgs(id, "timeout").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			call: func() error {
+				return r.MarkEmailDead(context.Background(), id, "timeout")
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "NotFound",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(emailMarkSentQ)).
+					WithArgs(id).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			call: func() error {
+				return r.MarkEmailSent(context.Background(), id)
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(emailMarkSentQ)).
+					WithArgs(id).
+					WillReturnError(errors.New("database error"))
+			},
+			call: func() error {
+				return r.MarkEmailSent(context.Background(), id)
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := tt.call()
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CountEmails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	rows := sqlmock.NewRows([]string{"status", "count"}).
+		AddRow(md.EmailPending, 3).
+		AddRow(md.EmailDead, 1)
+	mock.ExpectQuery(regexp.QuoteMeta(emailCountQ)).WillReturnRows(rows)
+
+	res, err := r.CountEmails(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{md.EmailPending: 3, md.EmailDead: 1}, res)
+
+	mock.ExpectQuery(regexp.QuoteMeta(emailCountQ)).WillReturnError(errors.New("database error"))
+	res, err = r.CountEmails(context.Background())
+	assert.EqualError(t, err, "database error")
+	assert.Nil(t, res)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_PurgeSentEmails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	before := time.Now().Add(-time.Hour)
+
+	mock.ExpectExec(regexp.QuoteMeta(emailPurgeSentQ)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := r.PurgeSentEmails(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	mock.ExpectExec(regexp.QuoteMeta(emailPurgeSentQ)).
+		WithArgs(before).
+		WillReturnError(errors.New("database error"))
+	_, err = r.PurgeSentEmails(context.Background(), before)
+	assert.EqualError(t, err, "database error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS email_outbox CASCADE;
+DROP INDEX IF EXISTS idx_email_outbox_pending CASCADE;
+DROP INDEX IF EXISTS idx_email_outbox_status CASCADE;
@@ -0,0 +1,9 @@
+INSERT INTO permissions (name, description)
+VALUES ('keys:rotate', 'Rotate token signing keys')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'keys:rotate'
+ON CONFLICT DO NOTHING;
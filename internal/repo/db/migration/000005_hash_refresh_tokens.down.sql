@@ -0,0 +1,2 @@
+-- Raw tokens cannot be restored from their hashes.
+DELETE FROM refresh_tokens;
@@ -0,0 +1,4 @@
+-- Refresh tokens used to be stored as raw JWTs. They cannot be rehashed
+-- without the application key, so revoke them and let clients sign in again.
+-- Hashes are hex encoded and never contain a dot.
+DELETE FROM refresh_tokens WHERE token_hash LIKE '%.%';
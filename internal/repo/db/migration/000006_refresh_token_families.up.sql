@@ -0,0 +1,19 @@
+-- REFRESH TOKEN FAMILIES
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES refresh_tokens (id) ON DELETE SET NULL;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+-- SECURITY EVENTS
+CREATE TABLE IF NOT EXISTS security_events (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    kind       VARCHAR(50) NOT NULL,
+    device_id  VARCHAR(36) NOT NULL DEFAULT '',
+    ip         VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id, created_at DESC);
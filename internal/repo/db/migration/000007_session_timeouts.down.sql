@@ -0,0 +1,3 @@
+ALTER TABLE refresh_tokens ALTER COLUMN last_used_at DROP DEFAULT;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS remember;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS session_started_at;
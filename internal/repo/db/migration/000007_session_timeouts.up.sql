@@ -0,0 +1,15 @@
+-- SESSION TIMEOUTS
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS session_started_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS remember BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE refresh_tokens t
+SET session_started_at = f.started_at
+FROM (
+    SELECT family_id, MIN(created_at) AS started_at
+    FROM refresh_tokens
+    GROUP BY family_id
+) f
+WHERE t.family_id = f.family_id;
+
+UPDATE refresh_tokens SET last_used_at = created_at WHERE last_used_at IS NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN last_used_at SET DEFAULT NOW();
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS device_history CASCADE;
+
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_user_device;
+ALTER TABLE devices DROP CONSTRAINT IF EXISTS devices_pkey;
+ALTER TABLE devices ADD CONSTRAINT devices_pkey PRIMARY KEY (id);
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_device FOREIGN KEY (device_id) REFERENCES devices (id) ON DELETE CASCADE;
@@ -0,0 +1,31 @@
+-- DEVICES ARE SCOPED TO THEIR USER
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_user_device') THEN
+        ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_device;
+        ALTER TABLE devices DROP CONSTRAINT IF EXISTS devices_pkey;
+        ALTER TABLE devices ADD CONSTRAINT devices_pkey PRIMARY KEY (user_id, id);
+        ALTER TABLE refresh_tokens
+            ADD CONSTRAINT fk_user_device FOREIGN KEY (user_id, device_id) REFERENCES devices (user_id, id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
+-- DEVICE HISTORY
+CREATE TABLE IF NOT EXISTS device_history (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    device_id  VARCHAR(36) NOT NULL,
+    ip         VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT        NOT NULL DEFAULT '',
+    first_seen TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_device_history_device FOREIGN KEY (user_id, device_id) REFERENCES devices (user_id, id) ON DELETE CASCADE,
+    CONSTRAINT uq_device_history UNIQUE (user_id, device_id, ip, user_agent)
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_history_device ON device_history (user_id, device_id, last_seen DESC);
+
+INSERT INTO device_history (user_id, device_id, ip, user_agent, first_seen, last_seen)
+SELECT user_id, id, COALESCE(ip, ''), COALESCE(user_agent, ''), created_at, last_active
+FROM devices
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,22 @@
+-- TOTP SECRETS, ENCRYPTED BY THE APPLICATION
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id      UUID PRIMARY KEY,
+    secret       TEXT        NOT NULL,
+    confirmed    BOOLEAN     NOT NULL DEFAULT FALSE,
+    last_step    BIGINT      NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    confirmed_at TIMESTAMPTZ,
+    CONSTRAINT fk_user_totp_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- ONE-TIME RECOVERY CODES
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    UUID        NOT NULL,
+    code_hash  VARCHAR(64) NOT NULL UNIQUE,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user ON recovery_codes (user_id);
@@ -0,0 +1,18 @@
+-- PASSKEYS
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id           VARCHAR(1400) PRIMARY KEY,
+    user_id      UUID         NOT NULL,
+    device_id    VARCHAR(36),
+    name         VARCHAR(64)  NOT NULL DEFAULT '',
+    public_key   BYTEA        NOT NULL,
+    alg          INTEGER      NOT NULL,
+    sign_count   BIGINT       NOT NULL DEFAULT 0,
+    aaguid       UUID         NOT NULL,
+    transports   TEXT         NOT NULL DEFAULT '',
+    last_used_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_webauthn_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_webauthn_device FOREIGN KEY (user_id, device_id) REFERENCES devices (user_id, id) ON DELETE SET NULL (device_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user ON webauthn_credentials (user_id);
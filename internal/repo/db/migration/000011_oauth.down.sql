@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'oauth:clients';
+DROP TABLE IF EXISTS oauth_tokens CASCADE;
+DROP TABLE IF EXISTS oauth_consents CASCADE;
+DROP TABLE IF EXISTS oauth_clients CASCADE;
@@ -0,0 +1,50 @@
+-- OAUTH CLIENTS
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name          VARCHAR(100) NOT NULL,
+    secret_hash   VARCHAR(64)  NOT NULL DEFAULT '',
+    public        BOOLEAN      NOT NULL DEFAULT FALSE,
+    redirect_uris TEXT         NOT NULL DEFAULT '',
+    scopes        TEXT         NOT NULL DEFAULT '',
+    grant_types   TEXT         NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- SCOPES GRANTED BY USERS
+CREATE TABLE IF NOT EXISTS oauth_consents (
+    user_id    UUID        NOT NULL,
+    client_id  UUID        NOT NULL,
+    scope      TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, client_id),
+    CONSTRAINT fk_oauth_consents_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_oauth_consents_client FOREIGN KEY (client_id) REFERENCES oauth_clients (id) ON DELETE CASCADE
+);
+
+-- REFRESH TOKENS ISSUED TO CLIENTS
+CREATE TABLE IF NOT EXISTS oauth_tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    client_id  UUID        NOT NULL,
+    user_id    UUID        NOT NULL,
+    scope      TEXT        NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked    BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_oauth_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_oauth_tokens_client FOREIGN KEY (client_id) REFERENCES oauth_clients (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_oauth_tokens_user_client ON oauth_tokens (user_id, client_id);
+
+-- SEED
+INSERT INTO permissions (name, description)
+VALUES ('oauth:clients', 'Register and remove OAuth clients')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'oauth:clients'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+-- JWT SIGNING KEYS SHARED BY ALL REPLICAS
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid         TEXT PRIMARY KEY,
+    alg         VARCHAR(16) NOT NULL,
+    private_key TEXT        NOT NULL DEFAULT '',
+    retire_at   TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+INSERT INTO permissions (name, description)
+VALUES ('tokens:introspect', 'Introspect any token'),
+       ('tokens:revoke', 'Revoke tokens of any user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name IN ('tokens:introspect', 'tokens:revoke')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'service-accounts:manage';
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS service_accounts CASCADE;
@@ -0,0 +1,33 @@
+-- SERVICE ACCOUNTS
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- API KEYS OF SERVICE ACCOUNTS
+CREATE TABLE IF NOT EXISTS api_keys (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    service_account_id UUID         NOT NULL,
+    name               VARCHAR(100) NOT NULL,
+    prefix             VARCHAR(16)  NOT NULL,
+    key_hash           VARCHAR(64)  NOT NULL UNIQUE,
+    scopes             TEXT         NOT NULL DEFAULT '',
+    expires_at         TIMESTAMPTZ,
+    last_used_at       TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (service_account_id, name),
+    CONSTRAINT fk_api_keys_service_account FOREIGN KEY (service_account_id) REFERENCES service_accounts (id) ON DELETE CASCADE
+);
+
+-- SEED
+INSERT INTO permissions (name, description)
+VALUES ('service-accounts:manage', 'Manage service accounts and their API keys')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = 'service-accounts:manage'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,358 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+func (r *Repository) CreateOAuthClient(ctx context.Context, c *md.OAuthClient) error {
+	const op = "oauth.CreateOAuthClient.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := r.conn.QueryRowContext(
+		ctx, createOAuthClient,
+		c.Name, c.SecretHash, c.Public, c.RedirectURIs, c.Scopes, c.GrantTypes,
+	).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create oauth client",
+			zap.String("op", op),
+			zap.String("name", c.Name),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetOAuthClient(ctx context.Context, id uuid.UUID) (*md.OAuthClient, error) {
+	const op = "oauth.GetOAuthClient.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := md.OAuthClient{}
+
+	err := r.conn.GetContext(ctx, &res, getOAuthClient, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get oauth client",
+			zap.String("op", op),
+			zap.String("clientID", id.String()),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *Repository) ListOAuthClients(ctx context.Context) ([]md.OAuthClient, error) {
+	const op = "oauth.ListOAuthClients.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := make([]md.OAuthClient, 0)
+
+	err := r.conn.SelectContext(ctx, &res, listOAuthClients)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to list oauth clients",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) DeleteOAuthClient(ctx context.Context, id uuid.UUID) error {
+	const op = "oauth.DeleteOAuthClient.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, uuid.Nil, deleteOAuthClient, id)
+}
+
+func (r *Repository) GetOAuthConsent(ctx context.Context, uid, clientID uuid.UUID) (*md.OAuthConsent, error) {
+	const op = "oauth.GetOAuthConsent.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := md.OAuthConsent{}
+
+	err := r.conn.GetContext(ctx, &res, getOAuthConsent, uid, clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get oauth consent",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.String("clientID", clientID.String()),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// SaveOAuthConsent stores the scopes granted to the client, replacing the
+// previous grant.
+func (r *Repository) SaveOAuthConsent(ctx context.Context, c *md.OAuthConsent) error {
+	const op = "oauth.SaveOAuthConsent.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, saveOAuthConsent, c.UserID, c.ClientID, c.Scope)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to save oauth consent",
+			zap.String("op", op),
+			zap.String("userID", c.UserID.String()),
+			zap.String("clientID", c.ClientID.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) CreateOAuthToken(ctx context.Context, t *md.OAuthToken) error {
+	const op = "oauth.CreateOAuthToken.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, createOAuthToken, t.TokenHash, t.ClientID, t.UserID, t.Scope, t.ExpiresAt)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create oauth token",
+			zap.String("op", op),
+			zap.String("userID", t.UserID.String()),
+			zap.String("clientID", t.ClientID.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetOAuthToken(ctx context.Context, hashedT string) (*md.OAuthToken, error) {
+	const op = "oauth.GetOAuthToken.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := md.OAuthToken{}
+
+	err := r.conn.GetContext(ctx, &res, getOAuthToken, hashedT)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get oauth token",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// RotateOAuthToken revokes parent and stores t in its place. It returns
+// repo.ErrNotFound if parent was already revoked.
+func (r *Repository) RotateOAuthToken(ctx context.Context, parent, t *md.OAuthToken) error {
+	const op = "oauth.RotateOAuthToken.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to begin transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"error while transaction rollback",
+				zap.String("op", op),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, revokeOAuthToken, parent.ID)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to revoke oauth token",
+			zap.String("op", op),
+			zap.Uint64("tokenID", parent.ID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		return repo.ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, createOAuthToken, t.TokenHash, t.ClientID, t.UserID, t.Scope, t.ExpiresAt)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create oauth token",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to commit transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// RevokeOAuthTokens revokes every refresh token the client holds for the user.
+func (r *Repository) RevokeOAuthTokens(ctx context.Context, uid, clientID uuid.UUID) error {
+	const op = "oauth.RevokeOAuthTokens.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, revokeOAuthTokens, uid, clientID)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to revoke oauth tokens",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.String("clientID", clientID.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserOAuthTokens revokes the refresh tokens of every client the user
+// authorized.
+func (r *Repository) RevokeUserOAuthTokens(ctx context.Context, uid uuid.UUID) error {
+	const op = "oauth.RevokeUserOAuthTokens.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, revokeUserOAuthTokens, uid)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to revoke user oauth tokens",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// RevokeOAuthToken revokes a single refresh token. Revoking a revoked token
+// is not an error.
+func (r *Repository) RevokeOAuthToken(ctx context.Context, id uint64) error {
+	const op = "oauth.RevokeOAuthToken.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, revokeOAuthToken, id)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to revoke oauth token",
+			zap.String("op", op),
+			zap.Uint64("id", id),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package db
+
+const createOAuthClient = `
+INSERT INTO oauth_clients (name, secret_hash, public, redirect_uris, scopes, grant_types)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at
+`
+
+const getOAuthClient = `
+SELECT id, name, secret_hash, public, redirect_uris, scopes, grant_types, created_at
+FROM oauth_clients
+WHERE id = $1
+`
+
+const listOAuthClients = `
+SELECT id, name, secret_hash, public, redirect_uris, scopes, grant_types, created_at
+FROM oauth_clients
+ORDER BY created_at
+`
+
+const deleteOAuthClient = `
+DELETE FROM oauth_clients
+WHERE id = $1
+`
+
+const getOAuthConsent = `
+SELECT user_id, client_id, scope, created_at, updated_at
+FROM oauth_consents
+WHERE user_id = $1 AND client_id = $2
+`
+
+const saveOAuthConsent = `
+INSERT INTO oauth_consents (user_id, client_id, scope)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, client_id) DO UPDATE SET scope = EXCLUDED.scope, updated_at = NOW()
+`
+
+const createOAuthToken = `
+INSERT INTO oauth_tokens (token_hash, client_id, user_id, scope, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+const getOAuthToken = `
+SELECT id, token_hash, client_id, user_id, scope, expires_at, revoked, created_at
+FROM oauth_tokens
+WHERE token_hash = $1
+`
+
+// revokeOAuthToken only matches live tokens, so a token can be rotated once.
+const revokeOAuthToken = `
+UPDATE oauth_tokens
+SET revoked = TRUE
+WHERE id = $1 AND revoked = FALSE
+`
+
+const revokeOAuthTokens = `
+UPDATE oauth_tokens
+SET revoked = TRUE
+WHERE user_id = $1 AND client_id = $2 AND revoked = FALSE
+`
+
+const revokeUserOAuthTokens = `
+UPDATE oauth_tokens
+SET revoked = TRUE
+WHERE user_id = $1 AND revoked = FALSE
+`
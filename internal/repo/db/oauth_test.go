@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRepository_CreateOAuthClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	id := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mock        func(c *md.OAuthClient)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func(c *md.OAuthClient) {
+				mock.ExpectQuery(regexp.QuoteMeta(createOAuthClient)).
+					WithArgs(c.Name, c.SecretHash, c.Public, c.RedirectURIs, c.Scopes, c.GrantTypes).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(id, now))
+			},
+		},
+		{
+			name: "DatabaseError",
+			mock: func(c *md.OAuthClient) {
+				mock.ExpectQuery(regexp.QuoteMeta(createOAuthClient)).
+					WithArgs(c.Name, c.SecretHash, c.Public, c.RedirectURIs, c.Scopes, c.GrantTypes).
+					WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &md.OAuthClient{
+				Name:         "app",
+				SecretHash:   "hash",
+				RedirectURIs: "https://app.example.com/cb",
+				Scopes:       "profile",
+				GrantTypes:   "authorization_code",
+			}
+			tt.mock(c)
+
+			err := r.CreateOAuthClient(context.Background(), c)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, id, c.ID)
+				assert.Equal(t, now, c.CreatedAt)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetOAuthToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	expected := &md.OAuthToken{
+		ID:        1,
+		TokenHash: "hash",
+		ClientID:  uuid.New(),
+		UserID:    uuid.New(),
+		Scope:     "profile",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    *md.OAuthToken
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows(
+					[]string{"id", "token_hash", "client_id", "user_id", "scope", "expires_at", "revoked", "created_at"},
+				).AddRow(
+					expected.ID, expected.TokenHash, expected.ClientID, expected.UserID,
+					expected.Scope, expected.ExpiresAt, expected.Revoked, expected.CreatedAt,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(getOAuthToken)).WithArgs("hash").WillReturnRows(rows)
+			},
+			expected: expected,
+		},
+		{
+			name: "NotFound",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(getOAuthToken)).WithArgs("hash").WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			res, err := r.GetOAuthToken(context.Background(), "hash")
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Nil(t, res)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_RotateOAuthToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	parent := &md.OAuthToken{ID: 1}
+	token := &md.OAuthToken{
+		TokenHash: "hash",
+		ClientID:  uuid.New(),
+		UserID:    uuid.New(),
+		Scope:     "profile",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(revokeOAuthToken)).
+					WithArgs(parent.ID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createOAuthToken)).
+					WithArgs(token.TokenHash, token.ClientID, token.UserID, token.Scope, token.ExpiresAt).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "AlreadyRotated",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(revokeOAuthToken)).
+					WithArgs(parent.ID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.RotateOAuthToken(context.Background(), parent, token)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_RevokeOAuthToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(revokeOAuthToken)).
+					WithArgs(uint64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "AlreadyRevoked",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(revokeOAuthToken)).
+					WithArgs(uint64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name: "DBError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(revokeOAuthToken)).
+					WithArgs(uint64(1)).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.RevokeOAuthToken(context.Background(), 1)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_RevokeUserOAuthTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(revokeUserOAuthTokens)).
+					WithArgs(uid).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+		},
+		{
+			name: "DBError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(revokeUserOAuthTokens)).
+					WithArgs(uid).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.RevokeUserOAuthTokens(context.Background(), uid)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
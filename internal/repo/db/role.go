@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+func (r *Repository) ListRoles(ctx context.Context) ([]md.Role, error) {
+	const op = "roles.ListRoles.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	rows, err := r.conn.QueryContext(ctx, roleListQ)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to list roles",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return scanRoles(span, op, rows)
+}
+
+func (r *Repository) ListUserRoles(ctx context.Context, uid uuid.UUID) ([]md.Role, error) {
+	const op = "roles.ListUserRoles.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	rows, err := r.conn.QueryContext(ctx, roleListByUserQ, uid)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to list user roles",
+			zap.String("op", op),
+			zap.String("uid", uid.String()),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return scanRoles(span, op, rows)
+}
+
+func (r *Repository) AssignRole(ctx context.Context, uid uuid.UUID, role string) error {
+	const op = "roles.AssignRole.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := r.conn.ExecContext(ctx, roleAssignQ, uid, role)
+	if err != nil {
+		trgtErr := &pgconn.PgError{}
+		if errors.As(err, &trgtErr) && trgtErr.Code == "23503" {
+			zap.L().Debug(
+				"user not found",
+				zap.String("op", op),
+				zap.String("uid", uid.String()),
+				zap.String("constraint", trgtErr.ConstraintName),
+			)
+			return repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to assign role",
+			zap.String("op", op),
+			zap.String("uid", uid.String()),
+			zap.String("role", role),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		zap.L().Debug(
+			"role not found",
+			zap.String("op", op),
+			zap.String("role", role),
+		)
+
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) RevokeRole(ctx context.Context, uid uuid.UUID, role string) error {
+	const op = "roles.RevokeRole.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := r.conn.ExecContext(ctx, roleRevokeQ, uid, role)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to revoke role",
+			zap.String("op", op),
+			zap.String("uid", uid.String()),
+			zap.String("role", role),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		zap.L().Debug(
+			"role assignment not found",
+			zap.String("op", op),
+			zap.String("uid", uid.String()),
+			zap.String("role", role),
+		)
+
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+// scanRoles folds (role, permission) rows ordered by role name into roles.
+func scanRoles(span opentracing.Span, op string, rows *sql.Rows) ([]md.Role, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			zap.L().Error("failed to close rows", zap.String("op", op), zap.Error(err))
+		}
+	}()
+
+	res := make([]md.Role, 0)
+	for rows.Next() {
+		var role md.Role
+		var perm sql.NullString
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &perm); err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error("failed to scan row", zap.String("op", op), zap.Error(err))
+			return nil, err
+		}
+
+		if len(res) == 0 || res[len(res)-1].ID != role.ID {
+			role.Permissions = make([]string, 0)
+			res = append(res, role)
+		}
+
+		if perm.Valid {
+			last := &res[len(res)-1]
+			last.Permissions = append(last.Permissions, perm.String)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to scan rows", zap.String("op", op), zap.Error(err))
+		return nil, err
+	}
+
+	return res, nil
+}
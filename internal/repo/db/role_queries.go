@@ -0,0 +1,35 @@
+package db
+
+const roleListQ = `
+SELECT r.id, r.name, r.description, p.name
+FROM roles r
+LEFT JOIN role_permissions rp ON rp.role_id = r.id
+LEFT JOIN permissions p ON p.id = rp.permission_id
+ORDER BY r.name, p.name
+`
+
+const roleListByUserQ = `
+SELECT r.id, r.name, r.description, p.name
+FROM user_roles ur
+JOIN roles r ON r.id = ur.role_id
+LEFT JOIN role_permissions rp ON rp.role_id = r.id
+LEFT JOIN permissions p ON p.id = rp.permission_id
+WHERE ur.user_id = $1
+ORDER BY r.name, p.name
+`
+
+// roleAssignQ touches the row on conflict so that zero affected rows always
+// means the role does not exist.
+const roleAssignQ = `
+INSERT INTO user_roles (user_id, role_id)
+SELECT $1, r.id
+FROM roles r
+WHERE r.name = $2
+ON CONFLICT (user_id, role_id) DO UPDATE SET user_id = EXCLUDED.user_id
+`
+
+const roleRevokeQ = `
+DELETE FROM user_roles ur
+USING roles r
+WHERE ur.role_id = r.id AND ur.user_id = $1 AND r.name = $2
+`
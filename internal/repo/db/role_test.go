@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestRepository_ListUserRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+	cols := []string{"id", "name", "description", "name"}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    []md.Role
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows(cols).
+					AddRow(1, "admin", "Full access", "users:delete").
+					AddRow(1, "admin", "Full access", "users:list").
+					AddRow(2, "viewer", "", nil)
+				mock.ExpectQuery(regexp.QuoteMeta(roleListByUserQ)).
+					WithArgs(uid).
+					WillReturnRows(rows)
+			},
+			expected: []md.Role{
+				{ID: 1, Name: "admin", Description: "Full access", Permissions: []string{"users:delete", "users:list"}},
+				{ID: 2, Name: "viewer", Description: "", Permissions: []string{}},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "NoRoles",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(roleListByUserQ)).
+					WithArgs(uid).
+					WillReturnRows(sqlmock.NewRows(cols))
+			},
+			expected:    []md.Role{},
+			expectedErr: nil,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(roleListByUserQ)).
+					WithArgs(uid).
+					WillReturnError(errors.New("database error"))
+			},
+			expected:    nil,
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			res, err := r.ListUserRoles(context.Background(), uid)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Nil(t, res)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_AssignRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(roleAssignQ)).
+					WithArgs(uid, "admin").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "RoleNotFound",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(roleAssignQ)).
+					WithArgs(uid, "admin").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "UserNotFound",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(roleAssignQ)).
+					WithArgs(uid, "admin").
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(roleAssignQ)).
+					WithArgs(uid, "admin").
+					WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			err := r.AssignRole(context.Background(), uid, "admin")
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_RevokeRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(roleRevokeQ)).
+		WithArgs(uid, "admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.RevokeRole(context.Background(), uid, "admin"))
+
+	mock.ExpectExec(regexp.QuoteMeta(roleRevokeQ)).
+		WithArgs(uid, "admin").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, r.RevokeRole(context.Background(), uid, "admin"), repo.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+// CreateServiceAccount stores sa. It returns repo.ErrAlreadyExists if the
+// name is taken.
+func (r *Repository) CreateServiceAccount(ctx context.Context, sa *md.ServiceAccount) error {
+	const op = "serviceAccount.CreateServiceAccount.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := r.conn.QueryRowContext(ctx, createServiceAccount, sa.Name, sa.Description).Scan(&sa.ID, &sa.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ErrAlreadyExists
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create service account",
+			zap.String("op", op),
+			zap.String("name", sa.Name),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetServiceAccount(ctx context.Context, id uuid.UUID) (*md.ServiceAccount, error) {
+	const op = "serviceAccount.GetServiceAccount.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := md.ServiceAccount{}
+
+	err := r.conn.GetContext(ctx, &res, getServiceAccount, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get service account",
+			zap.String("op", op),
+			zap.String("id", id.String()),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *Repository) ListServiceAccounts(ctx context.Context) ([]md.ServiceAccount, error) {
+	const op = "serviceAccount.ListServiceAccounts.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := make([]md.ServiceAccount, 0)
+
+	err := r.conn.SelectContext(ctx, &res, listServiceAccounts)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to list service accounts",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) DeleteServiceAccount(ctx context.Context, id uuid.UUID) error {
+	const op = "serviceAccount.DeleteServiceAccount.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, id, deleteServiceAccount, id)
+}
+
+// CreateAPIKey stores k. It returns repo.ErrAlreadyExists if the service
+// account already has a key with that name.
+func (r *Repository) CreateAPIKey(ctx context.Context, k *md.APIKey) error {
+	const op = "serviceAccount.CreateAPIKey.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	err := r.conn.QueryRowContext(
+		ctx, createAPIKey,
+		k.ServiceAccountID, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.ExpiresAt,
+	).Scan(&k.ID, &k.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ErrAlreadyExists
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create api key",
+			zap.String("op", op),
+			zap.String("serviceAccountID", k.ServiceAccountID.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) ListAPIKeys(ctx context.Context, saID uuid.UUID) ([]md.APIKey, error) {
+	const op = "serviceAccount.ListAPIKeys.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := make([]md.APIKey, 0)
+
+	err := r.conn.SelectContext(ctx, &res, listAPIKeys, saID)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to list api keys",
+			zap.String("op", op),
+			zap.String("serviceAccountID", saID.String()),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) GetAPIKeyByHash(ctx context.Context, hash string) (*md.APIKey, error) {
+	const op = "serviceAccount.GetAPIKeyByHash.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := md.APIKey{}
+
+	err := r.conn.GetContext(ctx, &res, getAPIKeyByHash, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get api key",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *Repository) DeleteAPIKey(ctx context.Context, saID, id uuid.UUID) error {
+	const op = "serviceAccount.DeleteAPIKey.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, saID, deleteAPIKey, id, saID)
+}
+
+// TouchAPIKey records that the key was just used.
+func (r *Repository) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	const op = "serviceAccount.TouchAPIKey.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	_, err := r.conn.ExecContext(ctx, touchAPIKey, id)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to touch api key",
+			zap.String("op", op),
+			zap.String("id", id.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
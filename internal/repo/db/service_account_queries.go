@@ -0,0 +1,58 @@
+package db
+
+const createServiceAccount = `
+INSERT INTO service_accounts (name, description)
+VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING
+RETURNING id, created_at
+`
+
+const getServiceAccount = `
+SELECT id, name, description, created_at
+FROM service_accounts
+WHERE id = $1
+`
+
+const listServiceAccounts = `
+SELECT id, name, description, created_at
+FROM service_accounts
+ORDER BY created_at
+`
+
+const deleteServiceAccount = `
+DELETE FROM service_accounts
+WHERE id = $1
+`
+
+const createAPIKey = `
+INSERT INTO api_keys (service_account_id, name, prefix, key_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (service_account_id, name) DO NOTHING
+RETURNING id, created_at
+`
+
+const listAPIKeys = `
+SELECT id, service_account_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+FROM api_keys
+WHERE service_account_id = $1
+ORDER BY created_at
+`
+
+const getAPIKeyByHash = `
+SELECT id, service_account_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at
+FROM api_keys
+WHERE key_hash = $1
+`
+
+const deleteAPIKey = `
+DELETE FROM api_keys
+WHERE id = $1 AND service_account_id = $2
+`
+
+// touchAPIKey records at most one use per minute, so busy keys don't write on
+// every request.
+const touchAPIKey = `
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+`
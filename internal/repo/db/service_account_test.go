@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRepository_CreateServiceAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	id := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mock        func(sa *md.ServiceAccount)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func(sa *md.ServiceAccount) {
+				mock.ExpectQuery(regexp.QuoteMeta(createServiceAccount)).
+					WithArgs(sa.Name, sa.Description).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(id, now))
+			},
+		},
+		{
+			name: "AlreadyExists",
+			mock: func(sa *md.ServiceAccount) {
+				mock.ExpectQuery(regexp.QuoteMeta(createServiceAccount)).
+					WithArgs(sa.Name, sa.Description).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: repo.ErrAlreadyExists,
+		},
+		{
+			name: "DatabaseError",
+			mock: func(sa *md.ServiceAccount) {
+				mock.ExpectQuery(regexp.QuoteMeta(createServiceAccount)).
+					WithArgs(sa.Name, sa.Description).
+					WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sa := &md.ServiceAccount{Name: "ci", Description: "CI pipeline"}
+			tt.mock(sa)
+
+			err := r.CreateServiceAccount(context.Background(), sa)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, id, sa.ID)
+				assert.Equal(t, now, sa.CreatedAt)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	id := uuid.New()
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		mock        func(k *md.APIKey)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func(k *md.APIKey) {
+				mock.ExpectQuery(regexp.QuoteMeta(createAPIKey)).
+					WithArgs(k.ServiceAccountID, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.ExpiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(id, now))
+			},
+		},
+		{
+			name: "DuplicateName",
+			mock: func(k *md.APIKey) {
+				mock.ExpectQuery(regexp.QuoteMeta(createAPIKey)).
+					WithArgs(k.ServiceAccountID, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.ExpiresAt).
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: repo.ErrAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &md.APIKey{
+				ServiceAccountID: uuid.New(),
+				Name:             "deploy",
+				Prefix:           "sk_0123abcd",
+				KeyHash:          "hash",
+				Scopes:           "users:list",
+				ExpiresAt:        &expiresAt,
+			}
+			tt.mock(k)
+
+			err := r.CreateAPIKey(context.Background(), k)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, id, k.ID)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	expected := &md.APIKey{
+		ID:               uuid.New(),
+		ServiceAccountID: uuid.New(),
+		Name:             "deploy",
+		Prefix:           "sk_0123abcd",
+		KeyHash:          "hash",
+		Scopes:           "users:list",
+		CreatedAt:        time.Now(),
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    *md.APIKey
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows(
+					[]string{
+						"id", "service_account_id", "name", "prefix", "key_hash",
+						"scopes", "expires_at", "last_used_at", "created_at",
+					},
+				).AddRow(
+					expected.ID, expected.ServiceAccountID, expected.Name, expected.Prefix, expected.KeyHash,
+					expected.Scopes, nil, nil, expected.CreatedAt,
+				)
+				mock.ExpectQuery(regexp.QuoteMeta(getAPIKeyByHash)).WithArgs("hash").WillReturnRows(rows)
+			},
+			expected: expected,
+		},
+		{
+			name: "NotFound",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(getAPIKeyByHash)).WithArgs("hash").WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			res, err := r.GetAPIKeyByHash(context.Background(), "hash")
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	saID := uuid.New()
+	id := uuid.New()
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(deleteAPIKey)).
+					WithArgs(id, saID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "KeyOfAnotherAccount",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(deleteAPIKey)).
+					WithArgs(id, saID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.DeleteAPIKey(context.Background(), saID, id)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
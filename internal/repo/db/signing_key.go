@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+// ListSigningKeys returns every stored signing key, oldest first.
+func (r *Repository) ListSigningKeys(ctx context.Context) ([]md.SigningKey, error) {
+	const op = "keys.ListSigningKeys.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := make([]md.SigningKey, 0)
+
+	err := r.conn.SelectContext(ctx, &res, listSigningKeys)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to list signing keys",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateSigningKeys stores keys, skipping the ones whose kid already exists.
+func (r *Repository) CreateSigningKeys(ctx context.Context, keys []md.SigningKey) error {
+	const op = "keys.CreateSigningKeys.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	for _, k := range keys {
+		_, err := r.conn.ExecContext(ctx, createSigningKey, k.ID, k.Alg, k.PrivateKey, k.RetireAt)
+		if err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"failed to create signing key",
+				zap.String("op", op),
+				zap.String("kid", k.ID),
+				zap.Error(err),
+			)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RotateSigningKey retires the active keys and prev at prev.RetireAt, drops
+// the generated keys that already retired and stores next as the active key.
+func (r *Repository) RotateSigningKey(ctx context.Context, prev, next *md.SigningKey) error {
+	const op = "keys.RotateSigningKey.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to begin transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"error while transaction rollback",
+				zap.String("op", op),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{retireSigningKeys, []any{prev.RetireAt}},
+		{createSigningKey, []any{prev.ID, prev.Alg, prev.PrivateKey, prev.RetireAt}},
+		{deleteRetiredSigningKeys, nil},
+		{createSigningKey, []any{next.ID, next.Alg, next.PrivateKey, next.RetireAt}},
+	}
+	for _, stmt := range stmts {
+		if _, err = tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"failed to rotate signing key",
+				zap.String("op", op),
+				zap.String("kid", next.ID),
+				zap.Error(err),
+			)
+
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to commit transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package db
+
+const listSigningKeys = `
+SELECT kid, alg, private_key, retire_at, created_at
+FROM signing_keys
+ORDER BY created_at, kid
+`
+
+const createSigningKey = `
+INSERT INTO signing_keys (kid, alg, private_key, retire_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (kid) DO NOTHING
+`
+
+const retireSigningKeys = `
+UPDATE signing_keys
+SET retire_at = $1
+WHERE retire_at IS NULL
+`
+
+const deleteRetiredSigningKeys = `
+DELETE FROM signing_keys
+WHERE private_key <> '' AND retire_at < NOW()
+`
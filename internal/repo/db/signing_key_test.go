@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRepository_ListSigningKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	now := time.Now()
+	expected := []md.SigningKey{
+		{ID: "configured", Alg: "HS256", RetireAt: &now, CreatedAt: now},
+		{ID: "generated", Alg: "HS256", PrivateKey: "encrypted", CreatedAt: now},
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    []md.SigningKey
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"kid", "alg", "private_key", "retire_at", "created_at"})
+				for _, k := range expected {
+					rows.AddRow(k.ID, k.Alg, k.PrivateKey, k.RetireAt, k.CreatedAt)
+				}
+				mock.ExpectQuery(regexp.QuoteMeta(listSigningKeys)).WillReturnRows(rows)
+			},
+			expected: expected,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(listSigningKeys)).WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			res, err := r.ListSigningKeys(context.Background())
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Nil(t, res)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_RotateSigningKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	retireAt := time.Now().Add(time.Hour)
+	prev := &md.SigningKey{ID: "prev", Alg: "HS256", RetireAt: &retireAt}
+	next := &md.SigningKey{ID: "next", Alg: "HS256", PrivateKey: "encrypted"}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(retireSigningKeys)).
+					WithArgs(prev.RetireAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(createSigningKey)).
+					WithArgs(prev.ID, prev.Alg, prev.PrivateKey, prev.RetireAt).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(deleteRetiredSigningKeys)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(createSigningKey)).
+					WithArgs(next.ID, next.Alg, next.PrivateKey, next.RetireAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(retireSigningKeys)).
+					WithArgs(prev.RetireAt).
+					WillReturnError(errors.New("database error"))
+				mock.ExpectRollback()
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.RotateSigningKey(context.Background(), prev, next)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+func (r *Repository) GetTOTP(ctx context.Context, uid uuid.UUID) (*md.TOTP, error) {
+	const op = "totp.GetTOTP.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := md.TOTP{}
+
+	err := r.conn.GetContext(ctx, &res, getTOTP, uid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get totp",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// CreateTOTP stores a new unconfirmed secret, replacing a pending one. It
+// returns repo.ErrAlreadyExists if the user already confirmed a secret.
+func (r *Repository) CreateTOTP(ctx context.Context, uid uuid.UUID, secret string) error {
+	const op = "totp.CreateTOTP.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := r.conn.ExecContext(ctx, createTOTP, uid, secret)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create totp",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		return repo.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// ConfirmTOTP enables the pending secret at the given step and replaces the
+// user's recovery codes with hashedCodes.
+func (r *Repository) ConfirmTOTP(ctx context.Context, uid uuid.UUID, step int64, hashedCodes []string) error {
+	const op = "totp.ConfirmTOTP.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	tx, err := r.conn.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to begin transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"error while transaction rollback",
+				zap.String("op", op),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, confirmTOTP, uid, step)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to confirm totp",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		return repo.ErrNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, deleteRecoveryCodes, uid); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to delete recovery codes",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	for _, hashed := range hashedCodes {
+		if _, err = tx.ExecContext(ctx, createRecoveryCode, uid, hashed); err != nil {
+			span.SetTag(config.ErrorSpanTag, true)
+			zap.L().Error(
+				"failed to create recovery code",
+				zap.String("op", op),
+				zap.String("userID", uid.String()),
+				zap.Error(err),
+			)
+
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to commit transaction",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// UseTOTPStep records step as used. It returns repo.ErrNotFound if 2FA is
+// not enabled or the step is not newer than the last accepted one.
+func (r *Repository) UseTOTPStep(ctx context.Context, uid uuid.UUID, step int64) error {
+	const op = "totp.UseTOTPStep.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, uid, useTOTPStep, uid, step)
+}
+
+// UseRecoveryCode marks an unused recovery code as used.
+func (r *Repository) UseRecoveryCode(ctx context.Context, uid uuid.UUID, hashedCode string) error {
+	const op = "totp.UseRecoveryCode.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, uid, useRecoveryCode, uid, hashedCode)
+}
+
+// DeleteTOTP disables 2FA and drops the user's recovery codes.
+func (r *Repository) DeleteTOTP(ctx context.Context, uid uuid.UUID) error {
+	const op = "totp.DeleteTOTP.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, uid, deleteTOTP, uid)
+}
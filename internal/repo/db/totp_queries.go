@@ -0,0 +1,51 @@
+package db
+
+const getTOTP = `
+SELECT user_id, secret, confirmed, last_step, created_at
+FROM user_totp
+WHERE user_id = $1
+`
+
+const createTOTP = `
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_step = 0, created_at = NOW()
+WHERE user_totp.confirmed = FALSE
+`
+
+const confirmTOTP = `
+UPDATE user_totp
+SET confirmed = TRUE, confirmed_at = NOW(), last_step = $2
+WHERE user_id = $1 AND confirmed = FALSE AND last_step < $2
+`
+
+const useTOTPStep = `
+UPDATE user_totp
+SET last_step = $2
+WHERE user_id = $1 AND confirmed = TRUE AND last_step < $2
+`
+
+const deleteTOTP = `
+WITH codes AS (
+	DELETE FROM recovery_codes WHERE user_id = $1
+)
+DELETE FROM user_totp
+WHERE user_id = $1
+`
+
+const deleteRecoveryCodes = `
+DELETE FROM recovery_codes
+WHERE user_id = $1
+`
+
+const createRecoveryCode = `
+INSERT INTO recovery_codes (user_id, code_hash)
+VALUES ($1, $2)
+`
+
+const useRecoveryCode = `
+UPDATE recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+`
@@ -0,0 +1,291 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRepository_GetTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+
+	uid := uuid.New()
+	expected := &md.TOTP{UserID: uid, Secret: "encrypted", Confirmed: true, LastStep: 42, CreatedAt: time.Now()}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    *md.TOTP
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"user_id", "secret", "confirmed", "last_step", "created_at"}).
+					AddRow(uid, expected.Secret, expected.Confirmed, expected.LastStep, expected.CreatedAt)
+				mock.ExpectQuery(regexp.QuoteMeta(getTOTP)).WithArgs(uid).WillReturnRows(rows)
+			},
+			expected: expected,
+		},
+		{
+			name: "NotFound",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(getTOTP)).WithArgs(uid).WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectQuery(regexp.QuoteMeta(getTOTP)).WithArgs(uid).WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			res, err := r.GetTOTP(context.Background(), uid)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				assert.Nil(t, res)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, res)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CreateTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(createTOTP)).
+					WithArgs(uid, "encrypted").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "AlreadyConfirmed",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(createTOTP)).
+					WithArgs(uid, "encrypted").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: repo.ErrAlreadyExists,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(createTOTP)).
+					WithArgs(uid, "encrypted").
+					WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.CreateTOTP(context.Background(), uid, "encrypted")
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ConfirmTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+	step := int64(42)
+	codes := []string{"hash1", "hash2"}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(confirmTOTP)).
+					WithArgs(uid, step).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(deleteRecoveryCodes)).
+					WithArgs(uid).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				for _, c := range codes {
+					mock.ExpectExec(regexp.QuoteMeta(createRecoveryCode)).
+						WithArgs(uid, c).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "NotPending",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(confirmTOTP)).
+					WithArgs(uid, step).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "CreateCodeError",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(confirmTOTP)).
+					WithArgs(uid, step).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(deleteRecoveryCodes)).
+					WithArgs(uid).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(createRecoveryCode)).
+					WithArgs(uid, codes[0]).
+					WillReturnError(errors.New("database error"))
+				mock.ExpectRollback()
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.ConfirmTOTP(context.Background(), uid, step, codes)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_UseTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(useTOTPStep)).
+		WithArgs(uid, int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.UseTOTPStep(context.Background(), uid, 42))
+
+	mock.ExpectExec(regexp.QuoteMeta(useTOTPStep)).
+		WithArgs(uid, int64(42)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, r.UseTOTPStep(context.Background(), uid, 42), repo.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_UseRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(useRecoveryCode)).
+		WithArgs(uid, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.UseRecoveryCode(context.Background(), uid, "hash"))
+
+	mock.ExpectExec(regexp.QuoteMeta(useRecoveryCode)).
+		WithArgs(uid, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, r.UseRecoveryCode(context.Background(), uid, "hash"), repo.ErrNotFound)
+
+	mock.ExpectExec(regexp.QuoteMeta(useRecoveryCode)).
+		WithArgs(uid, "hash").
+		WillReturnError(errors.New("database error"))
+	assert.EqualError(t, r.UseRecoveryCode(context.Background(), uid, "hash"), "database error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteTOTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(deleteTOTP)).
+		WithArgs(uid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.DeleteTOTP(context.Background(), uid))
+
+	mock.ExpectExec(regexp.QuoteMeta(deleteTOTP)).
+		WithArgs(uid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, r.DeleteTOTP(context.Background(), uid), repo.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -244,7 +244,6 @@ func (r *Repository) CreateUser(
 		req.Email,
 		req.Avatar,
 		req.IsActive,
-		req.IsEmail,
 	).Scan(&id)
 	if err != nil {
 		trgtErr := &pgconn.PgError{}
@@ -334,7 +333,6 @@ func (r *Repository) UpdateUser(
 		req.Email,
 		req.Avatar,
 		req.IsActive,
-		req.IsEmail,
 		id,
 	)
 	if err != nil {
@@ -382,6 +380,94 @@ func (r *Repository) UpdateUser(
 	return nil
 }
 
+func (r *Repository) UpdatePassword(ctx context.Context, id uuid.UUID, hashed string) error {
+	const op = "users.UpdatePassword.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := r.conn.ExecContext(ctx, userUpdatePasswordQ, hashed, id)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to update password",
+			zap.String("op", op),
+			zap.String("userID", id.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		zap.L().Debug(
+			"failed to find user",
+			zap.String("op", op),
+			zap.String("userID", id.String()),
+		)
+
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+// VerifyEmail marks email as verified. It returns repo.ErrNotFound if the
+// user no longer has that address.
+func (r *Repository) VerifyEmail(ctx context.Context, id uuid.UUID, email string) error {
+	const op = "users.VerifyEmail.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := r.conn.ExecContext(ctx, userVerifyEmailQ, id, email)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to verify email",
+			zap.String("op", op),
+			zap.String("userID", id.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		zap.L().Error(
+			"failed to get affected rows",
+			zap.String("op", op),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff == 0 {
+		zap.L().Debug(
+			"failed to find user",
+			zap.String("op", op),
+			zap.String("userID", id.String()),
+		)
+
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *Repository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	const op = "users.DeleteUser.repo"
 
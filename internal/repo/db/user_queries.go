@@ -51,8 +51,8 @@ GROUP BY u.id
 `
 
 const userCreateQ = `
-INSERT INTO users (name, password, email, avatar, is_active, is_email_verified) 
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO users (name, password, email, avatar, is_active) 
+VALUES ($1, $2, $3, $4, $5)
 RETURNING id
 `
 
@@ -62,8 +62,22 @@ SET name = $1,
     email = $2,
     avatar = $3,
 	is_active = $4,
-	is_email_verified = $5
-WHERE id = $6`
+	is_email_verified = is_email_verified AND email = $2
+WHERE id = $5`
+
+const userUpdatePasswordQ = `
+UPDATE users
+SET password = $1,
+    updated_at = NOW()
+WHERE id = $2
+`
+
+const userVerifyEmailQ = `
+UPDATE users
+SET is_email_verified = TRUE,
+    updated_at = NOW()
+WHERE id = $1 AND email = $2
+`
 
 const userDeleteQ = `
 DELETE FROM users 
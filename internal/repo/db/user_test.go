@@ -411,7 +411,6 @@ func TestRepository_CreateUser(t *testing.T) {
 		Email:    "test@example.com",
 		Avatar:   "avatar.jpg",
 		IsActive: true,
-		IsEmail:  true,
 	}
 
 	pgErr := &pgconn.PgError{Code: "23505"}
@@ -435,7 +434,6 @@ func TestRepository_CreateUser(t *testing.T) {
 						createReq.Email,
 						createReq.Avatar,
 						createReq.IsActive,
-						createReq.IsEmail,
 					).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testID))
 				mock.ExpectCommit()
@@ -455,7 +453,6 @@ func TestRepository_CreateUser(t *testing.T) {
 						createReq.Email,
 						createReq.Avatar,
 						createReq.IsActive,
-						createReq.IsEmail,
 					).
 					WillReturnError(pgErr)
 				mock.ExpectRollback()
@@ -484,7 +481,6 @@ func TestRepository_CreateUser(t *testing.T) {
 						createReq.Email,
 						createReq.Avatar,
 						createReq.IsActive,
-						createReq.IsEmail,
 					).
 					WillReturnError(errors.New("query error"))
 				mock.ExpectRollback()
@@ -504,7 +500,6 @@ func TestRepository_CreateUser(t *testing.T) {
 						createReq.Email,
 						createReq.Avatar,
 						createReq.IsActive,
-						createReq.IsEmail,
 					).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testID))
 				mock.ExpectCommit().WillReturnError(errors.New("commit error"))
@@ -556,7 +551,6 @@ func TestRepository_UpdateUser(t *testing.T) {
 		Email:    "updated@example.com",
 		Avatar:   "new-avatar.jpg",
 		IsActive: true,
-		IsEmail:  true,
 	}
 
 	tests := []struct {
@@ -578,7 +572,6 @@ func TestRepository_UpdateUser(t *testing.T) {
 						updateReq.Email,
 						updateReq.Avatar,
 						updateReq.IsActive,
-						updateReq.IsEmail,
 						userID,
 					).
 					WillReturnResult(sqlmock.NewResult(0, 1))
@@ -598,7 +591,6 @@ func TestRepository_UpdateUser(t *testing.T) {
 						updateReq.Email,
 						updateReq.Avatar,
 						updateReq.IsActive,
-						updateReq.IsEmail,
 						userID,
 					).
 					WillReturnResult(sqlmock.NewResult(0, 0))
@@ -627,7 +619,6 @@ func TestRepository_UpdateUser(t *testing.T) {
 						updateReq.Email,
 						updateReq.Avatar,
 						updateReq.IsActive,
-						updateReq.IsEmail,
 						userID,
 					).
 					WillReturnError(errors.New("update error"))
@@ -647,7 +638,6 @@ func TestRepository_UpdateUser(t *testing.T) {
 						updateReq.Email,
 						updateReq.Avatar,
 						updateReq.IsActive,
-						updateReq.IsEmail,
 						userID,
 					).
 					WillReturnResult(sqlmock.NewErrorResult(errors.New("rows affected error")))
@@ -667,7 +657,6 @@ func TestRepository_UpdateUser(t *testing.T) {
 						updateReq.Email,
 						updateReq.Avatar,
 						updateReq.IsActive,
-						updateReq.IsEmail,
 						userID,
 					).
 					WillReturnResult(sqlmock.NewResult(0, 1))
@@ -783,3 +772,157 @@ func TestRepository_DeleteUser(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRepository_UpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	r := &Repository{conn: sqlxDB}
+
+	userID := uuid.New()
+	hashed := "new-hashed-password"
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userUpdatePasswordQ)).
+					WithArgs(hashed, userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "UserNotFound",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userUpdatePasswordQ)).
+					WithArgs(hashed, userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "UpdateError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userUpdatePasswordQ)).
+					WithArgs(hashed, userID).
+					WillReturnError(errors.New("update error"))
+			},
+			expectedErr: errors.New("update error"),
+		},
+		{
+			name: "RowsAffectedError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userUpdatePasswordQ)).
+					WithArgs(hashed, userID).
+					WillReturnResult(sqlmock.NewErrorResult(errors.New("rows affected error")))
+			},
+			expectedErr: errors.New("rows affected error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.UpdatePassword(context.Background(), userID, hashed)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.expectedErr, repo.ErrNotFound) {
+					assert.ErrorIs(t, err, repo.ErrNotFound)
+				} else {
+					assert.EqualError(t, err, tt.expectedErr.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_VerifyEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	r := &Repository{conn: sqlxDB}
+
+	userID := uuid.New()
+	email := "test@example.com"
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userVerifyEmailQ)).
+					WithArgs(userID, email).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "UserNotFound",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userVerifyEmailQ)).
+					WithArgs(userID, email).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: repo.ErrNotFound,
+		},
+		{
+			name: "UpdateError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userVerifyEmailQ)).
+					WithArgs(userID, email).
+					WillReturnError(errors.New("update error"))
+			},
+			expectedErr: errors.New("update error"),
+		},
+		{
+			name: "RowsAffectedError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(userVerifyEmailQ)).
+					WithArgs(userID, email).
+					WillReturnResult(sqlmock.NewErrorResult(errors.New("rows affected error")))
+			},
+			expectedErr: errors.New("rows affected error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.VerifyEmail(context.Background(), userID, email)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				if errors.Is(tt.expectedErr, repo.ErrNotFound) {
+					assert.ErrorIs(t, err, repo.ErrNotFound)
+				} else {
+					assert.EqualError(t, err, tt.expectedErr.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
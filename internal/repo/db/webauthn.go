@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+func (r *Repository) ListWebAuthnCredentials(ctx context.Context, uid uuid.UUID) ([]md.WebAuthnCredential, error) {
+	const op = "webauthn.ListWebAuthnCredentials.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := make([]md.WebAuthnCredential, 0)
+
+	err := r.conn.SelectContext(ctx, &res, listWebAuthnCredentials, uid)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to list webauthn credentials",
+			zap.String("op", op),
+			zap.String("userID", uid.String()),
+			zap.Error(err),
+		)
+
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (r *Repository) GetWebAuthnCredential(ctx context.Context, id string) (*md.WebAuthnCredential, error) {
+	const op = "webauthn.GetWebAuthnCredential.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res := md.WebAuthnCredential{}
+
+	err := r.conn.GetContext(ctx, &res, getWebAuthnCredential, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to get webauthn credential",
+			zap.String("op", op),
+			zap.String("credentialID", id),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *Repository) CreateWebAuthnCredential(ctx context.Context, c *md.WebAuthnCredential) error {
+	const op = "webauthn.CreateWebAuthnCredential.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	res, err := r.conn.ExecContext(
+		ctx, createWebAuthnCredential,
+		c.ID, c.UserID, c.DeviceID, c.Name, c.PublicKey, c.Alg, c.SignCount, c.AAGUID, c.Transports,
+	)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error(
+			"failed to create webauthn credential",
+			zap.String("op", op),
+			zap.String("userID", c.UserID.String()),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if aff, err := res.RowsAffected(); err == nil && aff == 0 {
+		return repo.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// UpdateWebAuthnSignCount stores a new signature counter. It returns
+// repo.ErrNotFound unless the counter increased, or both the stored and the
+// new one are zero for authenticators without a counter.
+func (r *Repository) UpdateWebAuthnSignCount(ctx context.Context, c *md.WebAuthnCredential) error {
+	const op = "webauthn.UpdateWebAuthnSignCount.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, c.UserID, updateWebAuthnSignCount, c.ID, c.SignCount)
+}
+
+func (r *Repository) DeleteWebAuthnCredential(ctx context.Context, uid uuid.UUID, id string) error {
+	const op = "webauthn.DeleteWebAuthnCredential.repo"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	return r.execOne(ctx, span, op, uid, deleteWebAuthnCredential, id, uid)
+}
@@ -0,0 +1,53 @@
+package db
+
+const listWebAuthnCredentials = `
+SELECT
+	id,
+	user_id,
+	COALESCE(device_id, '') AS device_id,
+	name,
+	public_key,
+	alg,
+	sign_count,
+	aaguid,
+	transports,
+	last_used_at,
+	created_at
+FROM webauthn_credentials
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+const getWebAuthnCredential = `
+SELECT
+	id,
+	user_id,
+	COALESCE(device_id, '') AS device_id,
+	name,
+	public_key,
+	alg,
+	sign_count,
+	aaguid,
+	transports,
+	last_used_at,
+	created_at
+FROM webauthn_credentials
+WHERE id = $1
+`
+
+const createWebAuthnCredential = `
+INSERT INTO webauthn_credentials (id, user_id, device_id, name, public_key, alg, sign_count, aaguid, transports)
+VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9)
+ON CONFLICT (id) DO NOTHING
+`
+
+const updateWebAuthnSignCount = `
+UPDATE webauthn_credentials
+SET sign_count = $2, last_used_at = NOW()
+WHERE id = $1 AND (sign_count < $2 OR (sign_count = 0 AND $2 = 0))
+`
+
+const deleteWebAuthnCredential = `
+DELETE FROM webauthn_credentials
+WHERE id = $1 AND user_id = $2
+`
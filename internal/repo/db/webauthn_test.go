@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/JMURv/golang-clean-template/internal/repo"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var webAuthnColumns = []string{
+	"id", "user_id", "device_id", "name", "public_key", "alg", "sign_count", "aaguid", "transports", "last_used_at",
+	"created_at",
+}
+
+func TestRepository_ListWebAuthnCredentials(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+	now := time.Now()
+	expected := []md.WebAuthnCredential{
+		{
+			ID:         "cred-1",
+			UserID:     uid,
+			DeviceID:   "device-1",
+			Name:       "Laptop",
+			PublicKey:  []byte{1, 2, 3},
+			Alg:        -7,
+			SignCount:  4,
+			AAGUID:     uuid.Nil,
+			Transports: "internal",
+			LastUsedAt: now,
+			CreatedAt:  now,
+		},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(listWebAuthnCredentials)).
+		WithArgs(uid).
+		WillReturnRows(
+			sqlmock.NewRows(webAuthnColumns).AddRow(
+				"cred-1", uid, "device-1", "Laptop", []byte{1, 2, 3}, -7, 4, uuid.Nil, "internal", now, now,
+			),
+		)
+
+	res, err := r.ListWebAuthnCredentials(context.Background(), uid)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, res)
+
+	mock.ExpectQuery(regexp.QuoteMeta(listWebAuthnCredentials)).
+		WithArgs(uid).
+		WillReturnError(errors.New("database error"))
+
+	res, err = r.ListWebAuthnCredentials(context.Background(), uid)
+	assert.EqualError(t, err, "database error")
+	assert.Nil(t, res)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetWebAuthnCredential(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(getWebAuthnCredential)).
+		WithArgs("cred-1").
+		WillReturnRows(
+			sqlmock.NewRows(webAuthnColumns).AddRow(
+				"cred-1", uid, "", "", []byte{1}, -7, 0, uuid.Nil, "", now, now,
+			),
+		)
+
+	res, err := r.GetWebAuthnCredential(context.Background(), "cred-1")
+	assert.NoError(t, err)
+	assert.Equal(t, uid, res.UserID)
+	assert.Empty(t, res.DeviceID)
+
+	mock.ExpectQuery(regexp.QuoteMeta(getWebAuthnCredential)).
+		WithArgs("cred-1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = r.GetWebAuthnCredential(context.Background(), "cred-1")
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CreateWebAuthnCredential(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	c := &md.WebAuthnCredential{
+		ID:         "cred-1",
+		UserID:     uuid.New(),
+		Name:       "Laptop",
+		PublicKey:  []byte{1, 2, 3},
+		Alg:        -7,
+		AAGUID:     uuid.Nil,
+		Transports: "internal,hybrid",
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(createWebAuthnCredential)).
+					WithArgs(c.ID, c.UserID, c.DeviceID, c.Name, c.PublicKey, c.Alg, c.SignCount, c.AAGUID, c.Transports).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "AlreadyExists",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(createWebAuthnCredential)).
+					WithArgs(c.ID, c.UserID, c.DeviceID, c.Name, c.PublicKey, c.Alg, c.SignCount, c.AAGUID, c.Transports).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectedErr: repo.ErrAlreadyExists,
+		},
+		{
+			name: "DatabaseError",
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta(createWebAuthnCredential)).
+					WithArgs(c.ID, c.UserID, c.DeviceID, c.Name, c.PublicKey, c.Alg, c.SignCount, c.AAGUID, c.Transports).
+					WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+
+			err := r.CreateWebAuthnCredential(context.Background(), c)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_UpdateWebAuthnSignCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	c := &md.WebAuthnCredential{ID: "cred-1", UserID: uuid.New(), SignCount: 5}
+
+	mock.ExpectExec(regexp.QuoteMeta(updateWebAuthnSignCount)).
+		WithArgs(c.ID, c.SignCount).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.UpdateWebAuthnSignCount(context.Background(), c))
+
+	// Another login already stored this or a higher counter
+	mock.ExpectExec(regexp.QuoteMeta(updateWebAuthnSignCount)).
+		WithArgs(c.ID, c.SignCount).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, r.UpdateWebAuthnSignCount(context.Background(), c), repo.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_DeleteWebAuthnCredential(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &Repository{conn: sqlx.NewDb(db, "sqlmock")}
+	uid := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta(deleteWebAuthnCredential)).
+		WithArgs("cred-1", uid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.DeleteWebAuthnCredential(context.Background(), uid, "cred-1"))
+
+	mock.ExpectExec(regexp.QuoteMeta(deleteWebAuthnCredential)).
+		WithArgs("cred-1", uid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, r.DeleteWebAuthnCredential(context.Background(), uid, "cred-1"), repo.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,67 @@
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/goccy/go-json"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+// FileSender writes every message as a JSON file into dir, so dev setups and
+// integration tests can inspect outgoing mail without an SMTP server.
+type FileSender struct {
+	dir string
+}
+
+func NewFileSender(dir string) *FileSender {
+	return &FileSender{dir: dir}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	const op = "smtp.FileSender.Send"
+	span, _ := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to create mail dir", zap.String("op", op), zap.Error(err))
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to marshal message", zap.String("op", op), zap.Error(err))
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.json", time.Now().UnixNano(), msg.Template)
+	if err = os.WriteFile(filepath.Join(s.dir, name), bytes, 0o600); err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to write message", zap.String("op", op), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// LogSender only logs outgoing messages.
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, msg *Message) error {
+	zap.L().Info(
+		"email",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("template", string(msg.Template)),
+		zap.String("locale", msg.Locale),
+		zap.String("text", msg.Text),
+	)
+	return nil
+}
@@ -0,0 +1,139 @@
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Mailer renders the embedded templates and hands the result to a Sender.
+type Mailer struct {
+	sender       Sender
+	tmpl         registry
+	locale       string
+	serverConfig config.ServerConfig
+}
+
+// New returns a Mailer that delivers synchronously with the sender picked by EMAIL_DRIVER.
+func New(conf config.Config) *Mailer {
+	return NewMailer(conf, NewSender(conf))
+}
+
+func NewSender(conf config.Config) Sender {
+	switch conf.Email.Driver {
+	case DriverFile:
+		return NewFileSender(conf.Email.Dir)
+	case DriverLog:
+		return LogSender{}
+	default:
+		return NewEmailServer(conf)
+	}
+}
+
+func NewMailer(conf config.Config, sender Sender) *Mailer {
+	return &Mailer{
+		sender:       sender,
+		tmpl:         mustLoadTemplates(),
+		locale:       conf.Email.Locale,
+		serverConfig: conf.Server,
+	}
+}
+
+func (m *Mailer) SendVerificationEmail(
+	ctx context.Context,
+	code int,
+	uid uuid.UUID,
+	toEmail string,
+) error {
+	return m.send(
+		ctx, Verification, toEmail, CodeData{
+			Code:      code,
+			Link:      m.link("verify-email", uid, code),
+			ExpiresIn: config.VerifyCodeDuration,
+		},
+	)
+}
+
+func (m *Mailer) SendForgotPasswordEmail(
+	ctx context.Context,
+	code int,
+	uid uuid.UUID,
+	toEmail string,
+) error {
+	return m.send(
+		ctx, PasswordReset, toEmail, CodeData{
+			Code:      code,
+			Link:      m.link("password-reset", uid, code),
+			ExpiresIn: config.RecoveryCodeDuration,
+		},
+	)
+}
+
+func (m *Mailer) SendLoginCodeEmail(ctx context.Context, code int, toEmail string) error {
+	return m.send(
+		ctx, LoginCode, toEmail, CodeData{
+			Code:      code,
+			ExpiresIn: config.LoginCodeDuration,
+		},
+	)
+}
+
+func (m *Mailer) SendNewDeviceEmail(ctx context.Context, d *md.Device, toEmail string) error {
+	return m.send(
+		ctx, NewDevice, toEmail, NewDeviceData{
+			IP:      d.IP,
+			UA:      d.UA,
+			Browser: d.Browser,
+			OS:      d.OS,
+			Time:    time.Now(),
+		},
+	)
+}
+
+func (m *Mailer) SendAccountLockedEmail(ctx context.Context, ip string, until time.Time, toEmail string) error {
+	return m.send(ctx, AccountLocked, toEmail, AccountLockedData{IP: ip, Until: until})
+}
+
+func (m *Mailer) link(page string, uid uuid.UUID, code int) string {
+	return fmt.Sprintf(
+		"%s://%s/%s?uidb64=%s&token=%d",
+		m.serverConfig.Scheme,
+		m.serverConfig.Domain,
+		page,
+		uid,
+		code,
+	)
+}
+
+func (m *Mailer) send(ctx context.Context, name Template, toEmail string, data any) error {
+	op := "smtp.Send." + string(name)
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	locale := m.locale
+	if l, ok := ctx.Value(config.LocaleKey).(string); ok && l != "" {
+		locale = l
+	}
+
+	msg, err := m.tmpl.render(locale, name, data)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		zap.L().Error("failed to render email", zap.String("op", op), zap.Error(err))
+		return err
+	}
+
+	msg.To = toEmail
+	return m.sender.Send(ctx, msg)
+}
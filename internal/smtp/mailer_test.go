@@ -0,0 +1,144 @@
+package smtp
+
+import (
+	"context"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Render(t *testing.T) {
+	reg := mustLoadTemplates()
+
+	data := map[Template]any{
+		Verification:  CodeData{Code: 123456, Link: "http://localhost/verify", ExpiresIn: time.Hour * 24},
+		PasswordReset: CodeData{Code: 123456, Link: "http://localhost/reset", ExpiresIn: time.Minute * 15},
+		LoginCode:     CodeData{Code: 123456, ExpiresIn: time.Minute * 5},
+		NewDevice:     NewDeviceData{IP: "192.168.1.1", UA: "ua", Time: time.Now()},
+		AccountLocked: AccountLockedData{IP: "192.168.1.1", Until: time.Now().Add(time.Minute * 15)},
+	}
+
+	for _, locale := range locales {
+		for _, name := range templates {
+			t.Run(locale+"/"+string(name), func(t *testing.T) {
+				msg, err := reg.render(locale, name, data[name])
+				require.NoError(t, err)
+				assert.Equal(t, locale, msg.Locale)
+				assert.Equal(t, name, msg.Template)
+				assert.NotEmpty(t, msg.Subject)
+				assert.NotContains(t, msg.Subject, "\n")
+				assert.NotEmpty(t, msg.Text)
+				assert.Contains(t, msg.HTML, "<html")
+				if d, ok := data[name].(CodeData); ok {
+					assert.Contains(t, msg.Text, "123456")
+					assert.Contains(t, msg.HTML, "123456")
+					assert.Contains(t, msg.Text, d.Link)
+				}
+			})
+		}
+	}
+}
+
+func TestRegistry_RenderUnknownLocale(t *testing.T) {
+	msg, err := mustLoadTemplates().render("xx", LoginCode, CodeData{Code: 1, ExpiresIn: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, defaultLocale, msg.Locale)
+}
+
+func TestMailer_FileSender(t *testing.T) {
+	dir := t.TempDir()
+	conf := config.Config{}
+	conf.Email.Locale = "en"
+	conf.Server.Scheme = "https"
+	conf.Server.Domain = "example.com"
+
+	m := NewMailer(conf, NewFileSender(dir))
+	uid := uuid.New()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		send     func(ctx context.Context) error
+		template Template
+		locale   string
+		contains string
+	}{
+		{
+			name: "Verification",
+			ctx:  context.Background(),
+			send: func(ctx context.Context) error {
+				return m.SendVerificationEmail(ctx, 111111, uid, "user@example.com")
+			},
+			template: Verification,
+			locale:   "en",
+			contains: "https://example.com/verify-email?uidb64=" + uid.String() + "&token=111111",
+		},
+		{
+			name: "PasswordResetRu",
+			ctx:  context.WithValue(context.Background(), config.LocaleKey, "ru"),
+			send: func(ctx context.Context) error {
+				return m.SendForgotPasswordEmail(ctx, 222222, uid, "user@example.com")
+			},
+			template: PasswordReset,
+			locale:   "ru",
+			contains: "222222",
+		},
+		{
+			name: "LoginCode",
+			ctx:  context.Background(),
+			send: func(ctx context.Context) error {
+				return m.SendLoginCodeEmail(ctx, 333333, "user@example.com")
+			},
+			template: LoginCode,
+			locale:   "en",
+			contains: "333333",
+		},
+		{
+			name: "NewDevice",
+			ctx:  context.Background(),
+			send: func(ctx context.Context) error {
+				return m.SendNewDeviceEmail(ctx, &md.Device{IP: "10.0.0.1", UA: "curl"}, "user@example.com")
+			},
+			template: NewDevice,
+			locale:   "en",
+			contains: "10.0.0.1",
+		},
+		{
+			name: "AccountLockedRu",
+			ctx:  context.WithValue(context.Background(), config.LocaleKey, "ru"),
+			send: func(ctx context.Context) error {
+				return m.SendAccountLockedEmail(ctx, "10.0.0.2", time.Now().Add(time.Minute), "user@example.com")
+			},
+			template: AccountLocked,
+			locale:   "ru",
+			contains: "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.send(tt.ctx))
+
+			files, err := filepath.Glob(filepath.Join(dir, "*-"+string(tt.template)+".json"))
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			b, err := os.ReadFile(files[0])
+			require.NoError(t, err)
+
+			msg := &Message{}
+			require.NoError(t, json.Unmarshal(b, msg))
+			assert.Equal(t, "user@example.com", msg.To)
+			assert.Equal(t, tt.template, msg.Template)
+			assert.Equal(t, tt.locale, msg.Locale)
+			assert.Contains(t, msg.Text, tt.contains)
+		})
+	}
+}
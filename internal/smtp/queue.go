@@ -0,0 +1,244 @@
+package smtp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/JMURv/golang-clean-template/internal/config"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	metrics "github.com/JMURv/golang-clean-template/internal/observability/metrics/prometheus"
+	"github.com/opentracing/opentracing-go"
+	"go.uber.org/zap"
+)
+
+// queueLease is how long a claimed message stays hidden from other workers.
+// A delivery is given up after queueSendTimeout, well before the lease ends,
+// so another worker can't claim a message that is still being sent.
+const (
+	queueLease       = time.Minute
+	queueSendTimeout = queueLease / 2
+)
+
+type QueueRepo interface {
+	EnqueueEmail(ctx context.Context, e *md.Email) error
+	ClaimEmails(ctx context.Context, limit int, lease time.Duration) ([]md.Email, error)
+	MarkEmailSent(ctx context.Context, id int64) error
+	RetryEmail(ctx context.Context, id int64, next time.Time, lastErr string) error
+	MarkEmailDead(ctx context.Context, id int64, lastErr string) error
+	CountEmails(ctx context.Context) (map[string]int64, error)
+	PurgeSentEmails(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Queue is a Sender that stores messages in the outbox. They are delivered
+// later by Worker, so a slow mail server never blocks a request.
+type Queue struct {
+	repo QueueRepo
+}
+
+func NewQueue(repo QueueRepo) *Queue {
+	return &Queue{repo: repo}
+}
+
+func (q *Queue) Send(ctx context.Context, msg *Message) error {
+	return q.repo.EnqueueEmail(
+		ctx, &md.Email{
+			To:       msg.To,
+			Subject:  msg.Subject,
+			Template: string(msg.Template),
+			Locale:   msg.Locale,
+			Text:     msg.Text,
+			HTML:     msg.HTML,
+		},
+	)
+}
+
+// Worker drains the outbox. Failed messages are retried with exponential
+// backoff and moved to the dead state after MaxAttempts. Sent messages are
+// deleted once they are older than the retention.
+type Worker struct {
+	repo        QueueRepo
+	sender      Sender
+	interval    time.Duration
+	batch       int
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	retention   time.Duration
+
+	quit     chan struct{}
+	done     chan struct{}
+	quitOnce sync.Once
+}
+
+func NewWorker(conf config.Config, repo QueueRepo, sender Sender) *Worker {
+	return &Worker{
+		repo:        repo,
+		sender:      sender,
+		interval:    conf.Email.Queue.Interval,
+		batch:       conf.Email.Queue.Batch,
+		maxAttempts: conf.Email.Queue.MaxAttempts,
+		baseDelay:   conf.Email.Queue.BaseDelay,
+		maxDelay:    conf.Email.Queue.MaxDelay,
+		retention:   conf.Email.Queue.Retention,
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start polls the outbox until Close is called or ctx is done.
+func (w *Worker) Start(ctx context.Context) {
+	defer close(w.done)
+	zap.L().Info("Email queue worker has been started", zap.Duration("interval", w.interval))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back.
+		n := w.batch
+		for n == w.batch && !w.stopped(ctx) {
+			n = w.process(ctx)
+		}
+
+		w.observeDepth(ctx)
+		w.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops the worker after the batch in progress is delivered.
+func (w *Worker) Close(ctx context.Context) error {
+	w.quitOnce.Do(func() { close(w.quit) })
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.done:
+		return nil
+	}
+}
+
+func (w *Worker) stopped(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-w.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// process delivers one batch and returns the number of claimed messages.
+func (w *Worker) process(ctx context.Context) int {
+	const op = "smtp.Worker.process"
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
+
+	emails, err := w.repo.ClaimEmails(ctx, w.batch, queueLease)
+	if err != nil {
+		span.SetTag(config.ErrorSpanTag, true)
+		return 0
+	}
+
+	for i := range emails {
+		w.deliver(ctx, &emails[i])
+	}
+
+	return len(emails)
+}
+
+func (w *Worker) deliver(ctx context.Context, e *md.Email) {
+	const op = "smtp.Worker.deliver"
+
+	sendCtx, cancel := context.WithTimeout(ctx, queueSendTimeout)
+	defer cancel()
+
+	err := w.sender.Send(
+		sendCtx, &Message{
+			To:       e.To,
+			Subject:  e.Subject,
+			Template: Template(e.Template),
+			Locale:   e.Locale,
+			Text:     e.Text,
+			HTML:     e.HTML,
+		},
+	)
+	if err == nil {
+		metrics.EmailsSent.Inc()
+		if err = w.repo.MarkEmailSent(ctx, e.ID); err != nil {
+			zap.L().Error("failed to mark email as sent", zap.String("op", op), zap.Int64("id", e.ID), zap.Error(err))
+		}
+		return
+	}
+
+	if e.Attempts >= w.maxAttempts {
+		metrics.EmailFailures.WithLabelValues("dead").Inc()
+		zap.L().Warn(
+			"email moved to dead letter",
+			zap.String("op", op),
+			zap.Int64("id", e.ID),
+			zap.Int("attempts", e.Attempts),
+			zap.Error(err),
+		)
+
+		if err = w.repo.MarkEmailDead(ctx, e.ID, err.Error()); err != nil {
+			zap.L().Error("failed to mark email as dead", zap.String("op", op), zap.Int64("id", e.ID), zap.Error(err))
+		}
+		return
+	}
+
+	metrics.EmailFailures.WithLabelValues("retry").Inc()
+	next := time.Now().Add(backoff(e.Attempts, w.baseDelay, w.maxDelay))
+	if err = w.repo.RetryEmail(ctx, e.ID, next, err.Error()); err != nil {
+		zap.L().Error("failed to schedule email retry", zap.String("op", op), zap.Int64("id", e.ID), zap.Error(err))
+	}
+}
+
+func (w *Worker) observeDepth(ctx context.Context) {
+	counts, err := w.repo.CountEmails(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, status := range []string{md.EmailPending, md.EmailDead} {
+		metrics.EmailQueueDepth.WithLabelValues(status).Set(float64(counts[status]))
+	}
+}
+
+// purge deletes the sent messages older than the retention.
+func (w *Worker) purge(ctx context.Context) {
+	const op = "smtp.Worker.purge"
+
+	if w.retention <= 0 {
+		return
+	}
+
+	n, err := w.repo.PurgeSentEmails(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		return
+	}
+
+	if n > 0 {
+		zap.L().Debug("purged sent emails", zap.String("op", op), zap.Int64("count", n))
+	}
+}
+
+// backoff returns base * 2^(attempt-1), capped at maxDelay.
+func backoff(attempt int, base, maxDelay time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+
+	return min(d, maxDelay)
+}
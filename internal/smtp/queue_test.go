@@ -0,0 +1,171 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	md "github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fakeQueueRepo struct {
+	enqueued []md.Email
+	claimed  []md.Email
+	sent     []int64
+	retried  []int64
+	dead     []int64
+	purged   []time.Time
+}
+
+func (f *fakeQueueRepo) EnqueueEmail(_ context.Context, e *md.Email) error {
+	f.enqueued = append(f.enqueued, *e)
+	return nil
+}
+
+func (f *fakeQueueRepo) ClaimEmails(_ context.Context, limit int, _ time.Duration) ([]md.Email, error) {
+	n := min(limit, len(f.claimed))
+	res := f.claimed[:n]
+	f.claimed = f.claimed[n:]
+	return res, nil
+}
+
+func (f *fakeQueueRepo) MarkEmailSent(_ context.Context, id int64) error {
+	f.sent = append(f.sent, id)
+	return nil
+}
+
+func (f *fakeQueueRepo) RetryEmail(_ context.Context, id int64, _ time.Time, _ string) error {
+	f.retried = append(f.retried, id)
+	return nil
+}
+
+func (f *fakeQueueRepo) MarkEmailDead(_ context.Context, id int64, _ string) error {
+	f.dead = append(f.dead, id)
+	return nil
+}
+
+func (f *fakeQueueRepo) CountEmails(_ context.Context) (map[string]int64, error) {
+	return map[string]int64{}, nil
+}
+
+func (f *fakeQueueRepo) PurgeSentEmails(_ context.Context, before time.Time) (int64, error) {
+	f.purged = append(f.purged, before)
+	return 0, nil
+}
+
+type senderFunc func(ctx context.Context, msg *Message) error
+
+func (f senderFunc) Send(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+func TestQueue_Send(t *testing.T) {
+	repo := &fakeQueueRepo{}
+	err := NewQueue(repo).Send(
+		context.Background(), &Message{
+			To:       "test@example.com",
+			Subject:  "subject",
+			Template: Verification,
+			Locale:   "en",
+			Text:     "text",
+			HTML:     "html",
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t, []md.Email{
+			{
+				To:       "test@example.com",
+				Subject:  "subject",
+				Template: string(Verification),
+				Locale:   "en",
+				Text:     "text",
+				HTML:     "html",
+			},
+		}, repo.enqueued,
+	)
+}
+
+func TestWorker_Process(t *testing.T) {
+	repo := &fakeQueueRepo{
+		claimed: []md.Email{
+			{ID: 1, To: "ok@example.com", Attempts: 1},
+			{ID: 2, To: "fail@example.com", Attempts: 1},
+			{ID: 3, To: "fail@example.com", Attempts: 3},
+		},
+	}
+	sender := senderFunc(
+		func(ctx context.Context, msg *Message) error {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.Less(t, time.Until(deadline), queueLease)
+
+			if msg.To == "fail@example.com" {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	)
+
+	w := &Worker{
+		repo:        repo,
+		sender:      sender,
+		batch:       10,
+		maxAttempts: 3,
+		baseDelay:   time.Second,
+		maxDelay:    time.Minute,
+	}
+
+	assert.Equal(t, 3, w.process(context.Background()))
+	assert.Equal(t, []int64{1}, repo.sent)
+	assert.Equal(t, []int64{2}, repo.retried)
+	assert.Equal(t, []int64{3}, repo.dead)
+	assert.Equal(t, 0, w.process(context.Background()))
+}
+
+func TestWorker_Close(t *testing.T) {
+	w := &Worker{
+		repo:     &fakeQueueRepo{},
+		interval: time.Hour,
+		batch:    10,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go w.Start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, w.Close(ctx))
+	assert.NoError(t, w.Close(ctx))
+}
+
+func TestWorker_Purge(t *testing.T) {
+	repo := &fakeQueueRepo{}
+
+	(&Worker{repo: repo}).purge(context.Background())
+	assert.Empty(t, repo.purged)
+
+	(&Worker{repo: repo, retention: time.Hour}).purge(context.Background())
+	if assert.Len(t, repo.purged, 1) {
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), repo.purged[0], time.Second)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: 30 * time.Second},
+		{attempt: 2, expected: time.Minute},
+		{attempt: 3, expected: 2 * time.Minute},
+		{attempt: 10, expected: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, backoff(tt.attempt, 30*time.Second, 10*time.Minute))
+	}
+}
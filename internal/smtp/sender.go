@@ -0,0 +1,17 @@
+package smtp
+
+import "context"
+
+// Sender delivers rendered messages. The implementation is picked by EMAIL_DRIVER.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type Message struct {
+	To       string   `json:"to"`
+	Subject  string   `json:"subject"`
+	Template Template `json:"template"`
+	Locale   string   `json:"locale"`
+	Text     string   `json:"text"`
+	HTML     string   `json:"html"`
+}
@@ -10,22 +10,20 @@ import (
 )
 
 type EmailServer struct {
-	server       string
-	port         int
-	user         string
-	pass         string
-	admin        string
-	serverConfig config.ServerConfig
+	server string
+	port   int
+	user   string
+	pass   string
+	admin  string
 }
 
-func New(conf config.Config) *EmailServer {
+func NewEmailServer(conf config.Config) *EmailServer {
 	return &EmailServer{
-		server:       conf.Email.Server,
-		port:         conf.Email.Port,
-		user:         conf.Email.User,
-		pass:         conf.Email.Pass,
-		admin:        conf.Email.Admin,
-		serverConfig: conf.Server,
+		server: conf.Email.Server,
+		port:   conf.Email.Port,
+		user:   conf.Email.User,
+		pass:   conf.Email.Pass,
+		admin:  conf.Email.Admin,
 	}
 }
 
@@ -37,14 +35,36 @@ func (s *EmailServer) GetMessageBase(subject, toEmail string) *gomail.Message {
 	return m
 }
 
-func (s *EmailServer) Send(ctx context.Context, m *gomail.Message) error {
-	span, _ := opentracing.StartSpanFromContext(ctx, "smtp.GetMessageBase")
+func (s *EmailServer) Send(ctx context.Context, msg *Message) error {
+	const op = "smtp.EmailServer.Send"
+	span, _ := opentracing.StartSpanFromContext(ctx, op)
+	defer span.Finish()
 
-	d := gomail.NewDialer(s.server, s.port, s.user, s.pass)
-	if err := d.DialAndSend(m); err != nil {
+	m := s.GetMessageBase(msg.Subject, msg.To)
+	m.SetBody("text/plain", msg.Text)
+	if msg.HTML != "" {
+		m.AddAlternative("text/html", msg.HTML)
+	}
+
+	// gomail takes no context, so a send that outlives ctx is abandoned.
+	done := make(chan error, 1)
+	go func() {
+		done <- gomail.NewDialer(s.server, s.port, s.user, s.pass).DialAndSend(m)
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-done:
+	}
+
+	if err != nil {
 		span.SetTag(config.ErrorSpanTag, true)
 		zap.L().Error(
 			"Failed to send an email",
+			zap.String("op", op),
+			zap.String("template", string(msg.Template)),
 			zap.Error(err),
 		)
 		return err
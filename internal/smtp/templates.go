@@ -0,0 +1,124 @@
+package smtp
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"path"
+	texttemplate "text/template"
+	"time"
+)
+
+type Template string
+
+const (
+	Verification  Template = "verification"
+	PasswordReset Template = "password_reset"
+	NewDevice     Template = "new_device"
+	LoginCode     Template = "login_code"
+	AccountLocked Template = "account_locked"
+)
+
+const defaultLocale = "en"
+
+var (
+	//go:embed templates
+	templatesFS embed.FS
+
+	locales   = []string{"en", "ru"}
+	templates = []Template{Verification, PasswordReset, NewDevice, LoginCode, AccountLocked}
+)
+
+// CodeData is passed to the verification, password reset and login code templates.
+type CodeData struct {
+	Code      int
+	Link      string
+	ExpiresIn time.Duration
+}
+
+// NewDeviceData is passed to the new device alert template.
+type NewDeviceData struct {
+	IP      string
+	UA      string
+	Browser string
+	OS      string
+	Time    time.Time
+}
+
+// AccountLockedData is passed to the account lockout alert template.
+type AccountLockedData struct {
+	IP    string
+	Until time.Time
+}
+
+var funcs = map[string]any{
+	"minutes": func(d time.Duration) int { return int(d.Minutes()) },
+	"hours":   func(d time.Duration) int { return int(d.Hours()) },
+	"date":    func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 MST") },
+}
+
+// tmplSet holds one template in one locale. The text file defines the
+// "subject" and "body" blocks, the html file is the alternative body.
+type tmplSet struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+type registry map[string]map[Template]*tmplSet
+
+func mustLoadTemplates() registry {
+	res := make(registry, len(locales))
+	for _, locale := range locales {
+		res[locale] = make(map[Template]*tmplSet, len(templates))
+		for _, name := range templates {
+			base := path.Join("templates", locale, string(name))
+
+			text, err := texttemplate.New(string(name)).Funcs(funcs).ParseFS(templatesFS, base+".txt")
+			if err != nil {
+				panic(fmt.Sprintf("failed to parse %s.txt: %v", base, err))
+			}
+
+			html, err := htmltemplate.New(string(name)).Funcs(funcs).ParseFS(templatesFS, base+".html")
+			if err != nil {
+				panic(fmt.Sprintf("failed to parse %s.html: %v", base, err))
+			}
+
+			res[locale][name] = &tmplSet{text: text, html: html}
+		}
+	}
+
+	return res
+}
+
+func (r registry) render(locale string, name Template, data any) (*Message, error) {
+	if _, ok := r[locale]; !ok {
+		locale = defaultLocale
+	}
+
+	set, ok := r[locale][name]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %q", name)
+	}
+
+	subject, text, html := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	if err := set.text.ExecuteTemplate(subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	if err := set.text.ExecuteTemplate(text, "body", data); err != nil {
+		return nil, err
+	}
+
+	if err := set.html.ExecuteTemplate(html, string(name)+".html", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject:  subject.String(),
+		Template: name,
+		Locale:   locale,
+		Text:     text.String(),
+		HTML:     html.String(),
+	}, nil
+}
@@ -1,7 +1,13 @@
 package http
 
 import (
+	"bytes"
+	"github.com/JMURv/golang-clean-template/internal/auth/totp"
+	"github.com/JMURv/golang-clean-template/internal/auth/webauthn/webauthntest"
 	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/JMURv/golang-clean-template/internal/models"
+	"github.com/goccy/go-json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
@@ -53,3 +59,424 @@ func TestAuthRoutes(t *testing.T) {
 	req.AddCookie(newAccess)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+func TestAuthTokenMode(t *testing.T) {
+	ts, cleanup := setupTestServer()
+	t.Cleanup(func() {
+		cleanup(t)
+	})
+
+	_, userData := createTestUser(t, ts)
+	data, err := json.Marshal(map[string]any{
+		"email":    userData["email"],
+		"password": userData["password"],
+		"token":    "test-token",
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", ts.URL+"/auth/jwt", bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Cookies())
+
+	pair := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(pair))
+	require.NotEmpty(t, pair.Access)
+	require.NotEmpty(t, pair.Refresh)
+
+	// Use the access token as a bearer token
+	req, err = http.NewRequest("GET", ts.URL+"/users/me", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+pair.Access)
+
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Refresh with the token in the body
+	time.Sleep(time.Second * 1)
+	data, err = json.Marshal(&dto.RefreshRequest{Refresh: pair.Refresh})
+	require.NoError(t, err)
+
+	req, err = http.NewRequest("POST", ts.URL+"/auth/jwt/refresh", bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	newPair := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(newPair))
+	assert.NotEmpty(t, newPair.Access)
+}
+
+func TestAuthRefreshReuse(t *testing.T) {
+	ts, cleanup := setupTestServer()
+	t.Cleanup(func() {
+		cleanup(t)
+	})
+
+	_, userData := createTestUser(t, ts)
+	data, err := json.Marshal(map[string]any{
+		"email":    userData["email"],
+		"password": userData["password"],
+		"token":    "test-token",
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("POST", ts.URL+"/auth/jwt", bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	first := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(first))
+
+	refresh := func(token string) *http.Response {
+		data, err := json.Marshal(&dto.RefreshRequest{Refresh: token})
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", ts.URL+"/auth/jwt/refresh", bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Rotate once
+	resp = refresh(first.Refresh)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	second := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(second))
+
+	// Replaying the rotated token revokes the family
+	resp = refresh(first.Refresh)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = refresh(second.Refresh)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAuthLogoutDevice(t *testing.T) {
+	ts, cleanup := setupTestServer()
+	t.Cleanup(func() {
+		cleanup(t)
+	})
+
+	_, userData := createTestUser(t, ts)
+	do := func(uri, ua string, body any, access string) *http.Response {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", ts.URL+uri, bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set("User-Agent", ua)
+		req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+		if access != "" {
+			req.Header.Set("Authorization", "Bearer "+access)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	login := func(ua string) *dto.TokenPair {
+		resp := do("/auth/jwt", ua, map[string]any{
+			"email":    userData["email"],
+			"password": userData["password"],
+			"token":    "test-token",
+		}, "")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		pair := &dto.TokenPair{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(pair))
+		return pair
+	}
+
+	phone := login("phone-agent")
+	laptop := login("laptop-agent")
+
+	resp := do("/auth/logout", "phone-agent", nil, phone.Access)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Only the phone session is gone
+	resp = do("/auth/jwt/refresh", "phone-agent", &dto.RefreshRequest{Refresh: phone.Refresh}, "")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = do("/auth/jwt/refresh", "laptop-agent", &dto.RefreshRequest{Refresh: laptop.Refresh}, "")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The phone access token is rejected right away
+	me := func(access string) int {
+		req, err := http.NewRequest("GET", ts.URL+"/users/me", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+access)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusUnauthorized, me(phone.Access))
+	assert.Equal(t, http.StatusOK, me(laptop.Access))
+}
+
+func TestAuthStableDeviceID(t *testing.T) {
+	ts, cleanup := setupTestServer()
+	t.Cleanup(func() {
+		cleanup(t)
+	})
+
+	_, userData := createTestUser(t, ts)
+	do := func(uri, ua, deviceID string, body any) *http.Response {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest("POST", ts.URL+uri, bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set("User-Agent", ua)
+		req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+		if deviceID != "" {
+			req.Header.Set(config.DeviceIDHeader, deviceID)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do("/auth/jwt", "old-agent", "phone-1", map[string]any{
+		"email":    userData["email"],
+		"password": userData["password"],
+		"token":    "test-token",
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	pair := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(pair))
+	assert.Equal(t, "phone-1", pair.DeviceID)
+
+	// The device survives a User-Agent change
+	resp = do("/auth/jwt/refresh", "new-agent", "phone-1", &dto.RefreshRequest{Refresh: pair.Refresh})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	next := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(next))
+
+	// Without the ID the request belongs to another device
+	resp = do("/auth/jwt/refresh", "new-agent", "", &dto.RefreshRequest{Refresh: next.Refresh})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAuthTOTP(t *testing.T) {
+	ts, cleanup := setupTestServer()
+	t.Cleanup(func() {
+		cleanup(t)
+	})
+
+	_, userData := createTestUser(t, ts)
+	credentials := map[string]any{
+		"email":    userData["email"],
+		"password": userData["password"],
+		"token":    "test-token",
+	}
+	do := func(method, uri, access string, body any) *http.Response {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(method, ts.URL+uri, bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+		req.Header.Set(config.DeviceIDHeader, "laptop-1")
+		if access != "" {
+			req.Header.Set("Authorization", "Bearer "+access)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do("POST", "/auth/jwt", "", credentials)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	pair := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(pair))
+
+	// Enroll and confirm with the first code
+	resp = do("POST", "/auth/2fa/totp/enroll", pair.Access, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	enroll := &dto.TOTPEnrollResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(enroll))
+	assert.Contains(t, enroll.URI, enroll.Secret)
+
+	code, err := totp.Code(enroll.Secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	resp = do("POST", "/auth/2fa/totp/confirm", pair.Access, &dto.TOTPCodeRequest{Code: code})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	recovery := &dto.RecoveryCodesResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(recovery))
+	require.Len(t, recovery.Codes, config.RecoveryCodesCount)
+
+	// Password login now stops at a challenge
+	resp = do("POST", "/auth/jwt", "", credentials)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	challenge := &dto.TwoFactorChallenge{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(challenge))
+	require.NotEmpty(t, challenge.Challenge)
+
+	// The code used for enrollment can't be replayed
+	resp = do("POST", "/auth/2fa/verify", "", map[string]any{"challenge": challenge.Challenge, "code": code})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = do("POST", "/auth/2fa/verify", "", map[string]any{
+		"challenge":    challenge.Challenge,
+		"recoveryCode": recovery.Codes[0],
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	next := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(next))
+	assert.NotEmpty(t, next.Access)
+
+	// Recovery codes are single use
+	resp = do("DELETE", "/auth/2fa/totp", next.Access, map[string]any{"recoveryCode": recovery.Codes[0]})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = do("DELETE", "/auth/2fa/totp", next.Access, map[string]any{"recoveryCode": recovery.Codes[1]})
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = do("POST", "/auth/jwt", "", credentials)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestAuthWebAuthn(t *testing.T) {
+	ts, cleanup := setupTestServer()
+	t.Cleanup(func() {
+		cleanup(t)
+	})
+
+	_, userData := createTestUser(t, ts)
+	do := func(method, uri, access string, body any) *http.Response {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(method, ts.URL+uri, bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+		req.Header.Set(config.DeviceIDHeader, "laptop-1")
+		if access != "" {
+			req.Header.Set("Authorization", "Bearer "+access)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do("POST", "/auth/jwt", "", map[string]any{
+		"email":    userData["email"],
+		"password": userData["password"],
+		"token":    "test-token",
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	pair := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(pair))
+
+	// Register a passkey on the signed in device
+	resp = do("POST", "/auth/webauthn/register/begin", pair.Access, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	creation := &dto.WebAuthnCreationResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(creation))
+
+	a, err := webauthntest.New(conf.Auth.WebAuthn.RPID, conf.Auth.WebAuthn.Origins[0])
+	require.NoError(t, err)
+
+	attestation, err := a.Register(creation.PublicKey)
+	require.NoError(t, err)
+
+	resp = do("POST", "/auth/webauthn/register/finish", pair.Access, &dto.WebAuthnRegistrationRequest{
+		Name:       "laptop",
+		Credential: *attestation,
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	cred := &models.WebAuthnCredential{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(cred))
+	assert.Equal(t, "laptop-1", cred.DeviceID)
+
+	// Log in with a discoverable passkey
+	login := func() *http.Response {
+		resp := do("POST", "/auth/webauthn/login/begin", "", &dto.WebAuthnLoginRequest{})
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		request := &dto.WebAuthnRequestResponse{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(request))
+
+		assertion, err := a.Login(request.PublicKey)
+		require.NoError(t, err)
+
+		return do("POST", "/auth/webauthn/login/finish", "", &dto.FinishWebAuthnLoginRequest{Credential: *assertion})
+	}
+
+	resp = login()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	next := &dto.TokenPair{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(next))
+	assert.Equal(t, "laptop-1", next.DeviceID)
+
+	// A deleted passkey no longer signs in
+	resp = do("DELETE", "/auth/webauthn/credentials/"+cred.ID, next.Access, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = login()
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
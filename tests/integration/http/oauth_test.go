@@ -0,0 +1,148 @@
+package http
+
+import (
+	"bytes"
+	"github.com/JMURv/golang-clean-template/internal/auth/oauth"
+	"github.com/JMURv/golang-clean-template/internal/config"
+	"github.com/JMURv/golang-clean-template/internal/dto"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestOAuthAuthorizationCode(t *testing.T) {
+	ts, cleanup := setupTestServer()
+	t.Cleanup(func() {
+		cleanup(t)
+	})
+
+	cli := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	do := func(method, uri string, cookie *http.Cookie, body any) *http.Response {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(method, ts.URL+uri, bytes.NewReader(data))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		resp, err := cli.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	token := func(form url.Values) *http.Response {
+		resp, err := cli.PostForm(ts.URL+"/oauth/token", form)
+		require.NoError(t, err)
+		return resp
+	}
+
+	admin, _ := loginUser(t, ts, map[string]any{
+		"email":    conf.Auth.Admin.Email,
+		"password": conf.Auth.Admin.Password,
+	})
+
+	resp := do("POST", "/oauth/clients", admin, &dto.CreateOAuthClientRequest{
+		Name:         "spa",
+		Public:       true,
+		RedirectURIs: []string{"https://app.example.com/cb"},
+		Scopes:       []string{oauth.ScopeProfile},
+		GrantTypes:   []string{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken},
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	client := &dto.CreateOAuthClientResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(client))
+	assert.Empty(t, client.Secret)
+
+	_, userData := createTestUser(t, ts)
+	access, _ := loginUser(t, ts, userData)
+
+	const verifier = "dBjftJeZ4CVP-mJ92K9f4ydP2UWv0sZRwn3X2dcoqi0"
+	authorize := &dto.AuthorizeRequest{
+		ResponseType:        oauth.ResponseTypeCode,
+		ClientID:            client.Client.ID.String(),
+		Scope:               oauth.ScopeProfile,
+		State:               "xyz",
+		CodeChallenge:       oauth.S256Challenge(verifier),
+		CodeChallengeMethod: oauth.MethodS256,
+	}
+	query := url.Values{
+		"response_type":         {authorize.ResponseType},
+		"client_id":             {authorize.ClientID},
+		"scope":                 {authorize.Scope},
+		"state":                 {authorize.State},
+		"code_challenge":        {authorize.CodeChallenge},
+		"code_challenge_method": {authorize.CodeChallengeMethod},
+	}
+
+	// The first request asks for consent
+	resp = do("GET", "/oauth/authorize?"+query.Encode(), access, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do("POST", "/oauth/authorize", access, &dto.ConsentRequest{AuthorizeRequest: *authorize, Approve: true})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	res := &dto.AuthorizeResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(res))
+
+	redirect, err := url.Parse(res.RedirectTo)
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", redirect.Query().Get("state"))
+
+	resp = token(url.Values{
+		"grant_type":    {oauth.GrantAuthorizationCode},
+		"code":          {redirect.Query().Get("code")},
+		"code_verifier": {verifier},
+		"client_id":     {authorize.ClientID},
+	})
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	tokens := &dto.OAuthTokenResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(tokens))
+	require.NotEmpty(t, tokens.RefreshToken)
+
+	// The client token reads the profile
+	req, err := http.NewRequest("GET", ts.URL+"/users/me", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	req.Header.Set(config.AuthModeHeader, config.AuthModeToken)
+
+	resp, err = cli.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Consent is remembered
+	resp = do("GET", "/oauth/authorize?"+query.Encode(), access, nil)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Location"), "https://app.example.com/cb?"))
+
+	// Refresh tokens rotate and can't be used twice
+	refresh := url.Values{
+		"grant_type":    {oauth.GrantRefreshToken},
+		"refresh_token": {tokens.RefreshToken},
+		"client_id":     {authorize.ClientID},
+	}
+	resp = token(refresh)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = token(refresh)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
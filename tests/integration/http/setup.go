@@ -16,6 +16,7 @@ import (
 	"github.com/JMURv/golang-clean-template/internal/config"
 	"github.com/JMURv/golang-clean-template/internal/ctrl"
 	hdl "github.com/JMURv/golang-clean-template/internal/hdl/http"
+	"github.com/JMURv/golang-clean-template/internal/ratelimit"
 	"github.com/JMURv/golang-clean-template/internal/repo/db"
 	"github.com/JMURv/golang-clean-template/internal/repo/s3"
 	"github.com/JMURv/golang-clean-template/internal/smtp"
@@ -174,11 +175,11 @@ func getMinio() testcontainers.Container {
 func setupTestServer() (*httptest.Server, func(t *testing.T)) {
 	zap.ReplaceGlobals(zap.Must(zap.NewDevelopment()))
 
-	au := auth.New(conf)
 	cache := redis.New(conf)
 	repo := db.New(conf)
-	svc := ctrl.New(au, repo, cache, s3.New(conf), smtp.New(conf))
-	h := hdl.New(au, svc)
+	au := auth.New(conf, cache, repo)
+	svc := ctrl.New(conf, au, repo, cache, s3.New(conf), smtp.New(conf))
+	h := hdl.New(conf, au, svc, ratelimit.New(conf, cache))
 
 	ts := httptest.NewServer(h.Router)
 
@@ -103,7 +103,29 @@ func TestUserRoutes(t *testing.T) {
 		createTestUser(t, ts)
 		createTestUser(t, ts)
 
-		resp, err := http.Get(ts.URL + "/users?page=1&size=10")
+		_, userData := createTestUser(t, ts)
+		access, _ := loginUser(t, ts, userData)
+
+		req, err := http.NewRequest("GET", ts.URL+"/users?page=1&size=10", nil)
+		require.NoError(t, err)
+		req.AddCookie(access)
+
+		cli := &http.Client{}
+		resp, err := cli.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		admin, _ := loginUser(t, ts, map[string]any{
+			"email":    conf.Auth.Admin.Email,
+			"password": conf.Auth.Admin.Password,
+		})
+
+		req, err = http.NewRequest("GET", ts.URL+"/users?page=1&size=10", nil)
+		require.NoError(t, err)
+		req.AddCookie(admin)
+
+		resp, err = cli.Do(req)
 		require.NoError(t, err)
 		defer resp.Body.Close()
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -145,6 +167,23 @@ func TestUserRoutes(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 
+	t.Run("Test deleteUser of another user", func(t *testing.T) {
+		victimID, _ := createTestUser(t, ts)
+		_, userData := createTestUser(t, ts)
+		access, _ := loginUser(t, ts, userData)
+
+		req, err := http.NewRequest("DELETE", ts.URL+"/users/"+victimID.String(), nil)
+		require.NoError(t, err)
+		req.AddCookie(access)
+
+		cli := &http.Client{}
+		resp, err := cli.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
 	t.Run("Test deleteUser endpoint", func(t *testing.T) {
 		userID, userData := createTestUser(t, ts)
 		access, _ := loginUser(t, ts, userData)
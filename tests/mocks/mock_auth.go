@@ -44,6 +44,20 @@ func (m *MockCore) EXPECT() *MockCoreMockRecorder {
 	return m.recorder
 }
 
+// CheckRevoked mocks base method.
+func (m *MockCore) CheckRevoked(ctx context.Context, claims jwt.Claims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRevoked", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRevoked indicates an expected call of CheckRevoked.
+func (mr *MockCoreMockRecorder) CheckRevoked(ctx, claims any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRevoked", reflect.TypeOf((*MockCore)(nil).CheckRevoked), ctx, claims)
+}
+
 // ComparePasswords mocks base method.
 func (m *MockCore) ComparePasswords(hashed, pswd []byte) error {
 	m.ctrl.T.Helper()
@@ -59,9 +73,9 @@ func (mr *MockCoreMockRecorder) ComparePasswords(hashed, pswd any) *gomock.Call
 }
 
 // GenPair mocks base method.
-func (m *MockCore) GenPair(ctx context.Context, uid uuid.UUID) (string, string, error) {
+func (m *MockCore) GenPair(ctx context.Context, uid uuid.UUID, deviceID string, acc jwt.Access) (string, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenPair", ctx, uid)
+	ret := m.ctrl.Call(m, "GenPair", ctx, uid, deviceID, acc)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
@@ -69,9 +83,9 @@ func (m *MockCore) GenPair(ctx context.Context, uid uuid.UUID) (string, string,
 }
 
 // GenPair indicates an expected call of GenPair.
-func (mr *MockCoreMockRecorder) GenPair(ctx, uid any) *gomock.Call {
+func (mr *MockCoreMockRecorder) GenPair(ctx, uid, deviceID, acc any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenPair", reflect.TypeOf((*MockCore)(nil).GenPair), ctx, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenPair", reflect.TypeOf((*MockCore)(nil).GenPair), ctx, uid, deviceID, acc)
 }
 
 // GetAccessTime mocks base method.
@@ -117,19 +131,63 @@ func (mr *MockCoreMockRecorder) Hash(ctx, pswd any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockCore)(nil).Hash), ctx, pswd)
 }
 
+// JWKS mocks base method.
+func (m *MockCore) JWKS() jwt.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(jwt.JWKSet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockCoreMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockCore)(nil).JWKS))
+}
+
+// NewClientToken mocks base method.
+func (m *MockCore) NewClientToken(ctx context.Context, uid uuid.UUID, clientID, scope string, d time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewClientToken", ctx, uid, clientID, scope, d)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewClientToken indicates an expected call of NewClientToken.
+func (mr *MockCoreMockRecorder) NewClientToken(ctx, uid, clientID, scope, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewClientToken", reflect.TypeOf((*MockCore)(nil).NewClientToken), ctx, uid, clientID, scope, d)
+}
+
+// NewIDToken mocks base method.
+func (m *MockCore) NewIDToken(ctx context.Context, uid uuid.UUID, clientID, nonce string, id jwt.Identity, d time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewIDToken", ctx, uid, clientID, nonce, id, d)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewIDToken indicates an expected call of NewIDToken.
+func (mr *MockCoreMockRecorder) NewIDToken(ctx, uid, clientID, nonce, id, d any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewIDToken", reflect.TypeOf((*MockCore)(nil).NewIDToken), ctx, uid, clientID, nonce, id, d)
+}
+
 // NewToken mocks base method.
-func (m *MockCore) NewToken(ctx context.Context, uid uuid.UUID, d time.Duration) (string, error) {
+func (m *MockCore) NewToken(ctx context.Context, uid uuid.UUID, acc jwt.Access, d time.Duration) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewToken", ctx, uid, d)
+	ret := m.ctrl.Call(m, "NewToken", ctx, uid, acc, d)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NewToken indicates an expected call of NewToken.
-func (mr *MockCoreMockRecorder) NewToken(ctx, uid, d any) *gomock.Call {
+func (mr *MockCoreMockRecorder) NewToken(ctx, uid, acc, d any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockCore)(nil).NewToken), ctx, uid, d)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockCore)(nil).NewToken), ctx, uid, acc, d)
 }
 
 // ParseClaims mocks base method.
@@ -147,6 +205,72 @@ func (mr *MockCoreMockRecorder) ParseClaims(ctx, tokenStr any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseClaims", reflect.TypeOf((*MockCore)(nil).ParseClaims), ctx, tokenStr)
 }
 
+// ParseIDToken mocks base method.
+func (m *MockCore) ParseIDToken(ctx context.Context, tokenStr string) (jwt.IDClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseIDToken", ctx, tokenStr)
+	ret0, _ := ret[0].(jwt.IDClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseIDToken indicates an expected call of ParseIDToken.
+func (mr *MockCoreMockRecorder) ParseIDToken(ctx, tokenStr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseIDToken", reflect.TypeOf((*MockCore)(nil).ParseIDToken), ctx, tokenStr)
+}
+
+// RevokeDeviceTokens mocks base method.
+func (m *MockCore) RevokeDeviceTokens(ctx context.Context, uid uuid.UUID, deviceID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeDeviceTokens", ctx, uid, deviceID)
+}
+
+// RevokeDeviceTokens indicates an expected call of RevokeDeviceTokens.
+func (mr *MockCoreMockRecorder) RevokeDeviceTokens(ctx, uid, deviceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeDeviceTokens", reflect.TypeOf((*MockCore)(nil).RevokeDeviceTokens), ctx, uid, deviceID)
+}
+
+// RevokeToken mocks base method.
+func (m *MockCore) RevokeToken(ctx context.Context, claims jwt.Claims) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeToken", ctx, claims)
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockCoreMockRecorder) RevokeToken(ctx, claims any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockCore)(nil).RevokeToken), ctx, claims)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockCore) RevokeUserTokens(ctx context.Context, uid uuid.UUID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeUserTokens", ctx, uid)
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockCoreMockRecorder) RevokeUserTokens(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockCore)(nil).RevokeUserTokens), ctx, uid)
+}
+
+// Rotate mocks base method.
+func (m *MockCore) Rotate(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockCoreMockRecorder) Rotate(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockCore)(nil).Rotate), ctx)
+}
+
 // VerifyRecaptcha mocks base method.
 func (m *MockCore) VerifyRecaptcha(ctx context.Context, token string, action captcha.Actions) (bool, error) {
 	m.ctrl.T.Helper()
@@ -14,6 +14,8 @@ import (
 	reflect "reflect"
 	time "time"
 
+	jwt "github.com/JMURv/golang-clean-template/internal/auth/jwt"
+	webauthn "github.com/JMURv/golang-clean-template/internal/auth/webauthn"
 	dto "github.com/JMURv/golang-clean-template/internal/dto"
 	models "github.com/JMURv/golang-clean-template/internal/models"
 	s3 "github.com/JMURv/golang-clean-template/internal/repo/s3"
@@ -45,18 +47,130 @@ func (m *MockAppRepo) EXPECT() *MockAppRepoMockRecorder {
 	return m.recorder
 }
 
+// AssignRole mocks base method.
+func (m *MockAppRepo) AssignRole(ctx context.Context, uid uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, uid, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignRole indicates an expected call of AssignRole.
+func (mr *MockAppRepoMockRecorder) AssignRole(ctx, uid, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAppRepo)(nil).AssignRole), ctx, uid, role)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockAppRepo) ConfirmTOTP(ctx context.Context, uid uuid.UUID, step int64, hashedCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, uid, step, hashedCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockAppRepoMockRecorder) ConfirmTOTP(ctx, uid, step, hashedCodes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockAppRepo)(nil).ConfirmTOTP), ctx, uid, step, hashedCodes)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAppRepo) CreateAPIKey(ctx context.Context, k *models.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, k)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAppRepoMockRecorder) CreateAPIKey(ctx, k any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAppRepo)(nil).CreateAPIKey), ctx, k)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockAppRepo) CreateOAuthClient(ctx context.Context, c *models.OAuthClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockAppRepoMockRecorder) CreateOAuthClient(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockAppRepo)(nil).CreateOAuthClient), ctx, c)
+}
+
+// CreateOAuthToken mocks base method.
+func (m *MockAppRepo) CreateOAuthToken(ctx context.Context, t *models.OAuthToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthToken", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOAuthToken indicates an expected call of CreateOAuthToken.
+func (mr *MockAppRepoMockRecorder) CreateOAuthToken(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthToken", reflect.TypeOf((*MockAppRepo)(nil).CreateOAuthToken), ctx, t)
+}
+
+// CreateSecurityEvent mocks base method.
+func (m *MockAppRepo) CreateSecurityEvent(ctx context.Context, e *models.SecurityEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSecurityEvent", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSecurityEvent indicates an expected call of CreateSecurityEvent.
+func (mr *MockAppRepoMockRecorder) CreateSecurityEvent(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecurityEvent", reflect.TypeOf((*MockAppRepo)(nil).CreateSecurityEvent), ctx, e)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockAppRepo) CreateServiceAccount(ctx context.Context, sa *models.ServiceAccount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, sa)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockAppRepoMockRecorder) CreateServiceAccount(ctx, sa any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockAppRepo)(nil).CreateServiceAccount), ctx, sa)
+}
+
+// CreateTOTP mocks base method.
+func (m *MockAppRepo) CreateTOTP(ctx context.Context, uid uuid.UUID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTOTP", ctx, uid, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTOTP indicates an expected call of CreateTOTP.
+func (mr *MockAppRepoMockRecorder) CreateTOTP(ctx, uid, secret any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTOTP", reflect.TypeOf((*MockAppRepo)(nil).CreateTOTP), ctx, uid, secret)
+}
+
 // CreateToken mocks base method.
-func (m *MockAppRepo) CreateToken(ctx context.Context, userID uuid.UUID, hashedT string, expiresAt time.Time, device *models.Device) error {
+func (m *MockAppRepo) CreateToken(ctx context.Context, userID uuid.UUID, hashedT string, expiresAt time.Time, remember bool, device *models.Device) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateToken", ctx, userID, hashedT, expiresAt, device)
+	ret := m.ctrl.Call(m, "CreateToken", ctx, userID, hashedT, expiresAt, remember, device)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateToken indicates an expected call of CreateToken.
-func (mr *MockAppRepoMockRecorder) CreateToken(ctx, userID, hashedT, expiresAt, device any) *gomock.Call {
+func (mr *MockAppRepoMockRecorder) CreateToken(ctx, userID, hashedT, expiresAt, remember, device any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockAppRepo)(nil).CreateToken), ctx, userID, hashedT, expiresAt, device)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockAppRepo)(nil).CreateToken), ctx, userID, hashedT, expiresAt, remember, device)
 }
 
 // CreateUser mocks base method.
@@ -74,6 +188,34 @@ func (mr *MockAppRepoMockRecorder) CreateUser(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAppRepo)(nil).CreateUser), ctx, req)
 }
 
+// CreateWebAuthnCredential mocks base method.
+func (m *MockAppRepo) CreateWebAuthnCredential(ctx context.Context, c *models.WebAuthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebAuthnCredential", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebAuthnCredential indicates an expected call of CreateWebAuthnCredential.
+func (mr *MockAppRepoMockRecorder) CreateWebAuthnCredential(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebAuthnCredential", reflect.TypeOf((*MockAppRepo)(nil).CreateWebAuthnCredential), ctx, c)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockAppRepo) DeleteAPIKey(ctx context.Context, saID, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, saID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockAppRepoMockRecorder) DeleteAPIKey(ctx, saID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockAppRepo)(nil).DeleteAPIKey), ctx, saID, id)
+}
+
 // DeleteDevice mocks base method.
 func (m *MockAppRepo) DeleteDevice(ctx context.Context, uid uuid.UUID, deviceID string) error {
 	m.ctrl.T.Helper()
@@ -88,6 +230,48 @@ func (mr *MockAppRepoMockRecorder) DeleteDevice(ctx, uid, deviceID any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDevice", reflect.TypeOf((*MockAppRepo)(nil).DeleteDevice), ctx, uid, deviceID)
 }
 
+// DeleteOAuthClient mocks base method.
+func (m *MockAppRepo) DeleteOAuthClient(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthClient", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthClient indicates an expected call of DeleteOAuthClient.
+func (mr *MockAppRepoMockRecorder) DeleteOAuthClient(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClient", reflect.TypeOf((*MockAppRepo)(nil).DeleteOAuthClient), ctx, id)
+}
+
+// DeleteServiceAccount mocks base method.
+func (m *MockAppRepo) DeleteServiceAccount(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteServiceAccount", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteServiceAccount indicates an expected call of DeleteServiceAccount.
+func (mr *MockAppRepoMockRecorder) DeleteServiceAccount(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServiceAccount", reflect.TypeOf((*MockAppRepo)(nil).DeleteServiceAccount), ctx, id)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockAppRepo) DeleteTOTP(ctx context.Context, uid uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockAppRepoMockRecorder) DeleteTOTP(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockAppRepo)(nil).DeleteTOTP), ctx, uid)
+}
+
 // DeleteUser mocks base method.
 func (m *MockAppRepo) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -102,6 +286,35 @@ func (mr *MockAppRepoMockRecorder) DeleteUser(ctx, userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAppRepo)(nil).DeleteUser), ctx, userID)
 }
 
+// DeleteWebAuthnCredential mocks base method.
+func (m *MockAppRepo) DeleteWebAuthnCredential(ctx context.Context, uid uuid.UUID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebAuthnCredential", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebAuthnCredential indicates an expected call of DeleteWebAuthnCredential.
+func (mr *MockAppRepoMockRecorder) DeleteWebAuthnCredential(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebAuthnCredential", reflect.TypeOf((*MockAppRepo)(nil).DeleteWebAuthnCredential), ctx, uid, id)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockAppRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", ctx, hash)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockAppRepoMockRecorder) GetAPIKeyByHash(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockAppRepo)(nil).GetAPIKeyByHash), ctx, hash)
+}
+
 // GetByDevice mocks base method.
 func (m *MockAppRepo) GetByDevice(ctx context.Context, userID uuid.UUID, deviceID string) (*models.RefreshToken, error) {
 	m.ctrl.T.Helper()
@@ -147,6 +360,96 @@ func (mr *MockAppRepoMockRecorder) GetDeviceByID(ctx, dID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceByID", reflect.TypeOf((*MockAppRepo)(nil).GetDeviceByID), ctx, dID)
 }
 
+// GetOAuthClient mocks base method.
+func (m *MockAppRepo) GetOAuthClient(ctx context.Context, id uuid.UUID) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthClient", ctx, id)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthClient indicates an expected call of GetOAuthClient.
+func (mr *MockAppRepoMockRecorder) GetOAuthClient(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthClient", reflect.TypeOf((*MockAppRepo)(nil).GetOAuthClient), ctx, id)
+}
+
+// GetOAuthConsent mocks base method.
+func (m *MockAppRepo) GetOAuthConsent(ctx context.Context, uid, clientID uuid.UUID) (*models.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthConsent", ctx, uid, clientID)
+	ret0, _ := ret[0].(*models.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthConsent indicates an expected call of GetOAuthConsent.
+func (mr *MockAppRepoMockRecorder) GetOAuthConsent(ctx, uid, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthConsent", reflect.TypeOf((*MockAppRepo)(nil).GetOAuthConsent), ctx, uid, clientID)
+}
+
+// GetOAuthToken mocks base method.
+func (m *MockAppRepo) GetOAuthToken(ctx context.Context, hashedT string) (*models.OAuthToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuthToken", ctx, hashedT)
+	ret0, _ := ret[0].(*models.OAuthToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuthToken indicates an expected call of GetOAuthToken.
+func (mr *MockAppRepoMockRecorder) GetOAuthToken(ctx, hashedT any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthToken", reflect.TypeOf((*MockAppRepo)(nil).GetOAuthToken), ctx, hashedT)
+}
+
+// GetServiceAccount mocks base method.
+func (m *MockAppRepo) GetServiceAccount(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceAccount", ctx, id)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceAccount indicates an expected call of GetServiceAccount.
+func (mr *MockAppRepoMockRecorder) GetServiceAccount(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceAccount", reflect.TypeOf((*MockAppRepo)(nil).GetServiceAccount), ctx, id)
+}
+
+// GetTOTP mocks base method.
+func (m *MockAppRepo) GetTOTP(ctx context.Context, uid uuid.UUID) (*models.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, uid)
+	ret0, _ := ret[0].(*models.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockAppRepoMockRecorder) GetTOTP(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockAppRepo)(nil).GetTOTP), ctx, uid)
+}
+
+// GetToken mocks base method.
+func (m *MockAppRepo) GetToken(ctx context.Context, userID uuid.UUID, hashedT string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetToken", ctx, userID, hashedT)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetToken indicates an expected call of GetToken.
+func (mr *MockAppRepoMockRecorder) GetToken(ctx, userID, hashedT any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetToken", reflect.TypeOf((*MockAppRepo)(nil).GetToken), ctx, userID, hashedT)
+}
+
 // GetUserByEmail mocks base method.
 func (m *MockAppRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -177,19 +480,34 @@ func (mr *MockAppRepoMockRecorder) GetUserByID(ctx, userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockAppRepo)(nil).GetUserByID), ctx, userID)
 }
 
-// IsTokenValid mocks base method.
-func (m *MockAppRepo) IsTokenValid(ctx context.Context, userID uuid.UUID, d *models.Device, token string) (bool, error) {
+// GetWebAuthnCredential mocks base method.
+func (m *MockAppRepo) GetWebAuthnCredential(ctx context.Context, id string) (*models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebAuthnCredential", ctx, id)
+	ret0, _ := ret[0].(*models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebAuthnCredential indicates an expected call of GetWebAuthnCredential.
+func (mr *MockAppRepoMockRecorder) GetWebAuthnCredential(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebAuthnCredential", reflect.TypeOf((*MockAppRepo)(nil).GetWebAuthnCredential), ctx, id)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAppRepo) ListAPIKeys(ctx context.Context, saID uuid.UUID) ([]models.APIKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsTokenValid", ctx, userID, d, token)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, saID)
+	ret0, _ := ret[0].([]models.APIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsTokenValid indicates an expected call of IsTokenValid.
-func (mr *MockAppRepoMockRecorder) IsTokenValid(ctx, userID, d, token any) *gomock.Call {
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAppRepoMockRecorder) ListAPIKeys(ctx, saID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenValid", reflect.TypeOf((*MockAppRepo)(nil).IsTokenValid), ctx, userID, d, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAppRepo)(nil).ListAPIKeys), ctx, saID)
 }
 
 // ListDevices mocks base method.
@@ -207,6 +525,66 @@ func (mr *MockAppRepoMockRecorder) ListDevices(ctx, uid any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDevices", reflect.TypeOf((*MockAppRepo)(nil).ListDevices), ctx, uid)
 }
 
+// ListOAuthClients mocks base method.
+func (m *MockAppRepo) ListOAuthClients(ctx context.Context) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthClients", ctx)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthClients indicates an expected call of ListOAuthClients.
+func (mr *MockAppRepoMockRecorder) ListOAuthClients(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthClients", reflect.TypeOf((*MockAppRepo)(nil).ListOAuthClients), ctx)
+}
+
+// ListRoles mocks base method.
+func (m *MockAppRepo) ListRoles(ctx context.Context) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockAppRepoMockRecorder) ListRoles(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockAppRepo)(nil).ListRoles), ctx)
+}
+
+// ListServiceAccounts mocks base method.
+func (m *MockAppRepo) ListServiceAccounts(ctx context.Context) ([]models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceAccounts", ctx)
+	ret0, _ := ret[0].([]models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAccounts indicates an expected call of ListServiceAccounts.
+func (mr *MockAppRepoMockRecorder) ListServiceAccounts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAccounts", reflect.TypeOf((*MockAppRepo)(nil).ListServiceAccounts), ctx)
+}
+
+// ListUserRoles mocks base method.
+func (m *MockAppRepo) ListUserRoles(ctx context.Context, uid uuid.UUID) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserRoles", ctx, uid)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserRoles indicates an expected call of ListUserRoles.
+func (mr *MockAppRepoMockRecorder) ListUserRoles(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserRoles", reflect.TypeOf((*MockAppRepo)(nil).ListUserRoles), ctx, uid)
+}
+
 // ListUsers mocks base method.
 func (m *MockAppRepo) ListUsers(ctx context.Context, page, size int, filters map[string]any) (*dto.PaginatedUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -222,6 +600,21 @@ func (mr *MockAppRepoMockRecorder) ListUsers(ctx, page, size, filters any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAppRepo)(nil).ListUsers), ctx, page, size, filters)
 }
 
+// ListWebAuthnCredentials mocks base method.
+func (m *MockAppRepo) ListWebAuthnCredentials(ctx context.Context, uid uuid.UUID) ([]models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebAuthnCredentials", ctx, uid)
+	ret0, _ := ret[0].([]models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebAuthnCredentials indicates an expected call of ListWebAuthnCredentials.
+func (mr *MockAppRepoMockRecorder) ListWebAuthnCredentials(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebAuthnCredentials", reflect.TypeOf((*MockAppRepo)(nil).ListWebAuthnCredentials), ctx, uid)
+}
+
 // RevokeAllTokens mocks base method.
 func (m *MockAppRepo) RevokeAllTokens(ctx context.Context, userID uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -250,65 +643,304 @@ func (mr *MockAppRepoMockRecorder) RevokeByDevice(ctx, userID, deviceID any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByDevice", reflect.TypeOf((*MockAppRepo)(nil).RevokeByDevice), ctx, userID, deviceID)
 }
 
-// UpdateDevice mocks base method.
-func (m *MockAppRepo) UpdateDevice(ctx context.Context, uid uuid.UUID, dID string, req *dto.UpdateDeviceRequest) error {
+// RevokeFamily mocks base method.
+func (m *MockAppRepo) RevokeFamily(ctx context.Context, token *models.RefreshToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateDevice", ctx, uid, dID, req)
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateDevice indicates an expected call of UpdateDevice.
-func (mr *MockAppRepoMockRecorder) UpdateDevice(ctx, uid, dID, req any) *gomock.Call {
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockAppRepoMockRecorder) RevokeFamily(ctx, token any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDevice", reflect.TypeOf((*MockAppRepo)(nil).UpdateDevice), ctx, uid, dID, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockAppRepo)(nil).RevokeFamily), ctx, token)
 }
 
-// UpdateUser mocks base method.
-func (m *MockAppRepo) UpdateUser(ctx context.Context, id uuid.UUID, req *dto.UpdateUserRequest) error {
+// RevokeOAuthToken mocks base method.
+func (m *MockAppRepo) RevokeOAuthToken(ctx context.Context, id uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", ctx, id, req)
+	ret := m.ctrl.Call(m, "RevokeOAuthToken", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockAppRepoMockRecorder) UpdateUser(ctx, id, req any) *gomock.Call {
+// RevokeOAuthToken indicates an expected call of RevokeOAuthToken.
+func (mr *MockAppRepoMockRecorder) RevokeOAuthToken(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAppRepo)(nil).UpdateUser), ctx, id, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthToken", reflect.TypeOf((*MockAppRepo)(nil).RevokeOAuthToken), ctx, id)
 }
 
-// MockAppCtrl is a mock of AppCtrl interface.
-type MockAppCtrl struct {
-	ctrl     *gomock.Controller
-	recorder *MockAppCtrlMockRecorder
-	isgomock struct{}
+// RevokeOAuthTokens mocks base method.
+func (m *MockAppRepo) RevokeOAuthTokens(ctx context.Context, uid, clientID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOAuthTokens", ctx, uid, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// MockAppCtrlMockRecorder is the mock recorder for MockAppCtrl.
-type MockAppCtrlMockRecorder struct {
-	mock *MockAppCtrl
+// RevokeOAuthTokens indicates an expected call of RevokeOAuthTokens.
+func (mr *MockAppRepoMockRecorder) RevokeOAuthTokens(ctx, uid, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOAuthTokens", reflect.TypeOf((*MockAppRepo)(nil).RevokeOAuthTokens), ctx, uid, clientID)
 }
 
-// NewMockAppCtrl creates a new mock instance.
-func NewMockAppCtrl(ctrl *gomock.Controller) *MockAppCtrl {
-	mock := &MockAppCtrl{ctrl: ctrl}
-	mock.recorder = &MockAppCtrlMockRecorder{mock}
-	return mock
+// RevokeOtherDevices mocks base method.
+func (m *MockAppRepo) RevokeOtherDevices(ctx context.Context, userID uuid.UUID, deviceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherDevices", ctx, userID, deviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockAppCtrl) EXPECT() *MockAppCtrlMockRecorder {
-	return m.recorder
+// RevokeOtherDevices indicates an expected call of RevokeOtherDevices.
+func (mr *MockAppRepoMockRecorder) RevokeOtherDevices(ctx, userID, deviceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherDevices", reflect.TypeOf((*MockAppRepo)(nil).RevokeOtherDevices), ctx, userID, deviceID)
 }
 
-// Authenticate mocks base method.
-func (m *MockAppCtrl) Authenticate(ctx context.Context, d *dto.DeviceRequest, req *dto.EmailAndPasswordRequest) (*dto.TokenPair, error) {
+// RevokeRole mocks base method.
+func (m *MockAppRepo) RevokeRole(ctx context.Context, uid uuid.UUID, role string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Authenticate", ctx, d, req)
-	ret0, _ := ret[0].(*dto.TokenPair)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, uid, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockAppRepoMockRecorder) RevokeRole(ctx, uid, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockAppRepo)(nil).RevokeRole), ctx, uid, role)
+}
+
+// RevokeUserOAuthTokens mocks base method.
+func (m *MockAppRepo) RevokeUserOAuthTokens(ctx context.Context, uid uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserOAuthTokens", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserOAuthTokens indicates an expected call of RevokeUserOAuthTokens.
+func (mr *MockAppRepoMockRecorder) RevokeUserOAuthTokens(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserOAuthTokens", reflect.TypeOf((*MockAppRepo)(nil).RevokeUserOAuthTokens), ctx, uid)
+}
+
+// RotateOAuthToken mocks base method.
+func (m *MockAppRepo) RotateOAuthToken(ctx context.Context, parent, t *models.OAuthToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateOAuthToken", ctx, parent, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateOAuthToken indicates an expected call of RotateOAuthToken.
+func (mr *MockAppRepoMockRecorder) RotateOAuthToken(ctx, parent, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateOAuthToken", reflect.TypeOf((*MockAppRepo)(nil).RotateOAuthToken), ctx, parent, t)
+}
+
+// RotateToken mocks base method.
+func (m *MockAppRepo) RotateToken(ctx context.Context, parent *models.RefreshToken, hashedT string, expiresAt time.Time, device *models.Device) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateToken", ctx, parent, hashedT, expiresAt, device)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateToken indicates an expected call of RotateToken.
+func (mr *MockAppRepoMockRecorder) RotateToken(ctx, parent, hashedT, expiresAt, device any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateToken", reflect.TypeOf((*MockAppRepo)(nil).RotateToken), ctx, parent, hashedT, expiresAt, device)
+}
+
+// SaveOAuthConsent mocks base method.
+func (m *MockAppRepo) SaveOAuthConsent(ctx context.Context, c *models.OAuthConsent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOAuthConsent", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOAuthConsent indicates an expected call of SaveOAuthConsent.
+func (mr *MockAppRepoMockRecorder) SaveOAuthConsent(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOAuthConsent", reflect.TypeOf((*MockAppRepo)(nil).SaveOAuthConsent), ctx, c)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockAppRepo) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockAppRepoMockRecorder) TouchAPIKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockAppRepo)(nil).TouchAPIKey), ctx, id)
+}
+
+// UpdateDevice mocks base method.
+func (m *MockAppRepo) UpdateDevice(ctx context.Context, uid uuid.UUID, dID string, req *dto.UpdateDeviceRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDevice", ctx, uid, dID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDevice indicates an expected call of UpdateDevice.
+func (mr *MockAppRepoMockRecorder) UpdateDevice(ctx, uid, dID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDevice", reflect.TypeOf((*MockAppRepo)(nil).UpdateDevice), ctx, uid, dID, req)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockAppRepo) UpdatePassword(ctx context.Context, id uuid.UUID, hashed string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, hashed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockAppRepoMockRecorder) UpdatePassword(ctx, id, hashed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockAppRepo)(nil).UpdatePassword), ctx, id, hashed)
+}
+
+// UpdateUser mocks base method.
+func (m *MockAppRepo) UpdateUser(ctx context.Context, id uuid.UUID, req *dto.UpdateUserRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", ctx, id, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockAppRepoMockRecorder) UpdateUser(ctx, id, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAppRepo)(nil).UpdateUser), ctx, id, req)
+}
+
+// UpdateWebAuthnSignCount mocks base method.
+func (m *MockAppRepo) UpdateWebAuthnSignCount(ctx context.Context, c *models.WebAuthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebAuthnSignCount", ctx, c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebAuthnSignCount indicates an expected call of UpdateWebAuthnSignCount.
+func (mr *MockAppRepoMockRecorder) UpdateWebAuthnSignCount(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebAuthnSignCount", reflect.TypeOf((*MockAppRepo)(nil).UpdateWebAuthnSignCount), ctx, c)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockAppRepo) UseRecoveryCode(ctx context.Context, uid uuid.UUID, hashedCode string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, uid, hashedCode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockAppRepoMockRecorder) UseRecoveryCode(ctx, uid, hashedCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockAppRepo)(nil).UseRecoveryCode), ctx, uid, hashedCode)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockAppRepo) UseTOTPStep(ctx context.Context, uid uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, uid, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockAppRepoMockRecorder) UseTOTPStep(ctx, uid, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockAppRepo)(nil).UseTOTPStep), ctx, uid, step)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAppRepo) VerifyEmail(ctx context.Context, id uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, id, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAppRepoMockRecorder) VerifyEmail(ctx, id, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAppRepo)(nil).VerifyEmail), ctx, id, email)
+}
+
+// MockAppCtrl is a mock of AppCtrl interface.
+type MockAppCtrl struct {
+	ctrl     *gomock.Controller
+	recorder *MockAppCtrlMockRecorder
+	isgomock struct{}
+}
+
+// MockAppCtrlMockRecorder is the mock recorder for MockAppCtrl.
+type MockAppCtrlMockRecorder struct {
+	mock *MockAppCtrl
+}
+
+// NewMockAppCtrl creates a new mock instance.
+func NewMockAppCtrl(ctrl *gomock.Controller) *MockAppCtrl {
+	mock := &MockAppCtrl{ctrl: ctrl}
+	mock.recorder = &MockAppCtrlMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAppCtrl) EXPECT() *MockAppCtrlMockRecorder {
+	return m.recorder
+}
+
+// ApproveDevice mocks base method.
+func (m *MockAppCtrl) ApproveDevice(ctx context.Context, uid uuid.UUID, req *dto.DeviceApprovalRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveDevice", ctx, uid, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveDevice indicates an expected call of ApproveDevice.
+func (mr *MockAppCtrlMockRecorder) ApproveDevice(ctx, uid, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveDevice", reflect.TypeOf((*MockAppCtrl)(nil).ApproveDevice), ctx, uid, req)
+}
+
+// AssignRole mocks base method.
+func (m *MockAppCtrl) AssignRole(ctx context.Context, uid uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, uid, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignRole indicates an expected call of AssignRole.
+func (mr *MockAppCtrlMockRecorder) AssignRole(ctx, uid, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAppCtrl)(nil).AssignRole), ctx, uid, role)
+}
+
+// Authenticate mocks base method.
+func (m *MockAppCtrl) Authenticate(ctx context.Context, d *dto.DeviceRequest, req *dto.EmailAndPasswordRequest) (*dto.TokenPair, *dto.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, d, req)
+	ret0, _ := ret[0].(*dto.TokenPair)
+	ret1, _ := ret[1].(*dto.TwoFactorChallenge)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Authenticate indicates an expected call of Authenticate.
@@ -317,6 +949,171 @@ func (mr *MockAppCtrlMockRecorder) Authenticate(ctx, d, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAppCtrl)(nil).Authenticate), ctx, d, req)
 }
 
+// AuthenticateAPIKey mocks base method.
+func (m *MockAppCtrl) AuthenticateAPIKey(ctx context.Context, key string) (jwt.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, key)
+	ret0, _ := ret[0].(jwt.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockAppCtrlMockRecorder) AuthenticateAPIKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockAppCtrl)(nil).AuthenticateAPIKey), ctx, key)
+}
+
+// Authorize mocks base method.
+func (m *MockAppCtrl) Authorize(ctx context.Context, uid uuid.UUID, req *dto.AuthorizeRequest) (*dto.AuthorizeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, uid, req)
+	ret0, _ := ret[0].(*dto.AuthorizeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAppCtrlMockRecorder) Authorize(ctx, uid, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAppCtrl)(nil).Authorize), ctx, uid, req)
+}
+
+// BeginWebAuthnLogin mocks base method.
+func (m *MockAppCtrl) BeginWebAuthnLogin(ctx context.Context, req *dto.WebAuthnLoginRequest) (*webauthn.RequestOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginWebAuthnLogin", ctx, req)
+	ret0, _ := ret[0].(*webauthn.RequestOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginWebAuthnLogin indicates an expected call of BeginWebAuthnLogin.
+func (mr *MockAppCtrlMockRecorder) BeginWebAuthnLogin(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginWebAuthnLogin", reflect.TypeOf((*MockAppCtrl)(nil).BeginWebAuthnLogin), ctx, req)
+}
+
+// BeginWebAuthnRegistration mocks base method.
+func (m *MockAppCtrl) BeginWebAuthnRegistration(ctx context.Context, uid uuid.UUID) (*webauthn.CreationOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginWebAuthnRegistration", ctx, uid)
+	ret0, _ := ret[0].(*webauthn.CreationOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginWebAuthnRegistration indicates an expected call of BeginWebAuthnRegistration.
+func (mr *MockAppCtrlMockRecorder) BeginWebAuthnRegistration(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginWebAuthnRegistration", reflect.TypeOf((*MockAppCtrl)(nil).BeginWebAuthnRegistration), ctx, uid)
+}
+
+// CheckForgotPasswordEmail mocks base method.
+func (m *MockAppCtrl) CheckForgotPasswordEmail(ctx context.Context, req *dto.CheckForgotPasswordEmailRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckForgotPasswordEmail", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckForgotPasswordEmail indicates an expected call of CheckForgotPasswordEmail.
+func (mr *MockAppCtrlMockRecorder) CheckForgotPasswordEmail(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckForgotPasswordEmail", reflect.TypeOf((*MockAppCtrl)(nil).CheckForgotPasswordEmail), ctx, req)
+}
+
+// CheckLoginCode mocks base method.
+func (m *MockAppCtrl) CheckLoginCode(ctx context.Context, d *dto.DeviceRequest, req *dto.CheckLoginCodeRequest) (*dto.TokenPair, *dto.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLoginCode", ctx, d, req)
+	ret0, _ := ret[0].(*dto.TokenPair)
+	ret1, _ := ret[1].(*dto.TwoFactorChallenge)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CheckLoginCode indicates an expected call of CheckLoginCode.
+func (mr *MockAppCtrlMockRecorder) CheckLoginCode(ctx, d, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLoginCode", reflect.TypeOf((*MockAppCtrl)(nil).CheckLoginCode), ctx, d, req)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockAppCtrl) ConfirmTOTP(ctx context.Context, uid uuid.UUID, req *dto.TOTPCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, uid, req)
+	ret0, _ := ret[0].(*dto.RecoveryCodesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockAppCtrlMockRecorder) ConfirmTOTP(ctx, uid, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockAppCtrl)(nil).ConfirmTOTP), ctx, uid, req)
+}
+
+// Consent mocks base method.
+func (m *MockAppCtrl) Consent(ctx context.Context, uid uuid.UUID, req *dto.ConsentRequest) (*dto.AuthorizeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consent", ctx, uid, req)
+	ret0, _ := ret[0].(*dto.AuthorizeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consent indicates an expected call of Consent.
+func (mr *MockAppCtrlMockRecorder) Consent(ctx, uid, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consent", reflect.TypeOf((*MockAppCtrl)(nil).Consent), ctx, uid, req)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAppCtrl) CreateAPIKey(ctx context.Context, caller jwt.Claims, saID uuid.UUID, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, caller, saID, req)
+	ret0, _ := ret[0].(*dto.CreateAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAppCtrlMockRecorder) CreateAPIKey(ctx, caller, saID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAppCtrl)(nil).CreateAPIKey), ctx, caller, saID, req)
+}
+
+// CreateOAuthClient mocks base method.
+func (m *MockAppCtrl) CreateOAuthClient(ctx context.Context, req *dto.CreateOAuthClientRequest) (*dto.CreateOAuthClientResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOAuthClient", ctx, req)
+	ret0, _ := ret[0].(*dto.CreateOAuthClientResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOAuthClient indicates an expected call of CreateOAuthClient.
+func (mr *MockAppCtrlMockRecorder) CreateOAuthClient(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOAuthClient", reflect.TypeOf((*MockAppCtrl)(nil).CreateOAuthClient), ctx, req)
+}
+
+// CreateServiceAccount mocks base method.
+func (m *MockAppCtrl) CreateServiceAccount(ctx context.Context, req *dto.CreateServiceAccountRequest) (*models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateServiceAccount", ctx, req)
+	ret0, _ := ret[0].(*models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateServiceAccount indicates an expected call of CreateServiceAccount.
+func (mr *MockAppCtrlMockRecorder) CreateServiceAccount(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateServiceAccount", reflect.TypeOf((*MockAppCtrl)(nil).CreateServiceAccount), ctx, req)
+}
+
 // CreateUser mocks base method.
 func (m *MockAppCtrl) CreateUser(ctx context.Context, u *dto.CreateUserRequest, file *s3.UploadFileRequest) (*dto.CreateUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -332,6 +1129,20 @@ func (mr *MockAppCtrlMockRecorder) CreateUser(ctx, u, file any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAppCtrl)(nil).CreateUser), ctx, u, file)
 }
 
+// DeleteAPIKey mocks base method.
+func (m *MockAppCtrl) DeleteAPIKey(ctx context.Context, saID, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, saID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockAppCtrlMockRecorder) DeleteAPIKey(ctx, saID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockAppCtrl)(nil).DeleteAPIKey), ctx, saID, id)
+}
+
 // DeleteDevice mocks base method.
 func (m *MockAppCtrl) DeleteDevice(ctx context.Context, uid uuid.UUID, dID string) error {
 	m.ctrl.T.Helper()
@@ -346,33 +1157,164 @@ func (mr *MockAppCtrlMockRecorder) DeleteDevice(ctx, uid, dID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDevice", reflect.TypeOf((*MockAppCtrl)(nil).DeleteDevice), ctx, uid, dID)
 }
 
-// DeleteUser mocks base method.
-func (m *MockAppCtrl) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+// DeleteOAuthClient mocks base method.
+func (m *MockAppCtrl) DeleteOAuthClient(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuthClient", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuthClient indicates an expected call of DeleteOAuthClient.
+func (mr *MockAppCtrlMockRecorder) DeleteOAuthClient(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuthClient", reflect.TypeOf((*MockAppCtrl)(nil).DeleteOAuthClient), ctx, id)
+}
+
+// DeleteServiceAccount mocks base method.
+func (m *MockAppCtrl) DeleteServiceAccount(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteServiceAccount", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteServiceAccount indicates an expected call of DeleteServiceAccount.
+func (mr *MockAppCtrlMockRecorder) DeleteServiceAccount(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServiceAccount", reflect.TypeOf((*MockAppCtrl)(nil).DeleteServiceAccount), ctx, id)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAppCtrl) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAppCtrlMockRecorder) DeleteUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAppCtrl)(nil).DeleteUser), ctx, userID)
+}
+
+// DeleteWebAuthnCredential mocks base method.
+func (m *MockAppCtrl) DeleteWebAuthnCredential(ctx context.Context, uid uuid.UUID, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebAuthnCredential", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebAuthnCredential indicates an expected call of DeleteWebAuthnCredential.
+func (mr *MockAppCtrlMockRecorder) DeleteWebAuthnCredential(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebAuthnCredential", reflect.TypeOf((*MockAppCtrl)(nil).DeleteWebAuthnCredential), ctx, uid, id)
+}
+
+// DeviceAuthorization mocks base method.
+func (m *MockAppCtrl) DeviceAuthorization(ctx context.Context, req *dto.DeviceCodeRequest) (*dto.DeviceCodeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeviceAuthorization", ctx, req)
+	ret0, _ := ret[0].(*dto.DeviceCodeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeviceAuthorization indicates an expected call of DeviceAuthorization.
+func (mr *MockAppCtrlMockRecorder) DeviceAuthorization(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeviceAuthorization", reflect.TypeOf((*MockAppCtrl)(nil).DeviceAuthorization), ctx, req)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockAppCtrl) DisableTOTP(ctx context.Context, uid uuid.UUID, req *dto.SecondFactorRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, uid, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockAppCtrlMockRecorder) DisableTOTP(ctx, uid, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockAppCtrl)(nil).DisableTOTP), ctx, uid, req)
+}
+
+// EndSession mocks base method.
+func (m *MockAppCtrl) EndSession(ctx context.Context, req *dto.EndSessionRequest, deviceID string) (*dto.EndSessionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndSession", ctx, req, deviceID)
+	ret0, _ := ret[0].(*dto.EndSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndSession indicates an expected call of EndSession.
+func (mr *MockAppCtrlMockRecorder) EndSession(ctx, req, deviceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndSession", reflect.TypeOf((*MockAppCtrl)(nil).EndSession), ctx, req, deviceID)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockAppCtrl) EnrollTOTP(ctx context.Context, uid uuid.UUID) (*dto.TOTPEnrollResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx, uid)
+	ret0, _ := ret[0].(*dto.TOTPEnrollResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockAppCtrlMockRecorder) EnrollTOTP(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockAppCtrl)(nil).EnrollTOTP), ctx, uid)
+}
+
+// FinishWebAuthnLogin mocks base method.
+func (m *MockAppCtrl) FinishWebAuthnLogin(ctx context.Context, d *dto.DeviceRequest, req *dto.FinishWebAuthnLoginRequest) (*dto.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishWebAuthnLogin", ctx, d, req)
+	ret0, _ := ret[0].(*dto.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishWebAuthnLogin indicates an expected call of FinishWebAuthnLogin.
+func (mr *MockAppCtrlMockRecorder) FinishWebAuthnLogin(ctx, d, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishWebAuthnLogin", reflect.TypeOf((*MockAppCtrl)(nil).FinishWebAuthnLogin), ctx, d, req)
+}
+
+// FinishWebAuthnRegistration mocks base method.
+func (m *MockAppCtrl) FinishWebAuthnRegistration(ctx context.Context, uid uuid.UUID, d *dto.DeviceRequest, req *dto.WebAuthnRegistrationRequest) (*models.WebAuthnCredential, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", ctx, userID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "FinishWebAuthnRegistration", ctx, uid, d, req)
+	ret0, _ := ret[0].(*models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockAppCtrlMockRecorder) DeleteUser(ctx, userID any) *gomock.Call {
+// FinishWebAuthnRegistration indicates an expected call of FinishWebAuthnRegistration.
+func (mr *MockAppCtrlMockRecorder) FinishWebAuthnRegistration(ctx, uid, d, req any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAppCtrl)(nil).DeleteUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishWebAuthnRegistration", reflect.TypeOf((*MockAppCtrl)(nil).FinishWebAuthnRegistration), ctx, uid, d, req)
 }
 
 // GenPair mocks base method.
-func (m *MockAppCtrl) GenPair(ctx context.Context, d *dto.DeviceRequest, uid uuid.UUID) (dto.TokenPair, error) {
+func (m *MockAppCtrl) GenPair(ctx context.Context, d *dto.DeviceRequest, uid uuid.UUID, remember bool) (dto.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenPair", ctx, d, uid)
+	ret := m.ctrl.Call(m, "GenPair", ctx, d, uid, remember)
 	ret0, _ := ret[0].(dto.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenPair indicates an expected call of GenPair.
-func (mr *MockAppCtrlMockRecorder) GenPair(ctx, d, uid any) *gomock.Call {
+func (mr *MockAppCtrlMockRecorder) GenPair(ctx, d, uid, remember any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenPair", reflect.TypeOf((*MockAppCtrl)(nil).GenPair), ctx, d, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenPair", reflect.TypeOf((*MockAppCtrl)(nil).GenPair), ctx, d, uid, remember)
 }
 
 // GetDevice mocks base method.
@@ -405,6 +1347,21 @@ func (mr *MockAppCtrlMockRecorder) GetDeviceByID(ctx, dID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceByID", reflect.TypeOf((*MockAppCtrl)(nil).GetDeviceByID), ctx, dID)
 }
 
+// GetDeviceConsent mocks base method.
+func (m *MockAppCtrl) GetDeviceConsent(ctx context.Context, uid uuid.UUID, userCode string) (*dto.DeviceConsentResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceConsent", ctx, uid, userCode)
+	ret0, _ := ret[0].(*dto.DeviceConsentResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceConsent indicates an expected call of GetDeviceConsent.
+func (mr *MockAppCtrlMockRecorder) GetDeviceConsent(ctx, uid, userCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceConsent", reflect.TypeOf((*MockAppCtrl)(nil).GetDeviceConsent), ctx, uid, userCode)
+}
+
 // GetUserByEmail mocks base method.
 func (m *MockAppCtrl) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -435,6 +1392,36 @@ func (mr *MockAppCtrlMockRecorder) GetUserByID(ctx, userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockAppCtrl)(nil).GetUserByID), ctx, userID)
 }
 
+// Introspect mocks base method.
+func (m *MockAppCtrl) Introspect(ctx context.Context, req *dto.IntrospectRequest) (*dto.IntrospectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Introspect", ctx, req)
+	ret0, _ := ret[0].(*dto.IntrospectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Introspect indicates an expected call of Introspect.
+func (mr *MockAppCtrlMockRecorder) Introspect(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Introspect", reflect.TypeOf((*MockAppCtrl)(nil).Introspect), ctx, req)
+}
+
+// IntrospectToken mocks base method.
+func (m *MockAppCtrl) IntrospectToken(ctx context.Context, token string) (*dto.IntrospectionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IntrospectToken", ctx, token)
+	ret0, _ := ret[0].(*dto.IntrospectionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IntrospectToken indicates an expected call of IntrospectToken.
+func (mr *MockAppCtrlMockRecorder) IntrospectToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IntrospectToken", reflect.TypeOf((*MockAppCtrl)(nil).IntrospectToken), ctx, token)
+}
+
 // IsUserExist mocks base method.
 func (m *MockAppCtrl) IsUserExist(ctx context.Context, email string) (*dto.ExistsUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -450,6 +1437,21 @@ func (mr *MockAppCtrlMockRecorder) IsUserExist(ctx, email any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUserExist", reflect.TypeOf((*MockAppCtrl)(nil).IsUserExist), ctx, email)
 }
 
+// ListAPIKeys mocks base method.
+func (m *MockAppCtrl) ListAPIKeys(ctx context.Context, saID uuid.UUID) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, saID)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAppCtrlMockRecorder) ListAPIKeys(ctx, saID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAppCtrl)(nil).ListAPIKeys), ctx, saID)
+}
+
 // ListDevices mocks base method.
 func (m *MockAppCtrl) ListDevices(ctx context.Context, uid uuid.UUID) ([]models.Device, error) {
 	m.ctrl.T.Helper()
@@ -465,6 +1467,66 @@ func (mr *MockAppCtrlMockRecorder) ListDevices(ctx, uid any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDevices", reflect.TypeOf((*MockAppCtrl)(nil).ListDevices), ctx, uid)
 }
 
+// ListOAuthClients mocks base method.
+func (m *MockAppCtrl) ListOAuthClients(ctx context.Context) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOAuthClients", ctx)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOAuthClients indicates an expected call of ListOAuthClients.
+func (mr *MockAppCtrlMockRecorder) ListOAuthClients(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOAuthClients", reflect.TypeOf((*MockAppCtrl)(nil).ListOAuthClients), ctx)
+}
+
+// ListRoles mocks base method.
+func (m *MockAppCtrl) ListRoles(ctx context.Context) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockAppCtrlMockRecorder) ListRoles(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockAppCtrl)(nil).ListRoles), ctx)
+}
+
+// ListServiceAccounts mocks base method.
+func (m *MockAppCtrl) ListServiceAccounts(ctx context.Context) ([]models.ServiceAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListServiceAccounts", ctx)
+	ret0, _ := ret[0].([]models.ServiceAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListServiceAccounts indicates an expected call of ListServiceAccounts.
+func (mr *MockAppCtrlMockRecorder) ListServiceAccounts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListServiceAccounts", reflect.TypeOf((*MockAppCtrl)(nil).ListServiceAccounts), ctx)
+}
+
+// ListUserRoles mocks base method.
+func (m *MockAppCtrl) ListUserRoles(ctx context.Context, uid uuid.UUID) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserRoles", ctx, uid)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserRoles indicates an expected call of ListUserRoles.
+func (mr *MockAppCtrlMockRecorder) ListUserRoles(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserRoles", reflect.TypeOf((*MockAppCtrl)(nil).ListUserRoles), ctx, uid)
+}
+
 // ListUsers mocks base method.
 func (m *MockAppCtrl) ListUsers(ctx context.Context, page, size int, filters map[string]any) (*dto.PaginatedUserResponse, error) {
 	m.ctrl.T.Helper()
@@ -480,18 +1542,61 @@ func (mr *MockAppCtrlMockRecorder) ListUsers(ctx, page, size, filters any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockAppCtrl)(nil).ListUsers), ctx, page, size, filters)
 }
 
+// ListWebAuthnCredentials mocks base method.
+func (m *MockAppCtrl) ListWebAuthnCredentials(ctx context.Context, uid uuid.UUID) ([]models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebAuthnCredentials", ctx, uid)
+	ret0, _ := ret[0].([]models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebAuthnCredentials indicates an expected call of ListWebAuthnCredentials.
+func (mr *MockAppCtrlMockRecorder) ListWebAuthnCredentials(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebAuthnCredentials", reflect.TypeOf((*MockAppCtrl)(nil).ListWebAuthnCredentials), ctx, uid)
+}
+
 // Logout mocks base method.
-func (m *MockAppCtrl) Logout(ctx context.Context, uid uuid.UUID) error {
+func (m *MockAppCtrl) Logout(ctx context.Context, claims jwt.Claims, deviceID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Logout", ctx, uid)
+	ret := m.ctrl.Call(m, "Logout", ctx, claims, deviceID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Logout indicates an expected call of Logout.
-func (mr *MockAppCtrlMockRecorder) Logout(ctx, uid any) *gomock.Call {
+func (mr *MockAppCtrlMockRecorder) Logout(ctx, claims, deviceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAppCtrl)(nil).Logout), ctx, claims, deviceID)
+}
+
+// LogoutAll mocks base method.
+func (m *MockAppCtrl) LogoutAll(ctx context.Context, uid uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockAppCtrlMockRecorder) LogoutAll(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockAppCtrl)(nil).LogoutAll), ctx, uid)
+}
+
+// LogoutOthers mocks base method.
+func (m *MockAppCtrl) LogoutOthers(ctx context.Context, uid uuid.UUID, deviceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutOthers", ctx, uid, deviceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutOthers indicates an expected call of LogoutOthers.
+func (mr *MockAppCtrlMockRecorder) LogoutOthers(ctx, uid, deviceID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAppCtrl)(nil).Logout), ctx, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutOthers", reflect.TypeOf((*MockAppCtrl)(nil).LogoutOthers), ctx, uid, deviceID)
 }
 
 // Refresh mocks base method.
@@ -509,6 +1614,119 @@ func (mr *MockAppCtrlMockRecorder) Refresh(ctx, d, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAppCtrl)(nil).Refresh), ctx, d, req)
 }
 
+// RevokeClientToken mocks base method.
+func (m *MockAppCtrl) RevokeClientToken(ctx context.Context, req *dto.RevokeTokenRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeClientToken", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeClientToken indicates an expected call of RevokeClientToken.
+func (mr *MockAppCtrlMockRecorder) RevokeClientToken(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeClientToken", reflect.TypeOf((*MockAppCtrl)(nil).RevokeClientToken), ctx, req)
+}
+
+// RevokeRole mocks base method.
+func (m *MockAppCtrl) RevokeRole(ctx context.Context, uid uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, uid, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockAppCtrlMockRecorder) RevokeRole(ctx, uid, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockAppCtrl)(nil).RevokeRole), ctx, uid, role)
+}
+
+// RevokeToken mocks base method.
+func (m *MockAppCtrl) RevokeToken(ctx context.Context, caller jwt.Claims, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, caller, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockAppCtrlMockRecorder) RevokeToken(ctx, caller, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockAppCtrl)(nil).RevokeToken), ctx, caller, token)
+}
+
+// SendForgotPasswordEmail mocks base method.
+func (m *MockAppCtrl) SendForgotPasswordEmail(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendForgotPasswordEmail", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendForgotPasswordEmail indicates an expected call of SendForgotPasswordEmail.
+func (mr *MockAppCtrlMockRecorder) SendForgotPasswordEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendForgotPasswordEmail", reflect.TypeOf((*MockAppCtrl)(nil).SendForgotPasswordEmail), ctx, email)
+}
+
+// SendLoginCode mocks base method.
+func (m *MockAppCtrl) SendLoginCode(ctx context.Context, ip string, req *dto.LoginCodeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLoginCode", ctx, ip, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendLoginCode indicates an expected call of SendLoginCode.
+func (mr *MockAppCtrlMockRecorder) SendLoginCode(ctx, ip, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLoginCode", reflect.TypeOf((*MockAppCtrl)(nil).SendLoginCode), ctx, ip, req)
+}
+
+// SendVerificationEmail mocks base method.
+func (m *MockAppCtrl) SendVerificationEmail(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerificationEmail", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerificationEmail indicates an expected call of SendVerificationEmail.
+func (mr *MockAppCtrlMockRecorder) SendVerificationEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerificationEmail", reflect.TypeOf((*MockAppCtrl)(nil).SendVerificationEmail), ctx, email)
+}
+
+// Token mocks base method.
+func (m *MockAppCtrl) Token(ctx context.Context, req *dto.OAuthTokenRequest) (*dto.OAuthTokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token", ctx, req)
+	ret0, _ := ret[0].(*dto.OAuthTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockAppCtrlMockRecorder) Token(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockAppCtrl)(nil).Token), ctx, req)
+}
+
+// UnlockUser mocks base method.
+func (m *MockAppCtrl) UnlockUser(ctx context.Context, uid uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockAppCtrlMockRecorder) UnlockUser(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockAppCtrl)(nil).UnlockUser), ctx, uid)
+}
+
 // UpdateDevice mocks base method.
 func (m *MockAppCtrl) UpdateDevice(ctx context.Context, uid uuid.UUID, dID string, req *dto.UpdateDeviceRequest) error {
 	m.ctrl.T.Helper()
@@ -537,6 +1755,50 @@ func (mr *MockAppCtrlMockRecorder) UpdateUser(ctx, id, req, file any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAppCtrl)(nil).UpdateUser), ctx, id, req, file)
 }
 
+// UserInfo mocks base method.
+func (m *MockAppCtrl) UserInfo(ctx context.Context, uid uuid.UUID, scope string) (*dto.UserInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserInfo", ctx, uid, scope)
+	ret0, _ := ret[0].(*dto.UserInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserInfo indicates an expected call of UserInfo.
+func (mr *MockAppCtrlMockRecorder) UserInfo(ctx, uid, scope any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserInfo", reflect.TypeOf((*MockAppCtrl)(nil).UserInfo), ctx, uid, scope)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAppCtrl) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAppCtrlMockRecorder) VerifyEmail(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAppCtrl)(nil).VerifyEmail), ctx, req)
+}
+
+// VerifyTwoFactor mocks base method.
+func (m *MockAppCtrl) VerifyTwoFactor(ctx context.Context, d *dto.DeviceRequest, req *dto.VerifyTwoFactorRequest) (*dto.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTwoFactor", ctx, d, req)
+	ret0, _ := ret[0].(*dto.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTwoFactor indicates an expected call of VerifyTwoFactor.
+func (mr *MockAppCtrlMockRecorder) VerifyTwoFactor(ctx, d, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTwoFactor", reflect.TypeOf((*MockAppCtrl)(nil).VerifyTwoFactor), ctx, d, req)
+}
+
 // MockS3Service is a mock of S3Service interface.
 type MockS3Service struct {
 	ctrl     *gomock.Controller
@@ -626,6 +1888,20 @@ func (mr *MockCacheServiceMockRecorder) Delete(ctx, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCacheService)(nil).Delete), ctx, key)
 }
 
+// GetDelToStruct mocks base method.
+func (m *MockCacheService) GetDelToStruct(ctx context.Context, key string, dest any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelToStruct", ctx, key, dest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetDelToStruct indicates an expected call of GetDelToStruct.
+func (mr *MockCacheServiceMockRecorder) GetDelToStruct(ctx, key, dest any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelToStruct", reflect.TypeOf((*MockCacheService)(nil).GetDelToStruct), ctx, key, dest)
+}
+
 // GetToStruct mocks base method.
 func (m *MockCacheService) GetToStruct(ctx context.Context, key string, dest any) error {
 	m.ctrl.T.Helper()
@@ -640,6 +1916,21 @@ func (mr *MockCacheServiceMockRecorder) GetToStruct(ctx, key, dest any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetToStruct", reflect.TypeOf((*MockCacheService)(nil).GetToStruct), ctx, key, dest)
 }
 
+// Incr mocks base method.
+func (m *MockCacheService) Incr(ctx context.Context, t time.Duration, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", ctx, t, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockCacheServiceMockRecorder) Incr(ctx, t, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockCacheService)(nil).Incr), ctx, t, key)
+}
+
 // InvalidateKeysByPattern mocks base method.
 func (m *MockCacheService) InvalidateKeysByPattern(ctx context.Context, pattern string) {
 	m.ctrl.T.Helper()
@@ -663,3 +1954,97 @@ func (mr *MockCacheServiceMockRecorder) Set(ctx, t, key, val any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCacheService)(nil).Set), ctx, t, key, val)
 }
+
+// MockEmailService is a mock of EmailService interface.
+type MockEmailService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockEmailServiceMockRecorder is the mock recorder for MockEmailService.
+type MockEmailServiceMockRecorder struct {
+	mock *MockEmailService
+}
+
+// NewMockEmailService creates a new mock instance.
+func NewMockEmailService(ctrl *gomock.Controller) *MockEmailService {
+	mock := &MockEmailService{ctrl: ctrl}
+	mock.recorder = &MockEmailServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailService) EXPECT() *MockEmailServiceMockRecorder {
+	return m.recorder
+}
+
+// SendAccountLockedEmail mocks base method.
+func (m *MockEmailService) SendAccountLockedEmail(ctx context.Context, ip string, until time.Time, toEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAccountLockedEmail", ctx, ip, until, toEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAccountLockedEmail indicates an expected call of SendAccountLockedEmail.
+func (mr *MockEmailServiceMockRecorder) SendAccountLockedEmail(ctx, ip, until, toEmail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAccountLockedEmail", reflect.TypeOf((*MockEmailService)(nil).SendAccountLockedEmail), ctx, ip, until, toEmail)
+}
+
+// SendForgotPasswordEmail mocks base method.
+func (m *MockEmailService) SendForgotPasswordEmail(ctx context.Context, code int, uid uuid.UUID, toEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendForgotPasswordEmail", ctx, code, uid, toEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendForgotPasswordEmail indicates an expected call of SendForgotPasswordEmail.
+func (mr *MockEmailServiceMockRecorder) SendForgotPasswordEmail(ctx, code, uid, toEmail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendForgotPasswordEmail", reflect.TypeOf((*MockEmailService)(nil).SendForgotPasswordEmail), ctx, code, uid, toEmail)
+}
+
+// SendLoginCodeEmail mocks base method.
+func (m *MockEmailService) SendLoginCodeEmail(ctx context.Context, code int, toEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLoginCodeEmail", ctx, code, toEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendLoginCodeEmail indicates an expected call of SendLoginCodeEmail.
+func (mr *MockEmailServiceMockRecorder) SendLoginCodeEmail(ctx, code, toEmail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLoginCodeEmail", reflect.TypeOf((*MockEmailService)(nil).SendLoginCodeEmail), ctx, code, toEmail)
+}
+
+// SendNewDeviceEmail mocks base method.
+func (m *MockEmailService) SendNewDeviceEmail(ctx context.Context, d *models.Device, toEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendNewDeviceEmail", ctx, d, toEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendNewDeviceEmail indicates an expected call of SendNewDeviceEmail.
+func (mr *MockEmailServiceMockRecorder) SendNewDeviceEmail(ctx, d, toEmail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendNewDeviceEmail", reflect.TypeOf((*MockEmailService)(nil).SendNewDeviceEmail), ctx, d, toEmail)
+}
+
+// SendVerificationEmail mocks base method.
+func (m *MockEmailService) SendVerificationEmail(ctx context.Context, code int, uid uuid.UUID, toEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerificationEmail", ctx, code, uid, toEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerificationEmail indicates an expected call of SendVerificationEmail.
+func (mr *MockEmailServiceMockRecorder) SendVerificationEmail(ctx, code, uid, toEmail any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerificationEmail", reflect.TypeOf((*MockEmailService)(nil).SendVerificationEmail), ctx, code, uid, toEmail)
+}